@@ -0,0 +1,26 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// SPDX-License-Identifier: MIT
+
+pragma solidity >=0.8.0;
+
+// IAllowList is the interface of the allow list precompiles. Roles are
+// 0 (none), 1 (enabled) and 2 (admin). Only admins can modify the allow list.
+interface IAllowList {
+  // Emitted when an admin sets the role of [account] from [oldRole] to [role].
+  // Not emitted by the contract deployer allow list.
+  event RoleSet(uint256 indexed role, address indexed account, address indexed sender, uint256 oldRole);
+
+  // Set [addr] to have the admin role over the allow list
+  function setAdmin(address addr) external;
+
+  // Set [addr] to be enabled on the allow list
+  function setEnabled(address addr) external;
+
+  // Set [addr] to have no role over the allow list
+  function setNone(address addr) external;
+
+  // Read the role of [addr]
+  function readAllowList(address addr) external view returns (uint256 role);
+}
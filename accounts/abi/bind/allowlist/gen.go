@@ -0,0 +1,7 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package allowlist contains the Go bindings of the allow list precompiles.
+package allowlist
+
+//go:generate go run ../../../../cmd/abigen --abi IAllowList.abi --pkg allowlist --type IAllowList --out allowlist.go
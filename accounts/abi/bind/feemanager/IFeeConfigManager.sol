@@ -0,0 +1,53 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// SPDX-License-Identifier: MIT
+
+pragma solidity >=0.8.0;
+
+import "../allowlist/IAllowList.sol";
+
+// IFeeConfigManager is the interface of the fee config manager precompile.
+// Enabled addresses can change the fee config, which takes effect from the
+// block following the change.
+interface IFeeConfigManager is IAllowList {
+  // Emitted when [sender] changes the fee config
+  event FeeConfigChanged(
+    address indexed sender,
+    uint256 targetGas,
+    uint256 minBaseFee,
+    uint256 baseFeeChangeDenominator,
+    uint256 targetBlockRate,
+    uint256 minBlockGasCost,
+    uint256 maxBlockGasCost,
+    uint256 blockGasCostStep
+  );
+
+  // Set the fee config
+  function setFeeConfig(
+    uint256 targetGas,
+    uint256 minBaseFee,
+    uint256 baseFeeChangeDenominator,
+    uint256 targetBlockRate,
+    uint256 minBlockGasCost,
+    uint256 maxBlockGasCost,
+    uint256 blockGasCostStep
+  ) external;
+
+  // Get the current fee config
+  function getFeeConfig()
+    external
+    view
+    returns (
+      uint256 targetGas,
+      uint256 minBaseFee,
+      uint256 baseFeeChangeDenominator,
+      uint256 targetBlockRate,
+      uint256 minBlockGasCost,
+      uint256 maxBlockGasCost,
+      uint256 blockGasCostStep
+    );
+
+  // Get the number of the block in which the fee config was last changed
+  function getFeeConfigLastChangedAt() external view returns (uint256 blockNumber);
+}
@@ -0,0 +1,710 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package feemanager
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/sankar-boro/axia-network-v2-coreth/accounts/abi"
+	"github.com/sankar-boro/axia-network-v2-coreth/accounts/abi/bind"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
+	"github.com/sankar-boro/axia-network-v2-coreth/interfaces"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = interfaces.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// IFeeConfigManagerMetaData contains all meta data concerning the IFeeConfigManager contract.
+var IFeeConfigManagerMetaData = &bind.MetaData{
+	ABI: "[{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"targetGas\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"minBaseFee\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"baseFeeChangeDenominator\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"targetBlockRate\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"minBlockGasCost\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"maxBlockGasCost\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"blockGasCostStep\",\"type\":\"uint256\"}],\"name\":\"FeeConfigChanged\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"role\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"oldRole\",\"type\":\"uint256\"}],\"name\":\"RoleSet\",\"type\":\"event\"},{\"inputs\":[],\"name\":\"getFeeConfig\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"targetGas\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"minBaseFee\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"baseFeeChangeDenominator\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"targetBlockRate\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"minBlockGasCost\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"maxBlockGasCost\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"blockGasCostStep\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"getFeeConfigLastChangedAt\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"blockNumber\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"readAllowList\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"role\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"setAdmin\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"setEnabled\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"targetGas\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"minBaseFee\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"baseFeeChangeDenominator\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"targetBlockRate\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"minBlockGasCost\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"maxBlockGasCost\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"blockGasCostStep\",\"type\":\"uint256\"}],\"name\":\"setFeeConfig\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"setNone\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]",
+}
+
+// IFeeConfigManagerABI is the input ABI used to generate the binding from.
+// Deprecated: Use IFeeConfigManagerMetaData.ABI instead.
+var IFeeConfigManagerABI = IFeeConfigManagerMetaData.ABI
+
+// IFeeConfigManager is an auto generated Go binding around an Ethereum contract.
+type IFeeConfigManager struct {
+	IFeeConfigManagerCaller     // Read-only binding to the contract
+	IFeeConfigManagerTransactor // Write-only binding to the contract
+	IFeeConfigManagerFilterer   // Log filterer for contract events
+}
+
+// IFeeConfigManagerCaller is an auto generated read-only Go binding around an Ethereum contract.
+type IFeeConfigManagerCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// IFeeConfigManagerTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type IFeeConfigManagerTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// IFeeConfigManagerFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type IFeeConfigManagerFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// IFeeConfigManagerSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type IFeeConfigManagerSession struct {
+	Contract     *IFeeConfigManager // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts      // Call options to use throughout this session
+	TransactOpts bind.TransactOpts  // Transaction auth options to use throughout this session
+}
+
+// IFeeConfigManagerCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type IFeeConfigManagerCallerSession struct {
+	Contract *IFeeConfigManagerCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts            // Call options to use throughout this session
+}
+
+// IFeeConfigManagerTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type IFeeConfigManagerTransactorSession struct {
+	Contract     *IFeeConfigManagerTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts            // Transaction auth options to use throughout this session
+}
+
+// IFeeConfigManagerRaw is an auto generated low-level Go binding around an Ethereum contract.
+type IFeeConfigManagerRaw struct {
+	Contract *IFeeConfigManager // Generic contract binding to access the raw methods on
+}
+
+// IFeeConfigManagerCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type IFeeConfigManagerCallerRaw struct {
+	Contract *IFeeConfigManagerCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// IFeeConfigManagerTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type IFeeConfigManagerTransactorRaw struct {
+	Contract *IFeeConfigManagerTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewIFeeConfigManager creates a new instance of IFeeConfigManager, bound to a specific deployed contract.
+func NewIFeeConfigManager(address common.Address, backend bind.ContractBackend) (*IFeeConfigManager, error) {
+	contract, err := bindIFeeConfigManager(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &IFeeConfigManager{IFeeConfigManagerCaller: IFeeConfigManagerCaller{contract: contract}, IFeeConfigManagerTransactor: IFeeConfigManagerTransactor{contract: contract}, IFeeConfigManagerFilterer: IFeeConfigManagerFilterer{contract: contract}}, nil
+}
+
+// NewIFeeConfigManagerCaller creates a new read-only instance of IFeeConfigManager, bound to a specific deployed contract.
+func NewIFeeConfigManagerCaller(address common.Address, caller bind.ContractCaller) (*IFeeConfigManagerCaller, error) {
+	contract, err := bindIFeeConfigManager(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &IFeeConfigManagerCaller{contract: contract}, nil
+}
+
+// NewIFeeConfigManagerTransactor creates a new write-only instance of IFeeConfigManager, bound to a specific deployed contract.
+func NewIFeeConfigManagerTransactor(address common.Address, transactor bind.ContractTransactor) (*IFeeConfigManagerTransactor, error) {
+	contract, err := bindIFeeConfigManager(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &IFeeConfigManagerTransactor{contract: contract}, nil
+}
+
+// NewIFeeConfigManagerFilterer creates a new log filterer instance of IFeeConfigManager, bound to a specific deployed contract.
+func NewIFeeConfigManagerFilterer(address common.Address, filterer bind.ContractFilterer) (*IFeeConfigManagerFilterer, error) {
+	contract, err := bindIFeeConfigManager(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &IFeeConfigManagerFilterer{contract: contract}, nil
+}
+
+// bindIFeeConfigManager binds a generic wrapper to an already deployed contract.
+func bindIFeeConfigManager(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(IFeeConfigManagerABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_IFeeConfigManager *IFeeConfigManagerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _IFeeConfigManager.Contract.IFeeConfigManagerCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_IFeeConfigManager *IFeeConfigManagerRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _IFeeConfigManager.Contract.IFeeConfigManagerTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_IFeeConfigManager *IFeeConfigManagerRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _IFeeConfigManager.Contract.IFeeConfigManagerTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_IFeeConfigManager *IFeeConfigManagerCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _IFeeConfigManager.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_IFeeConfigManager *IFeeConfigManagerTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _IFeeConfigManager.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_IFeeConfigManager *IFeeConfigManagerTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _IFeeConfigManager.Contract.contract.Transact(opts, method, params...)
+}
+
+// GetFeeConfig is a free data retrieval call binding the contract method 0x5fbbc0d2.
+//
+// Solidity: function getFeeConfig() view returns(uint256 targetGas, uint256 minBaseFee, uint256 baseFeeChangeDenominator, uint256 targetBlockRate, uint256 minBlockGasCost, uint256 maxBlockGasCost, uint256 blockGasCostStep)
+func (_IFeeConfigManager *IFeeConfigManagerCaller) GetFeeConfig(opts *bind.CallOpts) (struct {
+	TargetGas                *big.Int
+	MinBaseFee               *big.Int
+	BaseFeeChangeDenominator *big.Int
+	TargetBlockRate          *big.Int
+	MinBlockGasCost          *big.Int
+	MaxBlockGasCost          *big.Int
+	BlockGasCostStep         *big.Int
+}, error) {
+	var out []interface{}
+	err := _IFeeConfigManager.contract.Call(opts, &out, "getFeeConfig")
+
+	outstruct := new(struct {
+		TargetGas                *big.Int
+		MinBaseFee               *big.Int
+		BaseFeeChangeDenominator *big.Int
+		TargetBlockRate          *big.Int
+		MinBlockGasCost          *big.Int
+		MaxBlockGasCost          *big.Int
+		BlockGasCostStep         *big.Int
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.TargetGas = *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+	outstruct.MinBaseFee = *abi.ConvertType(out[1], new(*big.Int)).(**big.Int)
+	outstruct.BaseFeeChangeDenominator = *abi.ConvertType(out[2], new(*big.Int)).(**big.Int)
+	outstruct.TargetBlockRate = *abi.ConvertType(out[3], new(*big.Int)).(**big.Int)
+	outstruct.MinBlockGasCost = *abi.ConvertType(out[4], new(*big.Int)).(**big.Int)
+	outstruct.MaxBlockGasCost = *abi.ConvertType(out[5], new(*big.Int)).(**big.Int)
+	outstruct.BlockGasCostStep = *abi.ConvertType(out[6], new(*big.Int)).(**big.Int)
+
+	return *outstruct, err
+
+}
+
+// GetFeeConfig is a free data retrieval call binding the contract method 0x5fbbc0d2.
+//
+// Solidity: function getFeeConfig() view returns(uint256 targetGas, uint256 minBaseFee, uint256 baseFeeChangeDenominator, uint256 targetBlockRate, uint256 minBlockGasCost, uint256 maxBlockGasCost, uint256 blockGasCostStep)
+func (_IFeeConfigManager *IFeeConfigManagerSession) GetFeeConfig() (struct {
+	TargetGas                *big.Int
+	MinBaseFee               *big.Int
+	BaseFeeChangeDenominator *big.Int
+	TargetBlockRate          *big.Int
+	MinBlockGasCost          *big.Int
+	MaxBlockGasCost          *big.Int
+	BlockGasCostStep         *big.Int
+}, error) {
+	return _IFeeConfigManager.Contract.GetFeeConfig(&_IFeeConfigManager.CallOpts)
+}
+
+// GetFeeConfig is a free data retrieval call binding the contract method 0x5fbbc0d2.
+//
+// Solidity: function getFeeConfig() view returns(uint256 targetGas, uint256 minBaseFee, uint256 baseFeeChangeDenominator, uint256 targetBlockRate, uint256 minBlockGasCost, uint256 maxBlockGasCost, uint256 blockGasCostStep)
+func (_IFeeConfigManager *IFeeConfigManagerCallerSession) GetFeeConfig() (struct {
+	TargetGas                *big.Int
+	MinBaseFee               *big.Int
+	BaseFeeChangeDenominator *big.Int
+	TargetBlockRate          *big.Int
+	MinBlockGasCost          *big.Int
+	MaxBlockGasCost          *big.Int
+	BlockGasCostStep         *big.Int
+}, error) {
+	return _IFeeConfigManager.Contract.GetFeeConfig(&_IFeeConfigManager.CallOpts)
+}
+
+// GetFeeConfigLastChangedAt is a free data retrieval call binding the contract method 0x9e05549a.
+//
+// Solidity: function getFeeConfigLastChangedAt() view returns(uint256 blockNumber)
+func (_IFeeConfigManager *IFeeConfigManagerCaller) GetFeeConfigLastChangedAt(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _IFeeConfigManager.contract.Call(opts, &out, "getFeeConfigLastChangedAt")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// GetFeeConfigLastChangedAt is a free data retrieval call binding the contract method 0x9e05549a.
+//
+// Solidity: function getFeeConfigLastChangedAt() view returns(uint256 blockNumber)
+func (_IFeeConfigManager *IFeeConfigManagerSession) GetFeeConfigLastChangedAt() (*big.Int, error) {
+	return _IFeeConfigManager.Contract.GetFeeConfigLastChangedAt(&_IFeeConfigManager.CallOpts)
+}
+
+// GetFeeConfigLastChangedAt is a free data retrieval call binding the contract method 0x9e05549a.
+//
+// Solidity: function getFeeConfigLastChangedAt() view returns(uint256 blockNumber)
+func (_IFeeConfigManager *IFeeConfigManagerCallerSession) GetFeeConfigLastChangedAt() (*big.Int, error) {
+	return _IFeeConfigManager.Contract.GetFeeConfigLastChangedAt(&_IFeeConfigManager.CallOpts)
+}
+
+// ReadAllowList is a free data retrieval call binding the contract method 0xeb54dae1.
+//
+// Solidity: function readAllowList(address addr) view returns(uint256 role)
+func (_IFeeConfigManager *IFeeConfigManagerCaller) ReadAllowList(opts *bind.CallOpts, addr common.Address) (*big.Int, error) {
+	var out []interface{}
+	err := _IFeeConfigManager.contract.Call(opts, &out, "readAllowList", addr)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// ReadAllowList is a free data retrieval call binding the contract method 0xeb54dae1.
+//
+// Solidity: function readAllowList(address addr) view returns(uint256 role)
+func (_IFeeConfigManager *IFeeConfigManagerSession) ReadAllowList(addr common.Address) (*big.Int, error) {
+	return _IFeeConfigManager.Contract.ReadAllowList(&_IFeeConfigManager.CallOpts, addr)
+}
+
+// ReadAllowList is a free data retrieval call binding the contract method 0xeb54dae1.
+//
+// Solidity: function readAllowList(address addr) view returns(uint256 role)
+func (_IFeeConfigManager *IFeeConfigManagerCallerSession) ReadAllowList(addr common.Address) (*big.Int, error) {
+	return _IFeeConfigManager.Contract.ReadAllowList(&_IFeeConfigManager.CallOpts, addr)
+}
+
+// SetAdmin is a paid mutator transaction binding the contract method 0x704b6c02.
+//
+// Solidity: function setAdmin(address addr) returns()
+func (_IFeeConfigManager *IFeeConfigManagerTransactor) SetAdmin(opts *bind.TransactOpts, addr common.Address) (*types.Transaction, error) {
+	return _IFeeConfigManager.contract.Transact(opts, "setAdmin", addr)
+}
+
+// SetAdmin is a paid mutator transaction binding the contract method 0x704b6c02.
+//
+// Solidity: function setAdmin(address addr) returns()
+func (_IFeeConfigManager *IFeeConfigManagerSession) SetAdmin(addr common.Address) (*types.Transaction, error) {
+	return _IFeeConfigManager.Contract.SetAdmin(&_IFeeConfigManager.TransactOpts, addr)
+}
+
+// SetAdmin is a paid mutator transaction binding the contract method 0x704b6c02.
+//
+// Solidity: function setAdmin(address addr) returns()
+func (_IFeeConfigManager *IFeeConfigManagerTransactorSession) SetAdmin(addr common.Address) (*types.Transaction, error) {
+	return _IFeeConfigManager.Contract.SetAdmin(&_IFeeConfigManager.TransactOpts, addr)
+}
+
+// SetEnabled is a paid mutator transaction binding the contract method 0x0aaf7043.
+//
+// Solidity: function setEnabled(address addr) returns()
+func (_IFeeConfigManager *IFeeConfigManagerTransactor) SetEnabled(opts *bind.TransactOpts, addr common.Address) (*types.Transaction, error) {
+	return _IFeeConfigManager.contract.Transact(opts, "setEnabled", addr)
+}
+
+// SetEnabled is a paid mutator transaction binding the contract method 0x0aaf7043.
+//
+// Solidity: function setEnabled(address addr) returns()
+func (_IFeeConfigManager *IFeeConfigManagerSession) SetEnabled(addr common.Address) (*types.Transaction, error) {
+	return _IFeeConfigManager.Contract.SetEnabled(&_IFeeConfigManager.TransactOpts, addr)
+}
+
+// SetEnabled is a paid mutator transaction binding the contract method 0x0aaf7043.
+//
+// Solidity: function setEnabled(address addr) returns()
+func (_IFeeConfigManager *IFeeConfigManagerTransactorSession) SetEnabled(addr common.Address) (*types.Transaction, error) {
+	return _IFeeConfigManager.Contract.SetEnabled(&_IFeeConfigManager.TransactOpts, addr)
+}
+
+// SetFeeConfig is a paid mutator transaction binding the contract method 0x40f35161.
+//
+// Solidity: function setFeeConfig(uint256 targetGas, uint256 minBaseFee, uint256 baseFeeChangeDenominator, uint256 targetBlockRate, uint256 minBlockGasCost, uint256 maxBlockGasCost, uint256 blockGasCostStep) returns()
+func (_IFeeConfigManager *IFeeConfigManagerTransactor) SetFeeConfig(opts *bind.TransactOpts, targetGas *big.Int, minBaseFee *big.Int, baseFeeChangeDenominator *big.Int, targetBlockRate *big.Int, minBlockGasCost *big.Int, maxBlockGasCost *big.Int, blockGasCostStep *big.Int) (*types.Transaction, error) {
+	return _IFeeConfigManager.contract.Transact(opts, "setFeeConfig", targetGas, minBaseFee, baseFeeChangeDenominator, targetBlockRate, minBlockGasCost, maxBlockGasCost, blockGasCostStep)
+}
+
+// SetFeeConfig is a paid mutator transaction binding the contract method 0x40f35161.
+//
+// Solidity: function setFeeConfig(uint256 targetGas, uint256 minBaseFee, uint256 baseFeeChangeDenominator, uint256 targetBlockRate, uint256 minBlockGasCost, uint256 maxBlockGasCost, uint256 blockGasCostStep) returns()
+func (_IFeeConfigManager *IFeeConfigManagerSession) SetFeeConfig(targetGas *big.Int, minBaseFee *big.Int, baseFeeChangeDenominator *big.Int, targetBlockRate *big.Int, minBlockGasCost *big.Int, maxBlockGasCost *big.Int, blockGasCostStep *big.Int) (*types.Transaction, error) {
+	return _IFeeConfigManager.Contract.SetFeeConfig(&_IFeeConfigManager.TransactOpts, targetGas, minBaseFee, baseFeeChangeDenominator, targetBlockRate, minBlockGasCost, maxBlockGasCost, blockGasCostStep)
+}
+
+// SetFeeConfig is a paid mutator transaction binding the contract method 0x40f35161.
+//
+// Solidity: function setFeeConfig(uint256 targetGas, uint256 minBaseFee, uint256 baseFeeChangeDenominator, uint256 targetBlockRate, uint256 minBlockGasCost, uint256 maxBlockGasCost, uint256 blockGasCostStep) returns()
+func (_IFeeConfigManager *IFeeConfigManagerTransactorSession) SetFeeConfig(targetGas *big.Int, minBaseFee *big.Int, baseFeeChangeDenominator *big.Int, targetBlockRate *big.Int, minBlockGasCost *big.Int, maxBlockGasCost *big.Int, blockGasCostStep *big.Int) (*types.Transaction, error) {
+	return _IFeeConfigManager.Contract.SetFeeConfig(&_IFeeConfigManager.TransactOpts, targetGas, minBaseFee, baseFeeChangeDenominator, targetBlockRate, minBlockGasCost, maxBlockGasCost, blockGasCostStep)
+}
+
+// SetNone is a paid mutator transaction binding the contract method 0x8c6bfb3b.
+//
+// Solidity: function setNone(address addr) returns()
+func (_IFeeConfigManager *IFeeConfigManagerTransactor) SetNone(opts *bind.TransactOpts, addr common.Address) (*types.Transaction, error) {
+	return _IFeeConfigManager.contract.Transact(opts, "setNone", addr)
+}
+
+// SetNone is a paid mutator transaction binding the contract method 0x8c6bfb3b.
+//
+// Solidity: function setNone(address addr) returns()
+func (_IFeeConfigManager *IFeeConfigManagerSession) SetNone(addr common.Address) (*types.Transaction, error) {
+	return _IFeeConfigManager.Contract.SetNone(&_IFeeConfigManager.TransactOpts, addr)
+}
+
+// SetNone is a paid mutator transaction binding the contract method 0x8c6bfb3b.
+//
+// Solidity: function setNone(address addr) returns()
+func (_IFeeConfigManager *IFeeConfigManagerTransactorSession) SetNone(addr common.Address) (*types.Transaction, error) {
+	return _IFeeConfigManager.Contract.SetNone(&_IFeeConfigManager.TransactOpts, addr)
+}
+
+// IFeeConfigManagerFeeConfigChangedIterator is returned from FilterFeeConfigChanged and is used to iterate over the raw logs and unpacked data for FeeConfigChanged events raised by the IFeeConfigManager contract.
+type IFeeConfigManagerFeeConfigChangedIterator struct {
+	Event *IFeeConfigManagerFeeConfigChanged // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log          // Log channel receiving the found contract events
+	sub  interfaces.Subscription // Subscription for errors, completion and termination
+	done bool                    // Whether the subscription completed delivering logs
+	fail error                   // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *IFeeConfigManagerFeeConfigChangedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(IFeeConfigManagerFeeConfigChanged)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(IFeeConfigManagerFeeConfigChanged)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *IFeeConfigManagerFeeConfigChangedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *IFeeConfigManagerFeeConfigChangedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// IFeeConfigManagerFeeConfigChanged represents a FeeConfigChanged event raised by the IFeeConfigManager contract.
+type IFeeConfigManagerFeeConfigChanged struct {
+	Sender                   common.Address
+	TargetGas                *big.Int
+	MinBaseFee               *big.Int
+	BaseFeeChangeDenominator *big.Int
+	TargetBlockRate          *big.Int
+	MinBlockGasCost          *big.Int
+	MaxBlockGasCost          *big.Int
+	BlockGasCostStep         *big.Int
+	Raw                      types.Log // Blockchain specific contextual infos
+}
+
+// FilterFeeConfigChanged is a free log retrieval operation binding the contract event 0x666e36e62183283a63560c0217d563f61e6e32dd0916531d40ac19d12d5fecac.
+//
+// Solidity: event FeeConfigChanged(address indexed sender, uint256 targetGas, uint256 minBaseFee, uint256 baseFeeChangeDenominator, uint256 targetBlockRate, uint256 minBlockGasCost, uint256 maxBlockGasCost, uint256 blockGasCostStep)
+func (_IFeeConfigManager *IFeeConfigManagerFilterer) FilterFeeConfigChanged(opts *bind.FilterOpts, sender []common.Address) (*IFeeConfigManagerFeeConfigChangedIterator, error) {
+
+	var senderRule []interface{}
+	for _, senderItem := range sender {
+		senderRule = append(senderRule, senderItem)
+	}
+
+	logs, sub, err := _IFeeConfigManager.contract.FilterLogs(opts, "FeeConfigChanged", senderRule)
+	if err != nil {
+		return nil, err
+	}
+	return &IFeeConfigManagerFeeConfigChangedIterator{contract: _IFeeConfigManager.contract, event: "FeeConfigChanged", logs: logs, sub: sub}, nil
+}
+
+// WatchFeeConfigChanged is a free log subscription operation binding the contract event 0x666e36e62183283a63560c0217d563f61e6e32dd0916531d40ac19d12d5fecac.
+//
+// Solidity: event FeeConfigChanged(address indexed sender, uint256 targetGas, uint256 minBaseFee, uint256 baseFeeChangeDenominator, uint256 targetBlockRate, uint256 minBlockGasCost, uint256 maxBlockGasCost, uint256 blockGasCostStep)
+func (_IFeeConfigManager *IFeeConfigManagerFilterer) WatchFeeConfigChanged(opts *bind.WatchOpts, sink chan<- *IFeeConfigManagerFeeConfigChanged, sender []common.Address) (event.Subscription, error) {
+
+	var senderRule []interface{}
+	for _, senderItem := range sender {
+		senderRule = append(senderRule, senderItem)
+	}
+
+	logs, sub, err := _IFeeConfigManager.contract.WatchLogs(opts, "FeeConfigChanged", senderRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(IFeeConfigManagerFeeConfigChanged)
+				if err := _IFeeConfigManager.contract.UnpackLog(event, "FeeConfigChanged", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseFeeConfigChanged is a log parse operation binding the contract event 0x666e36e62183283a63560c0217d563f61e6e32dd0916531d40ac19d12d5fecac.
+//
+// Solidity: event FeeConfigChanged(address indexed sender, uint256 targetGas, uint256 minBaseFee, uint256 baseFeeChangeDenominator, uint256 targetBlockRate, uint256 minBlockGasCost, uint256 maxBlockGasCost, uint256 blockGasCostStep)
+func (_IFeeConfigManager *IFeeConfigManagerFilterer) ParseFeeConfigChanged(log types.Log) (*IFeeConfigManagerFeeConfigChanged, error) {
+	event := new(IFeeConfigManagerFeeConfigChanged)
+	if err := _IFeeConfigManager.contract.UnpackLog(event, "FeeConfigChanged", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// IFeeConfigManagerRoleSetIterator is returned from FilterRoleSet and is used to iterate over the raw logs and unpacked data for RoleSet events raised by the IFeeConfigManager contract.
+type IFeeConfigManagerRoleSetIterator struct {
+	Event *IFeeConfigManagerRoleSet // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log          // Log channel receiving the found contract events
+	sub  interfaces.Subscription // Subscription for errors, completion and termination
+	done bool                    // Whether the subscription completed delivering logs
+	fail error                   // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *IFeeConfigManagerRoleSetIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(IFeeConfigManagerRoleSet)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(IFeeConfigManagerRoleSet)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *IFeeConfigManagerRoleSetIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *IFeeConfigManagerRoleSetIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// IFeeConfigManagerRoleSet represents a RoleSet event raised by the IFeeConfigManager contract.
+type IFeeConfigManagerRoleSet struct {
+	Role    *big.Int
+	Account common.Address
+	Sender  common.Address
+	OldRole *big.Int
+	Raw     types.Log // Blockchain specific contextual infos
+}
+
+// FilterRoleSet is a free log retrieval operation binding the contract event 0xcdb7ea01f00a414d78757bdb0f6391664ba3fedf987eed280927c1e7d695be3e.
+//
+// Solidity: event RoleSet(uint256 indexed role, address indexed account, address indexed sender, uint256 oldRole)
+func (_IFeeConfigManager *IFeeConfigManagerFilterer) FilterRoleSet(opts *bind.FilterOpts, role []*big.Int, account []common.Address, sender []common.Address) (*IFeeConfigManagerRoleSetIterator, error) {
+
+	var roleRule []interface{}
+	for _, roleItem := range role {
+		roleRule = append(roleRule, roleItem)
+	}
+	var accountRule []interface{}
+	for _, accountItem := range account {
+		accountRule = append(accountRule, accountItem)
+	}
+	var senderRule []interface{}
+	for _, senderItem := range sender {
+		senderRule = append(senderRule, senderItem)
+	}
+
+	logs, sub, err := _IFeeConfigManager.contract.FilterLogs(opts, "RoleSet", roleRule, accountRule, senderRule)
+	if err != nil {
+		return nil, err
+	}
+	return &IFeeConfigManagerRoleSetIterator{contract: _IFeeConfigManager.contract, event: "RoleSet", logs: logs, sub: sub}, nil
+}
+
+// WatchRoleSet is a free log subscription operation binding the contract event 0xcdb7ea01f00a414d78757bdb0f6391664ba3fedf987eed280927c1e7d695be3e.
+//
+// Solidity: event RoleSet(uint256 indexed role, address indexed account, address indexed sender, uint256 oldRole)
+func (_IFeeConfigManager *IFeeConfigManagerFilterer) WatchRoleSet(opts *bind.WatchOpts, sink chan<- *IFeeConfigManagerRoleSet, role []*big.Int, account []common.Address, sender []common.Address) (event.Subscription, error) {
+
+	var roleRule []interface{}
+	for _, roleItem := range role {
+		roleRule = append(roleRule, roleItem)
+	}
+	var accountRule []interface{}
+	for _, accountItem := range account {
+		accountRule = append(accountRule, accountItem)
+	}
+	var senderRule []interface{}
+	for _, senderItem := range sender {
+		senderRule = append(senderRule, senderItem)
+	}
+
+	logs, sub, err := _IFeeConfigManager.contract.WatchLogs(opts, "RoleSet", roleRule, accountRule, senderRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(IFeeConfigManagerRoleSet)
+				if err := _IFeeConfigManager.contract.UnpackLog(event, "RoleSet", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseRoleSet is a log parse operation binding the contract event 0xcdb7ea01f00a414d78757bdb0f6391664ba3fedf987eed280927c1e7d695be3e.
+//
+// Solidity: event RoleSet(uint256 indexed role, address indexed account, address indexed sender, uint256 oldRole)
+func (_IFeeConfigManager *IFeeConfigManagerFilterer) ParseRoleSet(log types.Log) (*IFeeConfigManagerRoleSet, error) {
+	event := new(IFeeConfigManagerRoleSet)
+	if err := _IFeeConfigManager.contract.UnpackLog(event, "RoleSet", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
@@ -0,0 +1,8 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package feemanager contains the Go bindings of the fee config manager
+// precompile.
+package feemanager
+
+//go:generate go run ../../../../cmd/abigen --abi IFeeConfigManager.abi --pkg feemanager --type IFeeConfigManager --out feemanager.go
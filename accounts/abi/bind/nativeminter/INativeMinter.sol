@@ -0,0 +1,24 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// SPDX-License-Identifier: MIT
+
+pragma solidity >=0.8.0;
+
+import "../allowlist/IAllowList.sol";
+
+// INativeMinter is the interface of the native minter precompile. Only
+// enabled addresses can mint.
+interface INativeMinter is IAllowList {
+  // Emitted when [sender] mints [amount] of the native coin to [recipient]
+  event NativeCoinMinted(address indexed sender, address indexed recipient, uint256 amount);
+
+  // Emitted when [sender] mints [amount] of the multicoin asset [assetID] to [recipient]
+  event NativeAssetMinted(address indexed sender, address indexed recipient, bytes32 indexed assetID, uint256 amount);
+
+  // Mint [amount] of the native coin to [recipient]
+  function mintNativeCoin(address recipient, uint256 amount) external;
+
+  // Mint [amount] of the multicoin asset [assetID] to [recipient]
+  function mintNativeAsset(address recipient, bytes32 assetID, uint256 amount) external;
+}
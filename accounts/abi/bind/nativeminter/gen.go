@@ -0,0 +1,8 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package nativeminter contains the Go bindings of the native minter
+// precompile.
+package nativeminter
+
+//go:generate go run ../../../../cmd/abigen --abi INativeMinter.abi --pkg nativeminter --type INativeMinter --out nativeminter.go
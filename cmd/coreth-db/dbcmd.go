@@ -0,0 +1,460 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/consensus/dummy"
+	"github.com/sankar-boro/axia-network-v2-coreth/core"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/state"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/vm"
+	"github.com/sankar-boro/axia-network-v2-coreth/ethdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/params"
+	"github.com/sankar-boro/axia-network-v2-coreth/plugin/evm"
+	"github.com/sankar-boro/axia-network-v2-coreth/trie"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	vmDBFlag = cli.BoolFlag{
+		Name:  "vm",
+		Usage: "Use the VM's database instead of the chain database",
+	}
+	noCodeFlag = cli.BoolFlag{
+		Name:  "nocode",
+		Usage: "Exclude contract code",
+	}
+	noStorageFlag = cli.BoolFlag{
+		Name:  "nostorage",
+		Usage: "Exclude storage entries",
+	}
+	commitIntervalFlag = cli.Uint64Flag{
+		Name:  "commit-interval",
+		Usage: "Number of blocks between commits of the atomic trie (commit-interval)",
+		Value: 4096,
+	}
+
+	inspectCommand = cli.Command{
+		Action:    inspect,
+		Name:      "inspect",
+		Usage:     "Inspect the storage size for each type of data in the databases",
+		ArgsUsage: "<prefix> <start>",
+		Description: `This command iterates the chain database, or the keys of the chain database
+with the given hex prefix starting at the given hex key, and reports the size of
+each type of data. It then reports the size of the tables kept in the VM's
+database, including the atomic trie and the atomic repository.`,
+	}
+	verifyStateCommand = cli.Command{
+		Action:    verifyState,
+		Name:      "verify-state",
+		Usage:     "Check that the state at a root is complete",
+		ArgsUsage: "<root>",
+		Description: `This command walks the account trie at the given state root, or the state root
+of the last accepted block, along with all the storage tries and contract codes
+it references, and reports the first missing trie node or code.`,
+	}
+	checkCanonicalCommand = cli.Command{
+		Action: checkCanonical,
+		Name:   "check-canonical",
+		Usage:  "Check the consistency of the canonical chain",
+		Description: `This command loads the chain at the last accepted block and checks that every
+canonical block, header, transaction lookup and receipt back to genesis is
+indexed consistently (see BlockChain.ValidateCanonicalChain).`,
+	}
+	dumpAccountCommand = cli.Command{
+		Action:    dumpAccount,
+		Name:      "dump-account",
+		Usage:     "Dump an account in JSON",
+		ArgsUsage: "<address> <root>",
+		Flags: []cli.Flag{
+			noCodeFlag,
+			noStorageFlag,
+		},
+		Description: `This command prints the account of the given address in the state with the
+given root, or the state root of the last accepted block. Storage slots are
+keyed by their hash if their preimage is not known.`,
+	}
+	getCommand = cli.Command{
+		Action:    get,
+		Name:      "get",
+		Usage:     "Show the value of a database key",
+		ArgsUsage: "<hex-encoded key>",
+		Flags: []cli.Flag{
+			vmDBFlag,
+		},
+	}
+	putCommand = cli.Command{
+		Action:    put,
+		Name:      "put",
+		Usage:     "Set the value of a database key (WARNING: may corrupt your database)",
+		ArgsUsage: "<hex-encoded key> <hex-encoded value>",
+		Flags: []cli.Flag{
+			vmDBFlag,
+		},
+	}
+	deleteCommand = cli.Command{
+		Action:    del,
+		Name:      "delete",
+		Usage:     "Delete a database key (WARNING: may corrupt your database)",
+		ArgsUsage: "<hex-encoded key>",
+		Flags: []cli.Flag{
+			vmDBFlag,
+		},
+	}
+	repairAtomicCommand = cli.Command{
+		Action: repairAtomic,
+		Name:   "repair-atomic",
+		Usage:  "Rebuild the atomic trie from the atomic repository",
+		Flags: []cli.Flag{
+			commitIntervalFlag,
+		},
+		Description: `This command discards the atomic trie and rebuilds it from the atomic
+transactions indexed by height in the atomic repository, up to the last
+accepted block. The commit interval must match the one the VM is configured
+with.`,
+	}
+)
+
+func inspect(ctx *cli.Context) error {
+	var prefix, start []byte
+	if ctx.NArg() > 2 {
+		return fmt.Errorf("max 2 arguments: %v", ctx.Command.ArgsUsage)
+	}
+	if ctx.NArg() >= 1 {
+		var err error
+		if prefix, err = hexutil.Decode(ctx.Args().Get(0)); err != nil {
+			return fmt.Errorf("failed to hex-decode 'prefix': %w", err)
+		}
+	}
+	if ctx.NArg() >= 2 {
+		var err error
+		if start, err = hexutil.Decode(ctx.Args().Get(1)); err != nil {
+			return fmt.Errorf("failed to hex-decode 'start': %w", err)
+		}
+	}
+	dbs, err := openDatabases(ctx, true)
+	if err != nil {
+		return err
+	}
+	defer dbs.Close()
+
+	if err := rawdb.InspectDatabase(dbs.chainDB, prefix, start); err != nil {
+		return err
+	}
+	return evm.InspectAtomicDatabase(dbs.vmDB)
+}
+
+func verifyState(ctx *cli.Context) error {
+	if ctx.NArg() > 1 {
+		return fmt.Errorf("max 1 argument: %v", ctx.Command.ArgsUsage)
+	}
+	dbs, err := openDatabases(ctx, true)
+	if err != nil {
+		return err
+	}
+	defer dbs.Close()
+
+	root, err := parseRoot(dbs, ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	triedb := trie.NewDatabase(dbs.chainDB)
+	accountTrie, err := trie.New(root, triedb)
+	if err != nil {
+		return err
+	}
+	var (
+		accounts, slots, nodes, codes int
+		start                         = time.Now()
+		logged                        = time.Now()
+	)
+	it := accountTrie.NodeIterator(nil)
+	for it.Next(true) {
+		nodes++
+		if !it.Leaf() {
+			continue
+		}
+		accounts++
+		var account types.StateAccount
+		if err := rlp.DecodeBytes(it.LeafBlob(), &account); err != nil {
+			return fmt.Errorf("invalid account %x: %w", it.LeafKey(), err)
+		}
+		accountHash := common.BytesToHash(it.LeafKey())
+		if account.Root != types.EmptyRootHash {
+			storageTrie, err := trie.NewWithOwner(accountHash, account.Root, triedb)
+			if err != nil {
+				return fmt.Errorf("missing storage trie of account %s: %w", accountHash, err)
+			}
+			storageIt := storageTrie.NodeIterator(nil)
+			for storageIt.Next(true) {
+				nodes++
+				if storageIt.Leaf() {
+					slots++
+				}
+			}
+			if err := storageIt.Error(); err != nil {
+				return fmt.Errorf("incomplete storage trie of account %s: %w", accountHash, err)
+			}
+		}
+		if codeHash := common.BytesToHash(account.CodeHash); codeHash != types.EmptyCodeHash {
+			if len(rawdb.ReadCode(dbs.chainDB, codeHash)) == 0 {
+				return fmt.Errorf("missing code %s of account %s", codeHash, accountHash)
+			}
+			codes++
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Verifying state", "at", accountHash, "accounts", accounts, "slots", slots, "nodes", nodes, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return fmt.Errorf("incomplete account trie: %w", err)
+	}
+	log.Info("State is complete", "root", root, "accounts", accounts, "slots", slots, "nodes", nodes, "codes", codes, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+func checkCanonical(ctx *cli.Context) error {
+	// Loading the chain writes to the chain database, e.g. the head markers.
+	dbs, err := openDatabases(ctx, false)
+	if err != nil {
+		return err
+	}
+	defer dbs.Close()
+
+	lastAcceptedHash, _, err := evm.ReadLastAccepted(dbs.vmDB, dbs.chainDB)
+	if err != nil {
+		return err
+	}
+	chainConfig, err := readChainConfig(dbs.chainDB)
+	if err != nil {
+		return err
+	}
+	// Snapshots are disabled, so that they are neither loaded nor generated.
+	cacheConfig := *core.DefaultCacheConfig
+	cacheConfig.SnapshotLimit = 0
+	chain, err := core.NewBlockChain(dbs.chainDB, &cacheConfig, chainConfig, dummy.NewFaker(), vm.Config{}, lastAcceptedHash)
+	if err != nil {
+		return fmt.Errorf("failed to load chain: %w", err)
+	}
+	defer chain.Stop()
+
+	if err := chain.ValidateCanonicalChain(); err != nil {
+		return err
+	}
+	log.Info("Canonical chain is consistent", "lastAccepted", lastAcceptedHash)
+	return nil
+}
+
+func dumpAccount(ctx *cli.Context) error {
+	if ctx.NArg() < 1 || ctx.NArg() > 2 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	if !common.IsHexAddress(ctx.Args().Get(0)) {
+		return fmt.Errorf("invalid address %q", ctx.Args().Get(0))
+	}
+	address := common.HexToAddress(ctx.Args().Get(0))
+
+	dbs, err := openDatabases(ctx, true)
+	if err != nil {
+		return err
+	}
+	defer dbs.Close()
+
+	root, err := parseRoot(dbs, ctx.Args().Get(1))
+	if err != nil {
+		return err
+	}
+	statedb, err := state.New(root, state.NewDatabase(dbs.chainDB), nil)
+	if err != nil {
+		return err
+	}
+	if !statedb.Exist(address) {
+		return fmt.Errorf("account %s not found in state %s", address, root)
+	}
+	storageTrie := statedb.StorageTrie(address)
+	account := state.DumpAccount{
+		Balance:  statedb.GetBalance(address).String(),
+		Nonce:    statedb.GetNonce(address),
+		Root:     storageTrie.Hash().Bytes(),
+		CodeHash: statedb.GetCodeHash(address).Bytes(),
+		Address:  &address,
+	}
+	if !ctx.Bool(noCodeFlag.Name) {
+		account.Code = statedb.GetCode(address)
+	}
+	if !ctx.Bool(noStorageFlag.Name) {
+		account.Storage = make(map[common.Hash]string)
+		it := trie.NewIterator(storageTrie.NodeIterator(nil))
+		for it.Next() {
+			_, content, _, err := rlp.Split(it.Value)
+			if err != nil {
+				return fmt.Errorf("invalid storage slot %x: %w", it.Key, err)
+			}
+			key := common.BytesToHash(it.Key)
+			if preimage := storageTrie.GetKey(it.Key); len(preimage) > 0 {
+				key = common.BytesToHash(preimage)
+			}
+			account.Storage[key] = common.Bytes2Hex(content)
+		}
+		if it.Err != nil {
+			return it.Err
+		}
+	}
+	out, err := json.MarshalIndent(account, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+func get(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	key, err := hexutil.Decode(ctx.Args().Get(0))
+	if err != nil {
+		return fmt.Errorf("failed to hex-decode 'key': %w", err)
+	}
+	dbs, err := openDatabases(ctx, true)
+	if err != nil {
+		return err
+	}
+	defer dbs.Close()
+
+	value, err := keyValueStore(ctx, dbs).Get(key)
+	if err != nil {
+		return fmt.Errorf("failed to get key %#x: %w", key, err)
+	}
+	fmt.Printf("key %#x: %#x\n", key, value)
+	return nil
+}
+
+func put(ctx *cli.Context) error {
+	if ctx.NArg() != 2 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	key, err := hexutil.Decode(ctx.Args().Get(0))
+	if err != nil {
+		return fmt.Errorf("failed to hex-decode 'key': %w", err)
+	}
+	value, err := hexutil.Decode(ctx.Args().Get(1))
+	if err != nil {
+		return fmt.Errorf("failed to hex-decode 'value': %w", err)
+	}
+	dbs, err := openDatabases(ctx, false)
+	if err != nil {
+		return err
+	}
+	defer dbs.Close()
+
+	db := keyValueStore(ctx, dbs)
+	if prev, err := db.Get(key); err == nil {
+		fmt.Printf("Previous value: %#x\n", prev)
+	}
+	return db.Put(key, value)
+}
+
+func del(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	key, err := hexutil.Decode(ctx.Args().Get(0))
+	if err != nil {
+		return fmt.Errorf("failed to hex-decode 'key': %w", err)
+	}
+	dbs, err := openDatabases(ctx, false)
+	if err != nil {
+		return err
+	}
+	defer dbs.Close()
+
+	db := keyValueStore(ctx, dbs)
+	if prev, err := db.Get(key); err == nil {
+		fmt.Printf("Previous value: %#x\n", prev)
+	}
+	return db.Delete(key)
+}
+
+func repairAtomic(ctx *cli.Context) error {
+	interval := ctx.Uint64(commitIntervalFlag.Name)
+	if interval == 0 {
+		return errors.New("commit interval must be positive (--commit-interval)")
+	}
+	dbs, err := openDatabases(ctx, false)
+	if err != nil {
+		return err
+	}
+	defer dbs.Close()
+
+	_, lastAcceptedHeight, err := evm.ReadLastAccepted(dbs.vmDB, dbs.chainDB)
+	if err != nil {
+		return err
+	}
+	chainConfig, err := readChainConfig(dbs.chainDB)
+	if err != nil {
+		return err
+	}
+	root, height, err := evm.RepairAtomicTrie(dbs.vmDB, evm.Codec, chainConfig.ChainID, lastAcceptedHeight, interval)
+	if err != nil {
+		return err
+	}
+	log.Info("Repaired atomic trie", "lastAcceptedHeight", lastAcceptedHeight, "root", root, "height", height)
+	return nil
+}
+
+// keyValueStore returns the database selected by the flags of [ctx].
+func keyValueStore(ctx *cli.Context, dbs *databases) ethdb.KeyValueStore {
+	if ctx.Bool(vmDBFlag.Name) {
+		return evm.Database{Database: dbs.vmDB}
+	}
+	return dbs.chainDB
+}
+
+// parseRoot returns the state root [arg], or the state root of the last
+// accepted block if [arg] is empty.
+func parseRoot(dbs *databases, arg string) (common.Hash, error) {
+	if arg != "" {
+		root, err := hexutil.Decode(arg)
+		if err != nil || len(root) != common.HashLength {
+			return common.Hash{}, fmt.Errorf("invalid state root %q", arg)
+		}
+		return common.BytesToHash(root), nil
+	}
+	hash, number, err := evm.ReadLastAccepted(dbs.vmDB, dbs.chainDB)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	header := rawdb.ReadHeader(dbs.chainDB, hash, number)
+	if header == nil {
+		return common.Hash{}, fmt.Errorf("last accepted block %s not found", hash)
+	}
+	log.Info("Using state of last accepted block", "number", number, "hash", hash, "root", header.Root)
+	return header.Root, nil
+}
+
+// readChainConfig returns the chain config stored with the genesis block of
+// [db].
+func readChainConfig(db ethdb.Database) (*params.ChainConfig, error) {
+	genesisHash := rawdb.ReadCanonicalHash(db, 0)
+	if genesisHash == (common.Hash{}) {
+		return nil, errors.New("genesis block not found")
+	}
+	config := rawdb.ReadChainConfig(db, genesisHash)
+	if config == nil {
+		return nil, fmt.Errorf("chain config of genesis block %s not found", genesisHash)
+	}
+	return config, nil
+}
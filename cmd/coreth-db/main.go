@@ -0,0 +1,180 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// coreth-db inspects and repairs the databases of a Coreth VM, that is the
+// chain database and the tables the VM keeps alongside it, such as the atomic
+// trie and the atomic repository.
+//
+// The databases are opened directly, so the node must not be running while the
+// tool is used.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/sankar-boro/axia-network-v2/database"
+	"github.com/sankar-boro/axia-network-v2/database/leveldb"
+	"github.com/sankar-boro/axia-network-v2/ids"
+	"github.com/sankar-boro/axia-network-v2/utils/logging"
+	"github.com/sankar-boro/axia-network-v2/version"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/ethdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/internal/flags"
+	"github.com/sankar-boro/axia-network-v2-coreth/plugin/evm"
+	"github.com/ethereum/go-ethereum/log"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	// Git SHA1 commit hash of the release (set via linker flags)
+	gitCommit = ""
+	gitDate   = ""
+
+	app *cli.App
+
+	dataDirFlag = cli.StringFlag{
+		Name:  "datadir",
+		Usage: "Database directory of the node (db-dir, including the network name)",
+	}
+	chainIDFlag = cli.StringFlag{
+		Name:  "chain-id",
+		Usage: "ID of the blockchain run by the VM",
+	}
+	chainDBTypeFlag = cli.StringFlag{
+		Name:  "chaindb.type",
+		Usage: "Type of the chain database managed by Coreth (database-type), if the chain data is not stored in the node's database",
+	}
+	chainDBPathFlag = cli.StringFlag{
+		Name:  "chaindb.path",
+		Usage: "Directory of the chain database managed by Coreth (database-path)",
+	}
+	ancientFlag = cli.StringFlag{
+		Name:  "ancient",
+		Usage: "Directory of the freezer (freezer-directory)",
+	}
+	cacheFlag = cli.IntFlag{
+		Name:  "cache",
+		Usage: "Megabytes of memory allocated to the caches of the chain database managed by Coreth",
+		Value: 512,
+	}
+	handlesFlag = cli.IntFlag{
+		Name:  "handles",
+		Usage: "Number of files the chain database managed by Coreth may keep open",
+		Value: 1024,
+	}
+)
+
+func init() {
+	app = flags.NewApp(gitCommit, gitDate, "coreth database inspection and repair tool")
+	app.Flags = []cli.Flag{
+		dataDirFlag,
+		chainIDFlag,
+		chainDBTypeFlag,
+		chainDBPathFlag,
+		ancientFlag,
+		cacheFlag,
+		handlesFlag,
+	}
+	app.Commands = []cli.Command{
+		inspectCommand,
+		verifyStateCommand,
+		checkCanonicalCommand,
+		dumpAccountCommand,
+		getCommand,
+		putCommand,
+		deleteCommand,
+		repairAtomicCommand,
+	}
+	cli.CommandHelpTemplate = flags.OriginCommandHelpTemplate
+}
+
+// databases holds the databases of a VM opened by the tool.
+type databases struct {
+	nodeDB  database.Database
+	vmDB    database.Database // Database of the VM within [nodeDB]
+	chainDB ethdb.Database
+}
+
+// openDatabases opens the node's database and the databases of the VM selected
+// by the global flags of [c]. The chain database managed by Coreth is opened
+// read-only if [readOnly] is set.
+func openDatabases(c *cli.Context, readOnly bool) (*databases, error) {
+	dataDir := c.GlobalString(dataDirFlag.Name)
+	if dataDir == "" {
+		return nil, errors.New("no node database specified (--datadir)")
+	}
+	if c.GlobalString(chainIDFlag.Name) == "" {
+		return nil, errors.New("no blockchain specified (--chain-id)")
+	}
+	chainID, err := ids.FromString(c.GlobalString(chainIDFlag.Name))
+	if err != nil {
+		return nil, fmt.Errorf("invalid blockchain ID: %w", err)
+	}
+	path := filepath.Join(dataDir, version.CurrentDatabase.String())
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("node database not found: %w", err)
+	}
+	nodeDB, err := leveldb.New(path, nil, logging.NoLog{}, "", prometheus.NewRegistry())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open node database: %w", err)
+	}
+	vmDB := evm.NewVMDatabase(nodeDB, chainID)
+
+	var chainKV ethdb.KeyValueStore
+	if chainDBType := c.GlobalString(chainDBTypeFlag.Name); chainDBType != "" {
+		chainKV, err = rawdb.Open(rawdb.OpenOptions{
+			Type:      chainDBType,
+			Directory: c.GlobalString(chainDBPathFlag.Name),
+			Namespace: "eth/db/chaindata/",
+			Cache:     c.GlobalInt(cacheFlag.Name),
+			Handles:   c.GlobalInt(handlesFlag.Name),
+			ReadOnly:  readOnly,
+		})
+		if err != nil {
+			nodeDB.Close()
+			return nil, fmt.Errorf("failed to open chain database: %w", err)
+		}
+	} else {
+		chainKV = evm.NewChainKeyValueStore(vmDB)
+	}
+	// The freezer is always opened read-only, even by the commands writing to
+	// the chain database, so that the tool does not move blocks into it in the
+	// background.
+	var chainDB ethdb.Database
+	if ancient := c.GlobalString(ancientFlag.Name); ancient != "" {
+		chainDB, err = rawdb.NewDatabaseWithFreezer(chainKV, ancient, "eth/db/chaindata/", true, 0)
+		if err != nil {
+			chainKV.Close()
+			nodeDB.Close()
+			return nil, fmt.Errorf("failed to open chain database: %w", err)
+		}
+	} else {
+		chainDB = rawdb.NewDatabase(chainKV)
+	}
+	return &databases{
+		nodeDB:  nodeDB,
+		vmDB:    vmDB,
+		chainDB: chainDB,
+	}, nil
+}
+
+// Close closes the databases.
+func (dbs *databases) Close() {
+	dbs.chainDB.Close()
+	dbs.nodeDB.Close()
+}
+
+func main() {
+	log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StreamHandler(os.Stderr, log.TerminalFormat(true))))
+
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,89 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/sankar-boro/axia-network-v2/database/leveldb"
+	"github.com/sankar-boro/axia-network-v2/ids"
+	"github.com/sankar-boro/axia-network-v2/utils/logging"
+	"github.com/sankar-boro/axia-network-v2/version"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Tests that commands reading the databases do not move accepted blocks into
+// the freezer.
+func TestReadOnlyCommandKeepsFreezer(t *testing.T) {
+	var (
+		dataDir   = t.TempDir()
+		chainPath = t.TempDir()
+		ancient   = t.TempDir()
+	)
+	nodeDB, err := leveldb.New(filepath.Join(dataDir, version.CurrentDatabase.String()), nil, logging.NoLog{}, "", prometheus.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodeDB.Close()
+
+	// Write a chain of accepted blocks, all of which may be frozen
+	chainDB, err := rawdb.Open(rawdb.OpenOptions{Type: rawdb.DBLeveldb, Directory: chainPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks := make([]*types.Block, 10)
+	parent := common.Hash{}
+	for i := range blocks {
+		block := types.NewBlockWithHeader(&types.Header{
+			ParentHash:  parent,
+			Number:      big.NewInt(int64(i)),
+			UncleHash:   types.EmptyUncleHash,
+			TxHash:      types.EmptyRootHash,
+			ReceiptHash: types.EmptyRootHash,
+		})
+		rawdb.WriteBlock(chainDB, block)
+		rawdb.WriteCanonicalHash(chainDB, block.Hash(), block.NumberU64())
+		rawdb.WriteReceipts(chainDB, block.Hash(), block.NumberU64(), types.Receipts{})
+		blocks[i] = block
+		parent = block.Hash()
+	}
+	if err := rawdb.WriteAcceptorTip(chainDB, parent); err != nil {
+		t.Fatal(err)
+	}
+	chainDB.Close()
+
+	err = app.Run([]string{
+		"coreth-db",
+		"--" + dataDirFlag.Name, dataDir,
+		"--" + chainIDFlag.Name, ids.GenerateTestID().String(),
+		"--" + chainDBTypeFlag.Name, rawdb.DBLeveldb,
+		"--" + chainDBPathFlag.Name, chainPath,
+		"--" + ancientFlag.Name, ancient,
+		getCommand.Name, "0x6800000000000000006e", // Canonical hash of the genesis block
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chainDB, err = rawdb.Open(rawdb.OpenOptions{Type: rawdb.DBLeveldb, Directory: chainPath, ReadOnly: true, AncientsDirectory: ancient})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chainDB.Close()
+	if frozen, _ := chainDB.Ancients(); frozen != 0 {
+		t.Fatalf("expected no frozen blocks, got %d", frozen)
+	}
+	for _, block := range blocks {
+		if !rawdb.HasBody(chainDB, block.Hash(), block.NumberU64()) {
+			t.Fatalf("block %d missing from key-value store", block.NumberU64())
+		}
+	}
+}
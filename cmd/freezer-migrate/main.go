@@ -0,0 +1,113 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// freezer-migrate moves the accepted blocks of an existing chain database
+// managed by Coreth (see the database-type config option) into a freezer.
+//
+// The VM must not be running while the tool is used. Chain data stored in the
+// node's database is migrated in the background once the VM is started with
+// the freezer-directory config option, so it does not need this tool.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/internal/flags"
+	"github.com/ethereum/go-ethereum/log"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	// Git SHA1 commit hash of the release (set via linker flags)
+	gitCommit = ""
+	gitDate   = ""
+
+	app *cli.App
+
+	dataDirFlag = cli.StringFlag{
+		Name:  "datadir",
+		Usage: "Directory of the chain database (database-path)",
+	}
+	dbTypeFlag = cli.StringFlag{
+		Name:  "db.type",
+		Usage: "Type of the chain database (leveldb or pebble), defaults to the type of the existing database",
+	}
+	ancientFlag = cli.StringFlag{
+		Name:  "ancient",
+		Usage: "Directory of the freezer (freezer-directory)",
+	}
+	thresholdFlag = cli.Uint64Flag{
+		Name:  "threshold",
+		Usage: "Number of most recent accepted blocks kept in the chain database (freezer-threshold)",
+		Value: 90000,
+	}
+	cacheFlag = cli.IntFlag{
+		Name:  "cache",
+		Usage: "Megabytes of memory allocated to the caches of the chain database",
+		Value: 512,
+	}
+	handlesFlag = cli.IntFlag{
+		Name:  "handles",
+		Usage: "Number of files the chain database may keep open",
+		Value: 1024,
+	}
+)
+
+func init() {
+	app = flags.NewApp(gitCommit, gitDate, "coreth freezer migration tool")
+	app.Flags = []cli.Flag{
+		dataDirFlag,
+		dbTypeFlag,
+		ancientFlag,
+		thresholdFlag,
+		cacheFlag,
+		handlesFlag,
+	}
+	app.Action = migrate
+	cli.CommandHelpTemplate = flags.OriginCommandHelpTemplate
+}
+
+func migrate(c *cli.Context) error {
+	if c.GlobalString(dataDirFlag.Name) == "" {
+		return errors.New("no chain database specified (--datadir)")
+	}
+	if c.GlobalString(ancientFlag.Name) == "" {
+		return errors.New("no freezer directory specified (--ancient)")
+	}
+	if c.GlobalUint64(thresholdFlag.Name) == 0 {
+		return errors.New("freezer threshold must be positive (--threshold)")
+	}
+	db, err := rawdb.Open(rawdb.OpenOptions{
+		Type:              c.GlobalString(dbTypeFlag.Name),
+		Directory:         c.GlobalString(dataDirFlag.Name),
+		Namespace:         "eth/db/chaindata/",
+		Cache:             c.GlobalInt(cacheFlag.Name),
+		Handles:           c.GlobalInt(handlesFlag.Name),
+		AncientsDirectory: c.GlobalString(ancientFlag.Name),
+		FreezerThreshold:  c.GlobalUint64(thresholdFlag.Name),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to open chain database: %w", err)
+	}
+	defer db.Close()
+
+	moved, err := rawdb.FreezeAncients(db)
+	if err != nil {
+		return fmt.Errorf("failed to move blocks into freezer after %d blocks: %w", moved, err)
+	}
+	frozen, _ := db.Ancients()
+	log.Info("Migrated chain database", "moved", moved, "frozen", frozen)
+	return nil
+}
+
+func main() {
+	log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StreamHandler(os.Stderr, log.TerminalFormat(true))))
+
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
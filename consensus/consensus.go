@@ -53,6 +53,9 @@ type ChainHeaderReader interface {
 
 	// GetHeaderByHash retrieves a block header from the database by its hash.
 	GetHeaderByHash(hash common.Hash) *types.Header
+
+	// GetFeeConfigAt retrieves the fee config in effect for the child of [parent].
+	GetFeeConfigAt(parent *types.Header) (params.FeeConfig, error)
 }
 
 // ChainReader defines a small collection of methods needed to access the local
@@ -36,8 +36,10 @@ var (
 // CalcBaseFee takes the previous header and the timestamp of its child block
 // and calculates the expected base fee as well as the encoding of the past
 // pricing information for the child block.
+// [feeConfig] is the fee config in effect for the child block, which is used
+// as of Apricot Phase 5.
 // CalcBaseFee should only be called if [timestamp] >= [config.ApricotPhase3Timestamp]
-func CalcBaseFee(config *params.ChainConfig, parent *types.Header, timestamp uint64) ([]byte, *big.Int, error) {
+func CalcBaseFee(config *params.ChainConfig, feeConfig params.FeeConfig, parent *types.Header, timestamp uint64) ([]byte, *big.Int, error) {
 	// If the current block is the first EIP-1559 block, or it is the genesis block
 	// return the initial slice and initial base fee.
 	bigTimestamp := new(big.Int).SetUint64(parent.Time)
@@ -67,16 +69,16 @@ func CalcBaseFee(config *params.ChainConfig, parent *types.Header, timestamp uin
 		return nil, nil, err
 	}
 
-	// If AP5, use a less responsive [BaseFeeChangeDenominator] and a higher gas
-	// block limit
+	// If AP5, use the [BaseFeeChangeDenominator] and gas target of the fee
+	// config
 	var (
 		baseFee                  = new(big.Int).Set(parent.BaseFee)
 		baseFeeChangeDenominator = ApricotPhase4BaseFeeChangeDenominator
 		parentGasTarget          = params.ApricotPhase3TargetGas
 	)
 	if isApricotPhase5 {
-		baseFeeChangeDenominator = ApricotPhase5BaseFeeChangeDenominator
-		parentGasTarget = params.ApricotPhase5TargetGas
+		baseFeeChangeDenominator = feeConfig.BaseFeeChangeDenominator
+		parentGasTarget = feeConfig.TargetGas.Uint64()
 	}
 	parentGasTargetBig := new(big.Int).SetUint64(parentGasTarget)
 
@@ -176,7 +178,7 @@ func CalcBaseFee(config *params.ChainConfig, parent *types.Header, timestamp uin
 	// Ensure that the base fee does not increase/decrease outside of the bounds
 	switch {
 	case isApricotPhase5:
-		baseFee = selectBigWithinBounds(ApricotPhase4MinBaseFee, baseFee, nil)
+		baseFee = selectBigWithinBounds(feeConfig.MinBaseFee, baseFee, nil)
 	case isApricotPhase4:
 		baseFee = selectBigWithinBounds(ApricotPhase4MinBaseFee, baseFee, ApricotPhase4MaxBaseFee)
 	default:
@@ -191,11 +193,11 @@ func CalcBaseFee(config *params.ChainConfig, parent *types.Header, timestamp uin
 // If [timestamp] is less than the timestamp of [parent], then it uses the same timestamp as parent.
 // Warning: This function should only be used in estimation and should not be used when calculating the canonical
 // base fee for a subsequent block.
-func EstimateNextBaseFee(config *params.ChainConfig, parent *types.Header, timestamp uint64) ([]byte, *big.Int, error) {
+func EstimateNextBaseFee(config *params.ChainConfig, feeConfig params.FeeConfig, parent *types.Header, timestamp uint64) ([]byte, *big.Int, error) {
 	if timestamp < parent.Time {
 		timestamp = parent.Time
 	}
-	return CalcBaseFee(config, parent, timestamp)
+	return CalcBaseFee(config, feeConfig, parent, timestamp)
 }
 
 // selectBigWithinBounds returns [value] if it is within the bounds:
@@ -282,6 +284,31 @@ func updateLongWindow(window []byte, start uint64, gasConsumed uint64) {
 	binary.BigEndian.PutUint64(window[start:], totalGasConsumed)
 }
 
+// CalcBlockGasCost calculates the required block gas cost of the child of
+// [parent] with [timestamp]. As of Apricot Phase 5, the block gas cost is
+// parameterized by [feeConfig], the fee config in effect for the child block.
+// CalcBlockGasCost should only be called if [timestamp] >= [config.ApricotPhase4Timestamp]
+func CalcBlockGasCost(config *params.ChainConfig, feeConfig params.FeeConfig, parent *types.Header, timestamp uint64) *big.Int {
+	if config.IsApricotPhase5(new(big.Int).SetUint64(timestamp)) {
+		return calcBlockGasCost(
+			feeConfig.TargetBlockRate,
+			feeConfig.MinBlockGasCost,
+			feeConfig.MaxBlockGasCost,
+			feeConfig.BlockGasCostStep,
+			parent.BlockGasCost,
+			parent.Time, timestamp,
+		)
+	}
+	return calcBlockGasCost(
+		ApricotPhase4TargetBlockRate,
+		ApricotPhase4MinBlockGasCost,
+		ApricotPhase4MaxBlockGasCost,
+		ApricotPhase4BlockGasCostStep,
+		parent.BlockGasCost,
+		parent.Time, timestamp,
+	)
+}
+
 // calcBlockGasCost calculates the required block gas cost. If [parentTime]
 // > [currentTime], the timeElapsed will be treated as 0.
 func calcBlockGasCost(
@@ -203,7 +203,7 @@ func testDynamicFeesStaysWithinRange(t *testing.T, test test) {
 	}
 
 	for index, block := range blocks[1:] {
-		nextExtraData, nextBaseFee, err := CalcBaseFee(params.TestApricotPhase3Config, header, block.timestamp)
+		nextExtraData, nextBaseFee, err := CalcBaseFee(params.TestApricotPhase3Config, params.DefaultFeeConfig, header, block.timestamp)
 		if err != nil {
 			t.Fatalf("Failed to calculate base fee at index %d: %s", index, err)
 		}
@@ -408,7 +408,7 @@ func TestCalcBaseFeeAP4(t *testing.T) {
 
 	for index, event := range events {
 		block := event.block
-		nextExtraData, nextBaseFee, err := CalcBaseFee(params.TestApricotPhase4Config, header, block.timestamp)
+		nextExtraData, nextBaseFee, err := CalcBaseFee(params.TestApricotPhase4Config, params.DefaultFeeConfig, header, block.timestamp)
 		assert.NoError(t, err)
 		log.Info("Update", "baseFee", nextBaseFee)
 		header = &types.Header{
@@ -419,7 +419,7 @@ func TestCalcBaseFeeAP4(t *testing.T) {
 			Extra:   nextExtraData,
 		}
 
-		nextExtraData, nextBaseFee, err = CalcBaseFee(params.TestApricotPhase4Config, extDataHeader, block.timestamp)
+		nextExtraData, nextBaseFee, err = CalcBaseFee(params.TestApricotPhase4Config, params.DefaultFeeConfig, extDataHeader, block.timestamp)
 		assert.NoError(t, err)
 		log.Info("Update", "baseFee (w/extData)", nextBaseFee)
 		extDataHeader = &types.Header{
@@ -536,3 +536,63 @@ func TestCalcBlockGasCost(t *testing.T) {
 		})
 	}
 }
+
+func TestCalcBaseFeeFeeConfig(t *testing.T) {
+	parent := &types.Header{
+		Number:  big.NewInt(1),
+		Time:    10,
+		Extra:   make([]byte, params.ApricotPhase3ExtraDataSize),
+		BaseFee: big.NewInt(params.ApricotPhase4MinBaseFee),
+		GasUsed: 0,
+	}
+
+	// An empty window decreases the base fee down to the minimum.
+	_, baseFee, err := CalcBaseFee(params.TestApricotPhase5Config, params.DefaultFeeConfig, parent, 12)
+	assert.NoError(t, err)
+	assert.Equal(t, params.DefaultFeeConfig.MinBaseFee, baseFee)
+
+	// A higher minimum base fee raises the floor.
+	feeConfig := params.DefaultFeeConfig
+	feeConfig.MinBaseFee = big.NewInt(100 * params.GWei)
+	_, baseFee, err = CalcBaseFee(params.TestApricotPhase5Config, feeConfig, parent, 12)
+	assert.NoError(t, err)
+	assert.Equal(t, feeConfig.MinBaseFee, baseFee)
+
+	// A lower target gas and change denominator increase the base fee faster.
+	parent.GasUsed = 10_000_000
+	_, defaultBaseFee, err := CalcBaseFee(params.TestApricotPhase5Config, params.DefaultFeeConfig, parent, 12)
+	assert.NoError(t, err)
+	feeConfig = params.DefaultFeeConfig
+	feeConfig.TargetGas = big.NewInt(5_000_000)
+	feeConfig.BaseFeeChangeDenominator = big.NewInt(12)
+	_, baseFee, err = CalcBaseFee(params.TestApricotPhase5Config, feeConfig, parent, 12)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, baseFee.Cmp(defaultBaseFee), "expected %d > %d", baseFee, defaultBaseFee)
+
+	// The fee config is ignored prior to Apricot Phase 5.
+	_, ap4BaseFee, err := CalcBaseFee(params.TestApricotPhase4Config, params.DefaultFeeConfig, parent, 12)
+	assert.NoError(t, err)
+	_, baseFee, err = CalcBaseFee(params.TestApricotPhase4Config, feeConfig, parent, 12)
+	assert.NoError(t, err)
+	assert.Equal(t, ap4BaseFee, baseFee)
+}
+
+func TestCalcBlockGasCostFeeConfig(t *testing.T) {
+	feeConfig := params.DefaultFeeConfig
+	feeConfig.TargetBlockRate = 5
+	feeConfig.MinBlockGasCost = big.NewInt(10_000)
+	feeConfig.MaxBlockGasCost = big.NewInt(500_000)
+	feeConfig.BlockGasCostStep = big.NewInt(50_000)
+
+	parent := &types.Header{Time: 1, BlockGasCost: big.NewInt(100_000)}
+	// 2s elapsed with a target of 5s increases the cost by 3 steps.
+	assert.Equal(t, big.NewInt(250_000), CalcBlockGasCost(params.TestApricotPhase5Config, feeConfig, parent, 3))
+	// The cost is bounded by the configured maximum and minimum.
+	assert.Equal(t, big.NewInt(500_000), CalcBlockGasCost(params.TestApricotPhase5Config, feeConfig, &types.Header{Time: 1, BlockGasCost: big.NewInt(400_000)}, 1))
+	assert.Equal(t, big.NewInt(10_000), CalcBlockGasCost(params.TestApricotPhase5Config, feeConfig, parent, 100))
+	// A nil parent block gas cost returns the configured minimum.
+	assert.Equal(t, big.NewInt(10_000), CalcBlockGasCost(params.TestApricotPhase5Config, feeConfig, &types.Header{Time: 1}, 3))
+
+	// Prior to Apricot Phase 5 the Apricot Phase 4 constants are used.
+	assert.Equal(t, big.NewInt(150_000), CalcBlockGasCost(params.TestApricotPhase4Config, feeConfig, parent, 2))
+}
@@ -76,6 +76,19 @@ func BenchmarkInsertChain_ring1000_diskdb(b *testing.B) {
 	benchInsertChain(b, true, genTxRing(1000))
 }
 
+func BenchmarkInsertChain_storage_sequential_memdb(b *testing.B) {
+	benchInsertStorageChain(b, false, 1)
+}
+func BenchmarkInsertChain_storage_sequential_diskdb(b *testing.B) {
+	benchInsertStorageChain(b, true, 1)
+}
+func BenchmarkInsertChain_storage_parallel_memdb(b *testing.B) {
+	benchInsertStorageChain(b, false, 0)
+}
+func BenchmarkInsertChain_storage_parallel_diskdb(b *testing.B) {
+	benchInsertStorageChain(b, true, 0)
+}
+
 var (
 	// This is the content of the genesis block used by the benchmarks.
 	benchRootKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
@@ -90,7 +103,7 @@ func genValueTx(nbytes int) func(int, *BlockGen) {
 	return func(i int, gen *BlockGen) {
 		toaddr := common.Address{}
 		data := make([]byte, nbytes)
-		gas, _ := IntrinsicGas(data, nil, false, false, false)
+		gas, _ := IntrinsicGas(data, nil, false, false, false, false)
 		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(benchRootAddr), toaddr, big.NewInt(1), gas, big.NewInt(225000000000), data), types.HomesteadSigner{}, benchRootKey)
 		gen.AddTx(tx)
 	}
@@ -141,7 +154,85 @@ func genTxRing(naccounts int) func(int, *BlockGen) {
 	}
 }
 
+const (
+	// benchStorageContracts is the number of contracts whose storage is
+	// modified by the blocks of the storage benchmarks.
+	benchStorageContracts = 1000
+
+	// benchStorageSlots is the number of storage slots of each contract in the
+	// genesis of the storage benchmarks.
+	benchStorageSlots = 256
+
+	// benchStorageWrites is the number of storage slots written by each call
+	// to a contract of the storage benchmarks.
+	benchStorageWrites = 8
+)
+
+// benchStorageCode stores the block number in the first [benchStorageWrites]
+// storage slots of the contract.
+var benchStorageCode = func() []byte {
+	var code []byte
+	for i := 0; i < benchStorageWrites; i++ {
+		code = append(code, byte(vm.NUMBER), byte(vm.PUSH1), byte(i), byte(vm.SSTORE))
+	}
+	return append(code, byte(vm.STOP))
+}()
+
+// benchStorageAddr returns the address of the [i]th contract of the storage
+// benchmarks.
+func benchStorageAddr(i int) common.Address {
+	return common.BigToAddress(big.NewInt(int64(0x10000 + i)))
+}
+
+// benchStorageAlloc returns the genesis allocation of the storage benchmarks,
+// which holds [benchStorageContracts] contracts with [benchStorageSlots]
+// storage slots each.
+func benchStorageAlloc() GenesisAlloc {
+	alloc := GenesisAlloc{benchRootAddr: {Balance: benchRootFunds}}
+	for i := 0; i < benchStorageContracts; i++ {
+		storage := make(map[common.Hash]common.Hash, benchStorageSlots)
+		for j := 0; j < benchStorageSlots; j++ {
+			storage[common.BigToHash(big.NewInt(int64(j)))] = common.BigToHash(big.NewInt(int64(i*benchStorageSlots + j + 1)))
+		}
+		alloc[benchStorageAddr(i)] = GenesisAccount{
+			Code:    benchStorageCode,
+			Storage: storage,
+			Balance: new(big.Int),
+		}
+	}
+	return alloc
+}
+
+// genStorageTx returns a block generator that fills the blocks with calls to
+// the contracts of the storage benchmarks, so that every block modifies the
+// storage tries of many accounts.
+func genStorageTx() func(int, *BlockGen) {
+	const gasPerTx = 100_000
+	next := 0
+	return func(i int, gen *BlockGen) {
+		gas := gen.PrevBlock(i - 1).GasLimit()
+		for ; gas >= gasPerTx; gas -= gasPerTx {
+			tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(benchRootAddr), benchStorageAddr(next), new(big.Int), gasPerTx, big.NewInt(225000000000), nil), types.HomesteadSigner{}, benchRootKey)
+			gen.AddTx(tx)
+			next = (next + 1) % benchStorageContracts
+		}
+	}
+}
+
+// benchInsertStorageChain benchmarks the insertion of blocks modifying the
+// storage tries of many accounts, with [workers] hashing and committing the
+// storage tries concurrently.
+func benchInsertStorageChain(b *testing.B, disk bool, workers int) {
+	cacheConfig := *DefaultCacheConfig
+	cacheConfig.StorageTrieWorkers = workers
+	benchInsertChainWithConfig(b, disk, &cacheConfig, benchStorageAlloc(), genStorageTx())
+}
+
 func benchInsertChain(b *testing.B, disk bool, gen func(int, *BlockGen)) {
+	benchInsertChainWithConfig(b, disk, DefaultCacheConfig, GenesisAlloc{benchRootAddr: {Balance: benchRootFunds}}, gen)
+}
+
+func benchInsertChainWithConfig(b *testing.B, disk bool, cacheConfig *CacheConfig, alloc GenesisAlloc, gen func(int, *BlockGen)) {
 	// Create the database in memory or in a temporary directory.
 	var db ethdb.Database
 	if !disk {
@@ -163,14 +254,14 @@ func benchInsertChain(b *testing.B, disk bool, gen func(int, *BlockGen)) {
 	// generator function.
 	gspec := Genesis{
 		Config: params.TestChainConfig,
-		Alloc:  GenesisAlloc{benchRootAddr: {Balance: benchRootFunds}},
+		Alloc:  alloc,
 	}
 	genesis := gspec.MustCommit(db)
 	chain, _, _ := GenerateChain(gspec.Config, genesis, dummy.NewFaker(), db, b.N, 10, gen)
 
 	// Time the insertion of the new chain.
 	// State and blocks are stored in the same DB.
-	chainman, _ := NewBlockChain(db, DefaultCacheConfig, gspec.Config, dummy.NewFaker(), vm.Config{}, common.Hash{})
+	chainman, _ := NewBlockChain(db, cacheConfig, gspec.Config, dummy.NewFaker(), vm.Config{}, common.Hash{})
 	defer chainman.Stop()
 	b.ReportAllocs()
 	b.ResetTimer()
@@ -28,6 +28,7 @@ package core
 
 import (
 	"fmt"
+	"math/big"
 
 	"github.com/sankar-boro/axia-network-v2-coreth/consensus"
 	"github.com/sankar-boro/axia-network-v2-coreth/core/state"
@@ -75,6 +76,15 @@ func (v *BlockValidator) ValidateBody(block *types.Block) error {
 	if hash := types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)); hash != header.TxHash {
 		return fmt.Errorf("transaction root hash mismatch: have %x, want %x", hash, header.TxHash)
 	}
+	// As of Apricot Phase 6, creation transactions with init code larger than
+	// the init code size limit are invalid (EIP-3860).
+	if v.config.IsApricotPhase6(new(big.Int).SetUint64(header.Time)) {
+		for i, tx := range block.Transactions() {
+			if tx.To() == nil && len(tx.Data()) > params.MaxInitCodeSize {
+				return fmt.Errorf("invalid transaction %d: %w: code size %v limit %v", i, ErrMaxInitCodeSizeExceeded, len(tx.Data()), params.MaxInitCodeSize)
+			}
+		}
+	}
 	if !v.bc.HasBlockAndState(block.ParentHash(), block.NumberU64()-1) {
 		if !v.bc.HasBlock(block.ParentHash(), block.NumberU64()-1) {
 			return consensus.ErrUnknownAncestor
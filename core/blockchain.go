@@ -41,6 +41,7 @@ import (
 	"github.com/sankar-boro/axia-network-v2-coreth/consensus"
 	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
 	"github.com/sankar-boro/axia-network-v2-coreth/core/state"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/state/pruner"
 	"github.com/sankar-boro/axia-network-v2-coreth/core/state/snapshot"
 	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
 	"github.com/sankar-boro/axia-network-v2-coreth/core/vm"
@@ -64,12 +65,13 @@ var (
 )
 
 const (
-	bodyCacheLimit     = 256
-	blockCacheLimit    = 256
-	receiptsCacheLimit = 32
-	txLookupCacheLimit = 1024
-	badBlockLimit      = 10
-	TriesInMemory      = 128
+	bodyCacheLimit      = 256
+	blockCacheLimit     = 256
+	receiptsCacheLimit  = 32
+	txLookupCacheLimit  = 1024
+	feeConfigCacheLimit = 256
+	badBlockLimit       = 10
+	TriesInMemory       = 128
 
 	// BlockChainVersion ensures that an incompatible database forces a resync from scratch.
 	//
@@ -119,6 +121,21 @@ type CacheConfig struct {
 	SnapshotVerify                  bool    // Verify generated snapshots
 	SkipSnapshotRebuild             bool    // Whether to skip rebuilding the snapshot in favor of returning an error (only set to true for tests)
 	Preimages                       bool    // Whether to store preimage of trie key to the disk
+	ParallelExecutionWorkers        int     // Number of workers to optimistically execute transactions in parallel (< 2 = serial execution)
+	StorageTrieWorkers              int     // Number of workers hashing and committing storage tries concurrently (< 1 = GOMAXPROCS)
+
+	OnlinePruning            bool          // Whether to delete unreachable trie nodes in the background (requires [Pruning])
+	OnlinePruningInterval    time.Duration // Time between the starts of two online pruning runs
+	OnlinePruningBloomSize   uint64        // Memory allowance (MB) for the bloom filter of live trie nodes
+	OnlinePruningBatchSize   int           // Maximum number of trie nodes deleted in one batch
+	OnlinePruningBatchDelay  time.Duration // Time to wait between two batches of deletions
+	StateSyncSummaryInterval uint64        // Interval of the state summaries whose state is retained by the online pruner
+
+	StateScheme  string // Scheme of the trie nodes on disk (rawdb.HashScheme or rawdb.PathScheme)
+	StateHistory uint64 // Number of most recently accepted states kept by the path scheme
+
+	HistoryDiffs bool // Whether to index the state changes of accepted blocks to serve their state once it is pruned
+	LogIndex     bool // Whether to index the logs of accepted blocks by address and topic
 }
 
 var DefaultCacheConfig = &CacheConfig{
@@ -172,12 +189,13 @@ type BlockChain struct {
 
 	currentBlock atomic.Value // Current head of the block chain
 
-	stateCache    state.Database // State database to reuse between imports (contains state cache)
-	stateManager  TrieWriter
-	bodyCache     *lru.Cache // Cache for the most recent block bodies
-	receiptsCache *lru.Cache // Cache for the most recent receipts per block
-	blockCache    *lru.Cache // Cache for the most recent entire blocks
-	txLookupCache *lru.Cache // Cache for the most recent transaction lookup data.
+	stateCache     state.Database // State database to reuse between imports (contains state cache)
+	stateManager   TrieWriter
+	bodyCache      *lru.Cache // Cache for the most recent block bodies
+	receiptsCache  *lru.Cache // Cache for the most recent receipts per block
+	blockCache     *lru.Cache // Cache for the most recent entire blocks
+	txLookupCache  *lru.Cache // Cache for the most recent transaction lookup data.
+	feeConfigCache *lru.Cache // Cache for the most recent fee configs read from the state
 
 	running int32 // 0 if chain is running, 1 when stopped
 
@@ -217,6 +235,13 @@ type BlockChain struct {
 	// processed blocks. This may be equal to [lastAccepted].
 	acceptorTip     *types.Block
 	acceptorTipLock sync.Mutex
+
+	// [onlinePruner] deletes unreachable trie nodes in the background if
+	// online pruning is enabled. [onlinePrunerQuit] stops it and
+	// [onlinePrunerWg] waits for it to exit.
+	onlinePruner     *pruner.OnlinePruner
+	onlinePrunerQuit chan struct{}
+	onlinePrunerWg   sync.WaitGroup
 }
 
 // NewBlockChain returns a fully initialised block chain using information
@@ -233,6 +258,7 @@ func NewBlockChain(
 	receiptsCache, _ := lru.New(receiptsCacheLimit)
 	blockCache, _ := lru.New(blockCacheLimit)
 	txLookupCache, _ := lru.New(txLookupCacheLimit)
+	feeConfigCache, _ := lru.New(feeConfigCacheLimit)
 	badBlocks, _ := lru.New(badBlockLimit)
 
 	bc := &BlockChain{
@@ -240,18 +266,21 @@ func NewBlockChain(
 		cacheConfig: cacheConfig,
 		db:          db,
 		stateCache: state.NewDatabaseWithConfig(db, &trie.Config{
-			Cache:     cacheConfig.TrieCleanLimit,
-			Preimages: cacheConfig.Preimages,
+			Cache:        cacheConfig.TrieCleanLimit,
+			Preimages:    cacheConfig.Preimages,
+			Scheme:       cacheConfig.StateScheme,
+			StateHistory: cacheConfig.StateHistory,
 		}),
-		bodyCache:     bodyCache,
-		receiptsCache: receiptsCache,
-		blockCache:    blockCache,
-		txLookupCache: txLookupCache,
-		engine:        engine,
-		vmConfig:      vmConfig,
-		badBlocks:     badBlocks,
-		senderCacher:  newTxSenderCacher(runtime.NumCPU()),
-		acceptorQueue: make(chan *types.Block, cacheConfig.AcceptorQueueLimit),
+		bodyCache:      bodyCache,
+		receiptsCache:  receiptsCache,
+		blockCache:     blockCache,
+		txLookupCache:  txLookupCache,
+		feeConfigCache: feeConfigCache,
+		engine:         engine,
+		vmConfig:       vmConfig,
+		badBlocks:      badBlocks,
+		senderCacher:   newTxSenderCacher(runtime.NumCPU()),
+		acceptorQueue:  make(chan *types.Block, cacheConfig.AcceptorQueueLimit),
 	}
 	bc.validator = NewBlockValidator(chainConfig, bc, engine)
 	bc.prefetcher = newStatePrefetcher(chainConfig, bc, engine)
@@ -312,6 +341,13 @@ func NewBlockChain(
 	// Start processing accepted blocks effects in the background
 	go bc.startAcceptor()
 
+	// Start pruning in the background once the state is available. If snapshot
+	// initialization is delayed, the state may still be synced, so the online
+	// pruner is started together with the snapshots.
+	if !bc.cacheConfig.SnapshotDelayInit {
+		bc.startOnlinePruner()
+	}
+
 	return bc, nil
 }
 
@@ -322,6 +358,12 @@ func NewBlockChain(
 func (bc *BlockChain) writeBlockAcceptedIndices(b *types.Block) error {
 	batch := bc.db.NewBatch()
 	rawdb.WriteTxLookupEntriesByBlock(batch, b)
+	if bc.cacheConfig.HistoryDiffs {
+		bc.indexStateHistory(batch, b)
+	}
+	if bc.cacheConfig.LogIndex {
+		bc.indexLogs(batch, b)
+	}
 	if err := rawdb.WriteAcceptorTip(batch, b.Hash()); err != nil {
 		return fmt.Errorf("%w: failed to write acceptor tip key", err)
 	}
@@ -331,6 +373,66 @@ func (bc *BlockChain) writeBlockAcceptedIndices(b *types.Block) error {
 	return nil
 }
 
+// writeStateHistory computes the state changes of [block] and stores them until
+// the block is accepted.
+func (bc *BlockChain) writeStateHistory(block *types.Block) error {
+	parent := bc.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return fmt.Errorf("missing parent %s:%d of block %s", block.ParentHash().Hex(), block.NumberU64()-1, block.Hash().Hex())
+	}
+	history, err := state.NewStateHistory(bc.stateCache, parent.Root, block.Root())
+	if err != nil {
+		return fmt.Errorf("failed to compute state changes of block %s: %w", block.Hash().Hex(), err)
+	}
+	rawdb.WriteStateHistory(bc.db, block.NumberU64(), block.Hash(), history)
+	return nil
+}
+
+// indexStateHistory indexes the state changes of the accepted block [b] into
+// [batch], so that the states of [b] and its ancestors since the tail of the
+// state history can be served once they are pruned.
+func (bc *BlockChain) indexStateHistory(batch ethdb.Batch, b *types.Block) {
+	number := b.NumberU64()
+	history := rawdb.ReadStateHistory(bc.db, number, b.Hash())
+	if history == nil {
+		// The state changes of [b] are unknown (e.g. it was inserted before
+		// history diffs were enabled), so only the states from [b] on can be
+		// served.
+		rawdb.WriteStateHistoryTail(batch, number+1)
+		rawdb.WriteStateHistoryNumber(batch, b.Root(), number)
+		rawdb.WriteStateHistoryHead(batch, number)
+		return
+	}
+	// Start a new range of indexed blocks if the state changes of the parent
+	// are not indexed (e.g. after state sync).
+	if head := rawdb.ReadStateHistoryHead(bc.db); head == nil || *head+1 != number {
+		rawdb.WriteStateHistoryTail(batch, number)
+		if parent := bc.GetHeader(b.ParentHash(), number-1); parent != nil {
+			rawdb.WriteStateHistoryNumber(batch, parent.Root, number-1)
+		}
+	}
+	rawdb.WriteStateHistoryIndex(batch, number, b.Root(), history)
+	rawdb.DeleteStateHistory(batch, number, b.Hash())
+	rawdb.WriteStateHistoryHead(batch, number)
+}
+
+// indexLogs indexes the logs of the accepted block [b] into [batch] by address
+// and topic, so that they can be filtered without the bloom bits.
+func (bc *BlockChain) indexLogs(batch ethdb.Batch, b *types.Block) {
+	number := b.NumberU64()
+	head := rawdb.ReadLogIndexHead(bc.db)
+	if head != nil && *head >= number {
+		return // already indexed (e.g. when reprocessing state)
+	}
+	// Start a new range of indexed blocks if the logs of the parent are not
+	// indexed (e.g. the index was just enabled).
+	if head == nil || *head+1 != number {
+		rawdb.WriteLogIndexTail(batch, number)
+	}
+	rawdb.WriteLogIndex(batch, bc.gatherBlockLogs(b.Hash(), number, false))
+	rawdb.WriteLogIndexHead(batch, number)
+}
+
 // flattenSnapshot attempts to flatten a block of [hash] to disk.
 func (bc *BlockChain) flattenSnapshot(postAbortWork func() error, hash common.Hash) error {
 	// If snapshots are not initialized, perform [postAbortWork] immediately.
@@ -452,6 +554,76 @@ func (bc *BlockChain) InitializeSnapshots() {
 
 	head := bc.CurrentBlock()
 	bc.initSnapshot(head)
+	bc.startOnlinePruner()
+}
+
+// startOnlinePruner starts deleting the unreachable trie nodes every
+// [OnlinePruningInterval] in the background, if online pruning is enabled and
+// the online pruner is not running yet.
+func (bc *BlockChain) startOnlinePruner() {
+	if !bc.cacheConfig.OnlinePruning || !bc.cacheConfig.Pruning || bc.onlinePruner != nil {
+		return
+	}
+	bc.onlinePruner = pruner.NewOnlinePruner(bc.db, bc.stateCache.TrieDB(), pruner.OnlinePrunerConfig{
+		BloomSize:  bc.cacheConfig.OnlinePruningBloomSize,
+		BatchSize:  bc.cacheConfig.OnlinePruningBatchSize,
+		BatchDelay: bc.cacheConfig.OnlinePruningBatchDelay,
+	})
+	bc.onlinePrunerQuit = make(chan struct{})
+
+	bc.onlinePrunerWg.Add(1)
+	go func() {
+		defer bc.onlinePrunerWg.Done()
+
+		timer := time.NewTimer(bc.cacheConfig.OnlinePruningInterval)
+		defer timer.Stop()
+		for {
+			select {
+			case <-timer.C:
+			case <-bc.onlinePrunerQuit:
+				return
+			}
+			if err := bc.onlinePruner.Prune(bc.retainedStateRoots, bc.onlinePrunerQuit); err != nil {
+				if errors.Is(err, pruner.ErrPruningAborted) {
+					return
+				}
+				log.Error("Online state pruning failed", "err", err)
+			}
+			timer.Reset(bc.cacheConfig.OnlinePruningInterval)
+		}
+	}()
+}
+
+// retainedStateRoots returns the state roots whose trie nodes must be kept by
+// the online pruner: the state of the last accepted block, the states of the
+// last two commit intervals, the state of the last state summary and the root
+// of the snapshot disk layer. The state of the last accepted block is first.
+func (bc *BlockChain) retainedStateRoots() []common.Hash {
+	bc.chainmu.RLock()
+	defer bc.chainmu.RUnlock()
+
+	lastAccepted := bc.LastAcceptedBlock()
+	roots := []common.Hash{lastAccepted.Root()}
+	addRoot := func(height uint64) {
+		if header := bc.GetHeaderByNumber(height); header != nil {
+			roots = append(roots, header.Root)
+		}
+	}
+	height := lastAccepted.NumberU64()
+	if interval := bc.cacheConfig.CommitInterval; interval != 0 {
+		lastCommit := height - height%interval
+		addRoot(lastCommit)
+		if lastCommit >= interval {
+			addRoot(lastCommit - interval)
+		}
+	}
+	if interval := bc.cacheConfig.StateSyncSummaryInterval; interval != 0 {
+		addRoot(height - height%interval)
+	}
+	if bc.snaps != nil {
+		roots = append(roots, bc.snaps.DiskRoot())
+	}
+	return roots
 }
 
 // SenderCacher returns the *TxSenderCacher used within the core package.
@@ -690,6 +862,14 @@ func (bc *BlockChain) Stop() {
 	bc.stopAcceptor()
 	log.Info("Acceptor queue drained", "t", time.Since(start))
 
+	// Stop the online pruner before the state manager flushes the dirty trie
+	// nodes on shutdown
+	if bc.onlinePruner != nil {
+		log.Info("Stopping online pruner")
+		close(bc.onlinePrunerQuit)
+		bc.onlinePrunerWg.Wait()
+	}
+
 	log.Info("Shutting down state manager")
 	start = time.Now()
 	if err := bc.stateManager.Shutdown(); err != nil {
@@ -825,6 +1005,9 @@ func (bc *BlockChain) Reject(block *types.Block) error {
 	// Remove the block since its data is no longer needed
 	batch := bc.db.NewBatch()
 	rawdb.DeleteBlock(batch, block.Hash(), block.NumberU64())
+	if bc.cacheConfig.HistoryDiffs {
+		rawdb.DeleteStateHistory(batch, block.NumberU64(), block.Hash())
+	}
 	if err := batch.Write(); err != nil {
 		return fmt.Errorf("failed to write delete block batch: %w", err)
 	}
@@ -910,6 +1093,19 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 		return err
 	}
 
+	// Record the state changes of the block to serve its state once it is
+	// accepted and pruned.
+	if bc.cacheConfig.HistoryDiffs {
+		if err := bc.writeStateHistory(block); err != nil {
+			if bc.snaps != nil {
+				if discardErr := bc.snaps.Discard(block.Hash()); discardErr != nil {
+					log.Debug("failed to discard snapshot after being unable to write state history", "block", block.Hash(), "root", block.Root())
+				}
+			}
+			return err
+		}
+	}
+
 	// Note: if InsertTrie must be the last step in verification that can return an error.
 	// This allows [stateManager] to assume that if it inserts a trie without returning an
 	// error then the block has passed verification and either AcceptTrie/RejectTrie will
@@ -1057,6 +1253,7 @@ func (bc *BlockChain) insertBlock(block *types.Block, writes bool) error {
 	if err != nil {
 		return err
 	}
+	statedb.SetStorageTrieWorkers(bc.cacheConfig.StorageTrieWorkers)
 
 	// Enable prefetching to pull in trie node paths while processing transactions
 	statedb.StartPrefetcher("chain")
@@ -1350,6 +1547,7 @@ func (bc *BlockChain) reprocessBlock(parent *types.Block, current *types.Block)
 	if err != nil {
 		return common.Hash{}, fmt.Errorf("could not fetch state for (%s: %d): %v", parent.Hash().Hex(), parent.NumberU64(), err)
 	}
+	statedb.SetStorageTrieWorkers(bc.cacheConfig.StorageTrieWorkers)
 
 	// Enable prefetching to pull in trie node paths while processing transactions
 	statedb.StartPrefetcher("chain")
@@ -1436,6 +1634,22 @@ func (bc *BlockChain) reprocessState(current *types.Block, reexec uint64) error
 		}
 	}
 
+	// The snapshot is initialized at the acceptor tip, unless its disk layer was
+	// flattened past the acceptor tip before shutdown, in which case it is
+	// initialized at the block of the disk layer to avoid regenerating it (or
+	// after reprocessing, if the disk layer is at the last accepted block).
+	snapshotBase := acceptorTip
+	if acceptorTip != (common.Hash{}) && bc.cacheConfig.SnapshotLimit > 0 {
+		if hash := snapshot.ReadBlockHash(bc.db); hash != (common.Hash{}) && hash != acceptorTip {
+			if header := bc.GetHeaderByHash(hash); header != nil {
+				number := header.Number.Uint64()
+				if number > current.NumberU64() && number <= origin && rawdb.ReadCanonicalHash(bc.db, number) == hash {
+					snapshotBase = hash
+				}
+			}
+		}
+	}
+
 	for i := 0; i < int(reexec); i++ {
 		// TODO: handle canceled context
 
@@ -1489,15 +1703,14 @@ func (bc *BlockChain) reprocessState(current *types.Block, reexec uint64) error
 
 		// Initialize snapshot if required (prevents full snapshot re-generation in
 		// the case of unclean shutdown)
-		if parent.Hash() == acceptorTip {
+		if parent.Hash() == snapshotBase {
 			log.Info("Recovering snapshot", "hash", parent.Hash(), "index", parent.NumberU64())
-			// TODO: switch to checking the snapshot block hash markers here to ensure that when we re-process the block, we have the opportunity to apply
-			// a snapshot diff layer that we may have been in the middle of committing during shutdown. This will prevent snapshot re-generation in the case
-			// that the node stops mid-way through snapshot flattening (performed across multiple DB batches).
 			// If snapshot initialization is delayed due to state sync, skip initializing snaps here
 			if !bc.cacheConfig.SnapshotDelayInit {
 				bc.initSnapshot(parent)
 			}
+		}
+		if parent.Hash() == acceptorTip {
 			writeIndices = true // Set [writeIndices] to true, so that the indices will be updated from the last accepted tip onwards.
 		}
 
@@ -1732,8 +1945,10 @@ func (bc *BlockChain) ResetState(block *types.Block) error {
 
 	lastAcceptedHash := block.Hash()
 	bc.stateCache = state.NewDatabaseWithConfig(bc.db, &trie.Config{
-		Cache:     bc.cacheConfig.TrieCleanLimit,
-		Preimages: bc.cacheConfig.Preimages,
+		Cache:        bc.cacheConfig.TrieCleanLimit,
+		Preimages:    bc.cacheConfig.Preimages,
+		Scheme:       bc.cacheConfig.StateScheme,
+		StateHistory: bc.cacheConfig.StateHistory,
 	})
 	if err := bc.loadLastState(lastAcceptedHash); err != nil {
 		return err
@@ -1748,5 +1963,6 @@ func (bc *BlockChain) ResetState(block *types.Block) error {
 	}
 
 	bc.initSnapshot(head)
+	bc.startOnlinePruner()
 	return nil
 }
@@ -27,6 +27,9 @@
 package core
 
 import (
+	"fmt"
+	"math/big"
+
 	"github.com/sankar-boro/axia-network-v2-coreth/consensus"
 	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
 	"github.com/sankar-boro/axia-network-v2-coreth/core/state"
@@ -253,8 +256,58 @@ func (bc *BlockChain) State() (*state.StateDB, error) {
 }
 
 // StateAt returns a new mutable state based on a particular point in time.
+// If history diffs are enabled, the pruned states of accepted blocks are
+// served as read-only states by HistoricalStateAt.
 func (bc *BlockChain) StateAt(root common.Hash) (*state.StateDB, error) {
-	return state.New(root, bc.stateCache, bc.snaps)
+	statedb, err := state.New(root, bc.stateCache, bc.snaps)
+	if err != nil && bc.cacheConfig.HistoryDiffs {
+		if historical, historyErr := bc.HistoricalStateAt(root); historyErr == nil {
+			return historical, nil
+		}
+	}
+	return statedb, err
+}
+
+// HistoricalStateAt returns a new read-only state of the accepted block with
+// state [root], reconstructed from the indexed state changes of the blocks
+// accepted since and the state of the last accepted block. The state changes
+// are only indexed if history diffs are enabled.
+func (bc *BlockChain) HistoricalStateAt(root common.Hash) (*state.StateDB, error) {
+	number := rawdb.ReadStateHistoryNumber(bc.db, root)
+	if number == nil {
+		return nil, fmt.Errorf("no accepted block with indexed state %s", root)
+	}
+	if tail := rawdb.ReadStateHistoryTail(bc.db); tail == nil || *number+1 < *tail {
+		return nil, fmt.Errorf("state changes after block %d are not indexed", *number)
+	}
+	live := bc.LastAcceptedBlock()
+	if *number > live.NumberU64() {
+		return nil, fmt.Errorf("block %d is above the last accepted block %d", *number, live.NumberU64())
+	}
+	db, err := state.NewHistoryDatabase(bc.stateCache, *number, root, live.Root())
+	if err != nil {
+		return nil, err
+	}
+	return state.New(root, db, nil)
+}
+
+// GetFeeConfigAt returns the fee config in effect for the child of [parent].
+// If the fee config manager precompile is active, the fee config is read from
+// the state of [parent].
+func (bc *BlockChain) GetFeeConfigAt(parent *types.Header) (params.FeeConfig, error) {
+	if !bc.chainConfig.IsStatefulPrecompileEnabled(params.FeeConfigManagerAddress, new(big.Int).SetUint64(parent.Time)) {
+		return bc.chainConfig.GetFeeConfig(), nil
+	}
+	if feeConfig, ok := bc.feeConfigCache.Get(parent.Root); ok {
+		return feeConfig.(params.FeeConfig), nil
+	}
+	statedb, err := bc.StateAt(parent.Root)
+	if err != nil {
+		return params.FeeConfig{}, err
+	}
+	feeConfig := vm.FeeConfigAt(bc.chainConfig, parent.Time, statedb)
+	bc.feeConfigCache.Add(parent.Root, feeConfig)
+	return feeConfig, nil
 }
 
 // Config retrieves the chain's fork configuration.
@@ -7,11 +7,13 @@ import (
 	"fmt"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/sankar-boro/axia-network-v2-coreth/consensus/dummy"
 	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
 	"github.com/sankar-boro/axia-network-v2-coreth/core/state"
 	"github.com/sankar-boro/axia-network-v2-coreth/core/state/pruner"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/state/snapshot"
 	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
 	"github.com/sankar-boro/axia-network-v2-coreth/core/vm"
 	"github.com/sankar-boro/axia-network-v2-coreth/ethdb"
@@ -282,6 +284,330 @@ func TestBlockChainOfflinePruningUngracefulShutdown(t *testing.T) {
 	}
 }
 
+func TestBlockChainOnlinePruning(t *testing.T) {
+	create := func(db ethdb.Database, chainConfig *params.ChainConfig, lastAcceptedHash common.Hash) (*BlockChain, error) {
+		blockchain, err := createBlockChain(
+			db,
+			&CacheConfig{
+				TrieCleanLimit:        256,
+				TrieDirtyLimit:        256,
+				TrieDirtyCommitTarget: 20,
+				Pruning:               true, // Enable pruning
+				CommitInterval:        4096,
+				SnapshotLimit:         256,
+				AcceptorQueueLimit:    64,
+
+				OnlinePruning:         true,
+				OnlinePruningInterval: time.Hour, // Runs are triggered below
+			},
+			chainConfig,
+			lastAcceptedHash,
+		)
+		if err != nil || lastAcceptedHash == (common.Hash{}) {
+			return blockchain, err
+		}
+
+		// Prune the state of the reopened chain before it is used again
+		if err := blockchain.onlinePruner.Prune(blockchain.retainedStateRoots, make(chan struct{})); err != nil {
+			return nil, fmt.Errorf("online pruning failed: %w", err)
+		}
+		return blockchain, nil
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.testFunc(t, create)
+		})
+	}
+}
+
+// Tests that online pruning retains the state of processing blocks whose roots
+// were flushed to disk by capping the trie database before the run.
+func TestOnlinePruningRetainsCappedProcessingBlocks(t *testing.T) {
+	var (
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		genDB   = rawdb.NewMemoryDatabase()
+		chainDB = rawdb.NewMemoryDatabase()
+		config  = &CacheConfig{
+			TrieCleanLimit:        256,
+			TrieDirtyLimit:        256,
+			TrieDirtyCommitTarget: 20,
+			Pruning:               true, // Enable pruning
+			CommitInterval:        4096,
+			AcceptorQueueLimit:    64,
+
+			OnlinePruning:         true,
+			OnlinePruningInterval: time.Hour, // Runs are triggered below
+		}
+	)
+	gspec := &Genesis{
+		Config: &params.ChainConfig{HomesteadBlock: new(big.Int)},
+		Alloc:  GenesisAlloc{addr1: {Balance: big.NewInt(params.Ether)}},
+	}
+	genesis := gspec.MustCommit(genDB)
+	gspec.MustCommit(chainDB)
+
+	blockchain, err := createBlockChain(chainDB, config, gspec.Config, common.Hash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer blockchain.Stop()
+
+	// Every block funds a new account, so that its state has unique nodes
+	signer := types.HomesteadSigner{}
+	chain, _, err := GenerateChain(gspec.Config, genesis, blockchain.engine, genDB, 10, 10, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr1), common.BigToAddress(big.NewInt(int64(0x1000+i))), big.NewInt(10000), params.TxGas, nil, nil), signer, key1)
+		gen.AddTx(tx)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatal(err)
+	}
+	for _, block := range chain[:5] {
+		if err := blockchain.Accept(block); err != nil {
+			t.Fatal(err)
+		}
+	}
+	blockchain.DrainAcceptorQueue()
+
+	// Flush the states of the processing blocks to disk, as the trie writer
+	// does when the dirty cache exceeds its limit, and prune afterwards
+	if err := blockchain.stateCache.TrieDB().Cap(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := blockchain.onlinePruner.Prune(blockchain.retainedStateRoots, make(chan struct{})); err != nil {
+		t.Fatal(err)
+	}
+	for _, block := range chain[5:] {
+		if err := blockchain.Accept(block); err != nil {
+			t.Fatal(err)
+		}
+	}
+	blockchain.DrainAcceptorQueue()
+
+	for _, block := range chain[5:] {
+		statedb, err := blockchain.StateAt(block.Root())
+		if err != nil {
+			t.Fatalf("failed to open state of block %d: %v", block.NumberU64(), err)
+		}
+		it := state.NewNodeIterator(statedb)
+		for it.Next() {
+		}
+		if it.Error != nil {
+			t.Fatalf("state of block %d incomplete: %v", block.NumberU64(), it.Error)
+		}
+	}
+}
+
+func TestPathSchemeBlockChain(t *testing.T) {
+	const history = 4
+	var (
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		addr2   = common.HexToAddress("0x0102")
+		genDB   = rawdb.NewMemoryDatabase()
+		chainDB = rawdb.NewMemoryDatabase()
+		config  = &CacheConfig{
+			TrieCleanLimit:        256,
+			TrieDirtyLimit:        256,
+			TrieDirtyCommitTarget: 20,
+			Pruning:               true, // Enable pruning
+			CommitInterval:        4096,
+			SnapshotLimit:         256,
+			AcceptorQueueLimit:    64,
+			StateScheme:           rawdb.PathScheme,
+			StateHistory:          history,
+		}
+	)
+	gspec := &Genesis{
+		Config: &params.ChainConfig{HomesteadBlock: new(big.Int)},
+		Alloc:  GenesisAlloc{addr1: {Balance: big.NewInt(1000000)}},
+	}
+	genesis := gspec.MustCommit(genDB)
+
+	// The scheme must be recorded before the genesis state is committed
+	rawdb.WriteStateScheme(chainDB, rawdb.PathScheme)
+	gspec.MustCommit(chainDB)
+
+	blockchain, err := createBlockChain(chainDB, config, gspec.Config, common.Hash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := types.HomesteadSigner{}
+	chain, _, err := GenerateChain(gspec.Config, genesis, blockchain.engine, genDB, 10, 10, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr1), addr2, big.NewInt(10000), params.TxGas, nil, nil), signer, key1)
+		gen.AddTx(tx)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatal(err)
+	}
+	for _, block := range chain {
+		if err := blockchain.Accept(block); err != nil {
+			t.Fatal(err)
+		}
+	}
+	blockchain.DrainAcceptorQueue()
+
+	// The most recent accepted states are served from the reverse diffs
+	checkStates := func(blockchain *BlockChain) {
+		for i, block := range chain {
+			if len(chain)-1-i > history {
+				continue
+			}
+			statedb, err := blockchain.StateAt(block.Root())
+			if err != nil {
+				t.Fatalf("state of block %d is not available: %v", block.NumberU64(), err)
+			}
+			if balance, expected := statedb.GetBalance(addr2), big.NewInt(int64(10000*(i+1))); balance.Cmp(expected) != 0 {
+				t.Fatalf("block %d: expected addr2 balance %d, found %d", block.NumberU64(), expected, balance)
+			}
+		}
+	}
+	checkStates(blockchain)
+	blockchain.Stop()
+
+	// One reverse diff is written for the genesis state and each accepted block
+	if head := rawdb.ReadReverseDiffHead(chainDB); head != uint64(len(chain)+1) {
+		t.Fatalf("reverse diff head %d, want %d", head, len(chain)+1)
+	}
+
+	// The history is restored after a restart
+	blockchain, err = createBlockChain(chainDB, config, gspec.Config, chain[len(chain)-1].Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer blockchain.Stop()
+	checkStates(blockchain)
+}
+
+func TestHistoryDiffsBlockChain(t *testing.T) {
+	var (
+		key1, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1    = crypto.PubkeyToAddress(key1.PublicKey)
+		addr2    = common.HexToAddress("0x0102")
+		contract = crypto.CreateAddress(addr1, 0)
+		coinAddr = common.HexToAddress("0xdeadbeef")
+		coinID   = common.HexToHash("0xdeadbeef")
+		genDB    = rawdb.NewMemoryDatabase()
+		chainDB  = rawdb.NewMemoryDatabase()
+		config   = &CacheConfig{
+			TrieCleanLimit:        256,
+			TrieDirtyLimit:        256,
+			TrieDirtyCommitTarget: 20,
+			Pruning:               true, // Enable pruning
+			CommitInterval:        4096,
+			SnapshotLimit:         256,
+			AcceptorQueueLimit:    64,
+			HistoryDiffs:          true,
+		}
+	)
+	gspec := &Genesis{
+		Config: &params.ChainConfig{HomesteadBlock: new(big.Int)},
+		Alloc:  GenesisAlloc{addr1: {Balance: big.NewInt(10000000)}},
+	}
+	genesis := gspec.MustCommit(genDB)
+	gspec.MustCommit(chainDB)
+
+	blockchain, err := createBlockChain(chainDB, config, gspec.Config, common.Hash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first block deploys a contract storing the block number in slot 0,
+	// which is called by every other block. More blocks than the tip buffer
+	// are generated, so that the state of the first blocks is pruned.
+	signer := types.HomesteadSigner{}
+	chain, _, err := GenerateChain(gspec.Config, genesis, blockchain.engine, genDB, 2*tipBufferSize, 10, func(i int, gen *BlockGen) {
+		var tx *types.Transaction
+		if i == 0 {
+			tx = types.NewContractCreation(gen.TxNonce(addr1), nil, 100000, nil, common.FromHex("0x6005600c60003960056000f34360005500"))
+		} else if i%2 == 0 {
+			tx = types.NewTransaction(gen.TxNonce(addr1), contract, nil, 100000, nil, nil)
+		} else {
+			tx = types.NewTransaction(gen.TxNonce(addr1), addr2, big.NewInt(10000), params.TxGas, nil, nil)
+		}
+		tx, _ = types.SignTx(tx, signer, key1)
+		gen.AddTx(tx)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatal(err)
+	}
+	for _, block := range chain {
+		if err := blockchain.Accept(block); err != nil {
+			t.Fatal(err)
+		}
+	}
+	blockchain.DrainAcceptorQueue()
+
+	// Every accepted state is served, including the pruned ones
+	checkStates := func(blockchain *BlockChain) {
+		var (
+			transfers int64
+			called    int64
+		)
+		for i, block := range chain {
+			switch {
+			case i == 0:
+			case i%2 == 0:
+				called = int64(i + 1)
+			default:
+				transfers++
+			}
+			statedb, err := blockchain.StateAt(block.Root())
+			if err != nil {
+				t.Fatalf("state of block %d is not available: %v", block.NumberU64(), err)
+			}
+			if nonce := statedb.GetNonce(addr1); nonce != uint64(i+1) {
+				t.Fatalf("block %d: expected addr1 nonce %d, found %d", block.NumberU64(), i+1, nonce)
+			}
+			if balance, expected := statedb.GetBalance(addr2), big.NewInt(10000*transfers); balance.Cmp(expected) != 0 {
+				t.Fatalf("block %d: expected addr2 balance %d, found %d", block.NumberU64(), expected, balance)
+			}
+			if code := statedb.GetCode(contract); len(code) == 0 {
+				t.Fatalf("block %d: missing contract code", block.NumberU64())
+			}
+			if value, expected := statedb.GetState(contract, common.Hash{}), common.BigToHash(big.NewInt(called)); value != expected {
+				t.Fatalf("block %d: expected contract slot %x, found %x", block.NumberU64(), expected, value)
+			}
+			if balance, expected := statedb.GetBalanceMultiCoin(coinAddr, coinID), block.Number(); balance.Cmp(expected) != 0 {
+				t.Fatalf("block %d: expected multicoin balance %d, found %d", block.NumberU64(), expected, balance)
+			}
+		}
+		// The genesis state is served as the state before the first block
+		statedb, err := blockchain.StateAt(genesis.Root())
+		if err != nil {
+			t.Fatalf("genesis state is not available: %v", err)
+		}
+		if statedb.Exist(contract) || statedb.GetNonce(addr1) != 0 {
+			t.Fatal("unexpected genesis state")
+		}
+	}
+	checkStates(blockchain)
+	blockchain.Stop()
+
+	if tail := rawdb.ReadStateHistoryTail(chainDB); tail == nil || *tail != 1 {
+		t.Fatalf("unexpected state history tail %v", tail)
+	}
+	if head := rawdb.ReadStateHistoryHead(chainDB); head == nil || *head != uint64(len(chain)) {
+		t.Fatalf("unexpected state history head %v", head)
+	}
+
+	// The history is served after a restart
+	blockchain, err = createBlockChain(chainDB, config, gspec.Config, chain[len(chain)-1].Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer blockchain.Stop()
+	checkStates(blockchain)
+}
+
 func testRepopulateMissingTriesParallel(t *testing.T, parallelism int) {
 	var (
 		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
@@ -530,3 +856,220 @@ func TestUngracefulAsyncShutdown(t *testing.T) {
 		}
 	}
 }
+
+// TestSnapshotAheadOfAcceptorTip tests that the snapshot is not regenerated
+// after an ungraceful shutdown between flattening an accepted block into the
+// snapshot disk layer and updating the acceptor tip.
+func TestSnapshotAheadOfAcceptorTip(t *testing.T) {
+	var (
+		cacheConfig = &CacheConfig{
+			TrieCleanLimit:        256,
+			TrieDirtyLimit:        256,
+			TrieDirtyCommitTarget: 20,
+			Pruning:               true,
+			CommitInterval:        4096,
+			SnapshotLimit:         256,
+			SkipSnapshotRebuild:   true, // Ensure the test errors if snapshot initialization fails
+			AcceptorQueueLimit:    1000, // ensure channel doesn't block
+		}
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		addr2   = common.Address{0x02}
+		genDB   = rawdb.NewMemoryDatabase()
+		chainDB = rawdb.NewMemoryDatabase()
+	)
+	gspec := &Genesis{
+		Config: &params.ChainConfig{HomesteadBlock: new(big.Int)},
+		Alloc:  GenesisAlloc{addr1: {Balance: big.NewInt(1000000)}},
+	}
+	genesis := gspec.MustCommit(genDB)
+	_ = gspec.MustCommit(chainDB)
+
+	blockchain, err := createBlockChain(chainDB, cacheConfig, gspec.Config, common.Hash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := types.HomesteadSigner{}
+	chain, _, err := GenerateChain(gspec.Config, genesis, blockchain.engine, genDB, 10, 10, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr1), addr2, big.NewInt(10000), params.TxGas, nil, nil), signer, key1)
+		gen.AddTx(tx)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatal(err)
+	}
+	for i, block := range chain {
+		if err := blockchain.Accept(block); err != nil {
+			t.Fatal(err)
+		}
+		if i == 6 {
+			// Kill the async accepted block processor after it flattened block
+			// 7 into the snapshot, and roll the acceptor tip back to block 6 as
+			// if the node crashed before updating it.
+			blockchain.DrainAcceptorQueue()
+			blockchain.stopAcceptor()
+			blockchain.acceptorQueue = nil
+			if err := rawdb.WriteAcceptorTip(chainDB, chain[5].Hash()); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if have := snapshot.ReadBlockHash(chainDB); have != chain[6].Hash() {
+		t.Fatalf("snapshot block hash = %s, want %s", have, chain[6].Hash())
+	}
+
+	restarted, err := createBlockChain(chainDB, cacheConfig, gspec.Config, chain[9].Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restarted.Stop()
+	if restarted.snaps == nil {
+		t.Fatal("snapshot initialization failed")
+	}
+	if have := restarted.snaps.DiskRoot(); have != chain[9].Root() {
+		t.Fatalf("snapshot disk root = %s, want %s", have, chain[9].Root())
+	}
+	snap := restarted.snaps.Snapshot(chain[9].Root())
+	acc, err := snap.Account(crypto.Keccak256Hash(addr2[:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acc == nil || acc.Balance.Cmp(big.NewInt(100000)) != 0 {
+		t.Fatalf("unexpected snapshot account %s: %+v", addr2, acc)
+	}
+}
+
+func TestFeeConfigManagerChangesFeeConfig(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		genDB   = rawdb.NewMemoryDatabase()
+		chainDB = rawdb.NewMemoryDatabase()
+	)
+	config := *params.TestChainConfig
+	config.FeeConfigManagerConfig = &params.FeeConfigManagerConfig{
+		PrecompileUpgrade: params.PrecompileUpgrade{BlockTimestamp: big.NewInt(0)},
+		AllowListConfig:   params.AllowListConfig{AdminAddresses: []common.Address{addr}},
+	}
+	gspec := &Genesis{
+		Config: &config,
+		Alloc:  GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}},
+	}
+	genesis := gspec.MustCommit(genDB)
+	_ = gspec.MustCommit(chainDB)
+
+	blockchain, err := NewBlockChain(chainDB, DefaultCacheConfig, gspec.Config, dummy.NewETHFaker(), vm.Config{}, common.Hash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer blockchain.Stop()
+
+	// Raise the minimum base fee above the initial base fee in the first block.
+	newFeeConfig := params.DefaultFeeConfig
+	newFeeConfig.MinBaseFee = big.NewInt(500 * params.GWei)
+	signer := types.LatestSigner(gspec.Config)
+	chain, _, err := GenerateChain(gspec.Config, genesis, blockchain.engine, genDB, 3, 10, func(i int, gen *BlockGen) {
+		if i != 0 {
+			return
+		}
+		tx, err := types.SignTx(types.NewTransaction(gen.TxNonce(addr), params.FeeConfigManagerAddress, common.Big0, 300_000, big.NewInt(300*params.GWei), vm.PackSetFeeConfigInput(newFeeConfig)), signer, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gen.AddTx(tx)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatal(err)
+	}
+
+	// The new fee config takes effect from the block following the change.
+	feeConfig, err := blockchain.GetFeeConfigAt(genesis.Header())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !feeConfig.Equal(&params.DefaultFeeConfig) {
+		t.Fatalf("expected default fee config for block 1, got %+v", feeConfig)
+	}
+	feeConfig, err = blockchain.GetFeeConfigAt(chain[0].Header())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !feeConfig.Equal(&newFeeConfig) {
+		t.Fatalf("expected new fee config for block 2, got %+v", feeConfig)
+	}
+	if baseFee := chain[0].BaseFee(); baseFee.Cmp(newFeeConfig.MinBaseFee) >= 0 {
+		t.Fatalf("expected base fee of block 1 (%d) below the new minimum", baseFee)
+	}
+	for _, block := range chain[1:] {
+		if block.BaseFee().Cmp(newFeeConfig.MinBaseFee) < 0 {
+			t.Fatalf("expected base fee of block %d (%d) to be at least %d", block.NumberU64(), block.BaseFee(), newFeeConfig.MinBaseFee)
+		}
+	}
+}
+
+func TestApricotPhase6Transition(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		genDB   = rawdb.NewMemoryDatabase()
+		chainDB = rawdb.NewMemoryDatabase()
+		// COINBASE BALANCE POP STOP
+		coinbaseReader = common.HexToAddress("0xc0ffee")
+		// PUSH0 PUSH0 RETURN
+		push0InitCode = []byte{byte(vm.PUSH0), byte(vm.PUSH0), byte(vm.RETURN)}
+	)
+	// Apricot Phase 6 activates with the second block.
+	config := *params.TestApricotPhase5Config
+	config.ApricotPhase6BlockTimestamp = big.NewInt(20)
+	gspec := &Genesis{
+		Config: &config,
+		Alloc: GenesisAlloc{
+			addr:           {Balance: big.NewInt(params.Ether)},
+			coinbaseReader: {Code: []byte{byte(vm.COINBASE), byte(vm.BALANCE), byte(vm.POP), byte(vm.STOP)}, Balance: common.Big0},
+		},
+	}
+	genesis := gspec.MustCommit(genDB)
+	_ = gspec.MustCommit(chainDB)
+
+	blockchain, err := NewBlockChain(chainDB, DefaultCacheConfig, gspec.Config, dummy.NewETHFaker(), vm.Config{}, common.Hash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer blockchain.Stop()
+
+	signer := types.LatestSigner(gspec.Config)
+	chain, receipts, err := GenerateChain(gspec.Config, genesis, blockchain.engine, genDB, 2, 10, func(i int, gen *BlockGen) {
+		gasPrice := big.NewInt(300 * params.GWei)
+		create, _ := types.SignTx(types.NewContractCreation(gen.TxNonce(addr), common.Big0, 100_000, gasPrice, push0InitCode), signer, key)
+		gen.AddTx(create)
+		call, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr), coinbaseReader, common.Big0, 100_000, gasPrice, nil), signer, key)
+		gen.AddTx(call)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatal(err)
+	}
+
+	// PUSH0 is an invalid opcode prior to Apricot Phase 6, so the creation
+	// consumes all of its gas.
+	if receipts[0][0].Status != types.ReceiptStatusFailed || receipts[0][0].GasUsed != 100_000 {
+		t.Fatalf("expected creation with PUSH0 to fail before Apricot Phase 6, status %d gas used %d", receipts[0][0].Status, receipts[0][0].GasUsed)
+	}
+	// The creation costs 53000 + 3 * 16 for the init code bytes + 2 for its
+	// single word and 2 * 2 for the PUSH0 instructions.
+	if receipts[1][0].Status != types.ReceiptStatusSuccessful || receipts[1][0].GasUsed != 53_054 {
+		t.Fatalf("expected creation with PUSH0 to succeed in Apricot Phase 6, status %d gas used %d", receipts[1][0].Status, receipts[1][0].GasUsed)
+	}
+	// Reading the balance of the warm coinbase is cheaper by the difference
+	// between a cold and a warm account access.
+	if have, want := receipts[0][1].GasUsed-receipts[1][1].GasUsed, params.ColdAccountAccessCostEIP2929-params.WarmStorageReadCostEIP2929; have != want {
+		t.Fatalf("expected coinbase access to be cheaper by %d in Apricot Phase 6, have %d", want, have)
+	}
+}
@@ -217,7 +217,7 @@ func GenerateChain(config *params.ChainConfig, parent *types.Block, engine conse
 		config = params.TestChainConfig
 	}
 	blocks, receipts := make(types.Blocks, n), make([]types.Receipts, n)
-	chainreader := &fakeChainReader{config: config}
+	chainreader := &fakeChainReader{config: config, db: db}
 	genblock := func(i int, parent *types.Block, statedb *state.StateDB) (*types.Block, types.Receipts, error) {
 		b := &BlockGen{i: i, chain: blocks, parent: parent, statedb: statedb, config: config, engine: engine}
 		b.header = makeHeader(chainreader, config, parent, gap, statedb, b.engine)
@@ -238,6 +238,10 @@ func GenerateChain(config *params.ChainConfig, parent *types.Block, engine conse
 		if config.DAOForkSupport && config.DAOForkBlock != nil && config.DAOForkBlock.Cmp(b.header.Number) == 0 {
 			misc.ApplyDAOHardFork(statedb)
 		}
+		// Configure any stateful precompiles activated in this block
+		if err := vm.ConfigureStatefulPrecompiles(config, new(big.Int).SetUint64(parent.Time()), NewEVMBlockContext(b.header, nil, &b.header.Coinbase), statedb); err != nil {
+			return nil, nil, fmt.Errorf("failed to configure stateful precompiles at index %d: %w", i, err)
+		}
 		// Execute any user modifications to the block
 		if gen != nil {
 			gen(i, b)
@@ -311,8 +315,11 @@ func makeHeader(chain consensus.ChainReader, config *params.ChainConfig, parent
 		Time:     time,
 	}
 	if chain.Config().IsApricotPhase3(timestamp) {
-		var err error
-		header.Extra, header.BaseFee, err = dummy.CalcBaseFee(chain.Config(), parent.Header(), time)
+		feeConfig, err := chain.GetFeeConfigAt(parent.Header())
+		if err != nil {
+			panic(err)
+		}
+		header.Extra, header.BaseFee, err = dummy.CalcBaseFee(chain.Config(), feeConfig, parent.Header(), time)
 		if err != nil {
 			panic(err)
 		}
@@ -322,6 +329,7 @@ func makeHeader(chain consensus.ChainReader, config *params.ChainConfig, parent
 
 type fakeChainReader struct {
 	config *params.ChainConfig
+	db     ethdb.Database // Used to read the fee config from the state, may be nil
 }
 
 // Config returns the chain configuration.
@@ -334,3 +342,15 @@ func (cr *fakeChainReader) GetHeaderByNumber(number uint64) *types.Header
 func (cr *fakeChainReader) GetHeaderByHash(hash common.Hash) *types.Header          { return nil }
 func (cr *fakeChainReader) GetHeader(hash common.Hash, number uint64) *types.Header { return nil }
 func (cr *fakeChainReader) GetBlock(hash common.Hash, number uint64) *types.Block   { return nil }
+
+// GetFeeConfigAt returns the fee config in effect for the child of [parent].
+func (cr *fakeChainReader) GetFeeConfigAt(parent *types.Header) (params.FeeConfig, error) {
+	if cr.db == nil {
+		return cr.config.GetFeeConfig(), nil
+	}
+	statedb, err := state.New(parent.Root, state.NewDatabase(cr.db), nil)
+	if err != nil {
+		return params.FeeConfig{}, err
+	}
+	return vm.FeeConfigAt(cr.config, parent.Time, statedb), nil
+}
@@ -37,6 +37,7 @@ import (
 	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
 	"github.com/sankar-boro/axia-network-v2-coreth/core/state"
 	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/vm"
 	"github.com/sankar-boro/axia-network-v2-coreth/ethdb"
 	"github.com/sankar-boro/axia-network-v2-coreth/params"
 	"github.com/sankar-boro/axia-network-v2-coreth/trie"
@@ -246,6 +247,20 @@ func (g *Genesis) ToBlock(db ethdb.Database) *types.Block {
 			}
 		}
 	}
+	if g.Config != nil {
+		// Configure the stateful precompiles that are active at genesis
+		blockContext := vm.BlockContext{
+			BlockNumber: new(big.Int).SetUint64(g.Number),
+			Time:        new(big.Int).SetUint64(g.Timestamp),
+			Coinbase:    g.Coinbase,
+			GasLimit:    g.GasLimit,
+			Difficulty:  g.Difficulty,
+			BaseFee:     g.BaseFee,
+		}
+		if err := vm.ConfigureStatefulPrecompiles(g.Config, nil, blockContext, statedb); err != nil {
+			panic(fmt.Sprintf("unable to configure stateful precompiles in genesis: %v", err))
+		}
+	}
 	root := statedb.IntermediateRoot(false)
 	head := &types.Header{
 		Number:     new(big.Int).SetUint64(g.Number),
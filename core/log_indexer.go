@@ -0,0 +1,100 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
+	"github.com/sankar-boro/axia-network-v2-coreth/ethdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/params"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// LogIndexer implements a core.ChainIndexer, rebuilding the exact log index of
+// the canonical chain from its receipts. The logs of the blocks accepted while
+// the index is enabled are indexed by the acceptor instead.
+type LogIndexer struct {
+	db     ethdb.Database
+	config *params.ChainConfig
+	batch  ethdb.Batch
+
+	tail, head *uint64 // Range of blocks indexed by the acceptor when the section started
+}
+
+// NewLogIndexer returns a chain indexer that generates the exact log index for
+// the canonical chain in sections of [size] blocks.
+func NewLogIndexer(db ethdb.Database, config *params.ChainConfig, size, confirms uint64) *ChainIndexer {
+	backend := &LogIndexer{
+		db:     db,
+		config: config,
+	}
+	table := rawdb.NewTable(db, string(rawdb.LogIndexIndexPrefix))
+
+	return NewChainIndexer(db, table, backend, size, confirms, bloomThrottling, "logindex")
+}
+
+// Reset implements core.ChainIndexerBackend, starting a new log index section.
+func (l *LogIndexer) Reset(ctx context.Context, section uint64, lastSectionHead common.Hash) error {
+	l.batch = l.db.NewBatch()
+	l.tail, l.head = rawdb.ReadLogIndexTail(l.db), rawdb.ReadLogIndexHead(l.db)
+	return nil
+}
+
+// Process implements core.ChainIndexerBackend, adding the logs of a new header
+// into the index.
+func (l *LogIndexer) Process(ctx context.Context, header *types.Header) error {
+	number := header.Number.Uint64()
+	if l.tail != nil && l.head != nil && *l.tail <= number && number <= *l.head {
+		return nil // already indexed by the acceptor
+	}
+	hash := header.Hash()
+	receipts := rawdb.ReadReceipts(l.db, hash, number, l.config)
+	if receipts == nil && header.Bloom != (types.Bloom{}) {
+		return fmt.Errorf("missing receipts of block %s:%d", hash.Hex(), number)
+	}
+	for _, receipt := range receipts {
+		rawdb.WriteLogIndex(l.batch, receipt.Logs)
+	}
+	if l.batch.ValueSize() >= ethdb.IdealBatchSize {
+		if err := l.batch.Write(); err != nil {
+			return err
+		}
+		l.batch.Reset()
+	}
+	return nil
+}
+
+// Commit implements core.ChainIndexerBackend, writing the rest of the section
+// out into the database.
+func (l *LogIndexer) Commit() error {
+	return l.batch.Write()
+}
+
+// Prune returns an empty error since we don't support pruning here.
+func (l *LogIndexer) Prune(threshold uint64) error {
+	return nil
+}
+
+// LogIndexRange returns the consecutive range of blocks whose logs are indexed
+// in [db], given that the first [indexed] blocks were indexed by the
+// LogIndexer. The returned boolean is false if no block is indexed.
+func LogIndexRange(db ethdb.KeyValueReader, indexed uint64) (uint64, uint64, bool) {
+	tail, head := rawdb.ReadLogIndexTail(db), rawdb.ReadLogIndexHead(db)
+	switch {
+	case tail != nil && head != nil && *tail <= indexed:
+		if *head+1 < indexed {
+			return 0, indexed - 1, true
+		}
+		return 0, *head, true
+	case tail != nil && head != nil:
+		return *tail, *head, true
+	case indexed > 0:
+		return 0, indexed - 1, true
+	default:
+		return 0, 0, false
+	}
+}
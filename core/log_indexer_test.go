@@ -0,0 +1,192 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/vm"
+	"github.com/sankar-boro/axia-network-v2-coreth/ethdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/params"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	logIndexTestKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	logIndexTestAddr   = crypto.PubkeyToAddress(logIndexTestKey.PublicKey)
+
+	// logIndexTestEmitter1 emits a log with the block number as its topic.
+	logIndexTestEmitter1 = common.Address{0xaa}
+	// logIndexTestEmitter2 emits a log with the block number and the caller as
+	// its topics.
+	logIndexTestEmitter2 = common.Address{0xbb}
+)
+
+// newLogIndexTestChain inserts and accepts [blocks] blocks emitting logs into
+// a new blockchain with [cacheConfig].
+func newLogIndexTestChain(t *testing.T, cacheConfig *CacheConfig, blocks int) (*BlockChain, ethdb.Database, []*types.Block) {
+	var (
+		genDB   = rawdb.NewMemoryDatabase()
+		chainDB = rawdb.NewMemoryDatabase()
+		signer  = types.HomesteadSigner{}
+	)
+	gspec := &Genesis{
+		Config: &params.ChainConfig{HomesteadBlock: new(big.Int)},
+		Alloc: GenesisAlloc{
+			logIndexTestAddr: {Balance: big.NewInt(params.Ether)},
+			logIndexTestEmitter1: {
+				Balance: new(big.Int),
+				Code:    []byte{byte(vm.NUMBER), byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.LOG1)},
+			},
+			logIndexTestEmitter2: {
+				Balance: new(big.Int),
+				Code:    []byte{byte(vm.CALLER), byte(vm.NUMBER), byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.LOG2)},
+			},
+		},
+	}
+	genesis := gspec.MustCommit(genDB)
+	_ = gspec.MustCommit(chainDB)
+
+	blockchain, err := createBlockChain(chainDB, cacheConfig, gspec.Config, common.Hash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain, _, err := GenerateChain(gspec.Config, genesis, blockchain.engine, genDB, blocks, 10, func(i int, gen *BlockGen) {
+		if i%2 == 0 {
+			tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(logIndexTestAddr), logIndexTestEmitter1, new(big.Int), 100000, nil, nil), signer, logIndexTestKey)
+			gen.AddTx(tx)
+		}
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(logIndexTestAddr), logIndexTestEmitter2, new(big.Int), 100000, nil, nil), signer, logIndexTestKey)
+		gen.AddTx(tx)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatal(err)
+	}
+	for _, block := range chain {
+		if err := blockchain.Accept(block); err != nil {
+			t.Fatal(err)
+		}
+	}
+	blockchain.DrainAcceptorQueue()
+	return blockchain, chainDB, chain
+}
+
+// checkLogIndex checks that the log index in [db] holds the positions of the
+// logs of [chain].
+func checkLogIndex(t *testing.T, blockchain *BlockChain, db ethdb.Database, chain []*types.Block) {
+	t.Helper()
+
+	var emitter1, emitter2, caller []rawdb.LogPosition
+	for _, block := range chain {
+		for _, log := range blockchain.gatherBlockLogs(block.Hash(), block.NumberU64(), false) {
+			pos := rawdb.LogPosition{Number: log.BlockNumber, TxIndex: uint32(log.TxIndex), Index: uint32(log.Index)}
+			switch log.Address {
+			case logIndexTestEmitter1:
+				emitter1 = append(emitter1, pos)
+			case logIndexTestEmitter2:
+				emitter2 = append(emitter2, pos)
+				caller = append(caller, pos)
+			}
+		}
+	}
+	if len(emitter1) == 0 || len(emitter2) == 0 {
+		t.Fatal("test chain has no logs")
+	}
+	last := chain[len(chain)-1].NumberU64()
+	if have := rawdb.ReadAddressLogPositions(db, logIndexTestEmitter1, 0, last); !reflect.DeepEqual(have, emitter1) {
+		t.Fatalf("emitter 1 log positions mismatch: have %v, want %v", have, emitter1)
+	}
+	if have := rawdb.ReadAddressLogPositions(db, logIndexTestEmitter2, 0, last); !reflect.DeepEqual(have, emitter2) {
+		t.Fatalf("emitter 2 log positions mismatch: have %v, want %v", have, emitter2)
+	}
+	if have := rawdb.ReadTopicLogPositions(db, 1, common.BytesToHash(logIndexTestAddr[:]), 0, last); !reflect.DeepEqual(have, caller) {
+		t.Fatalf("caller topic log positions mismatch: have %v, want %v", have, caller)
+	}
+	// The first topic of both emitters is the block number
+	want := []rawdb.LogPosition{emitter1[1], emitter2[2]}
+	if have := rawdb.ReadTopicLogPositions(db, 0, common.BigToHash(big.NewInt(3)), 0, last); !reflect.DeepEqual(have, want) {
+		t.Fatalf("block number topic log positions mismatch: have %v, want %v", have, want)
+	}
+}
+
+func TestAcceptorLogIndex(t *testing.T) {
+	cacheConfig := *DefaultCacheConfig
+	cacheConfig.LogIndex = true
+	blockchain, db, chain := newLogIndexTestChain(t, &cacheConfig, 10)
+	defer blockchain.Stop()
+
+	checkLogIndex(t, blockchain, db, chain)
+	from, to, ok := LogIndexRange(db, 0)
+	if !ok || from != 1 || to != 10 {
+		t.Fatalf("log index range mismatch: have [%d, %d] (%t), want [1, 10]", from, to, ok)
+	}
+}
+
+func TestLogIndexer(t *testing.T) {
+	blockchain, db, chain := newLogIndexTestChain(t, DefaultCacheConfig, 10)
+	defer blockchain.Stop()
+
+	if _, _, ok := LogIndexRange(db, 0); ok {
+		t.Fatal("logs indexed by the acceptor with the log index disabled")
+	}
+	// Rebuild the index of the chain as a single section
+	indexer := &LogIndexer{db: db, config: blockchain.Config()}
+	if err := indexer.Reset(context.Background(), 0, common.Hash{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := indexer.Process(context.Background(), blockchain.Genesis().Header()); err != nil {
+		t.Fatal(err)
+	}
+	for _, block := range chain {
+		if err := indexer.Process(context.Background(), block.Header()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := indexer.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	checkLogIndex(t, blockchain, db, chain)
+	from, to, ok := LogIndexRange(db, 11)
+	if !ok || from != 0 || to != 10 {
+		t.Fatalf("log index range mismatch: have [%d, %d] (%t), want [0, 10]", from, to, ok)
+	}
+}
+
+func TestLogIndexRange(t *testing.T) {
+	tests := []struct {
+		acceptor   bool // Whether the acceptor indexed the blocks [tail, head]
+		tail, head uint64
+		indexed    uint64
+		from, to   uint64
+		ok         bool
+	}{
+		{false, 0, 0, 0, 0, 0, false},
+		{false, 0, 0, 4096, 0, 4095, true},
+		{true, 10, 20, 0, 10, 20, true},
+		{true, 5000, 6000, 4096, 5000, 6000, true},
+		{true, 4000, 6000, 4096, 0, 6000, true},
+		{true, 4096, 6000, 4096, 0, 6000, true},
+		{true, 100, 200, 4096, 0, 4095, true},
+	}
+	for i, test := range tests {
+		db := rawdb.NewMemoryDatabase()
+		if test.acceptor {
+			rawdb.WriteLogIndexTail(db, test.tail)
+			rawdb.WriteLogIndexHead(db, test.head)
+		}
+		from, to, ok := LogIndexRange(db, test.indexed)
+		if from != test.from || to != test.to || ok != test.ok {
+			t.Errorf("test %d: log index range mismatch: have [%d, %d] (%t), want [%d, %d] (%t)", i, from, to, ok, test.from, test.to, test.ok)
+		}
+	}
+}
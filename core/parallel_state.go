@@ -0,0 +1,316 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"math/big"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/core/state"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ripemdAddress is the address of the RIPEMD-160 precompile. A touch of this
+// account survives a revert (see state.journal), so a transaction whose
+// reverted writes touched it cannot be replayed from its recorded writes.
+var ripemdAddress = common.BytesToAddress([]byte{3})
+
+type stateKeyKind uint8
+
+const (
+	keyExistence stateKeyKind = iota // Existence and emptiness of an account
+	keyBalance
+	keyNonce
+	keyCode
+	keyStorage // Storage slot, including the slots holding multicoin balances
+)
+
+// stateKey identifies a single piece of state read or written by a
+// transaction. [slot] is only set for keyStorage.
+type stateKey struct {
+	addr common.Address
+	kind stateKeyKind
+	slot common.Hash
+}
+
+// stateWrite is a state modification made by a speculatively executed
+// transaction, which can be replayed on top of another StateDB.
+type stateWrite struct {
+	addr  common.Address
+	apply func(*state.StateDB)
+}
+
+// recordingStateDB wraps a StateDB and records the state keys read and
+// written by the execution of a single transaction. If [recordWrites] is set,
+// it also records the state modifications, excluding reverted ones, so that
+// they can be replayed on another StateDB once the transaction is known to
+// have read the same state there.
+//
+// recordingStateDB implements vm.StateDB.
+type recordingStateDB struct {
+	*state.StateDB
+
+	reads  map[stateKey]struct{}
+	writes map[stateKey]struct{}
+	// Accounts that were created or destructed, which resets their storage.
+	wiped map[common.Address]struct{}
+
+	recordWrites bool
+	ops          []stateWrite
+	snapshots    map[int]int // Snapshot id -> number of ops at the time of the snapshot
+	// replayable is false if the recorded ops cannot be replayed, in which case
+	// the transaction must be re-executed.
+	replayable bool
+}
+
+func newRecordingStateDB(statedb *state.StateDB, recordWrites bool) *recordingStateDB {
+	return &recordingStateDB{
+		StateDB:      statedb,
+		reads:        make(map[stateKey]struct{}),
+		writes:       make(map[stateKey]struct{}),
+		wiped:        make(map[common.Address]struct{}),
+		recordWrites: recordWrites,
+		snapshots:    make(map[int]int),
+		replayable:   true,
+	}
+}
+
+func (r *recordingStateDB) read(addr common.Address, kind stateKeyKind) {
+	r.reads[stateKey{addr: addr, kind: kind}] = struct{}{}
+}
+
+func (r *recordingStateDB) readSlot(addr common.Address, slot common.Hash) {
+	r.reads[stateKey{addr: addr, kind: keyStorage, slot: slot}] = struct{}{}
+}
+
+func (r *recordingStateDB) write(addr common.Address, kinds ...stateKeyKind) {
+	for _, kind := range kinds {
+		r.writes[stateKey{addr: addr, kind: kind}] = struct{}{}
+	}
+}
+
+func (r *recordingStateDB) writeSlot(addr common.Address, slot common.Hash) {
+	r.writes[stateKey{addr: addr, kind: keyStorage, slot: slot}] = struct{}{}
+}
+
+// modify applies [op] to the wrapped StateDB and records it. Since modifying
+// an account may create, touch or empty it, which changes its existence
+// after the transaction is finalised, the existence of [addr] is recorded as
+// read and also as written if the account is or becomes empty.
+func (r *recordingStateDB) modify(addr common.Address, op func(*state.StateDB)) {
+	r.read(addr, keyExistence)
+	wasEmpty := !r.StateDB.Exist(addr) || r.StateDB.Empty(addr)
+	op(r.StateDB)
+	if wasEmpty || r.StateDB.Empty(addr) {
+		r.write(addr, keyExistence)
+	}
+	if r.recordWrites {
+		r.ops = append(r.ops, stateWrite{addr: addr, apply: op})
+	}
+}
+
+// conflicts returns true if the transaction read any of the state in
+// [writes] or [wiped].
+func (r *recordingStateDB) conflicts(writes map[stateKey]struct{}, wiped map[common.Address]struct{}) bool {
+	for key := range r.reads {
+		if _, ok := writes[key]; ok {
+			return true
+		}
+		if key.kind != keyStorage {
+			continue
+		}
+		if _, ok := wiped[key.addr]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeWrites adds the state written by the transaction to [writes] and
+// [wiped].
+func (r *recordingStateDB) mergeWrites(writes map[stateKey]struct{}, wiped map[common.Address]struct{}) {
+	for key := range r.writes {
+		writes[key] = struct{}{}
+	}
+	for addr := range r.wiped {
+		wiped[addr] = struct{}{}
+	}
+}
+
+// replay applies the recorded state modifications to [statedb].
+func (r *recordingStateDB) replay(statedb *state.StateDB) {
+	for _, op := range r.ops {
+		op.apply(statedb)
+	}
+}
+
+func (r *recordingStateDB) CreateAccount(addr common.Address) {
+	r.wiped[addr] = struct{}{}
+	r.write(addr, keyBalance, keyNonce, keyCode)
+	r.modify(addr, func(s *state.StateDB) { s.CreateAccount(addr) })
+}
+
+func (r *recordingStateDB) SubBalance(addr common.Address, amount *big.Int) {
+	amount = new(big.Int).Set(amount)
+	r.write(addr, keyBalance)
+	r.modify(addr, func(s *state.StateDB) { s.SubBalance(addr, amount) })
+}
+
+func (r *recordingStateDB) AddBalance(addr common.Address, amount *big.Int) {
+	amount = new(big.Int).Set(amount)
+	r.write(addr, keyBalance)
+	r.modify(addr, func(s *state.StateDB) { s.AddBalance(addr, amount) })
+}
+
+func (r *recordingStateDB) GetBalance(addr common.Address) *big.Int {
+	r.read(addr, keyBalance)
+	return r.StateDB.GetBalance(addr)
+}
+
+// multiCoinSlot returns the storage slot holding the balance of [coinID].
+func multiCoinSlot(coinID common.Hash) common.Hash {
+	state.NormalizeCoinID(&coinID)
+	return coinID
+}
+
+// stateSlot returns the storage slot addressed by [key] in the account storage.
+func stateSlot(key common.Hash) common.Hash {
+	state.NormalizeStateKey(&key)
+	return key
+}
+
+func (r *recordingStateDB) SubBalanceMultiCoin(addr common.Address, coinID common.Hash, amount *big.Int) {
+	amount = new(big.Int).Set(amount)
+	r.writeSlot(addr, multiCoinSlot(coinID))
+	r.modify(addr, func(s *state.StateDB) { s.SubBalanceMultiCoin(addr, coinID, amount) })
+}
+
+func (r *recordingStateDB) AddBalanceMultiCoin(addr common.Address, coinID common.Hash, amount *big.Int) {
+	amount = new(big.Int).Set(amount)
+	r.writeSlot(addr, multiCoinSlot(coinID))
+	r.modify(addr, func(s *state.StateDB) { s.AddBalanceMultiCoin(addr, coinID, amount) })
+}
+
+func (r *recordingStateDB) GetBalanceMultiCoin(addr common.Address, coinID common.Hash) *big.Int {
+	r.readSlot(addr, multiCoinSlot(coinID))
+	return r.StateDB.GetBalanceMultiCoin(addr, coinID)
+}
+
+func (r *recordingStateDB) GetNonce(addr common.Address) uint64 {
+	r.read(addr, keyNonce)
+	return r.StateDB.GetNonce(addr)
+}
+
+func (r *recordingStateDB) SetNonce(addr common.Address, nonce uint64) {
+	r.write(addr, keyNonce)
+	r.modify(addr, func(s *state.StateDB) { s.SetNonce(addr, nonce) })
+}
+
+func (r *recordingStateDB) GetCodeHash(addr common.Address) common.Hash {
+	// The code hash of a non-existent account differs from the hash of an
+	// existing account without code.
+	r.read(addr, keyExistence)
+	r.read(addr, keyCode)
+	return r.StateDB.GetCodeHash(addr)
+}
+
+func (r *recordingStateDB) GetCode(addr common.Address) []byte {
+	r.read(addr, keyCode)
+	return r.StateDB.GetCode(addr)
+}
+
+func (r *recordingStateDB) SetCode(addr common.Address, code []byte) {
+	r.write(addr, keyCode)
+	r.modify(addr, func(s *state.StateDB) { s.SetCode(addr, code) })
+}
+
+func (r *recordingStateDB) GetCodeSize(addr common.Address) int {
+	r.read(addr, keyCode)
+	return r.StateDB.GetCodeSize(addr)
+}
+
+func (r *recordingStateDB) GetCommittedState(addr common.Address, hash common.Hash) common.Hash {
+	// The slot is read without normalizing its key, which may address a
+	// multicoin balance, so both slots are recorded.
+	r.readSlot(addr, hash)
+	r.readSlot(addr, stateSlot(hash))
+	return r.StateDB.GetCommittedState(addr, hash)
+}
+
+func (r *recordingStateDB) GetCommittedStateAP1(addr common.Address, hash common.Hash) common.Hash {
+	r.readSlot(addr, stateSlot(hash))
+	return r.StateDB.GetCommittedStateAP1(addr, hash)
+}
+
+func (r *recordingStateDB) GetState(addr common.Address, hash common.Hash) common.Hash {
+	r.readSlot(addr, stateSlot(hash))
+	return r.StateDB.GetState(addr, hash)
+}
+
+func (r *recordingStateDB) SetState(addr common.Address, key, value common.Hash) {
+	r.writeSlot(addr, stateSlot(key))
+	r.modify(addr, func(s *state.StateDB) { s.SetState(addr, key, value) })
+}
+
+func (r *recordingStateDB) Suicide(addr common.Address) bool {
+	r.wiped[addr] = struct{}{}
+	r.write(addr, keyBalance, keyNonce, keyCode)
+	// The account is deleted once the transaction is finalised.
+	r.write(addr, keyExistence)
+	var suicided bool
+	r.modify(addr, func(s *state.StateDB) { suicided = s.Suicide(addr) })
+	return suicided
+}
+
+func (r *recordingStateDB) Exist(addr common.Address) bool {
+	r.read(addr, keyExistence)
+	return r.StateDB.Exist(addr)
+}
+
+func (r *recordingStateDB) Empty(addr common.Address) bool {
+	r.read(addr, keyExistence)
+	return r.StateDB.Empty(addr)
+}
+
+func (r *recordingStateDB) ForEachStorage(addr common.Address, cb func(common.Hash, common.Hash) bool) error {
+	// Iterating the storage reads an unknown set of slots.
+	r.replayable = false
+	return r.StateDB.ForEachStorage(addr, cb)
+}
+
+func (r *recordingStateDB) Snapshot() int {
+	id := r.StateDB.Snapshot()
+	r.snapshots[id] = len(r.ops)
+	return id
+}
+
+func (r *recordingStateDB) RevertToSnapshot(revid int) {
+	r.StateDB.RevertToSnapshot(revid)
+	if !r.recordWrites {
+		return
+	}
+	// Reads and writes of the reverted execution are kept, since they only
+	// make the conflict detection more conservative.
+	n := r.snapshots[revid]
+	for _, op := range r.ops[n:] {
+		if op.addr == ripemdAddress {
+			r.replayable = false
+		}
+	}
+	r.ops = r.ops[:n]
+}
+
+func (r *recordingStateDB) AddLog(log *types.Log) {
+	if r.recordWrites {
+		r.ops = append(r.ops, stateWrite{apply: func(s *state.StateDB) { s.AddLog(log) }})
+	}
+	r.StateDB.AddLog(log)
+}
+
+func (r *recordingStateDB) AddPreimage(hash common.Hash, preimage []byte) {
+	if r.recordWrites {
+		r.ops = append(r.ops, stateWrite{apply: func(s *state.StateDB) { s.AddPreimage(hash, preimage) }})
+	}
+	r.StateDB.AddPreimage(hash, preimage)
+}
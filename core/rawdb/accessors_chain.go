@@ -43,11 +43,22 @@ import (
 func ReadCanonicalHash(db ethdb.Reader, number uint64) common.Hash {
 	data, _ := db.Get(headerHashKey(number))
 	if len(data) == 0 {
-		return common.Hash{}
+		// Accepted blocks may have been moved to the freezer
+		data, _ = db.Ancient(freezerHashTable, number)
+		if len(data) == 0 {
+			return common.Hash{}
+		}
 	}
 	return common.BytesToHash(data)
 }
 
+// isFrozen returns whether the block with [hash] and [number] has been moved
+// to the freezer. Only canonical blocks are ever frozen.
+func isFrozen(db ethdb.AncientReader, hash common.Hash, number uint64) bool {
+	data, _ := db.Ancient(freezerHashTable, number)
+	return len(data) > 0 && common.BytesToHash(data) == hash
+}
+
 // WriteCanonicalHash stores the hash assigned to a canonical block number.
 func WriteCanonicalHash(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
 	if err := db.Put(headerHashKey(number), hash.Bytes()); err != nil {
@@ -203,20 +214,27 @@ func WriteHeadBlockHash(db ethdb.KeyValueWriter, hash common.Hash) {
 
 // ReadHeaderRLP retrieves a block header in its raw RLP database encoding.
 func ReadHeaderRLP(db ethdb.Reader, hash common.Hash, number uint64) rlp.RawValue {
-	// Then try to look up the data in leveldb.
+	// First try to look up the data in the key-value store.
 	data, _ := db.Get(headerKey(number, hash))
 	if len(data) > 0 {
 		return data
 	}
+	// Then try to look up the data in the freezer.
+	if isFrozen(db, hash, number) {
+		data, _ = db.Ancient(freezerHeaderTable, number)
+		if len(data) > 0 {
+			return data
+		}
+	}
 	return nil // Can't find the data anywhere.
 }
 
 // HasHeader verifies the existence of a block header corresponding to the hash.
 func HasHeader(db ethdb.Reader, hash common.Hash, number uint64) bool {
-	if has, err := db.Has(headerKey(number, hash)); !has || err != nil {
-		return false
+	if has, err := db.Has(headerKey(number, hash)); has && err == nil {
+		return true
 	}
-	return true
+	return isFrozen(db, hash, number)
 }
 
 // ReadHeader retrieves the block header corresponding to the hash.
@@ -272,11 +290,18 @@ func deleteHeaderWithoutNumber(db ethdb.KeyValueWriter, hash common.Hash, number
 
 // ReadBodyRLP retrieves the block body (transactions and uncles) in RLP encoding.
 func ReadBodyRLP(db ethdb.Reader, hash common.Hash, number uint64) rlp.RawValue {
-	// Then try to look up the data in leveldb.
+	// First try to look up the data in the key-value store.
 	data, _ := db.Get(blockBodyKey(number, hash))
 	if len(data) > 0 {
 		return data
 	}
+	// Then try to look up the data in the freezer.
+	if isFrozen(db, hash, number) {
+		data, _ = db.Ancient(freezerBodiesTable, number)
+		if len(data) > 0 {
+			return data
+		}
+	}
 	return nil // Can't find the data anywhere.
 }
 
@@ -288,6 +313,11 @@ func ReadCanonicalBodyRLP(db ethdb.Reader, number uint64) rlp.RawValue {
 	if len(data) > 0 {
 		return data
 	}
+	// Frozen blocks are always canonical
+	data, _ = db.Ancient(freezerBodiesTable, number)
+	if len(data) > 0 {
+		return data
+	}
 	return nil
 }
 
@@ -300,10 +330,10 @@ func WriteBodyRLP(db ethdb.KeyValueWriter, hash common.Hash, number uint64, rlp
 
 // HasBody verifies the existence of a block body corresponding to the hash.
 func HasBody(db ethdb.Reader, hash common.Hash, number uint64) bool {
-	if has, err := db.Has(blockBodyKey(number, hash)); !has || err != nil {
-		return false
+	if has, err := db.Has(blockBodyKey(number, hash)); has && err == nil {
+		return true
 	}
-	return true
+	return isFrozen(db, hash, number)
 }
 
 // ReadBody retrieves the block body corresponding to the hash.
@@ -339,19 +369,26 @@ func DeleteBody(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
 // HasReceipts verifies the existence of all the transaction receipts belonging
 // to a block.
 func HasReceipts(db ethdb.Reader, hash common.Hash, number uint64) bool {
-	if has, err := db.Has(blockReceiptsKey(number, hash)); !has || err != nil {
-		return false
+	if has, err := db.Has(blockReceiptsKey(number, hash)); has && err == nil {
+		return true
 	}
-	return true
+	return isFrozen(db, hash, number)
 }
 
 // ReadReceiptsRLP retrieves all the transaction receipts belonging to a block in RLP encoding.
 func ReadReceiptsRLP(db ethdb.Reader, hash common.Hash, number uint64) rlp.RawValue {
-	// Then try to look up the data in leveldb.
+	// First try to look up the data in the key-value store.
 	data, _ := db.Get(blockReceiptsKey(number, hash))
 	if len(data) > 0 {
 		return data
 	}
+	// Then try to look up the data in the freezer.
+	if isFrozen(db, hash, number) {
+		data, _ = db.Ancient(freezerReceiptTable, number)
+		if len(data) > 0 {
+			return data
+		}
+	}
 	return nil // Can't find the data anywhere.
 }
 
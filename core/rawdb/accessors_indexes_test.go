@@ -179,3 +179,61 @@ func TestDeleteBloomBits(t *testing.T) {
 	check(1, 1, genesisHash0, true)
 	check(1, 1, genesisHash1, true)
 }
+
+// Tests that the positions of indexed logs can be looked up by address and by
+// topic at a given topic position.
+func TestLogIndex(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	var (
+		addr1  = common.Address{0x01}
+		addr2  = common.Address{0x02}
+		topic1 = common.Hash{0x01}
+		topic2 = common.Hash{0x02}
+	)
+	WriteLogIndex(db, []*types.Log{
+		{Address: addr1, Topics: []common.Hash{topic1, topic2}, BlockNumber: 1, TxIndex: 0, Index: 0},
+		{Address: addr2, Topics: []common.Hash{topic2}, BlockNumber: 1, TxIndex: 1, Index: 1},
+	})
+	WriteLogIndex(db, []*types.Log{
+		{Address: addr1, Topics: []common.Hash{topic2, topic1}, BlockNumber: 256, TxIndex: 3, Index: 7},
+	})
+	WriteLogIndex(db, []*types.Log{
+		{Address: addr1, BlockNumber: 300, TxIndex: 0, Index: 0},
+	})
+
+	check := func(have []LogPosition, want ...LogPosition) {
+		t.Helper()
+		if len(have) != len(want) {
+			t.Fatalf("log positions mismatch: have %v, want %v", have, want)
+		}
+		for i := range want {
+			if have[i] != want[i] {
+				t.Fatalf("log position %d mismatch: have %v, want %v", i, have[i], want[i])
+			}
+		}
+	}
+	check(ReadAddressLogPositions(db, addr1, 0, 1000), LogPosition{1, 0, 0}, LogPosition{256, 3, 7}, LogPosition{300, 0, 0})
+	check(ReadAddressLogPositions(db, addr1, 2, 299), LogPosition{256, 3, 7})
+	check(ReadAddressLogPositions(db, addr1, 257, 299))
+	check(ReadAddressLogPositions(db, addr2, 0, 1000), LogPosition{1, 1, 1})
+	check(ReadAddressLogPositions(db, common.Address{0x03}, 0, 1000))
+
+	check(ReadTopicLogPositions(db, 0, topic1, 0, 1000), LogPosition{1, 0, 0})
+	check(ReadTopicLogPositions(db, 1, topic1, 0, 1000), LogPosition{256, 3, 7})
+	check(ReadTopicLogPositions(db, 0, topic2, 0, 1000), LogPosition{1, 1, 1}, LogPosition{256, 3, 7})
+	check(ReadTopicLogPositions(db, 0, topic2, 1, 1), LogPosition{1, 1, 1})
+	check(ReadTopicLogPositions(db, 2, topic2, 0, 1000))
+
+	if tail := ReadLogIndexTail(db); tail != nil {
+		t.Fatalf("unexpected log index tail %d", *tail)
+	}
+	WriteLogIndexTail(db, 1)
+	WriteLogIndexHead(db, 300)
+	if tail := ReadLogIndexTail(db); tail == nil || *tail != 1 {
+		t.Fatalf("log index tail mismatch: have %v, want 1", tail)
+	}
+	if head := ReadLogIndexHead(db); head == nil || *head != 300 {
+		t.Fatalf("log index head mismatch: have %v, want 300", head)
+	}
+}
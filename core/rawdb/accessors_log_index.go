@@ -0,0 +1,191 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
+	"github.com/sankar-boro/axia-network-v2-coreth/ethdb"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// logPositionLength is the length of an encoded LogPosition.
+const logPositionLength = 8 + 4 + 4
+
+// LogPosition is the position of a log in the chain.
+type LogPosition struct {
+	Number  uint64 // Number of the block of the log
+	TxIndex uint32 // Index of the transaction of the log in the block
+	Index   uint32 // Index of the log in the block
+}
+
+// encode returns the big endian encoding of the position, which sorts the
+// positions by block, transaction and log.
+func (p LogPosition) encode() []byte {
+	enc := make([]byte, logPositionLength)
+	binary.BigEndian.PutUint64(enc, p.Number)
+	binary.BigEndian.PutUint32(enc[8:], p.TxIndex)
+	binary.BigEndian.PutUint32(enc[12:], p.Index)
+	return enc
+}
+
+func decodeLogPosition(enc []byte) LogPosition {
+	return LogPosition{
+		Number:  binary.BigEndian.Uint64(enc),
+		TxIndex: binary.BigEndian.Uint32(enc[8:]),
+		Index:   binary.BigEndian.Uint32(enc[12:]),
+	}
+}
+
+// WriteLogIndex indexes [logs] by address and by topic and topic position, so
+// that their positions can be looked up without the bloom bits. The logs must
+// have their position fields derived.
+func WriteLogIndex(db ethdb.KeyValueWriter, logs []*types.Log) {
+	for _, l := range logs {
+		pos := LogPosition{Number: l.BlockNumber, TxIndex: uint32(l.TxIndex), Index: uint32(l.Index)}.encode()
+		if err := db.Put(append(addressLogKeyPrefix(l.Address), pos...), nil); err != nil {
+			log.Crit("Failed to store address log index", "err", err)
+		}
+		for i, topic := range l.Topics {
+			if err := db.Put(append(topicLogKeyPrefix(i, topic), pos...), nil); err != nil {
+				log.Crit("Failed to store topic log index", "err", err)
+			}
+		}
+	}
+}
+
+// LogPositionIterator iterates over the positions of the logs with an address
+// or a topic in the log index, in chain order, without loading them into
+// memory at once.
+type LogPositionIterator struct {
+	it     ethdb.Iterator
+	prefix []byte
+	to     uint64
+	pos    LogPosition
+	done   bool
+}
+
+// NewAddressLogPositionIterator returns an iterator over the positions of the
+// logs emitted by [address] in the blocks [from] to [to].
+func NewAddressLogPositionIterator(db ethdb.Iteratee, address common.Address, from, to uint64) *LogPositionIterator {
+	return newLogPositionIterator(db, addressLogKeyPrefix(address), from, to)
+}
+
+// NewTopicLogPositionIterator returns an iterator over the positions of the
+// logs with [topic] at [position] of their topics in the blocks [from] to
+// [to].
+func NewTopicLogPositionIterator(db ethdb.Iteratee, position int, topic common.Hash, from, to uint64) *LogPositionIterator {
+	return newLogPositionIterator(db, topicLogKeyPrefix(position, topic), from, to)
+}
+
+func newLogPositionIterator(db ethdb.Iteratee, prefix []byte, from, to uint64) *LogPositionIterator {
+	return &LogPositionIterator{
+		it:     db.NewIterator(prefix, encodeBlockNumber(from)),
+		prefix: prefix,
+		to:     to,
+	}
+}
+
+// Next moves the iterator to the next position, returning whether there is
+// one.
+func (it *LogPositionIterator) Next() bool {
+	for !it.done && it.it.Next() {
+		key := it.it.Key()
+		if len(key) != len(it.prefix)+logPositionLength {
+			continue
+		}
+		it.pos = decodeLogPosition(key[len(it.prefix):])
+		if it.pos.Number > it.to {
+			break
+		}
+		return true
+	}
+	it.done = true
+	return false
+}
+
+// Position returns the current position of the iterator.
+func (it *LogPositionIterator) Position() LogPosition {
+	return it.pos
+}
+
+// Error returns any failure that occurred during iteration.
+func (it *LogPositionIterator) Error() error {
+	return it.it.Error()
+}
+
+// Release releases the resources of the iterator.
+func (it *LogPositionIterator) Release() {
+	it.it.Release()
+}
+
+// ReadAddressLogPositions retrieves the positions of the logs emitted by
+// [address] in the blocks [from] to [to], in chain order. All the positions
+// are loaded into memory, so large ranges should be iterated over with
+// NewAddressLogPositionIterator instead.
+func ReadAddressLogPositions(db ethdb.Iteratee, address common.Address, from, to uint64) []LogPosition {
+	return readLogPositions(NewAddressLogPositionIterator(db, address, from, to))
+}
+
+// ReadTopicLogPositions retrieves the positions of the logs with [topic] at
+// [position] of their topics in the blocks [from] to [to], in chain order. All
+// the positions are loaded into memory, so large ranges should be iterated
+// over with NewTopicLogPositionIterator instead.
+func ReadTopicLogPositions(db ethdb.Iteratee, position int, topic common.Hash, from, to uint64) []LogPosition {
+	return readLogPositions(NewTopicLogPositionIterator(db, position, topic, from, to))
+}
+
+func readLogPositions(it *LogPositionIterator) []LogPosition {
+	defer it.Release()
+
+	var positions []LogPosition
+	for it.Next() {
+		positions = append(positions, it.Position())
+	}
+	if it.Error() != nil {
+		log.Error("Failed to iterate log index", "err", it.Error())
+	}
+	return positions
+}
+
+// ReadLogIndexTail retrieves the number of the first block of the consecutive
+// range of accepted blocks whose logs are indexed by the acceptor, or nil if
+// there is none.
+func ReadLogIndexTail(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(logIndexTailKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteLogIndexTail stores the number of the first block of the range of
+// accepted blocks whose logs are indexed by the acceptor.
+func WriteLogIndexTail(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(logIndexTailKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store log index tail", "err", err)
+	}
+}
+
+// ReadLogIndexHead retrieves the number of the last accepted block whose logs
+// are indexed by the acceptor, or nil if there is none.
+func ReadLogIndexHead(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(logIndexHeadKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteLogIndexHead stores the number of the last accepted block whose logs
+// are indexed by the acceptor.
+func WriteLogIndexHead(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(logIndexHeadKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store log index head", "err", err)
+	}
+}
@@ -153,3 +153,27 @@ func WriteSnapshotGenerator(db ethdb.KeyValueWriter, generator []byte) {
 		log.Crit("Failed to store snapshot generator", "err", err)
 	}
 }
+
+// ReadSnapshotJournal retrieves the serialized diff layer that was being
+// flattened into the persisted snapshot.
+func ReadSnapshotJournal(db ethdb.KeyValueReader) []byte {
+	data, _ := db.Get(snapshotJournalKey)
+	return data
+}
+
+// WriteSnapshotJournal stores the serialized diff layer that is being flattened
+// into the persisted snapshot, so that the flatten can be completed after a
+// crash.
+func WriteSnapshotJournal(db ethdb.KeyValueWriter, journal []byte) {
+	if err := db.Put(snapshotJournalKey, journal); err != nil {
+		log.Crit("Failed to store snapshot journal", "err", err)
+	}
+}
+
+// DeleteSnapshotJournal deletes the serialized diff layer that was being
+// flattened into the persisted snapshot.
+func DeleteSnapshotJournal(db ethdb.KeyValueWriter) {
+	if err := db.Delete(snapshotJournalKey); err != nil {
+		log.Crit("Failed to remove snapshot journal", "err", err)
+	}
+}
@@ -0,0 +1,178 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/ethdb"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// StateHistory is the change set of the state of a block. It holds the value
+// of every account and storage slot modified by the block as it was before the
+// block modified it. An empty value denotes an account or storage slot that
+// did not exist.
+//
+// Multicoin balances are stored in the storage of their account, so their
+// changes are part of [Storage].
+type StateHistory struct {
+	Accounts []StateHistoryAccount
+	Storage  []StateHistoryStorage
+}
+
+// StateHistoryAccount is the value of the account of [Hash] before it was
+// modified, in the slim snapshot encoding.
+type StateHistoryAccount struct {
+	Hash common.Hash
+	Prev []byte
+}
+
+// StateHistoryStorage is the value of the storage slot of [Slot] of the
+// account of [Account] before it was modified.
+type StateHistoryStorage struct {
+	Account common.Hash
+	Slot    common.Hash
+	Prev    []byte
+}
+
+// ReadStateHistory retrieves the state changes of the block of [hash] and
+// [number] that have not been indexed yet, or nil if there are none.
+func ReadStateHistory(db ethdb.KeyValueReader, number uint64, hash common.Hash) *StateHistory {
+	data, _ := db.Get(stateHistoryKey(number, hash))
+	if len(data) == 0 {
+		return nil
+	}
+	history := new(StateHistory)
+	if err := rlp.DecodeBytes(data, history); err != nil {
+		log.Error("Invalid state history RLP", "hash", hash, "err", err)
+		return nil
+	}
+	return history
+}
+
+// WriteStateHistory stores the state changes of the block of [hash] and
+// [number] until the block is accepted.
+func WriteStateHistory(db ethdb.KeyValueWriter, number uint64, hash common.Hash, history *StateHistory) {
+	data, err := rlp.EncodeToBytes(history)
+	if err != nil {
+		log.Crit("Failed to RLP encode state history", "err", err)
+	}
+	if err := db.Put(stateHistoryKey(number, hash), data); err != nil {
+		log.Crit("Failed to store state history", "err", err)
+	}
+}
+
+// DeleteStateHistory deletes the state changes of the block of [hash] and
+// [number] that have not been indexed.
+func DeleteStateHistory(db ethdb.KeyValueWriter, number uint64, hash common.Hash) {
+	if err := db.Delete(stateHistoryKey(number, hash)); err != nil {
+		log.Crit("Failed to delete state history", "err", err)
+	}
+}
+
+// WriteStateHistoryIndex indexes the state changes [history] of the accepted
+// block [number] with state [root], so that the historical state can be
+// looked up by account and storage slot.
+func WriteStateHistoryIndex(db ethdb.KeyValueWriter, number uint64, root common.Hash, history *StateHistory) {
+	for _, account := range history.Accounts {
+		if err := db.Put(accountHistoryKey(account.Hash, number), account.Prev); err != nil {
+			log.Crit("Failed to store account history", "err", err)
+		}
+	}
+	for _, slot := range history.Storage {
+		if err := db.Put(storageHistoryKey(slot.Account, slot.Slot, number), slot.Prev); err != nil {
+			log.Crit("Failed to store storage history", "err", err)
+		}
+	}
+	WriteStateHistoryNumber(db, root, number)
+}
+
+// ReadAccountHistory retrieves the value of the account of [accountHash] in
+// the state of block [number], which is the value recorded by the first
+// indexed block after [number] that modified the account. The returned
+// boolean is false if no such block exists, in which case the account has not
+// been modified since. An empty value denotes an account that did not exist.
+func ReadAccountHistory(db ethdb.Iteratee, accountHash common.Hash, number uint64) ([]byte, bool) {
+	it := db.NewIterator(accountHistoryKeyPrefix(accountHash), encodeBlockNumber(number+1))
+	defer it.Release()
+
+	if !it.Next() {
+		return nil, false
+	}
+	return common.CopyBytes(it.Value()), true
+}
+
+// ReadStorageHistory retrieves the value of the storage slot of [storageHash]
+// of the account of [accountHash] in the state of block [number] (see
+// ReadAccountHistory).
+func ReadStorageHistory(db ethdb.Iteratee, accountHash, storageHash common.Hash, number uint64) ([]byte, bool) {
+	it := db.NewIterator(storageHistoryKeyPrefix(accountHash, storageHash), encodeBlockNumber(number+1))
+	defer it.Release()
+
+	if !it.Next() {
+		return nil, false
+	}
+	return common.CopyBytes(it.Value()), true
+}
+
+// ReadStateHistoryNumber retrieves the number of an indexed block with the
+// state [root], or nil if there is none.
+func ReadStateHistoryNumber(db ethdb.KeyValueReader, root common.Hash) *uint64 {
+	data, _ := db.Get(stateHistoryRootKey(root))
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteStateHistoryNumber stores the [number] of an indexed block with the
+// state [root].
+func WriteStateHistoryNumber(db ethdb.KeyValueWriter, root common.Hash, number uint64) {
+	if err := db.Put(stateHistoryRootKey(root), encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store state history number", "err", err)
+	}
+}
+
+// ReadStateHistoryTail retrieves the number of the first block of the
+// consecutive range of blocks whose state changes are indexed, or nil if
+// there is none.
+func ReadStateHistoryTail(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(stateHistoryTailKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteStateHistoryTail stores the number of the first block of the range of
+// blocks whose state changes are indexed.
+func WriteStateHistoryTail(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(stateHistoryTailKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store state history tail", "err", err)
+	}
+}
+
+// ReadStateHistoryHead retrieves the number of the last block whose state
+// changes are indexed, or nil if there is none.
+func ReadStateHistoryHead(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(stateHistoryHeadKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteStateHistoryHead stores the number of the last block whose state
+// changes are indexed.
+func WriteStateHistoryHead(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(stateHistoryHeadKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store state history head", "err", err)
+	}
+}
@@ -0,0 +1,227 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/ethdb"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// HashScheme stores each trie node keyed by its hash. Trie nodes that are
+	// no longer referenced stay on disk until they are pruned.
+	HashScheme = "hash"
+
+	// PathScheme stores each trie node keyed by its owner and path, so that a
+	// trie node is overwritten in place once the state changes. Recent states
+	// are served from reverse diffs.
+	PathScheme = "path"
+)
+
+// ReadStateScheme retrieves the scheme used to store the trie nodes of the
+// state. An empty string is returned if no scheme has been recorded yet.
+func ReadStateScheme(db ethdb.KeyValueReader) string {
+	data, _ := db.Get(stateSchemeKey)
+	return string(data)
+}
+
+// WriteStateScheme records the scheme used to store the trie nodes of the
+// state.
+func WriteStateScheme(db ethdb.KeyValueWriter, scheme string) {
+	if err := db.Put(stateSchemeKey, []byte(scheme)); err != nil {
+		log.Crit("Failed to store state scheme", "err", err)
+	}
+}
+
+// ParseStateScheme checks the [provided] state scheme against the scheme
+// recorded in [db] and returns the scheme to use. If no scheme has been
+// recorded yet, the [provided] scheme (defaulting to the hash scheme) is
+// recorded. The path scheme can only be selected for a new database.
+func ParseStateScheme(provided string, db ethdb.KeyValueStore) (string, error) {
+	if provided != "" && provided != HashScheme && provided != PathScheme {
+		return "", fmt.Errorf("unknown state scheme %q", provided)
+	}
+	stored := ReadStateScheme(db)
+	if stored == "" {
+		// Databases created before the scheme was recorded use the hash scheme
+		if provided == "" {
+			provided = HashScheme
+		}
+		if provided == PathScheme && ReadHeadHeaderHash(db) != (common.Hash{}) {
+			return "", fmt.Errorf("cannot use %s scheme for existing %s scheme database", PathScheme, HashScheme)
+		}
+		WriteStateScheme(db, provided)
+		return provided, nil
+	}
+	if provided != "" && provided != stored {
+		return "", fmt.Errorf("incompatible state scheme, stored: %s, provided: %s", stored, provided)
+	}
+	return stored, nil
+}
+
+// ReadAccountTrieNode retrieves the account trie node at [path].
+func ReadAccountTrieNode(db ethdb.KeyValueReader, path []byte) []byte {
+	data, _ := db.Get(accountTrieNodeKey(path))
+	return data
+}
+
+// WriteAccountTrieNode writes the account trie node at [path].
+func WriteAccountTrieNode(db ethdb.KeyValueWriter, path []byte, node []byte) {
+	if err := db.Put(accountTrieNodeKey(path), node); err != nil {
+		log.Crit("Failed to store account trie node", "err", err)
+	}
+}
+
+// DeleteAccountTrieNode deletes the account trie node at [path].
+func DeleteAccountTrieNode(db ethdb.KeyValueWriter, path []byte) {
+	if err := db.Delete(accountTrieNodeKey(path)); err != nil {
+		log.Crit("Failed to delete account trie node", "err", err)
+	}
+}
+
+// ReadStorageTrieNode retrieves the trie node at [path] of the storage trie of
+// [accountHash].
+func ReadStorageTrieNode(db ethdb.KeyValueReader, accountHash common.Hash, path []byte) []byte {
+	data, _ := db.Get(storageTrieNodeKey(accountHash, path))
+	return data
+}
+
+// WriteStorageTrieNode writes the trie node at [path] of the storage trie of
+// [accountHash].
+func WriteStorageTrieNode(db ethdb.KeyValueWriter, accountHash common.Hash, path []byte, node []byte) {
+	if err := db.Put(storageTrieNodeKey(accountHash, path), node); err != nil {
+		log.Crit("Failed to store storage trie node", "err", err)
+	}
+}
+
+// DeleteStorageTrieNode deletes the trie node at [path] of the storage trie of
+// [accountHash].
+func DeleteStorageTrieNode(db ethdb.KeyValueWriter, accountHash common.Hash, path []byte) {
+	if err := db.Delete(storageTrieNodeKey(accountHash, path)); err != nil {
+		log.Crit("Failed to delete storage trie node", "err", err)
+	}
+}
+
+// IterateStorageTrieNodes returns an iterator over the trie nodes of the
+// storage trie of [accountHash]. The path of a trie node is the key of the
+// iterator without the first [StorageTrieNodePathOffset] bytes.
+func IterateStorageTrieNodes(db ethdb.Iteratee, accountHash common.Hash) ethdb.Iterator {
+	return db.NewIterator(storageTrieNodeKey(accountHash, nil), nil)
+}
+
+// StorageTrieNodePathOffset is the offset of the path in the key of a storage
+// trie node.
+const StorageTrieNodePathOffset = 1 + common.HashLength
+
+// isTrieNodePath reports whether [path] is a valid path of a trie node, which
+// consists of hex nibbles.
+func isTrieNodePath(path []byte) bool {
+	for _, nibble := range path {
+		if nibble >= 16 {
+			return false
+		}
+	}
+	return true
+}
+
+// ReadTrieNodeWithPath retrieves the trie node at [path] of the account trie if
+// [owner] is the zero hash, or of the storage trie of [owner] otherwise.
+func ReadTrieNodeWithPath(db ethdb.KeyValueReader, owner common.Hash, path []byte) []byte {
+	if owner == (common.Hash{}) {
+		return ReadAccountTrieNode(db, path)
+	}
+	return ReadStorageTrieNode(db, owner, path)
+}
+
+// WriteTrieNodeWithPath writes the trie node at [path] of the account trie if
+// [owner] is the zero hash, or of the storage trie of [owner] otherwise.
+func WriteTrieNodeWithPath(db ethdb.KeyValueWriter, owner common.Hash, path []byte, node []byte) {
+	if owner == (common.Hash{}) {
+		WriteAccountTrieNode(db, path, node)
+	} else {
+		WriteStorageTrieNode(db, owner, path, node)
+	}
+}
+
+// DeleteTrieNodeWithPath deletes the trie node at [path] of the account trie
+// if [owner] is the zero hash, or of the storage trie of [owner] otherwise.
+func DeleteTrieNodeWithPath(db ethdb.KeyValueWriter, owner common.Hash, path []byte) {
+	if owner == (common.Hash{}) {
+		DeleteAccountTrieNode(db, path)
+	} else {
+		DeleteStorageTrieNode(db, owner, path)
+	}
+}
+
+// ReadReverseDiff retrieves the encoded reverse diff with [id].
+func ReadReverseDiff(db ethdb.KeyValueReader, id uint64) []byte {
+	data, _ := db.Get(reverseDiffKey(id))
+	return data
+}
+
+// WriteReverseDiff writes the encoded reverse diff with [id].
+func WriteReverseDiff(db ethdb.KeyValueWriter, id uint64, diff []byte) {
+	if err := db.Put(reverseDiffKey(id), diff); err != nil {
+		log.Crit("Failed to store reverse diff", "err", err)
+	}
+}
+
+// DeleteReverseDiff deletes the reverse diff with [id].
+func DeleteReverseDiff(db ethdb.KeyValueWriter, id uint64) {
+	if err := db.Delete(reverseDiffKey(id)); err != nil {
+		log.Crit("Failed to delete reverse diff", "err", err)
+	}
+}
+
+// ReadReverseDiffHead retrieves the id of the most recent reverse diff, or 0 if
+// there is none.
+func ReadReverseDiffHead(db ethdb.KeyValueReader) uint64 {
+	data, _ := db.Get(reverseDiffHeadKey)
+	if len(data) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(data)
+}
+
+// WriteReverseDiffHead writes the id of the most recent reverse diff.
+func WriteReverseDiffHead(db ethdb.KeyValueWriter, id uint64) {
+	if err := db.Put(reverseDiffHeadKey, encodeBlockNumber(id)); err != nil {
+		log.Crit("Failed to store reverse diff head", "err", err)
+	}
+}
+
+// DeleteReverseDiffs deletes all reverse diffs and their head. This is used
+// once the state on disk has been replaced by other means than committing
+// state roots, e.g. by state sync.
+func DeleteReverseDiffs(db ethdb.KeyValueStore) error {
+	batch := db.NewBatch()
+	it := db.NewIterator(reverseDiffPrefix, nil)
+	defer it.Release()
+
+	for it.Next() {
+		if len(it.Key()) != len(reverseDiffPrefix)+8 {
+			continue
+		}
+		if err := batch.Delete(it.Key()); err != nil {
+			return err
+		}
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if err := batch.Delete(reverseDiffHeadKey); err != nil {
+		return err
+	}
+	return batch.Write()
+}
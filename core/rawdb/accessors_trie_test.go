@@ -0,0 +1,47 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestParseStateScheme(t *testing.T) {
+	// The scheme of a new database defaults to the hash scheme
+	db := NewMemoryDatabase()
+	if scheme, err := ParseStateScheme("", db); err != nil || scheme != HashScheme {
+		t.Fatalf("unexpected scheme for new database: %q, %v", scheme, err)
+	}
+	if _, err := ParseStateScheme(PathScheme, db); err == nil {
+		t.Fatal("expected error switching hash scheme database to path scheme")
+	}
+
+	// The path scheme is recorded and used once selected
+	db = NewMemoryDatabase()
+	if scheme, err := ParseStateScheme(PathScheme, db); err != nil || scheme != PathScheme {
+		t.Fatalf("unexpected scheme selecting path scheme: %q, %v", scheme, err)
+	}
+	if scheme, err := ParseStateScheme("", db); err != nil || scheme != PathScheme {
+		t.Fatalf("unexpected scheme for path scheme database: %q, %v", scheme, err)
+	}
+	if _, err := ParseStateScheme(HashScheme, db); err == nil {
+		t.Fatal("expected error switching path scheme database to hash scheme")
+	}
+
+	// Databases created before the scheme was recorded use the hash scheme
+	db = NewMemoryDatabase()
+	WriteHeadHeaderHash(db, common.Hash{1})
+	if _, err := ParseStateScheme(PathScheme, db); err == nil {
+		t.Fatal("expected error selecting path scheme for existing database")
+	}
+	if scheme, err := ParseStateScheme("", db); err != nil || scheme != HashScheme {
+		t.Fatalf("unexpected scheme for existing database: %q, %v", scheme, err)
+	}
+
+	if _, err := ParseStateScheme("unknown", NewMemoryDatabase()); err == nil {
+		t.Fatal("expected error for unknown scheme")
+	}
+}
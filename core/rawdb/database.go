@@ -28,8 +28,10 @@ package rawdb
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/sankar-boro/axia-network-v2-coreth/ethdb"
@@ -40,17 +42,132 @@ import (
 	"github.com/olekukonko/tablewriter"
 )
 
+// freezerdb is a database wrapper that enables freezer data retrievals.
+type freezerdb struct {
+	ethdb.KeyValueStore
+	ethdb.AncientStore
+}
+
+// Close implements io.Closer, closing both the fast key-value store as well as
+// the slow ancient tables.
+func (frdb *freezerdb) Close() error {
+	var errs []error
+	if err := frdb.AncientStore.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := frdb.KeyValueStore.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
 // nofreezedb is a database wrapper that disables freezer data retrievals.
 type nofreezedb struct {
 	ethdb.KeyValueStore
 }
 
+// HasAncient returns an error as we don't have a backing chain freezer.
+func (db *nofreezedb) HasAncient(kind string, number uint64) (bool, error) {
+	return false, errNotSupported
+}
+
+// Ancient returns an error as we don't have a backing chain freezer.
+func (db *nofreezedb) Ancient(kind string, number uint64) ([]byte, error) {
+	return nil, errNotSupported
+}
+
+// Ancients returns an error as we don't have a backing chain freezer.
+func (db *nofreezedb) Ancients() (uint64, error) {
+	return 0, errNotSupported
+}
+
+// AncientSize returns an error as we don't have a backing chain freezer.
+func (db *nofreezedb) AncientSize(kind string) (uint64, error) {
+	return 0, errNotSupported
+}
+
+// AppendAncient returns an error as we don't have a backing chain freezer.
+func (db *nofreezedb) AppendAncient(number uint64, hash, header, body, receipts []byte) error {
+	return errNotSupported
+}
+
+// TruncateAncients returns an error as we don't have a backing chain freezer.
+func (db *nofreezedb) TruncateAncients(items uint64) error {
+	return errNotSupported
+}
+
+// Sync returns an error as we don't have a backing chain freezer.
+func (db *nofreezedb) Sync() error {
+	return errNotSupported
+}
+
 // NewDatabase creates a high level database on top of a given key-value data
 // store without a freezer moving immutable chain segments into cold storage.
 func NewDatabase(db ethdb.KeyValueStore) ethdb.Database {
 	return &nofreezedb{KeyValueStore: db}
 }
 
+// NewDatabaseWithFreezer creates a high level database on top of a given key-
+// value data store with a freezer in [freezer], which moves accepted blocks that
+// are more than [threshold] blocks behind the acceptor tip into cold storage.
+// Unless [readonly] is set, the blocks are moved in the background.
+func NewDatabaseWithFreezer(db ethdb.KeyValueStore, freezer string, namespace string, readonly bool, threshold uint64) (ethdb.Database, error) {
+	// Create the idle freezer instance
+	frdb, err := newFreezer(freezer, namespace, readonly, threshold)
+	if err != nil {
+		return nil, err
+	}
+	// Since the freezer can be stored separately from the user's key-value database,
+	// there's a fairly high probability that the user requests invalid combinations
+	// of the freezer and database. Ensure that we don't shoot ourselves in the foot
+	// by serving up conflicting data, leading to both datastores getting corrupted.
+	//
+	// The genesis block is never deleted from the key-value store, so it can be
+	// used to verify that the freezer belongs to the database.
+	if kvgenesis, _ := db.Get(headerHashKey(0)); len(kvgenesis) > 0 {
+		if frgenesis, err := frdb.Ancient(freezerHashTable, 0); err == nil && len(frgenesis) > 0 {
+			if !bytes.Equal(kvgenesis, frgenesis) {
+				frdb.Close()
+				return nil, fmt.Errorf("genesis mismatch: %#x (leveldb) != %#x (ancients)", kvgenesis, frgenesis)
+			}
+		}
+	} else if frozen, _ := frdb.Ancients(); frozen > 0 {
+		frdb.Close()
+		return nil, errors.New("ancient chain segments already extracted, please set the matching database")
+	}
+	// Freezer is consistent with the key-value database, permit combining the two
+	if !readonly {
+		frdb.wg.Add(1)
+		go frdb.freeze(db)
+	}
+	return &freezerdb{
+		KeyValueStore: db,
+		AncientStore:  frdb,
+	}, nil
+}
+
+// FreezeAncients moves all the accepted blocks of [db] that are eligible to be
+// frozen into its freezer and returns the number of blocks moved. It returns an
+// error if [db] has no freezer.
+func FreezeAncients(db ethdb.Database) (uint64, error) {
+	frdb, ok := db.(*freezerdb)
+	if !ok {
+		return 0, errNotSupported
+	}
+	f := frdb.AncientStore.(*freezer)
+	var total uint64
+	for {
+		frozen, err := f.freezeBatch(frdb.KeyValueStore)
+		total += frozen
+		if err != nil || frozen < freezerBatchLimit {
+			return total, err
+		}
+	}
+}
+
 // NewMemoryDatabase creates an ephemeral in-memory key-value database without a
 // freezer moving immutable chain segments into cold storage.
 func NewMemoryDatabase() ethdb.Database {
@@ -74,6 +191,94 @@ func NewLevelDBDatabase(file string, cache int, handles int, namespace string, r
 	return NewDatabase(db), nil
 }
 
+// NewPebbleDBDatabase creates a persistent key-value database without a freezer
+// moving immutable chain segments into cold storage.
+func NewPebbleDBDatabase(file string, cache int, handles int, namespace string, readonly bool) (ethdb.Database, error) {
+	db, err := newPebbleDBKeyValueStore(file, cache, handles, namespace, readonly)
+	if err != nil {
+		return nil, err
+	}
+	return NewDatabase(db), nil
+}
+
+const (
+	// DBLeveldb is the database type of a LevelDB backed key-value store.
+	DBLeveldb = "leveldb"
+	// DBPebble is the database type of a Pebble backed key-value store.
+	DBPebble = "pebble"
+)
+
+// PreexistingDatabase checks whether a database is already instantiated at
+// [path] and, if so, returns its type. It returns the empty string if there
+// is no database at [path].
+func PreexistingDatabase(path string) string {
+	if _, err := os.Stat(filepath.Join(path, "CURRENT")); err != nil {
+		return "" // No pre-existing db
+	}
+	// Pebble writes an OPTIONS file next to its manifest, which LevelDB does
+	// not.
+	if matches, err := filepath.Glob(filepath.Join(path, "OPTIONS*")); err == nil && len(matches) > 0 {
+		return DBPebble
+	}
+	return DBLeveldb
+}
+
+// OpenOptions contains the options to apply when opening a database.
+type OpenOptions struct {
+	Type      string // "leveldb" | "pebble", defaults to the type of a pre-existing database or leveldb
+	Directory string // the datadir
+	Namespace string // the namespace for database relevant metrics
+	Cache     int    // the capacity (in megabytes) of the data caching
+	Handles   int    // number of files to be open simultaneously
+	ReadOnly  bool
+
+	AncientsDirectory string // the ancients data directory, the freezer is disabled if empty
+	FreezerThreshold  uint64 // number of recent accepted blocks kept in the key-value store
+}
+
+// openKeyValueDatabase opens a persistent key-value store of the requested type
+// in [o.Directory]. If a database already exists there, it must be of the
+// requested type.
+func openKeyValueDatabase(o OpenOptions) (ethdb.KeyValueStore, error) {
+	existingDb := PreexistingDatabase(o.Directory)
+	if len(existingDb) != 0 && len(o.Type) != 0 && o.Type != existingDb {
+		return nil, fmt.Errorf("database type was %s but found pre-existing %s database in %s", o.Type, existingDb, o.Directory)
+	}
+	dbType := o.Type
+	if len(dbType) == 0 {
+		dbType = existingDb
+	}
+	switch dbType {
+	case DBPebble:
+		log.Info("Using pebble as the backing database")
+		return newPebbleDBKeyValueStore(o.Directory, o.Cache, o.Handles, o.Namespace, o.ReadOnly)
+	case DBLeveldb, "":
+		log.Info("Using leveldb as the backing database")
+		return leveldb.New(o.Directory, o.Cache, o.Handles, o.Namespace, o.ReadOnly)
+	default:
+		return nil, fmt.Errorf("unknown database type %s", dbType)
+	}
+}
+
+// Open opens a persistent database of the requested type in [o.Directory],
+// with a freezer in [o.AncientsDirectory] if set. If a database already exists
+// in [o.Directory], it must be of the requested type.
+func Open(o OpenOptions) (ethdb.Database, error) {
+	kvdb, err := openKeyValueDatabase(o)
+	if err != nil {
+		return nil, err
+	}
+	if len(o.AncientsDirectory) == 0 {
+		return NewDatabase(kvdb), nil
+	}
+	frdb, err := NewDatabaseWithFreezer(kvdb, o.AncientsDirectory, o.Namespace, o.ReadOnly, o.FreezerThreshold)
+	if err != nil {
+		kvdb.Close()
+		return nil, err
+	}
+	return frdb, nil
+}
+
 type counter uint64
 
 func (c counter) String() string {
@@ -122,6 +327,10 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		numHashPairings stat
 		hashNumPairings stat
 		tries           stat
+		pathTries       stat
+		reverseDiffs    stat
+		stateHistory    stat
+		logIndex        stat
 		codes           stat
 		txLookups       stat
 		accountSnaps    stat
@@ -161,6 +370,21 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			hashNumPairings.Add(size)
 		case len(key) == common.HashLength:
 			tries.Add(size)
+		case bytes.HasPrefix(key, TrieNodeAccountPrefix) && isTrieNodePath(key[len(TrieNodeAccountPrefix):]):
+			pathTries.Add(size)
+		case bytes.HasPrefix(key, TrieNodeStoragePrefix) && len(key) >= StorageTrieNodePathOffset && isTrieNodePath(key[StorageTrieNodePathOffset:]):
+			pathTries.Add(size)
+		case bytes.HasPrefix(key, reverseDiffPrefix) && len(key) == len(reverseDiffPrefix)+8:
+			reverseDiffs.Add(size)
+		case bytes.HasPrefix(key, stateHistoryPrefix) && len(key) == len(stateHistoryPrefix)+8+common.HashLength,
+			bytes.HasPrefix(key, accountHistoryPrefix) && len(key) == len(accountHistoryPrefix)+common.HashLength+8,
+			bytes.HasPrefix(key, storageHistoryPrefix) && len(key) == len(storageHistoryPrefix)+2*common.HashLength+8,
+			bytes.HasPrefix(key, stateHistoryRootPrefix) && len(key) == len(stateHistoryRootPrefix)+common.HashLength:
+			stateHistory.Add(size)
+		case bytes.HasPrefix(key, addressLogPrefix) && len(key) == len(addressLogPrefix)+common.AddressLength+logPositionLength,
+			bytes.HasPrefix(key, topicLogPrefix) && len(key) == len(topicLogPrefix)+1+common.HashLength+logPositionLength,
+			bytes.HasPrefix(key, LogIndexIndexPrefix):
+			logIndex.Add(size)
 		case bytes.HasPrefix(key, CodePrefix) && len(key) == len(CodePrefix)+common.HashLength:
 			codes.Add(size)
 		case bytes.HasPrefix(key, txLookupPrefix) && len(key) == (len(txLookupPrefix)+common.HashLength):
@@ -191,7 +415,9 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			var accounted bool
 			for _, meta := range [][]byte{
 				databaseVersionKey, headHeaderKey, headBlockKey,
-				snapshotRootKey, snapshotGeneratorKey, uncleanShutdownKey,
+				snapshotRootKey, snapshotGeneratorKey, snapshotJournalKey, uncleanShutdownKey,
+				stateSchemeKey, reverseDiffHeadKey, stateHistoryTailKey, stateHistoryHeadKey,
+				logIndexTailKey, logIndexHeadKey,
 			} {
 				if bytes.Equal(key, meta) {
 					metadata.Add(size)
@@ -220,6 +446,10 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		{"Key-Value store", "Bloombit index", bloomBits.Size(), bloomBits.Count()},
 		{"Key-Value store", "Contract codes", codes.Size(), codes.Count()},
 		{"Key-Value store", "Trie nodes", tries.Size(), tries.Count()},
+		{"Key-Value store", "Path trie nodes", pathTries.Size(), pathTries.Count()},
+		{"Key-Value store", "Reverse diffs", reverseDiffs.Size(), reverseDiffs.Count()},
+		{"Key-Value store", "State history", stateHistory.Size(), stateHistory.Count()},
+		{"Key-Value store", "Log index", logIndex.Size(), logIndex.Count()},
 		{"Key-Value store", "Trie preimages", preimages.Size(), preimages.Count()},
 		{"Key-Value store", "Account snapshot", accountSnaps.Size(), accountSnaps.Count()},
 		{"Key-Value store", "Storage snapshot", storageSnaps.Size(), storageSnaps.Count()},
@@ -228,6 +458,24 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		{"Light client", "CHT trie nodes", chtTrieNodes.Size(), chtTrieNodes.Count()},
 		{"Light client", "Bloom trie nodes", bloomTrieNodes.Size(), bloomTrieNodes.Count()},
 	}
+	// Inspect the append-only file store, if there is one.
+	if ancients, err := db.Ancients(); err == nil {
+		for _, category := range []struct {
+			table, name string
+		}{
+			{freezerHeaderTable, "Headers"},
+			{freezerBodiesTable, "Bodies"},
+			{freezerReceiptTable, "Receipt lists"},
+			{freezerHashTable, "Block number->hash"},
+		} {
+			size, err := db.AncientSize(category.table)
+			if err != nil {
+				return err
+			}
+			total += common.StorageSize(size)
+			stats = append(stats, []string{"Ancient store", category.name, common.StorageSize(size).String(), counter(ancients).String()})
+		}
+	}
 	table := tablewriter.NewWriter(os.Stdout)
 	table.SetHeader([]string{"Database", "Category", "Size", "Items"})
 	table.SetFooter([]string{"", "Total", total.String(), " "})
@@ -0,0 +1,415 @@
+// (c) 2022, Axia Systems, Inc.
+//
+// This file is a derived work, based on the go-ethereum library whose original
+// notices appear below.
+//
+// It is distributed under a license compatible with the licensing terms of the
+// original code from which it is derived.
+//
+// Much love to the original authors for their work.
+// **********
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/ethdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/metrics"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var (
+	// errReadOnly is returned if the freezer is opened in read only mode. All the
+	// mutations are disallowed.
+	errReadOnly = errors.New("read only")
+
+	// errUnknownTable is returned if the user attempts to read from a table that is
+	// not tracked by the freezer.
+	errUnknownTable = errors.New("unknown table")
+
+	// errOutOrderInsertion is returned if the user attempts to inject out-of-order
+	// binary blobs into the freezer.
+	errOutOrderInsertion = errors.New("the append operation is out-order")
+)
+
+const (
+	// freezerRecheckInterval is the frequency to check the key-value database for
+	// chain progression that might permit new blocks to be frozen into immutable
+	// storage.
+	freezerRecheckInterval = time.Minute
+
+	// freezerBatchLimit is the maximum number of blocks to freeze in one batch
+	// before doing an fsync and deleting it from the key-value store.
+	freezerBatchLimit = 30000
+
+	// freezerTableSize defines the maximum size of freezer data files.
+	freezerTableSize = 2 * 1000 * 1000 * 1000
+)
+
+// freezer is an append-only database to store immutable chain data into flat
+// files. The append only nature ensures that disk writes are minimized and the
+// key-value store does not need to compact the immutable data over and over.
+//
+// Under Snowman, accepted blocks are final, so the blocks more than [threshold]
+// blocks behind the acceptor tip can be moved to the freezer safely.
+type freezer struct {
+	// WARNING: The `frozen` field is accessed atomically. On 32 bit platforms, only
+	// 64-bit aligned fields can be atomic. The struct is guaranteed to be so aligned,
+	// so take advantage of that (https://golang.org/pkg/sync/atomic/#pkg-note-BUG).
+	frozen    uint64 // Number of blocks already frozen
+	threshold uint64 // Number of recent accepted blocks not to freeze
+
+	readonly bool
+	tables   map[string]*freezerTable // Data tables for storing everything
+
+	freezeLock sync.Mutex // Serialises the moves of blocks into the freezer
+
+	quit      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// newFreezer creates a chain freezer that moves ancient chain data into
+// append-only flat file containers.
+func newFreezer(datadir string, namespace string, readonly bool, threshold uint64) (*freezer, error) {
+	// Create the initial freezer object
+	var (
+		readMeter  = metrics.NewRegisteredMeter(namespace+"ancient/read", nil)
+		writeMeter = metrics.NewRegisteredMeter(namespace+"ancient/write", nil)
+		sizeGauge  = metrics.NewRegisteredGauge(namespace+"ancient/size", nil)
+	)
+	// Open all the supported data tables
+	freezer := &freezer{
+		threshold: threshold,
+		readonly:  readonly,
+		tables:    make(map[string]*freezerTable),
+		quit:      make(chan struct{}),
+	}
+	for name, disableSnappy := range freezerNoSnappy {
+		table, err := newTable(datadir, name, readMeter, writeMeter, sizeGauge, freezerTableSize, disableSnappy)
+		if err != nil {
+			for _, table := range freezer.tables {
+				table.Close()
+			}
+			return nil, err
+		}
+		freezer.tables[name] = table
+	}
+	if err := freezer.repair(); err != nil {
+		for _, table := range freezer.tables {
+			table.Close()
+		}
+		return nil, err
+	}
+	log.Info("Opened ancient database", "database", datadir, "readonly", readonly, "frozen", freezer.frozen)
+	return freezer, nil
+}
+
+// Close terminates the chain freezer, closing all the data files.
+func (f *freezer) Close() error {
+	var errs []error
+	f.closeOnce.Do(func() {
+		close(f.quit)
+		// Wait for any background freezing to stop
+		f.wg.Wait()
+		for _, table := range f.tables {
+			if err := table.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	})
+	if errs != nil {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// HasAncient returns an indicator whether the specified ancient data exists
+// in the freezer.
+func (f *freezer) HasAncient(kind string, number uint64) (bool, error) {
+	if table := f.tables[kind]; table != nil {
+		return table.has(number), nil
+	}
+	return false, nil
+}
+
+// Ancient retrieves an ancient binary blob from the append-only immutable files.
+func (f *freezer) Ancient(kind string, number uint64) ([]byte, error) {
+	if table := f.tables[kind]; table != nil {
+		return table.Retrieve(number)
+	}
+	return nil, errUnknownTable
+}
+
+// Ancients returns the length of the frozen items.
+func (f *freezer) Ancients() (uint64, error) {
+	return atomic.LoadUint64(&f.frozen), nil
+}
+
+// AncientSize returns the ancient size of the specified category.
+func (f *freezer) AncientSize(kind string) (uint64, error) {
+	if table := f.tables[kind]; table != nil {
+		return table.size()
+	}
+	return 0, errUnknownTable
+}
+
+// AppendAncient injects all binary blobs belong to block at the end of the
+// append-only immutable table files.
+//
+// All out-of-order injection will be rejected, but the freezer only supports a
+// single writer.
+func (f *freezer) AppendAncient(number uint64, hash, header, body, receipts []byte) (err error) {
+	if f.readonly {
+		return errReadOnly
+	}
+	// Ensure the binary blobs we are appending is continuous with freezer.
+	if atomic.LoadUint64(&f.frozen) != number {
+		return errOutOrderInsertion
+	}
+	// Rollback all inserted data if any insertion below failed to ensure
+	// the tables won't out of sync.
+	defer func() {
+		if err != nil {
+			rerr := f.repair()
+			if rerr != nil {
+				log.Crit("Failed to repair freezer", "err", rerr)
+			}
+			log.Info("Append ancient failed", "number", number, "err", err)
+		}
+	}()
+	// Inject all the components into the relevant data tables
+	if err := f.tables[freezerHashTable].Append(number, hash); err != nil {
+		log.Error("Failed to append ancient hash", "number", number, "hash", common.BytesToHash(hash), "err", err)
+		return err
+	}
+	if err := f.tables[freezerHeaderTable].Append(number, header); err != nil {
+		log.Error("Failed to append ancient header", "number", number, "hash", common.BytesToHash(hash), "err", err)
+		return err
+	}
+	if err := f.tables[freezerBodiesTable].Append(number, body); err != nil {
+		log.Error("Failed to append ancient body", "number", number, "hash", common.BytesToHash(hash), "err", err)
+		return err
+	}
+	if err := f.tables[freezerReceiptTable].Append(number, receipts); err != nil {
+		log.Error("Failed to append ancient receipts", "number", number, "hash", common.BytesToHash(hash), "err", err)
+		return err
+	}
+	atomic.AddUint64(&f.frozen, 1) // Only modify atomically
+	return nil
+}
+
+// TruncateAncients discards any recent data above the provided threshold number.
+func (f *freezer) TruncateAncients(items uint64) error {
+	if f.readonly {
+		return errReadOnly
+	}
+	if atomic.LoadUint64(&f.frozen) <= items {
+		return nil
+	}
+	for _, table := range f.tables {
+		if err := table.truncate(items); err != nil {
+			return err
+		}
+	}
+	atomic.StoreUint64(&f.frozen, items)
+	return nil
+}
+
+// Sync flushes all data tables to disk.
+func (f *freezer) Sync() error {
+	var errs []error
+	for _, table := range f.tables {
+		if err := table.Sync(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if errs != nil {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// repair truncates all data tables to the same length.
+func (f *freezer) repair() error {
+	min := uint64(math.MaxUint64)
+	for _, table := range f.tables {
+		items := atomic.LoadUint64(&table.items)
+		if min > items {
+			min = items
+		}
+	}
+	for _, table := range f.tables {
+		if err := table.truncate(min); err != nil {
+			return err
+		}
+	}
+	atomic.StoreUint64(&f.frozen, min)
+	return nil
+}
+
+// freeze is a background thread that periodically checks the blockchain for any
+// import progress and moves ancient data from the fast database into the freezer.
+//
+// This functionality is deliberately broken off from block importing to avoid
+// incurring additional data shuffling delays on block propagation.
+func (f *freezer) freeze(db ethdb.KeyValueStore) {
+	defer f.wg.Done()
+
+	timer := time.NewTimer(freezerRecheckInterval)
+	defer timer.Stop()
+
+	for {
+		// Keep freezing batches until the freezer caught up with the chain
+		for {
+			frozen, err := f.freezeBatch(db)
+			if err != nil {
+				log.Error("Failed to freeze ancient blocks", "err", err)
+				break
+			}
+			if frozen < freezerBatchLimit {
+				break
+			}
+			select {
+			case <-f.quit:
+				return
+			default:
+			}
+		}
+		select {
+		case <-f.quit:
+			log.Info("Freezer shutting down")
+			return
+		case <-timer.C:
+			timer.Reset(freezerRecheckInterval)
+		}
+	}
+}
+
+// freezeBatch moves up to [freezerBatchLimit] accepted blocks, which are more
+// than [f.threshold] blocks behind the acceptor tip, from [db] into the freezer.
+// It returns the number of blocks frozen, including the skipped ones.
+//
+// Blocks missing from [db], e.g. the ones below the summary of a state synced
+// node, are skipped by freezing empty items in their place. The chain readers
+// treat these like blocks that are not frozen, so any of their data that is in
+// [db] is left there and stays readable.
+func (f *freezer) freezeBatch(db ethdb.KeyValueStore) (uint64, error) {
+	f.freezeLock.Lock()
+	defer f.freezeLock.Unlock()
+
+	// Only read from the key-value store, the freezer has not got the blocks yet
+	nfdb := &nofreezedb{KeyValueStore: db}
+
+	// Retrieve the freezing threshold.
+	tip, err := ReadAcceptorTip(nfdb)
+	if err != nil {
+		return 0, err
+	}
+	if tip == (common.Hash{}) {
+		return 0, nil
+	}
+	number := ReadHeaderNumber(nfdb, tip)
+	if number == nil || *number+1 <= f.threshold {
+		return 0, nil
+	}
+	var (
+		first = atomic.LoadUint64(&f.frozen)
+		limit = *number + 1 - f.threshold
+	)
+	if limit <= first {
+		return 0, nil
+	}
+	if limit-first > freezerBatchLimit {
+		limit = first + freezerBatchLimit
+	}
+	// First we need to write the blocks into the freezer
+	var (
+		start   = time.Now()
+		hashes  = make([]common.Hash, 0, limit-first) // Empty for skipped blocks
+		skipped int
+	)
+	for number := first; number < limit; number++ {
+		// Retrieves all the components of the canonical block
+		var (
+			hash     = ReadCanonicalHash(nfdb, number)
+			header   = ReadHeaderRLP(nfdb, hash, number)
+			body     = ReadBodyRLP(nfdb, hash, number)
+			receipts = ReadReceiptsRLP(nfdb, hash, number)
+		)
+		if hash == (common.Hash{}) || len(header) == 0 || len(body) == 0 || len(receipts) == 0 {
+			if err := f.AppendAncient(number, nil, nil, nil, nil); err != nil {
+				return 0, err
+			}
+			hashes = append(hashes, common.Hash{})
+			skipped++
+			continue
+		}
+		// Inject all the components into the relevant data tables
+		if err := f.AppendAncient(number, hash[:], header, body, receipts); err != nil {
+			return 0, err
+		}
+		hashes = append(hashes, hash)
+	}
+	// Batch of blocks have been frozen, flush them before wiping them from the key-value store
+	if err := f.Sync(); err != nil {
+		return 0, err
+	}
+	// Wipe out all data from the active database
+	batch := db.NewBatch()
+	for i, hash := range hashes {
+		number := first + uint64(i)
+		// Always keep the genesis block and anything left of skipped blocks in
+		// the active database
+		if number == 0 || hash == (common.Hash{}) {
+			continue
+		}
+		DeleteBlockWithoutNumber(batch, hash, number)
+		DeleteCanonicalHash(batch, number)
+
+		// Wipe out side chains at the same height, which can never be
+		// accepted
+		for _, sideHash := range ReadAllHashes(db, number) {
+			if sideHash != hash {
+				DeleteBlock(batch, sideHash, number)
+			}
+		}
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return 0, err
+			}
+			batch.Reset()
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return 0, err
+	}
+	// Log something friendly for the user
+	if skipped > 0 {
+		log.Warn("Skipped ancient blocks missing from database", "blocks", skipped)
+	}
+	log.Info("Moved ancient blocks into freezer", "blocks", len(hashes)-skipped, "number", limit-1, "elapsed", common.PrettyDuration(time.Since(start)))
+	return uint64(len(hashes)), nil
+}
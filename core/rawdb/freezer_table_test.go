@@ -0,0 +1,225 @@
+// (c) 2022, Axia Systems, Inc.
+//
+// This file is a derived work, based on the go-ethereum library whose original
+// notices appear below.
+//
+// It is distributed under a license compatible with the licensing terms of the
+// original code from which it is derived.
+//
+// Much love to the original authors for their work.
+// **********
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/metrics"
+)
+
+// getChunk returns a chunk of data of [size] bytes, all set to [b].
+func getChunk(size int, b int) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(b)
+	}
+	return data
+}
+
+// openTestTable opens the table [name] in [dir] with tiny data files, so that
+// the tests cover the file rollover.
+func openTestTable(t *testing.T, dir string, name string, noCompression bool) *freezerTable {
+	t.Helper()
+
+	f, err := newTable(dir, name, metrics.NilMeter{}, metrics.NilMeter{}, metrics.NilGauge{}, 50, noCompression)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+// checkRetrieve checks that the items [from, to) of [f] match getChunk.
+func checkRetrieve(t *testing.T, f *freezerTable, from, to int) {
+	t.Helper()
+
+	for y := from; y < to; y++ {
+		got, err := f.Retrieve(uint64(y))
+		if err != nil {
+			t.Fatalf("failed to retrieve item %d: %v", y, err)
+		}
+		if exp := getChunk(15, y); !bytes.Equal(got, exp) {
+			t.Fatalf("item %d mismatch: have %x, want %x", y, got, exp)
+		}
+	}
+}
+
+// TestFreezerBasics tests initializing a freezer table from scratch, writing to
+// it and reading it back, also after reopening it.
+func TestFreezerBasics(t *testing.T) {
+	for _, noCompression := range []bool{true, false} {
+		t.Run(fmt.Sprintf("noCompression=%t", noCompression), func(t *testing.T) {
+			dir := t.TempDir()
+			f := openTestTable(t, dir, "basics", noCompression)
+
+			// Write 15 bytes 255 times, results in 85 files
+			for x := 0; x < 255; x++ {
+				if err := f.Append(uint64(x), getChunk(15, x)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// Appending out of order is rejected
+			if err := f.Append(300, getChunk(15, 0)); err == nil {
+				t.Fatal("expected out of order append to fail")
+			}
+			checkRetrieve(t, f, 0, 255)
+			if _, err := f.Retrieve(255); err != errOutOfBounds {
+				t.Fatalf("expected %v retrieving item past the head, got %v", errOutOfBounds, err)
+			}
+			if err := f.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := f.Retrieve(0); err != errClosed {
+				t.Fatalf("expected %v retrieving from closed table, got %v", errClosed, err)
+			}
+
+			// Reopen the table and check that the data survived
+			f = openTestTable(t, dir, "basics", noCompression)
+			defer f.Close()
+			if f.items != 255 {
+				t.Fatalf("expected 255 items after reopening, got %d", f.items)
+			}
+			checkRetrieve(t, f, 0, 255)
+		})
+	}
+}
+
+// TestFreezerRepairDanglingHead tests that a partially written index entry is
+// discarded when the table is reopened.
+func TestFreezerRepairDanglingHead(t *testing.T) {
+	dir := t.TempDir()
+	f := openTestTable(t, dir, "dangling_head", true)
+	for x := 0; x < 255; x++ {
+		if err := f.Append(uint64(x), getChunk(15, x)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Chop off the last four bytes of the index file
+	idxFile, err := os.OpenFile(filepath.Join(dir, "dangling_head.ridx"), os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stat, err := idxFile.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := idxFile.Truncate(stat.Size() - 4); err != nil {
+		t.Fatal(err)
+	}
+	idxFile.Close()
+
+	// The last item should be gone and the rest readable
+	f = openTestTable(t, dir, "dangling_head", true)
+	defer f.Close()
+	if f.items != 254 {
+		t.Fatalf("expected 254 items after repair, got %d", f.items)
+	}
+	checkRetrieve(t, f, 0, 254)
+
+	// The table accepts the lost item again
+	if err := f.Append(254, getChunk(15, 254)); err != nil {
+		t.Fatal(err)
+	}
+	checkRetrieve(t, f, 0, 255)
+}
+
+// TestFreezerRepairDanglingData tests that index entries pointing past the end
+// of the head data file are discarded when the table is reopened.
+func TestFreezerRepairDanglingData(t *testing.T) {
+	dir := t.TempDir()
+	f := openTestTable(t, dir, "dangling_data", true)
+	// Three items per data file, the head file holds items 6 and 7
+	for x := 0; x < 8; x++ {
+		if err := f.Append(uint64(x), getChunk(15, x)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Lose the second half of the last item in the head data file
+	if err := os.Truncate(filepath.Join(dir, "dangling_data.0002.rdat"), 22); err != nil {
+		t.Fatal(err)
+	}
+
+	f = openTestTable(t, dir, "dangling_data", true)
+	defer f.Close()
+	if f.items != 7 {
+		t.Fatalf("expected 7 items after repair, got %d", f.items)
+	}
+	checkRetrieve(t, f, 0, 7)
+}
+
+// TestFreezerTruncate tests that truncating a table discards the newer items,
+// including the data files holding only discarded items.
+func TestFreezerTruncate(t *testing.T) {
+	dir := t.TempDir()
+	f := openTestTable(t, dir, "truncation", true)
+	for x := 0; x < 30; x++ {
+		if err := f.Append(uint64(x), getChunk(15, x)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.truncate(10); err != nil {
+		t.Fatal(err)
+	}
+	if f.items != 10 {
+		t.Fatalf("expected 10 items after truncation, got %d", f.items)
+	}
+	checkRetrieve(t, f, 0, 10)
+	if _, err := f.Retrieve(10); err != errOutOfBounds {
+		t.Fatalf("expected %v retrieving truncated item, got %v", errOutOfBounds, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "truncation.0004.rdat")); !os.IsNotExist(err) {
+		t.Fatalf("expected data file past the new head to be removed, got %v", err)
+	}
+
+	// Appending continues from the new head, also after reopening
+	for x := 10; x < 20; x++ {
+		if err := f.Append(uint64(x), getChunk(15, x)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f = openTestTable(t, dir, "truncation", true)
+	defer f.Close()
+	if f.items != 20 {
+		t.Fatalf("expected 20 items after reopening, got %d", f.items)
+	}
+	checkRetrieve(t, f, 0, 20)
+}
@@ -0,0 +1,205 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
+	"github.com/sankar-boro/axia-network-v2-coreth/ethdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// writeTestChain writes a canonical chain of [n] blocks, each with a receipt,
+// into [db] and marks the last one as the acceptor tip.
+func writeTestChain(t *testing.T, db ethdb.KeyValueWriter, n int, extra string) []*types.Block {
+	t.Helper()
+
+	blocks := make([]*types.Block, n)
+	parent := common.Hash{}
+	for i := 0; i < n; i++ {
+		block := types.NewBlockWithHeader(&types.Header{
+			ParentHash:  parent,
+			Number:      big.NewInt(int64(i)),
+			Extra:       []byte(extra),
+			UncleHash:   types.EmptyUncleHash,
+			TxHash:      types.EmptyRootHash,
+			ReceiptHash: types.EmptyRootHash,
+		})
+		WriteBlock(db, block)
+		WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		WriteReceipts(db, block.Hash(), block.NumberU64(), types.Receipts{
+			{Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: uint64(i), Logs: []*types.Log{}},
+		})
+		blocks[i] = block
+		parent = block.Hash()
+	}
+	if err := WriteAcceptorTip(db, parent); err != nil {
+		t.Fatal(err)
+	}
+	return blocks
+}
+
+// Tests that accepted blocks behind the freezer threshold are moved into the
+// freezer and that the chain readers fall back to it.
+func TestFreezeAcceptedBlocks(t *testing.T) {
+	var (
+		kvdb   = memorydb.New()
+		dir    = t.TempDir()
+		blocks = writeTestChain(t, kvdb, 20, "canonical")
+	)
+	receipts := make([][]byte, len(blocks))
+	for i, block := range blocks {
+		receipts[i] = ReadReceiptsRLP(NewDatabase(kvdb), block.Hash(), block.NumberU64())
+	}
+	// Add a block at a frozen height that never got accepted
+	side := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(5), Extra: []byte("side")})
+	WriteBlock(kvdb, side)
+
+	db, err := NewDatabaseWithFreezer(kvdb, dir, "", false, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FreezeAncients(db); err != nil {
+		t.Fatal(err)
+	}
+	if frozen, _ := db.Ancients(); frozen != 15 {
+		t.Fatalf("expected 15 frozen blocks, got %d", frozen)
+	}
+	checkChain := func(db ethdb.Database) {
+		for i, block := range blocks {
+			hash, number := block.Hash(), block.NumberU64()
+			if entry := ReadCanonicalHash(db, number); entry != hash {
+				t.Fatalf("canonical hash %d mismatch: have %s, want %s", number, entry, hash)
+			}
+			if entry := ReadBlock(db, hash, number); entry == nil || entry.Hash() != hash {
+				t.Fatalf("block %d mismatch: have %v, want %v", number, entry, block)
+			}
+			if entry := ReadReceiptsRLP(db, hash, number); !bytes.Equal(entry, receipts[i]) {
+				t.Fatalf("receipts %d mismatch: have %x, want %x", number, entry, receipts[i])
+			}
+			if !HasHeader(db, hash, number) || !HasBody(db, hash, number) || !HasReceipts(db, hash, number) {
+				t.Fatalf("block %d reported missing", number)
+			}
+			if entry := ReadHeaderNumber(db, hash); entry == nil || *entry != number {
+				t.Fatalf("header number %d mismatch: have %v", number, entry)
+			}
+		}
+	}
+	checkChain(db)
+
+	// The frozen blocks must be wiped from the key-value store, except for the genesis
+	for _, block := range blocks {
+		has, _ := kvdb.Has(headerKey(block.NumberU64(), block.Hash()))
+		if want := block.NumberU64() == 0 || block.NumberU64() >= 15; has != want {
+			t.Fatalf("block %d in key-value store: have %t, want %t", block.NumberU64(), has, want)
+		}
+	}
+	// Blocks that were never accepted are not frozen, but deleted
+	if HasHeader(db, side.Hash(), 5) || HasBody(db, side.Hash(), 5) {
+		t.Fatal("side chain block not deleted")
+	}
+	// Only close the freezer, as the key-value store is reused
+	if err := db.(*freezerdb).AncientStore.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The freezer must serve the same chain after reopening
+	db, err = NewDatabaseWithFreezer(kvdb, dir, "", true, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if frozen, _ := db.Ancients(); frozen != 15 {
+		t.Fatalf("expected 15 frozen blocks after reopening, got %d", frozen)
+	}
+	checkChain(db)
+}
+
+// Tests that the freezer skips blocks missing from the key-value store, e.g.
+// the ones below the summary of a state synced node, and keeps freezing the
+// blocks after them.
+func TestFreezeMissingBlocks(t *testing.T) {
+	kvdb := memorydb.New()
+	blocks := writeTestChain(t, kvdb, 20, "canonical")
+
+	// Drop all blocks between the genesis and the summary at 10 and the body
+	// of block 12, leaving its other components in place
+	for _, block := range blocks[1:10] {
+		DeleteBlock(kvdb, block.Hash(), block.NumberU64())
+		DeleteCanonicalHash(kvdb, block.NumberU64())
+	}
+	DeleteBody(kvdb, blocks[12].Hash(), 12)
+
+	db, err := NewDatabaseWithFreezer(kvdb, t.TempDir(), "", false, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if frozen, err := FreezeAncients(db); err != nil || frozen != 15 {
+		t.Fatalf("expected 15 frozen blocks, got %d (err: %v)", frozen, err)
+	}
+	for _, block := range blocks {
+		hash, number := block.Hash(), block.NumberU64()
+		switch {
+		case number >= 1 && number < 10:
+			if entry := ReadCanonicalHash(db, number); entry != (common.Hash{}) {
+				t.Fatalf("skipped block %d has canonical hash %s", number, entry)
+			}
+			if HasHeader(db, hash, number) || HasBody(db, hash, number) || HasReceipts(db, hash, number) {
+				t.Fatalf("skipped block %d reported present", number)
+			}
+		case number == 12:
+			// The remains of the block are kept in the key-value store
+			if entry := ReadCanonicalHash(db, number); entry != hash {
+				t.Fatalf("canonical hash %d mismatch: have %s, want %s", number, entry, hash)
+			}
+			if !HasHeader(db, hash, number) || !HasReceipts(db, hash, number) || HasBody(db, hash, number) {
+				t.Fatalf("skipped block %d components mismatch", number)
+			}
+			if has, _ := kvdb.Has(headerKey(number, hash)); !has {
+				t.Fatalf("skipped block %d deleted from key-value store", number)
+			}
+		default:
+			if entry := ReadBlock(db, hash, number); entry == nil || entry.Hash() != hash {
+				t.Fatalf("block %d mismatch: have %v, want %v", number, entry, block)
+			}
+			has, _ := kvdb.Has(headerKey(number, hash))
+			if want := number == 0 || number >= 15; has != want {
+				t.Fatalf("block %d in key-value store: have %t, want %t", number, has, want)
+			}
+		}
+	}
+}
+
+// Tests that a freezer cannot be combined with the database of another chain.
+func TestFreezerGenesisMismatch(t *testing.T) {
+	var (
+		kvdb = memorydb.New()
+		dir  = t.TempDir()
+	)
+	writeTestChain(t, kvdb, 10, "canonical")
+	db, err := NewDatabaseWithFreezer(kvdb, dir, "", false, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FreezeAncients(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.(*freezerdb).AncientStore.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	other := memorydb.New()
+	writeTestChain(t, other, 10, "other")
+	if _, err := NewDatabaseWithFreezer(other, dir, "", false, 5); err == nil {
+		t.Fatal("expected genesis mismatch to be rejected")
+	}
+	if _, err := NewDatabaseWithFreezer(memorydb.New(), dir, "", false, 5); err == nil {
+		t.Fatal("expected empty key-value store to be rejected")
+	}
+}
@@ -55,6 +55,10 @@ var (
 	// snapshotGeneratorKey tracks the snapshot generation marker across restarts.
 	snapshotGeneratorKey = []byte("SnapshotGenerator")
 
+	// snapshotJournalKey tracks the diff layer being flattened into the snapshot
+	// disk layer, if the flatten is not written in a single batch.
+	snapshotJournalKey = []byte("SnapshotJournal")
+
 	// uncleanShutdownKey tracks the list of local crashes
 	uncleanShutdownKey = []byte("unclean-shutdown") // config prefix for the db
 
@@ -71,6 +75,23 @@ var (
 	// acceptorTipKey tracks the tip of the last accepted block that has been fully processed.
 	acceptorTipKey = []byte("AcceptorTipKey")
 
+	// stateSchemeKey tracks the scheme used to store the trie nodes of the state.
+	stateSchemeKey = []byte("StateScheme")
+
+	// reverseDiffHeadKey tracks the id of the most recent reverse diff of the
+	// path-based state.
+	reverseDiffHeadKey = []byte("ReverseDiffHead")
+
+	// stateHistoryTailKey and stateHistoryHeadKey track the range of accepted
+	// blocks whose state changes are indexed.
+	stateHistoryTailKey = []byte("StateHistoryTail")
+	stateHistoryHeadKey = []byte("StateHistoryHead")
+
+	// logIndexTailKey and logIndexHeadKey track the range of accepted blocks
+	// whose logs are indexed by the acceptor.
+	logIndexTailKey = []byte("LogIndexTail")
+	logIndexHeadKey = []byte("LogIndexHead")
+
 	// Data item prefixes (use single byte to avoid mixing data types, avoid `i`, used for indexes).
 	headerPrefix       = []byte("h") // headerPrefix + num (uint64 big endian) + hash -> header
 	headerHashSuffix   = []byte("n") // headerPrefix + num (uint64 big endian) + headerHashSuffix -> hash
@@ -84,17 +105,52 @@ var (
 	SnapshotAccountPrefix = []byte("a") // SnapshotAccountPrefix + account hash -> account trie value
 	SnapshotStoragePrefix = []byte("o") // SnapshotStoragePrefix + account hash + storage hash -> storage trie value
 	CodePrefix            = []byte("c") // CodePrefix + code hash -> account code
+	TrieNodeAccountPrefix = []byte("A") // TrieNodeAccountPrefix + hex path -> account trie node (path scheme)
+	TrieNodeStoragePrefix = []byte("O") // TrieNodeStoragePrefix + account hash + hex path -> storage trie node (path scheme)
+	reverseDiffPrefix     = []byte("D") // reverseDiffPrefix + id (uint64 big endian) -> reverse diff (path scheme)
+
+	stateHistoryPrefix     = []byte("X") // stateHistoryPrefix + num (uint64 big endian) + hash -> state changes of a block awaiting acceptance
+	accountHistoryPrefix   = []byte("Y") // accountHistoryPrefix + account hash + num (uint64 big endian) -> account before the block modified it
+	storageHistoryPrefix   = []byte("Z") // storageHistoryPrefix + account hash + storage hash + num (uint64 big endian) -> storage slot before the block modified it
+	stateHistoryRootPrefix = []byte("N") // stateHistoryRootPrefix + state root -> num (uint64 big endian) of an accepted block with the root
+
+	addressLogPrefix = []byte("E") // addressLogPrefix + address + num (uint64 big endian) + tx index (uint32 big endian) + log index (uint32 big endian) -> nil
+	topicLogPrefix   = []byte("T") // topicLogPrefix + topic position (byte) + topic + num (uint64 big endian) + tx index (uint32 big endian) + log index (uint32 big endian) -> nil
 
 	preimagePrefix = []byte("secure-key-")      // preimagePrefix + hash -> preimage
 	configPrefix   = []byte("ethereum-config-") // config prefix for the db
 
 	// Chain index prefixes (use `i` + single byte to avoid mixing data types).
 	BloomBitsIndexPrefix = []byte("iB") // BloomBitsIndexPrefix is the data table of a chain indexer to track its progress
+	LogIndexIndexPrefix  = []byte("iL") // LogIndexIndexPrefix is the data table of the log index chain indexer to track its progress
 
 	preimageCounter    = metrics.NewRegisteredCounter("db/preimage/total", nil)
 	preimageHitCounter = metrics.NewRegisteredCounter("db/preimage/hits", nil)
 )
 
+const (
+	// freezerHeaderTable indicates the name of the freezer header table.
+	freezerHeaderTable = "headers"
+
+	// freezerHashTable indicates the name of the freezer canonical hash table.
+	freezerHashTable = "hashes"
+
+	// freezerBodiesTable indicates the name of the freezer block body table.
+	freezerBodiesTable = "bodies"
+
+	// freezerReceiptTable indicates the name of the freezer receipts table.
+	freezerReceiptTable = "receipts"
+)
+
+// freezerNoSnappy configures whether compression is disabled for the ancient-tables.
+// Hashes don't compress well.
+var freezerNoSnappy = map[string]bool{
+	freezerHeaderTable:  false,
+	freezerHashTable:    true,
+	freezerBodiesTable:  false,
+	freezerReceiptTable: false,
+}
+
 // LegacyTxLookupEntry is the legacy TxLookupEntry definition with some unnecessary
 // fields.
 type LegacyTxLookupEntry struct {
@@ -160,6 +216,46 @@ func storageSnapshotsKey(accountHash common.Hash) []byte {
 	return append(SnapshotStoragePrefix, accountHash.Bytes()...)
 }
 
+// stateHistoryKey = stateHistoryPrefix + num (uint64 big endian) + hash
+func stateHistoryKey(number uint64, hash common.Hash) []byte {
+	return append(append(stateHistoryPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
+// accountHistoryKeyPrefix = accountHistoryPrefix + account hash
+func accountHistoryKeyPrefix(accountHash common.Hash) []byte {
+	return append(accountHistoryPrefix, accountHash.Bytes()...)
+}
+
+// accountHistoryKey = accountHistoryPrefix + account hash + num (uint64 big endian)
+func accountHistoryKey(accountHash common.Hash, number uint64) []byte {
+	return append(accountHistoryKeyPrefix(accountHash), encodeBlockNumber(number)...)
+}
+
+// storageHistoryKeyPrefix = storageHistoryPrefix + account hash + storage hash
+func storageHistoryKeyPrefix(accountHash, storageHash common.Hash) []byte {
+	return append(append(storageHistoryPrefix, accountHash.Bytes()...), storageHash.Bytes()...)
+}
+
+// storageHistoryKey = storageHistoryPrefix + account hash + storage hash + num (uint64 big endian)
+func storageHistoryKey(accountHash, storageHash common.Hash, number uint64) []byte {
+	return append(storageHistoryKeyPrefix(accountHash, storageHash), encodeBlockNumber(number)...)
+}
+
+// stateHistoryRootKey = stateHistoryRootPrefix + state root
+func stateHistoryRootKey(root common.Hash) []byte {
+	return append(stateHistoryRootPrefix, root.Bytes()...)
+}
+
+// addressLogKeyPrefix = addressLogPrefix + address
+func addressLogKeyPrefix(address common.Address) []byte {
+	return append(append([]byte{}, addressLogPrefix...), address.Bytes()...)
+}
+
+// topicLogKeyPrefix = topicLogPrefix + topic position (byte) + topic
+func topicLogKeyPrefix(position int, topic common.Hash) []byte {
+	return append(append(append([]byte{}, topicLogPrefix...), byte(position)), topic.Bytes()...)
+}
+
 // bloomBitsKey = bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash
 func bloomBitsKey(bit uint, section uint64, hash common.Hash) []byte {
 	key := append(append(bloomBitsPrefix, make([]byte, 10)...), hash.Bytes()...)
@@ -170,6 +266,21 @@ func bloomBitsKey(bit uint, section uint64, hash common.Hash) []byte {
 	return key
 }
 
+// accountTrieNodeKey = TrieNodeAccountPrefix + hex path
+func accountTrieNodeKey(path []byte) []byte {
+	return append(TrieNodeAccountPrefix, path...)
+}
+
+// storageTrieNodeKey = TrieNodeStoragePrefix + account hash + hex path
+func storageTrieNodeKey(accountHash common.Hash, path []byte) []byte {
+	return append(append(TrieNodeStoragePrefix, accountHash.Bytes()...), path...)
+}
+
+// reverseDiffKey = reverseDiffPrefix + id (uint64 big endian)
+func reverseDiffKey(id uint64) []byte {
+	return append(reverseDiffPrefix, encodeBlockNumber(id)...)
+}
+
 // preimageKey = preimagePrefix + hash
 func preimageKey(hash common.Hash) []byte {
 	return append(preimagePrefix, hash.Bytes()...)
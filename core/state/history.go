@@ -0,0 +1,250 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/state/snapshot"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
+	"github.com/sankar-boro/axia-network-v2-coreth/ethdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/trie"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// errHistoricalState is returned when modifying or proving a historical state.
+var errHistoricalState = errors.New("operation not supported on historical state")
+
+// NewStateHistory returns the change set from the state of [parentRoot] to the
+// state of [root], holding the values of the modified accounts and storage
+// slots in the state of [parentRoot]. It is computed by comparing the tries of
+// both states, so only the modified parts of the tries are visited.
+func NewStateHistory(db Database, parentRoot, root common.Hash) (*rawdb.StateHistory, error) {
+	triedb := db.TrieDB()
+	oldTrie, err := trie.New(parentRoot, triedb)
+	if err != nil {
+		return nil, err
+	}
+	newTrie, err := trie.New(root, triedb)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		history  = new(rawdb.StateHistory)
+		accounts = make(map[common.Hash]struct{})
+	)
+	// Record the accounts removed or modified by the new state first, which
+	// are the leaves of the old trie missing from the new trie.
+	if err := diffLeaves(newTrie, oldTrie, func(key, prev []byte) error {
+		var account types.StateAccount
+		if err := rlp.DecodeBytes(prev, &account); err != nil {
+			return err
+		}
+		hash := common.BytesToHash(key)
+		accounts[hash] = struct{}{}
+		history.Accounts = append(history.Accounts, rawdb.StateHistoryAccount{
+			Hash: hash,
+			Prev: snapshot.SlimAccountRLP(account.Nonce, account.Balance, account.Root, account.CodeHash, account.IsMultiCoin),
+		})
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	// Record the accounts created by the new state, and the storage slots
+	// modified in all accounts.
+	if err := diffLeaves(oldTrie, newTrie, func(key, _ []byte) error {
+		hash := common.BytesToHash(key)
+		if _, ok := accounts[hash]; !ok {
+			accounts[hash] = struct{}{}
+			history.Accounts = append(history.Accounts, rawdb.StateHistoryAccount{Hash: hash})
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	for _, account := range history.Accounts {
+		oldRoot, err := storageRoot(oldTrie, account.Hash)
+		if err != nil {
+			return nil, err
+		}
+		newRoot, err := storageRoot(newTrie, account.Hash)
+		if err != nil {
+			return nil, err
+		}
+		if oldRoot == newRoot {
+			continue
+		}
+		oldStorage, err := trie.NewWithOwner(account.Hash, oldRoot, triedb)
+		if err != nil {
+			return nil, err
+		}
+		newStorage, err := trie.NewWithOwner(account.Hash, newRoot, triedb)
+		if err != nil {
+			return nil, err
+		}
+		slots := make(map[common.Hash]struct{})
+		if err := diffLeaves(newStorage, oldStorage, func(key, prev []byte) error {
+			slot := common.BytesToHash(key)
+			slots[slot] = struct{}{}
+			history.Storage = append(history.Storage, rawdb.StateHistoryStorage{Account: account.Hash, Slot: slot, Prev: common.CopyBytes(prev)})
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+		if err := diffLeaves(oldStorage, newStorage, func(key, _ []byte) error {
+			slot := common.BytesToHash(key)
+			if _, ok := slots[slot]; !ok {
+				history.Storage = append(history.Storage, rawdb.StateHistoryStorage{Account: account.Hash, Slot: slot})
+			}
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return history, nil
+}
+
+// diffLeaves calls [onLeaf] with the key and value of each leaf of [b] that is
+// not in [a].
+func diffLeaves(a, b *trie.Trie, onLeaf func(key, value []byte) error) error {
+	it, _ := trie.NewDifferenceIterator(a.NodeIterator(nil), b.NodeIterator(nil))
+	for it.Next(true) {
+		if !it.Leaf() {
+			continue
+		}
+		if err := onLeaf(it.LeafKey(), it.LeafBlob()); err != nil {
+			return err
+		}
+	}
+	return it.Error()
+}
+
+// storageRoot returns the storage root of the account of [accountHash] in
+// [accountTrie], or the empty root if the account does not exist.
+func storageRoot(accountTrie *trie.Trie, accountHash common.Hash) (common.Hash, error) {
+	enc, err := accountTrie.TryGet(accountHash[:])
+	if err != nil || len(enc) == 0 {
+		return emptyRoot, err
+	}
+	var account types.StateAccount
+	if err := rlp.DecodeBytes(enc, &account); err != nil {
+		return common.Hash{}, err
+	}
+	return account.Root, nil
+}
+
+// historyDatabase is a read-only Database serving the state of block [number]
+// with [root]. The accounts and storage slots modified after [number] are read
+// from the indexed state changes, and all others from the state of [liveRoot],
+// which must be the state of an accepted block whose state changes and those
+// of its ancestors after [number] are indexed.
+type historyDatabase struct {
+	Database
+	diskdb ethdb.KeyValueStore
+
+	number   uint64
+	root     common.Hash
+	liveTrie *trie.Trie
+}
+
+// NewHistoryDatabase returns a read-only Database serving the state of the
+// accepted block [number] with [root], reconstructed from the indexed state
+// changes and the state of [liveRoot] (see rawdb.StateHistory).
+func NewHistoryDatabase(db Database, number uint64, root, liveRoot common.Hash) (Database, error) {
+	liveTrie, err := trie.New(liveRoot, db.TrieDB())
+	if err != nil {
+		return nil, err
+	}
+	return &historyDatabase{
+		Database: db,
+		diskdb:   db.TrieDB().DiskDB(),
+		number:   number,
+		root:     root,
+		liveTrie: liveTrie,
+	}, nil
+}
+
+// OpenTrie opens the account trie of the historical state.
+func (db *historyDatabase) OpenTrie(root common.Hash) (Trie, error) {
+	if root != db.root {
+		return nil, fmt.Errorf("historical state %s cannot serve state %s", db.root, root)
+	}
+	return &historyTrie{db: db, root: root, live: db.liveTrie}, nil
+}
+
+// OpenStorageTrie opens the storage trie of an account of the historical
+// state.
+func (db *historyDatabase) OpenStorageTrie(addrHash, root common.Hash) (Trie, error) {
+	liveRoot, err := storageRoot(db.liveTrie, addrHash)
+	if err != nil {
+		return nil, err
+	}
+	live, err := trie.NewWithOwner(addrHash, liveRoot, db.TrieDB())
+	if err != nil {
+		return nil, err
+	}
+	return &historyTrie{db: db, owner: addrHash, root: root, live: live}, nil
+}
+
+// CopyTrie returns [t], as historical tries cannot be modified.
+func (db *historyDatabase) CopyTrie(t Trie) Trie {
+	if t, ok := t.(*historyTrie); ok {
+		return t
+	}
+	return db.Database.CopyTrie(t)
+}
+
+// historyTrie is a read-only Trie of a historical state. It is the account
+// trie if [owner] is the zero hash, or the storage trie of [owner] otherwise.
+type historyTrie struct {
+	db    *historyDatabase
+	owner common.Hash
+	root  common.Hash
+	live  *trie.Trie // Trie of the live state keyed by hash
+}
+
+func (t *historyTrie) GetKey([]byte) []byte { return nil }
+
+// TryGet returns the value of [key] in the historical state.
+func (t *historyTrie) TryGet(key []byte) ([]byte, error) {
+	hash := crypto.Keccak256Hash(key)
+	if t.owner == (common.Hash{}) {
+		if prev, ok := rawdb.ReadAccountHistory(t.db.diskdb, hash, t.db.number); ok {
+			if len(prev) == 0 {
+				return nil, nil
+			}
+			return snapshot.FullAccountRLP(prev)
+		}
+	} else if prev, ok := rawdb.ReadStorageHistory(t.db.diskdb, t.owner, hash, t.db.number); ok {
+		if len(prev) == 0 {
+			return nil, nil
+		}
+		return prev, nil
+	}
+	return t.live.TryGet(hash[:])
+}
+
+func (t *historyTrie) TryUpdateAccount([]byte, *types.StateAccount) error { return errHistoricalState }
+func (t *historyTrie) TryUpdate(_, _ []byte) error                       { return errHistoricalState }
+func (t *historyTrie) TryDelete([]byte) error                            { return errHistoricalState }
+
+// Hash returns the root of the historical trie.
+func (t *historyTrie) Hash() common.Hash { return t.root }
+
+func (t *historyTrie) Commit(trie.LeafCallback) (common.Hash, int, error) {
+	return common.Hash{}, 0, errHistoricalState
+}
+
+// NodeIterator returns an iterator over no nodes, as the nodes of historical
+// tries are not stored.
+func (t *historyTrie) NodeIterator(startKey []byte) trie.NodeIterator {
+	empty, _ := trie.New(common.Hash{}, t.db.TrieDB())
+	return empty.NodeIterator(startKey)
+}
+
+func (t *historyTrie) Prove([]byte, uint, ethdb.KeyValueWriter) error { return errHistoricalState }
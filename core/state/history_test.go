@@ -0,0 +1,125 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"bytes"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// historyTestAccount is the content of an account checked by the state
+// history tests.
+type historyTestAccount struct {
+	exists    bool
+	nonce     uint64
+	balance   *big.Int
+	multiCoin *big.Int
+	code      []byte
+	storage   [4]common.Hash
+}
+
+var historyTestCoin = common.HexToHash("0xc0")
+
+func readHistoryTestAccounts(statedb *StateDB, addrs []common.Address) []historyTestAccount {
+	accounts := make([]historyTestAccount, len(addrs))
+	for i, addr := range addrs {
+		acc := historyTestAccount{
+			exists:    statedb.Exist(addr),
+			nonce:     statedb.GetNonce(addr),
+			balance:   statedb.GetBalance(addr),
+			multiCoin: statedb.GetBalanceMultiCoin(addr, historyTestCoin),
+			code:      statedb.GetCode(addr),
+		}
+		for j := range acc.storage {
+			acc.storage[j] = statedb.GetState(addr, common.BigToHash(big.NewInt(int64(j))))
+		}
+		accounts[i] = acc
+	}
+	return accounts
+}
+
+func TestStateHistory(t *testing.T) {
+	var (
+		rng    = rand.New(rand.NewSource(1))
+		db     = NewDatabase(rawdb.NewMemoryDatabase())
+		diskdb = db.TrieDB().DiskDB()
+		addrs  = make([]common.Address, 8)
+		roots  = []common.Hash{emptyRoot}
+	)
+	for i := range addrs {
+		addrs[i] = common.BigToAddress(big.NewInt(int64(i + 1)))
+	}
+	expected := [][]historyTestAccount{readHistoryTestAccounts(mustNewState(t, emptyRoot, db), addrs)}
+
+	for number := uint64(1); number <= 12; number++ {
+		statedb := mustNewState(t, roots[len(roots)-1], db)
+		for _, addr := range addrs {
+			switch rng.Intn(6) {
+			case 0:
+				// Leave the account unmodified
+			case 1:
+				statedb.Suicide(addr)
+			case 2:
+				statedb.SetNonce(addr, statedb.GetNonce(addr)+1)
+				statedb.AddBalanceMultiCoin(addr, historyTestCoin, big.NewInt(int64(rng.Intn(100))))
+			case 3:
+				statedb.SetNonce(addr, statedb.GetNonce(addr)+1)
+				statedb.SetCode(addr, []byte{byte(number)})
+			default:
+				statedb.SetNonce(addr, statedb.GetNonce(addr)+1)
+				statedb.AddBalance(addr, big.NewInt(int64(rng.Intn(100))))
+				slot := common.BigToHash(big.NewInt(int64(rng.Intn(4))))
+				statedb.SetState(addr, slot, common.BigToHash(big.NewInt(int64(rng.Intn(3)))))
+			}
+		}
+		root, err := statedb.Commit(true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		history, err := NewStateHistory(db, roots[len(roots)-1], root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rawdb.WriteStateHistoryIndex(diskdb, number, root, history)
+
+		roots = append(roots, root)
+		expected = append(expected, readHistoryTestAccounts(mustNewState(t, root, db), addrs))
+	}
+
+	// Reconstruct every state from the last one
+	live := roots[len(roots)-1]
+	for number, root := range roots {
+		historyDB, err := NewHistoryDatabase(db, uint64(number), root, live)
+		if err != nil {
+			t.Fatal(err)
+		}
+		statedb := mustNewState(t, root, historyDB)
+		for i, acc := range readHistoryTestAccounts(statedb, addrs) {
+			want := expected[number][i]
+			if acc.exists != want.exists || acc.nonce != want.nonce || acc.balance.Cmp(want.balance) != 0 ||
+				acc.multiCoin.Cmp(want.multiCoin) != 0 || !bytes.Equal(acc.code, want.code) || acc.storage != want.storage {
+				t.Fatalf("block %d, account %s: have %+v, want %+v", number, addrs[i], acc, want)
+			}
+		}
+		// Historical states are read-only
+		statedb.AddBalance(addrs[0], big.NewInt(1))
+		if _, err := statedb.Commit(true); err == nil {
+			t.Fatalf("block %d: committed historical state", number)
+		}
+	}
+}
+
+func mustNewState(t *testing.T, root common.Hash, db Database) *StateDB {
+	t.Helper()
+	statedb, err := New(root, db, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return statedb
+}
@@ -49,7 +49,7 @@ func (f stateBloomHasher) BlockSize() int                    { panic("not implem
 func (f stateBloomHasher) Size() int                         { return 8 }
 func (f stateBloomHasher) Sum64() uint64                     { return binary.BigEndian.Uint64(f) }
 
-// stateBloom is a bloom filter used during the state convesion(snapshot->state).
+// StateBloom is a bloom filter used during the state convesion(snapshot->state).
 // The keys of all generated entries will be recorded here so that in the pruning
 // stage the entries belong to the specific version can be avoided for deletion.
 //
@@ -64,36 +64,36 @@ func (f stateBloomHasher) Sum64() uint64                     { return binary.Big
 //
 // After the entire state is generated, the bloom filter should be persisted into
 // the disk. It indicates the whole generation procedure is finished.
-type stateBloom struct {
+type StateBloom struct {
 	bloom *bloomfilter.Filter
 }
 
-// newStateBloomWithSize creates a brand new state bloom for state generation.
+// NewStateBloomWithSize creates a brand new state bloom for state generation.
 // The bloom filter will be created by the passing bloom filter size. According
 // to the https://hur.st/bloomfilter/?n=600000000&p=&m=2048MB&k=4, the parameters
 // are picked so that the false-positive rate for mainnet is low enough.
-func newStateBloomWithSize(size uint64) (*stateBloom, error) {
+func NewStateBloomWithSize(size uint64) (*StateBloom, error) {
 	bloom, err := bloomfilter.New(size*1024*1024*8, 4)
 	if err != nil {
 		return nil, err
 	}
 	log.Info("Initialized state bloom", "size", common.StorageSize(float64(bloom.M()/8)))
-	return &stateBloom{bloom: bloom}, nil
+	return &StateBloom{bloom: bloom}, nil
 }
 
 // NewStateBloomFromDisk loads the state bloom from the given file.
 // In this case the assumption is held the bloom filter is complete.
-func NewStateBloomFromDisk(filename string) (*stateBloom, error) {
+func NewStateBloomFromDisk(filename string) (*StateBloom, error) {
 	bloom, _, err := bloomfilter.ReadFile(filename)
 	if err != nil {
 		return nil, err
 	}
-	return &stateBloom{bloom: bloom}, nil
+	return &StateBloom{bloom: bloom}, nil
 }
 
 // Commit flushes the bloom filter content into the disk and marks the bloom
 // as complete.
-func (bloom *stateBloom) Commit(filename, tempname string) error {
+func (bloom *StateBloom) Commit(filename, tempname string) error {
 	// Write the bloom out into a temporary file
 	_, err := bloom.bloom.WriteFile(tempname)
 	if err != nil {
@@ -115,7 +115,7 @@ func (bloom *stateBloom) Commit(filename, tempname string) error {
 }
 
 // Put implements the KeyValueWriter interface. But here only the key is needed.
-func (bloom *stateBloom) Put(key []byte, value []byte) error {
+func (bloom *StateBloom) Put(key []byte, value []byte) error {
 	// If the key length is not 32bytes, ensure it's contract code
 	// entry with new scheme.
 	if len(key) != common.HashLength {
@@ -131,12 +131,12 @@ func (bloom *stateBloom) Put(key []byte, value []byte) error {
 }
 
 // Delete removes the key from the key-value data store.
-func (bloom *stateBloom) Delete(key []byte) error { panic("not supported") }
+func (bloom *StateBloom) Delete(key []byte) error { panic("not supported") }
 
 // Contain is the wrapper of the underlying contains function which
 // reports whether the key is contained.
 // - If it says yes, the key may be contained
 // - If it says no, the key is definitely not contained.
-func (bloom *stateBloom) Contain(key []byte) (bool, error) {
+func (bloom *StateBloom) Contain(key []byte) (bool, error) {
 	return bloom.bloom.Contains(stateBloomHasher(key)), nil
 }
@@ -0,0 +1,343 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package pruner
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
+	"github.com/sankar-boro/axia-network-v2-coreth/ethdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/metrics"
+	"github.com/sankar-boro/axia-network-v2-coreth/trie"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	// onlineMinBloomSize is the minimum size (MB) of the bloom filter of the
+	// online pruner.
+	onlineMinBloomSize = 256
+
+	// onlineAbortCheckInterval is the number of trie nodes the online pruner
+	// marks between two checks whether it should stop.
+	onlineAbortCheckInterval = 10000
+)
+
+var (
+	// ErrPruningAborted is returned if an online pruning run is stopped before
+	// it completes.
+	ErrPruningAborted = errors.New("online pruning aborted")
+
+	onlineMarkedNodesGauge   = metrics.NewRegisteredGauge("state/pruner/online/marked", nil)
+	onlineSweptNodesMeter    = metrics.NewRegisteredMeter("state/pruner/online/swept/nodes", nil)
+	onlineSweptSizeMeter     = metrics.NewRegisteredMeter("state/pruner/online/swept/size", nil)
+	onlineSweepProgressGauge = metrics.NewRegisteredGauge("state/pruner/online/progress", nil)
+	onlineRunTimer           = metrics.NewRegisteredTimer("state/pruner/online/run", nil)
+)
+
+// OnlinePrunerConfig configures an OnlinePruner.
+type OnlinePrunerConfig struct {
+	BloomSize  uint64        // Megabytes of memory allocated to the bloom filter of live trie nodes during a run
+	BatchSize  int           // Maximum number of trie nodes deleted in one batch
+	BatchDelay time.Duration // Time to wait between two batches of deletions
+}
+
+// OnlinePruner deletes the trie nodes that are unreachable from the retained
+// state roots while the node keeps running. Each run marks the trie nodes of
+// the retained roots in a bloom filter and then sweeps all other trie nodes
+// from the database in small, throttled batches.
+//
+// Trie nodes flushed to disk by the trie database while a run is in progress
+// are added to the bloom filter before they are written, so a trie node that is
+// rewritten after being marked as unreachable is never deleted. Similarly, the
+// roots referenced in the trie database, such as the states of processing
+// blocks, are referenced until they are marked, so none of their nodes are
+// garbage collected in the meantime. This includes the roots already flushed
+// to disk by capping the trie database.
+type OnlinePruner struct {
+	config OnlinePrunerConfig
+	db     ethdb.KeyValueStore
+	triedb *trie.Database
+
+	lock  sync.Mutex  // Protects [bloom] and serialises deletions with flushes
+	bloom *StateBloom // Live trie nodes of the current run, nil between runs
+}
+
+// NewOnlinePruner creates an online pruner for the trie nodes of [triedb],
+// which are stored in [db].
+func NewOnlinePruner(db ethdb.KeyValueStore, triedb *trie.Database, config OnlinePrunerConfig) *OnlinePruner {
+	if config.BloomSize < onlineMinBloomSize {
+		log.Warn("Sanitizing online pruning bloomfilter size", "provided(MB)", config.BloomSize, "updated(MB)", onlineMinBloomSize)
+		config.BloomSize = onlineMinBloomSize
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = ethdb.IdealBatchSize / 100
+	}
+	p := &OnlinePruner{
+		config: config,
+		db:     db,
+		triedb: triedb,
+	}
+	triedb.SetFlushCallback(p.onFlush)
+	return p
+}
+
+// onFlush marks a trie node as live if a run is in progress. It is invoked
+// before the trie node is written to disk.
+func (p *OnlinePruner) onFlush(hash common.Hash) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.bloom != nil {
+		p.bloom.Put(hash[:], nil)
+	}
+}
+
+// mark adds [key] to the live trie nodes of the current run.
+func (p *OnlinePruner) mark(key []byte) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.bloom.Put(key, nil)
+}
+
+// Prune performs a single run of the online pruner. It retains the trie nodes
+// reachable from the roots returned by [roots] and the roots referenced in the
+// trie database. The first root returned by [roots] must be available,
+// while the others are skipped if they are missing.
+//
+// If [quit] is closed, the run is stopped and [ErrPruningAborted] returned.
+func (p *OnlinePruner) Prune(roots func() []common.Hash, quit <-chan struct{}) error {
+	bloom, err := NewStateBloomWithSize(p.config.BloomSize)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	defer onlineRunTimer.UpdateSince(start)
+
+	// Track the flushed trie nodes before collecting the roots, so that all
+	// trie nodes derived from the roots are either marked or tracked.
+	p.lock.Lock()
+	p.bloom = bloom
+	p.lock.Unlock()
+	defer func() {
+		p.lock.Lock()
+		p.bloom = nil
+		p.lock.Unlock()
+	}()
+
+	retained := roots()
+	referenced := p.triedb.ReferenceRoots()
+	marked, err := p.markRoots(append(retained, referenced...), quit)
+	for _, root := range referenced {
+		p.triedb.Dereference(root)
+	}
+	if err != nil {
+		return err
+	}
+	log.Info("Marked live trie nodes", "nodes", marked, "roots", len(referenced), "elapsed", common.PrettyDuration(time.Since(start)))
+
+	count, size, err := p.sweep(quit)
+	if err != nil {
+		return err
+	}
+	log.Info("Online state pruning successful", "nodes", count, "size", size, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// markRoots marks all the trie nodes and contract codes reachable from [roots]
+// and returns the number of marked trie nodes. The first root is marked in full,
+// while only the differences to the first root are marked for the others.
+func (p *OnlinePruner) markRoots(roots []common.Hash, quit <-chan struct{}) (uint64, error) {
+	var marked uint64
+	onlineMarkedNodesGauge.Update(0)
+
+	// markTrie marks the trie nodes of the trie at [root], which are not part
+	// of [baseTrie] if it is not nil, and invokes [onLeaf] for each of its
+	// leaves that is not part of [baseTrie].
+	markTrie := func(baseTrie *trie.Trie, root common.Hash, onLeaf func(key, blob []byte) error) error {
+		t, err := trie.New(root, p.triedb)
+		if err != nil {
+			return err
+		}
+		it := t.NodeIterator(nil)
+		if baseTrie != nil {
+			it, _ = trie.NewDifferenceIterator(baseTrie.NodeIterator(nil), it)
+		}
+		for it.Next(true) {
+			if hash := it.Hash(); hash != (common.Hash{}) {
+				p.mark(hash[:])
+				marked++
+				if marked%onlineAbortCheckInterval == 0 {
+					onlineMarkedNodesGauge.Update(int64(marked))
+					select {
+					case <-quit:
+						return ErrPruningAborted
+					default:
+					}
+				}
+			}
+			if it.Leaf() && onLeaf != nil {
+				if err := onLeaf(it.LeafKey(), it.LeafBlob()); err != nil {
+					return err
+				}
+			}
+		}
+		return it.Error()
+	}
+	// markAccount returns a leaf callback for an account trie, which marks the
+	// code and the storage trie of each account. If [baseTrie] is not nil, only
+	// the differences to the storage trie of the account in [baseTrie] are
+	// marked.
+	markAccount := func(baseTrie *trie.Trie) func(key, blob []byte) error {
+		return func(key, blob []byte) error {
+			var account types.StateAccount
+			if err := rlp.DecodeBytes(blob, &account); err != nil {
+				return err
+			}
+			if !bytes.Equal(account.CodeHash, emptyCode) {
+				p.mark(account.CodeHash)
+			}
+			if account.Root == emptyRoot {
+				return nil
+			}
+			var baseStorageTrie *trie.Trie
+			if baseTrie != nil {
+				enc, err := baseTrie.TryGet(key)
+				if err != nil {
+					return err
+				}
+				if len(enc) > 0 {
+					var baseAccount types.StateAccount
+					if err := rlp.DecodeBytes(enc, &baseAccount); err != nil {
+						return err
+					}
+					if baseAccount.Root == account.Root {
+						return nil
+					}
+					if baseAccount.Root != emptyRoot {
+						if baseStorageTrie, err = trie.New(baseAccount.Root, p.triedb); err != nil {
+							return err
+						}
+					}
+				}
+			}
+			return markTrie(baseStorageTrie, account.Root, nil)
+		}
+	}
+
+	base := roots[0]
+	baseTrie, err := trie.New(base, p.triedb)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open base root %s: %w", base, err)
+	}
+	if err := markTrie(nil, base, markAccount(nil)); err != nil {
+		return marked, fmt.Errorf("failed to mark base root %s: %w", base, err)
+	}
+	for _, root := range roots[1:] {
+		if root == base || root == emptyRoot {
+			continue
+		}
+		if _, err := trie.New(root, p.triedb); err != nil {
+			log.Debug("Skipping missing root while marking live trie nodes", "root", root)
+			continue
+		}
+		if err := markTrie(baseTrie, root, markAccount(baseTrie)); err != nil {
+			return marked, fmt.Errorf("failed to mark root %s: %w", root, err)
+		}
+	}
+	onlineMarkedNodesGauge.Update(int64(marked))
+	return marked, nil
+}
+
+// sweep deletes all the trie nodes and legacy contract codes that are not live
+// from the database, in batches of at most [BatchSize] entries separated by
+// [BatchDelay]. It returns the number and the size of the deleted entries.
+func (p *OnlinePruner) sweep(quit <-chan struct{}) (int, common.StorageSize, error) {
+	var (
+		count  int
+		size   common.StorageSize
+		start  = time.Now()
+		logged = time.Now()
+		keys   = make([][]byte, 0, p.config.BatchSize)
+		sizes  = make([]int, 0, p.config.BatchSize)
+		iter   = p.db.NewIterator(nil, nil)
+	)
+	// We wrap iter.Release() in an anonymous function so that the [iter]
+	// value captured is the value of [iter] at the end of the function.
+	defer func() {
+		iter.Release()
+	}()
+	onlineSweepProgressGauge.Update(0)
+
+	// deleteBatch deletes the collected keys which are still not live. The lock
+	// is held while checking and deleting, so that no trie node is flushed to
+	// disk in between.
+	deleteBatch := func() error {
+		p.lock.Lock()
+		defer p.lock.Unlock()
+
+		batch := p.db.NewBatch()
+		for i, key := range keys {
+			if ok, _ := p.bloom.Contain(key); ok {
+				continue
+			}
+			if err := batch.Delete(key); err != nil {
+				return err
+			}
+			count++
+			size += common.StorageSize(sizes[i])
+			onlineSweptNodesMeter.Mark(1)
+			onlineSweptSizeMeter.Mark(int64(sizes[i]))
+		}
+		keys, sizes = keys[:0], sizes[:0]
+		return batch.Write()
+	}
+	for iter.Next() {
+		key := iter.Key()
+		if len(key) != common.HashLength {
+			continue
+		}
+		if ok, _ := p.bloom.Contain(key); ok {
+			continue
+		}
+		keys = append(keys, common.CopyBytes(key))
+		sizes = append(sizes, len(key)+len(iter.Value()))
+		if len(keys) < p.config.BatchSize {
+			continue
+		}
+		if err := deleteBatch(); err != nil {
+			return count, size, err
+		}
+		onlineSweepProgressGauge.Update(int64(binary.BigEndian.Uint64(key[:8]) / (math.MaxUint64 / 100)))
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Pruning state data online", "nodes", count, "size", size, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+		select {
+		case <-quit:
+			return count, size, ErrPruningAborted
+		case <-time.After(p.config.BatchDelay):
+		}
+		// Recreate the iterator after every batch in order to allow the
+		// underlying compactor to delete the entries.
+		iter.Release()
+		iter = p.db.NewIterator(nil, key)
+	}
+	if err := iter.Error(); err != nil {
+		return count, size, fmt.Errorf("failed to iterate db during online pruning: %w", err)
+	}
+	if err := deleteBatch(); err != nil {
+		return count, size, err
+	}
+	onlineSweepProgressGauge.Update(100)
+	return count, size, nil
+}
@@ -0,0 +1,169 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package pruner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/state"
+	"github.com/sankar-boro/axia-network-v2-coreth/ethdb"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// commitTestState applies [update] to the state at [root], commits it to disk
+// and returns the new root.
+func commitTestState(t *testing.T, sdb state.Database, root common.Hash, update func(*state.StateDB)) common.Hash {
+	t.Helper()
+
+	statedb, err := state.New(root, sdb, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	update(statedb)
+	root, err = statedb.Commit(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sdb.TrieDB().Commit(root, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+// checkTestState checks that the full account and storage tries of [root] are
+// readable.
+func checkTestState(t *testing.T, sdb state.Database, root common.Hash) {
+	t.Helper()
+
+	it := state.NewNodeIterator(mustState(t, sdb, root))
+	for it.Next() {
+	}
+	if it.Error != nil {
+		t.Fatalf("state %s incomplete: %v", root, it.Error)
+	}
+}
+
+func mustState(t *testing.T, sdb state.Database, root common.Hash) *state.StateDB {
+	t.Helper()
+
+	statedb, err := state.New(root, sdb, nil)
+	if err != nil {
+		t.Fatalf("failed to open state %s: %v", root, err)
+	}
+	return statedb
+}
+
+// newTestOnlinePruner returns an online pruner using a small bloom filter.
+func newTestOnlinePruner(db ethdb.KeyValueStore, sdb state.Database) *OnlinePruner {
+	p := NewOnlinePruner(db, sdb.TrieDB(), OnlinePrunerConfig{BatchSize: 10})
+	p.config.BloomSize = 1
+	return p
+}
+
+func populateTestState(statedb *state.StateDB, seed int64) {
+	for i := int64(0); i < 50; i++ {
+		addr := common.BigToAddress(big.NewInt(i))
+		statedb.SetBalance(addr, big.NewInt(seed*100+i))
+		if i%5 == 0 {
+			statedb.SetCode(addr, []byte{byte(i), 0x01})
+			for j := int64(0); j < 10; j++ {
+				statedb.SetState(addr, common.BigToHash(big.NewInt(j)), common.BigToHash(big.NewInt(seed*1000+i*10+j)))
+			}
+		}
+	}
+}
+
+func TestOnlinePrunerDeletesUnreachableNodes(t *testing.T) {
+	var (
+		db  = rawdb.NewMemoryDatabase()
+		sdb = state.NewDatabase(db)
+	)
+	old := commitTestState(t, sdb, common.Hash{}, func(statedb *state.StateDB) { populateTestState(statedb, 1) })
+	retained := commitTestState(t, sdb, old, func(statedb *state.StateDB) { populateTestState(statedb, 2) })
+	other := commitTestState(t, sdb, retained, func(statedb *state.StateDB) { populateTestState(statedb, 3) })
+
+	p := newTestOnlinePruner(db, sdb)
+	if err := p.Prune(func() []common.Hash { return []common.Hash{retained, other} }, make(chan struct{})); err != nil {
+		t.Fatal(err)
+	}
+	checkTestState(t, state.NewDatabase(db), retained)
+	checkTestState(t, state.NewDatabase(db), other)
+	if has, _ := db.Has(old[:]); has {
+		t.Fatal("unreachable state root not deleted")
+	}
+}
+
+func TestOnlinePrunerRetainsFlushedNodes(t *testing.T) {
+	var (
+		db  = rawdb.NewMemoryDatabase()
+		sdb = state.NewDatabase(db)
+	)
+	old := commitTestState(t, sdb, common.Hash{}, func(statedb *state.StateDB) { populateTestState(statedb, 1) })
+	retained := commitTestState(t, sdb, old, func(statedb *state.StateDB) { populateTestState(statedb, 2) })
+
+	// Commit a new state after the run started, which is not part of the
+	// retained roots
+	var flushed common.Hash
+	roots := func() []common.Hash {
+		flushed = commitTestState(t, sdb, retained, func(statedb *state.StateDB) { populateTestState(statedb, 3) })
+		return []common.Hash{retained}
+	}
+	p := newTestOnlinePruner(db, sdb)
+	if err := p.Prune(roots, make(chan struct{})); err != nil {
+		t.Fatal(err)
+	}
+	checkTestState(t, state.NewDatabase(db), retained)
+	checkTestState(t, state.NewDatabase(db), flushed)
+	if has, _ := db.Has(old[:]); has {
+		t.Fatal("unreachable state root not deleted")
+	}
+}
+
+func TestOnlinePrunerRetainsDirtyRoots(t *testing.T) {
+	var (
+		db  = rawdb.NewMemoryDatabase()
+		sdb = state.NewDatabase(db)
+	)
+	retained := commitTestState(t, sdb, common.Hash{}, func(statedb *state.StateDB) { populateTestState(statedb, 1) })
+
+	// Keep a child state in the dirty cache, referenced by the meta root
+	statedb := mustState(t, sdb, retained)
+	populateTestState(statedb, 2)
+	dirty, err := statedb.Commit(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sdb.TrieDB().Reference(dirty, common.Hash{})
+
+	p := newTestOnlinePruner(db, sdb)
+	if err := p.Prune(func() []common.Hash { return []common.Hash{retained} }, make(chan struct{})); err != nil {
+		t.Fatal(err)
+	}
+	// Flushing the dirty state afterwards must result in a complete state
+	if err := sdb.TrieDB().Commit(dirty, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	checkTestState(t, state.NewDatabase(db), retained)
+	checkTestState(t, state.NewDatabase(db), dirty)
+}
+
+func TestOnlinePrunerAbort(t *testing.T) {
+	var (
+		db  = rawdb.NewMemoryDatabase()
+		sdb = state.NewDatabase(db)
+	)
+	old := commitTestState(t, sdb, common.Hash{}, func(statedb *state.StateDB) { populateTestState(statedb, 1) })
+	retained := commitTestState(t, sdb, old, func(statedb *state.StateDB) { populateTestState(statedb, 2) })
+
+	quit := make(chan struct{})
+	close(quit)
+	p := newTestOnlinePruner(db, sdb)
+	if err := p.Prune(func() []common.Hash { return []common.Hash{retained} }, quit); err != ErrPruningAborted {
+		t.Fatalf("expected %v, got %v", ErrPruningAborted, err)
+	}
+	// The first batch may be deleted before the run is stopped
+	checkTestState(t, state.NewDatabase(db), retained)
+}
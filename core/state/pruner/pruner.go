@@ -86,7 +86,7 @@ var (
 // disk read performance to some extent.
 type Pruner struct {
 	db         ethdb.Database
-	stateBloom *stateBloom
+	stateBloom *StateBloom
 	datadir    string
 	headHeader *types.Header
 	snaptree   *snapshot.Tree
@@ -110,7 +110,7 @@ func NewPruner(db ethdb.Database, datadir string, bloomSize uint64) (*Pruner, er
 		log.Warn("Sanitizing bloomfilter size", "provided(MB)", bloomSize, "updated(MB)", 256)
 		bloomSize = 256
 	}
-	stateBloom, err := newStateBloomWithSize(bloomSize)
+	stateBloom, err := NewStateBloomWithSize(bloomSize)
 	if err != nil {
 		return nil, err
 	}
@@ -123,7 +123,7 @@ func NewPruner(db ethdb.Database, datadir string, bloomSize uint64) (*Pruner, er
 	}, nil
 }
 
-func prune(maindb ethdb.Database, stateBloom *stateBloom, bloomPath string, start time.Time) error {
+func prune(maindb ethdb.Database, stateBloom *StateBloom, bloomPath string, start time.Time) error {
 	// Delete all stale trie nodes in the disk. With the help of state bloom
 	// the trie nodes(and codes) belong to the active state will be filtered
 	// out. A very small part of stale tries will also be filtered because of
@@ -332,7 +332,7 @@ func RecoverPruning(datadir string, db ethdb.Database) error {
 
 // extractGenesis loads the genesis state and commits all the state entries
 // into the given bloomfilter.
-func extractGenesis(db ethdb.Database, stateBloom *stateBloom) error {
+func extractGenesis(db ethdb.Database, stateBloom *StateBloom) error {
 	genesisHash := rawdb.ReadCanonicalHash(db, 0)
 	if genesisHash == (common.Hash{}) {
 		return errors.New("missing genesis hash")
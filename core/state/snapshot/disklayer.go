@@ -57,6 +57,7 @@ type diskLayer struct {
 
 	created      time.Time // Time at which disk layer was created
 	logged       time.Time // Time at which last logged generation progress
+	checkpointed time.Time // Time at which last journalled generation progress
 	abortStarted time.Time // Time as which disk layer started to be aborted
 
 	lock sync.RWMutex
@@ -33,6 +33,7 @@ import (
 	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
 	"github.com/sankar-boro/axia-network-v2-coreth/ethdb"
 	"github.com/sankar-boro/axia-network-v2-coreth/ethdb/memorydb"
+	"github.com/sankar-boro/axia-network-v2-coreth/trie"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rlp"
 )
@@ -501,6 +502,150 @@ func TestDiskGeneratorPersistence(t *testing.T) {
 	}
 }
 
+// Tests that a flatten interrupted after writing part of a diff layer onto disk
+// is completed from the journalled diff layer when the snapshot is loaded.
+func TestDiskJournalReplay(t *testing.T) {
+	var (
+		conNuke       = randomHash()
+		conNukeSlot   = randomHash()
+		conMod        = randomHash()
+		conModSlot    = randomHash()
+		baseRoot      = randomHash()
+		baseBlockHash = randomHash()
+		diffRoot      = randomHash()
+		diffBlockHash = randomHash()
+		accounts      = make(map[common.Hash][]byte)
+	)
+	db := rawdb.NewMemoryDatabase()
+	rawdb.WriteAccountSnapshot(db, conNuke, conNuke[:])
+	rawdb.WriteStorageSnapshot(db, conNuke, conNukeSlot, conNukeSlot[:])
+	rawdb.WriteAccountSnapshot(db, conMod, conMod[:])
+	rawdb.WriteSnapshotBlockHash(db, baseBlockHash)
+	rawdb.WriteSnapshotRoot(db, baseRoot)
+	ResetSnapshotGeneration(db)
+
+	// Create a diff layer too large to be flushed in a single batch
+	for len(accounts) < 2*ethdb.IdealBatchSize/common.HashLength {
+		accounts[randomHash()] = randomAccount()
+	}
+	snaps := NewTestTree(db, baseBlockHash, baseRoot)
+	if err := snaps.Update(diffBlockHash, diffRoot, baseBlockHash, map[common.Hash]struct{}{
+		conNuke: {},
+	}, accounts, map[common.Hash]map[common.Hash][]byte{
+		conMod: {conModSlot: conModSlot[:]},
+	}); err != nil {
+		t.Fatalf("failed to update snapshot tree: %v", err)
+	}
+
+	// Interrupt the flatten after the journal and the first batch are written
+	journalDiffLayer(snaps.disklayer(), snaps.blockLayers[diffBlockHash].(*diffLayer))
+	rawdb.DeleteSnapshotBlockHash(db)
+	rawdb.DeleteSnapshotRoot(db)
+	rawdb.DeleteAccountSnapshot(db, conNuke)
+	if have := ReadBlockHash(db); have != diffBlockHash {
+		t.Fatalf("block hash mismatch: have %s, want %s", have, diffBlockHash)
+	}
+
+	if _, generated, err := loadSnapshot(db, trie.NewDatabase(db), 16, diffBlockHash, diffRoot); err != nil {
+		t.Fatalf("failed to load snapshot: %v", err)
+	} else if !generated {
+		t.Fatalf("snapshot not generated after replay")
+	}
+	if blob := rawdb.ReadSnapshotJournal(db); len(blob) != 0 {
+		t.Fatalf("journal not deleted after replay")
+	}
+	for hash, data := range accounts {
+		if blob := rawdb.ReadAccountSnapshot(db, hash); !bytes.Equal(blob, data) {
+			t.Fatalf("account %s: have %x, want %x", hash, blob, data)
+		}
+	}
+	if blob := rawdb.ReadStorageSnapshot(db, conNuke, conNukeSlot); len(blob) != 0 {
+		t.Fatalf("destructed storage slot not deleted: %x", blob)
+	}
+	if blob := rawdb.ReadStorageSnapshot(db, conMod, conModSlot); !bytes.Equal(blob, conModSlot[:]) {
+		t.Fatalf("storage slot: have %x, want %x", blob, conModSlot[:])
+	}
+
+	// A journal of a flatten onto another disk layer is ignored
+	journalDiffLayer(&diskLayer{diskdb: db, blockHash: baseBlockHash, root: baseRoot}, &diffLayer{
+		blockHash: randomHash(),
+		root:      randomHash(),
+	})
+	if have := ReadBlockHash(db); have != diffBlockHash {
+		t.Fatalf("block hash mismatch with stale journal: have %s, want %s", have, diffBlockHash)
+	}
+	if _, _, err := loadSnapshot(db, trie.NewDatabase(db), 16, diffBlockHash, diffRoot); err != nil {
+		t.Fatalf("failed to load snapshot with stale journal: %v", err)
+	}
+}
+
+// journalCheckDB records, for every batch written, whether a flatten journal
+// was present in the database when the batch was written.
+type journalCheckDB struct {
+	ethdb.KeyValueStore
+	journalled []bool
+}
+
+func (db *journalCheckDB) NewBatch() ethdb.Batch {
+	return &journalCheckBatch{Batch: db.KeyValueStore.NewBatch(), db: db}
+}
+
+type journalCheckBatch struct {
+	ethdb.Batch
+	db *journalCheckDB
+}
+
+func (b *journalCheckBatch) Write() error {
+	b.db.journalled = append(b.db.journalled, len(rawdb.ReadSnapshotJournal(b.db.KeyValueStore)) > 0)
+	return b.Batch.Write()
+}
+
+// Tests that a flatten of a small diff layer destructing a contract, whose
+// storage is deleted in several batches, is journalled before the first batch
+// is written.
+func TestDiskJournalDestruct(t *testing.T) {
+	var (
+		conNuke       = randomHash()
+		baseRoot      = randomHash()
+		baseBlockHash = randomHash()
+		diffRoot      = randomHash()
+		diffBlockHash = randomHash()
+	)
+	db := &journalCheckDB{KeyValueStore: rawdb.NewMemoryDatabase()}
+	rawdb.WriteAccountSnapshot(db, conNuke, conNuke[:])
+	for i := 0; i < 2*ethdb.IdealBatchSize/(1+2*common.HashLength); i++ {
+		slot := randomHash()
+		rawdb.WriteStorageSnapshot(db, conNuke, slot, slot[:])
+	}
+	rawdb.WriteSnapshotBlockHash(db, baseBlockHash)
+	rawdb.WriteSnapshotRoot(db, baseRoot)
+
+	snaps := NewTestTree(db, baseBlockHash, baseRoot)
+	if err := snaps.Update(diffBlockHash, diffRoot, baseBlockHash, map[common.Hash]struct{}{
+		conNuke: {},
+	}, nil, nil); err != nil {
+		t.Fatalf("failed to update snapshot tree: %v", err)
+	}
+	snaps.verified = true // Bypass validation of junk data
+	if err := snaps.Flatten(diffBlockHash); err != nil {
+		t.Fatalf("failed to flatten snapshot tree: %v", err)
+	}
+	if len(db.journalled) < 2 {
+		t.Fatalf("expected the storage deletions to be written in several batches, got %d", len(db.journalled))
+	}
+	for i, journalled := range db.journalled {
+		if !journalled {
+			t.Fatalf("batch %d written without journal", i)
+		}
+	}
+	if blob := rawdb.ReadSnapshotJournal(db); len(blob) != 0 {
+		t.Fatalf("journal not deleted after flatten")
+	}
+	if it := rawdb.IterateStorageSnapshots(db, conNuke); it.Next() {
+		t.Fatalf("destructed storage slot not deleted: %x", it.Key())
+	}
+}
+
 // Tests that merging something into a disk layer persists it into the database
 // and invalidates any previously written and cached values, discarding anything
 // after the in-progress generation marker.
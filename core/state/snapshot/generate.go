@@ -52,6 +52,11 @@ var (
 	emptyCode = crypto.Keccak256Hash(nil)
 )
 
+// generatorCheckpointInterval is the maximum time between two checkpoints of
+// the generator progress, so that little work is lost if the node crashes while
+// generating a snapshot slowly.
+const generatorCheckpointInterval = time.Minute
+
 // generatorStats is a collection of statistics gathered by the snapshot generator
 // for logging purposes.
 type generatorStats struct {
@@ -211,7 +216,7 @@ func (dl *diskLayer) checkAndFlush(batch ethdb.Batch, stats *generatorStats, cur
 	case abort = <-dl.genAbort:
 	default:
 	}
-	if batch.ValueSize() > ethdb.IdealBatchSize || abort != nil {
+	if batch.ValueSize() > ethdb.IdealBatchSize || abort != nil || time.Since(dl.checkpointed) > generatorCheckpointInterval {
 		if bytes.Compare(currentLocation, dl.genMarker) < 0 {
 			log.Error("Snapshot generator went backwards",
 				"currentLocation", fmt.Sprintf("%x", currentLocation),
@@ -232,6 +237,7 @@ func (dl *diskLayer) checkAndFlush(batch ethdb.Batch, stats *generatorStats, cur
 			return true
 		}
 		batch.Reset()
+		dl.checkpointed = time.Now()
 
 		dl.lock.Lock()
 		dl.genMarker = currentLocation
@@ -294,6 +300,7 @@ func (dl *diskLayer) generate(stats *generatorStats) {
 
 	// Iterate from the previous marker and continue generating the state snapshot
 	dl.logged = time.Now()
+	dl.checkpointed = time.Now()
 	for accIt.Next() {
 		// Retrieve the current account and flatten it into the internal format
 		accountHash := common.BytesToHash(accIt.Key)
@@ -329,7 +336,7 @@ func (dl *diskLayer) generate(stats *generatorStats) {
 		// If the iterated account is a contract, iterate through corresponding contract
 		// storage to generate snapshot entries.
 		if acc.Root != emptyRoot {
-			storeTrie, err := trie.NewSecure(acc.Root, dl.triedb)
+			storeTrie, err := trie.NewSecureWithOwner(accountHash, acc.Root, dl.triedb)
 			if err != nil {
 				log.Error("Generator failed to access storage trie", "root", dl.root, "account", accountHash, "stroot", acc.Root, "err", err)
 				abort := <-dl.genAbort
@@ -388,6 +395,7 @@ func (dl *diskLayer) generate(stats *generatorStats) {
 
 	log.Info("Generated state snapshot", "accounts", stats.accounts, "slots", stats.slots,
 		"storage", stats.storage, "elapsed", common.PrettyDuration(time.Since(stats.start)))
+	snapshotGenerationTimer.Update(time.Since(stats.start))
 
 	dl.lock.Lock()
 	dl.genMarker = nil
@@ -54,10 +54,174 @@ type journalGenerator struct {
 	Storage  uint64
 }
 
+// journalAccount is an account of a journalled diff layer.
+type journalAccount struct {
+	Hash common.Hash
+	Blob []byte
+}
+
+// journalStorage is the storage slots of an account of a journalled diff layer.
+type journalStorage struct {
+	Hash common.Hash
+	Keys []common.Hash
+	Vals [][]byte
+}
+
+// journalDiff is a diff layer that is being flattened into the disk layer of
+// the block [ParentBlockHash]. It is persisted while the flatten is written in
+// several batches, so that a flatten interrupted by a crash is completed on the
+// next load of the snapshot.
+type journalDiff struct {
+	ParentBlockHash common.Hash
+	ParentRoot      common.Hash
+	BlockHash       common.Hash
+	Root            common.Hash
+	Destructs       []common.Hash
+	Accounts        []journalAccount
+	Storage         []journalStorage
+}
+
+// journalDiffLayer persists [bottom], which is about to be flattened into the
+// disk layer [base].
+func journalDiffLayer(base *diskLayer, bottom *diffLayer) {
+	entry := journalDiff{
+		ParentBlockHash: base.blockHash,
+		ParentRoot:      base.root,
+		BlockHash:       bottom.blockHash,
+		Root:            bottom.root,
+	}
+	for hash := range bottom.destructSet {
+		entry.Destructs = append(entry.Destructs, hash)
+	}
+	for hash, blob := range bottom.accountData {
+		entry.Accounts = append(entry.Accounts, journalAccount{Hash: hash, Blob: blob})
+	}
+	for hash, storage := range bottom.storageData {
+		keys := make([]common.Hash, 0, len(storage))
+		vals := make([][]byte, 0, len(storage))
+		for key, val := range storage {
+			keys = append(keys, key)
+			vals = append(vals, val)
+		}
+		entry.Storage = append(entry.Storage, journalStorage{Hash: hash, Keys: keys, Vals: vals})
+	}
+	blob, err := rlp.EncodeToBytes(entry)
+	if err != nil {
+		panic(err) // Cannot happen, here to catch dev errors
+	}
+	rawdb.WriteSnapshotJournal(base.diskdb, blob)
+	snapshotJournalWriteMeter.Mark(1)
+	log.Debug("Journalled diff layer", "blockHash", bottom.blockHash, "root", bottom.root, "size", common.StorageSize(len(blob)))
+}
+
+// readJournalDiff returns the diff layer whose flatten into the disk layer
+// persisted in [diskdb] was interrupted, or nil if there is none.
+func readJournalDiff(diskdb ethdb.KeyValueReader) *journalDiff {
+	blob := rawdb.ReadSnapshotJournal(diskdb)
+	if len(blob) == 0 {
+		return nil
+	}
+	var journal journalDiff
+	if err := rlp.DecodeBytes(blob, &journal); err != nil {
+		log.Warn("Failed to decode snapshot journal", "err", err)
+		return nil
+	}
+	// The block markers are deleted by the first batch written by the flatten,
+	// and updated by the last one, which also deletes the journal. If the
+	// markers refer to another block, the journal is stale.
+	if baseBlockHash := rawdb.ReadSnapshotBlockHash(diskdb); baseBlockHash != (common.Hash{}) && baseBlockHash != journal.ParentBlockHash {
+		return nil
+	}
+	return &journal
+}
+
+// replay completes the flatten of the journalled diff layer into the disk
+// layer persisted in [diskdb].
+func (journal *journalDiff) replay(diskdb ethdb.KeyValueStore, triedb *trie.Database) error {
+	generatorBlob := rawdb.ReadSnapshotGenerator(diskdb)
+	if len(generatorBlob) == 0 {
+		return errors.New("missing snapshot generator")
+	}
+	var generator journalGenerator
+	if err := rlp.DecodeBytes(generatorBlob, &generator); err != nil {
+		return fmt.Errorf("failed to decode snapshot generator: %v", err)
+	}
+	// The cache of the disk layer is discarded once the flatten completes.
+	base := &diskLayer{
+		diskdb:    diskdb,
+		triedb:    triedb,
+		cache:     fastcache.New(1),
+		root:      journal.ParentRoot,
+		blockHash: journal.ParentBlockHash,
+		created:   time.Now(),
+	}
+	// Keep the generator progress, so that it is persisted unchanged.
+	if !generator.Done {
+		base.genMarker = generator.Marker
+		if base.genMarker == nil {
+			base.genMarker = []byte{}
+		}
+		base.genStats = &generatorStats{
+			accounts: generator.Accounts,
+			slots:    generator.Slots,
+			storage:  common.StorageSize(generator.Storage),
+		}
+		if generator.Wiping {
+			base.genStats.wiping = make(chan struct{})
+		}
+	}
+	var (
+		destructs = make(map[common.Hash]struct{}, len(journal.Destructs))
+		accounts  = make(map[common.Hash][]byte, len(journal.Accounts))
+		storage   = make(map[common.Hash]map[common.Hash][]byte, len(journal.Storage))
+	)
+	for _, hash := range journal.Destructs {
+		destructs[hash] = struct{}{}
+	}
+	for _, acc := range journal.Accounts {
+		accounts[acc.Hash] = acc.Blob
+	}
+	for _, entry := range journal.Storage {
+		if len(entry.Keys) != len(entry.Vals) {
+			return fmt.Errorf("invalid journalled storage of account %s: %d keys, %d values", entry.Hash, len(entry.Keys), len(entry.Vals))
+		}
+		slots := make(map[common.Hash][]byte, len(entry.Keys))
+		for i, key := range entry.Keys {
+			slots[key] = entry.Vals[i]
+		}
+		storage[entry.Hash] = slots
+	}
+	if _, _, err := diffToDisk(newDiffLayer(base, journal.BlockHash, journal.Root, destructs, accounts, storage)); err != nil {
+		return err
+	}
+	snapshotJournalReplayMeter.Mark(1)
+	log.Info("Completed interrupted snapshot flatten", "blockHash", journal.BlockHash, "root", journal.Root)
+	return nil
+}
+
+// ReadBlockHash returns the hash of the block whose state is contained in the
+// snapshot persisted in [diskdb]. If the flatten of a diff layer into the disk
+// layer was interrupted, the block of the diff layer is returned, since the
+// flatten is completed when the snapshot is loaded.
+func ReadBlockHash(diskdb ethdb.KeyValueReader) common.Hash {
+	if journal := readJournalDiff(diskdb); journal != nil {
+		return journal.BlockHash
+	}
+	return rawdb.ReadSnapshotBlockHash(diskdb)
+}
+
 // loadSnapshot loads a pre-existing state snapshot backed by a key-value
 // store. If loading the snapshot from disk is successful, this function also
 // returns a boolean indicating whether or not the snapshot is fully generated.
 func loadSnapshot(diskdb ethdb.KeyValueStore, triedb *trie.Database, cache int, blockHash, root common.Hash) (snapshot, bool, error) {
+	// Complete the flatten of a diff layer into the disk layer if it was
+	// interrupted, so that the partially written disk layer is not regenerated.
+	if journal := readJournalDiff(diskdb); journal != nil {
+		if err := journal.replay(diskdb, triedb); err != nil {
+			return nil, false, fmt.Errorf("failed to replay snapshot journal: %w", err)
+		}
+	}
+
 	// Retrieve the block number and hash of the snapshot, failing if no snapshot
 	// is present in the database (or crashed mid-update).
 	baseBlockHash := rawdb.ReadSnapshotBlockHash(diskdb)
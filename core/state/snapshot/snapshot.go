@@ -99,6 +99,10 @@ var (
 	snapshotBloomStorageFalseHitMeter = metrics.NewRegisteredMeter("state/snapshot/bloom/storage/falsehit", nil)
 	snapshotBloomStorageMissMeter     = metrics.NewRegisteredMeter("state/snapshot/bloom/storage/miss", nil)
 
+	snapshotGenerationTimer    = metrics.NewRegisteredTimer("state/snapshot/generation/time", nil)
+	snapshotJournalWriteMeter  = metrics.NewRegisteredMeter("state/snapshot/journal/write", nil)
+	snapshotJournalReplayMeter = metrics.NewRegisteredMeter("state/snapshot/journal/replay", nil)
+
 	// ErrSnapshotStale is returned from data accessors if the underlying snapshot
 	// layer had been invalidated due to the chain progressing forward far enough
 	// to not maintain the layer's original state.
@@ -592,6 +596,13 @@ func diffToDisk(bottom *diffLayer) (*diskLayer, bool, error) {
 	// Attempt to abort generation (if not already aborted)
 	base.abortGeneration()
 
+	// If the diff may not be flushed in a single batch, journal it before any
+	// update is written, so that an interrupted flatten is completed on restart
+	// instead of leaving a disk layer that must be regenerated.
+	if bottom.memory > ethdb.IdealBatchSize || len(bottom.destructSet) > 0 {
+		journalDiffLayer(base, bottom)
+	}
+
 	// Put the deletion in the batch writer, flush all updates in the final step.
 	rawdb.DeleteSnapshotBlockHash(batch)
 	rawdb.DeleteSnapshotRoot(batch)
@@ -687,6 +698,7 @@ func diffToDisk(bottom *diffLayer) (*diskLayer, bool, error) {
 	// Update the snapshot block marker and write any remainder data
 	rawdb.WriteSnapshotBlockHash(batch, bottom.blockHash)
 	rawdb.WriteSnapshotRoot(batch, bottom.root)
+	rawdb.DeleteSnapshotJournal(batch)
 
 	// Write out the generator progress marker and report
 	journalProgress(batch, base.genMarker, base.genStats)
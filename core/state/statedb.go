@@ -28,10 +28,14 @@
 package state
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"math/big"
+	"runtime"
 	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
@@ -78,6 +82,10 @@ type StateDB struct {
 	trie         Trie
 	hasher       crypto.KeccakState
 
+	// Number of storage tries hashed and committed concurrently (< 1 =
+	// GOMAXPROCS)
+	storageTrieWorkers int
+
 	snap          snapshot.Snapshot
 	snapDestructs map[common.Hash]struct{}
 	snapAccounts  map[common.Hash][]byte
@@ -108,6 +116,9 @@ type StateDB struct {
 	// Per-transaction access list
 	accessList *accessList
 
+	// Transient storage, which is discarded at the end of each transaction
+	transientStorage transientStorage
+
 	// Journal of state modifications. This is the backbone of
 	// Snapshot and RevertToSnapshot.
 	journal        *journal
@@ -162,6 +173,7 @@ func NewWithSnapshot(root common.Hash, db Database, snap snapshot.Snapshot) (*St
 		preimages:           make(map[common.Hash][]byte),
 		journal:             newJournal(),
 		accessList:          newAccessList(),
+		transientStorage:    newTransientStorage(),
 		hasher:              crypto.NewKeccakState(),
 	}
 	if snap != nil {
@@ -486,6 +498,33 @@ func (s *StateDB) SetState(addr common.Address, key, value common.Hash) {
 	}
 }
 
+// SetTransientState sets transient storage for a given account. It
+// adds the change to the journal so that it can be rolled back
+// to its previous value if there is a revert.
+func (s *StateDB) SetTransientState(addr common.Address, key, value common.Hash) {
+	prev := s.GetTransientState(addr, key)
+	if prev == value {
+		return
+	}
+	s.journal.append(transientStorageChange{
+		account:  &addr,
+		key:      key,
+		prevalue: prev,
+	})
+	s.setTransientState(addr, key, value)
+}
+
+// setTransientState is a lower level setter for transient storage. It
+// is called during a revert to prevent modifications to the journal.
+func (s *StateDB) setTransientState(addr common.Address, key, value common.Hash) {
+	s.transientStorage.Set(addr, key, value)
+}
+
+// GetTransientState gets transient storage for a given account.
+func (s *StateDB) GetTransientState(addr common.Address, key common.Hash) common.Hash {
+	return s.transientStorage.Get(addr, key)
+}
+
 // SetStorage replaces the entire storage for the specified account with given
 // storage. This function should only be used for debugging.
 func (s *StateDB) SetStorage(addr common.Address, storage map[common.Hash]common.Hash) {
@@ -726,6 +765,7 @@ func (s *StateDB) Copy() *StateDB {
 		preimages:           make(map[common.Hash][]byte, len(s.preimages)),
 		journal:             newJournal(),
 		hasher:              crypto.NewKeccakState(),
+		storageTrieWorkers:  s.storageTrieWorkers,
 	}
 	// Copy the dirty states, logs, and preimages
 	for addr := range s.journal.dirties {
@@ -775,6 +815,7 @@ func (s *StateDB) Copy() *StateDB {
 	// However, it doesn't cost us much to copy an empty list, so we do it anyway
 	// to not blow up if we ever decide copy it in the middle of a transaction
 	state.accessList = s.accessList.Copy()
+	state.transientStorage = s.transientStorage.Copy()
 
 	// If there's a prefetcher running, make an inactive copy of it that can
 	// only access data but does not actively preload (since the user will not
@@ -878,10 +919,12 @@ func (s *StateDB) Finalise(deleteEmptyObjects bool) {
 		addressesToPrefetch = append(addressesToPrefetch, common.CopyBytes(addr[:])) // Copy needed for closure
 	}
 	if s.prefetcher != nil && len(addressesToPrefetch) > 0 {
-		s.prefetcher.prefetch(s.originalRoot, addressesToPrefetch)
+		s.prefetcher.prefetch(common.Hash{}, s.originalRoot, addressesToPrefetch)
 	}
 	// Invalidate journal because reverting across transactions is not allowed.
 	s.clearJournalAndRefund()
+	// Transient storage only lives for the duration of a transaction.
+	s.transientStorage = newTransientStorage()
 }
 
 // IntermediateRoot computes the current root hash of the state trie.
@@ -910,16 +953,21 @@ func (s *StateDB) IntermediateRoot(deleteEmptyObjects bool) common.Hash {
 	// the account prefetcher. Instead, let's process all the storage updates
 	// first, giving the account prefeches just a few more milliseconds of time
 	// to pull useful data from disk.
+	//
+	// The updates are applied sequentially, since they modify state shared by
+	// all objects, then the independent storage tries are hashed concurrently.
+	updated := make([]*stateObject, 0, len(s.stateObjectsPending))
 	for addr := range s.stateObjectsPending {
-		if obj := s.stateObjects[addr]; !obj.deleted {
-			obj.updateRoot(s.db)
+		if obj := s.stateObjects[addr]; !obj.deleted && obj.updateTrie(s.db) != nil {
+			updated = append(updated, obj)
 		}
 	}
+	s.hashStorageTries(updated)
 	// Now we're about to start to write changes to the trie. The trie is so far
 	// _untouched_. We can check with the prefetcher, if it can give us a trie
 	// which has the same root, but also has some content loaded into it.
 	if prefetcher != nil {
-		if trie := prefetcher.trie(s.originalRoot); trie != nil {
+		if trie := prefetcher.trie(common.Hash{}, s.originalRoot); trie != nil {
 			s.trie = trie
 		}
 	}
@@ -935,7 +983,7 @@ func (s *StateDB) IntermediateRoot(deleteEmptyObjects bool) common.Hash {
 		usedAddrs = append(usedAddrs, common.CopyBytes(addr[:])) // Copy needed for closure
 	}
 	if prefetcher != nil {
-		prefetcher.used(s.originalRoot, usedAddrs)
+		prefetcher.used(common.Hash{}, s.originalRoot, usedAddrs)
 	}
 	if len(s.stateObjectsPending) > 0 {
 		s.stateObjectsPending = make(map[common.Address]struct{})
@@ -983,8 +1031,8 @@ func (s *StateDB) commit(deleteEmptyObjects bool, snaps *snapshot.Tree, blockHas
 	s.IntermediateRoot(deleteEmptyObjects)
 
 	// Commit objects to the trie, measuring the elapsed time
-	var storageCommitted int
 	codeWriter := s.db.TrieDB().DiskDB().NewBatch()
+	updated := make([]*stateObject, 0, len(s.stateObjectsDirty))
 	for addr := range s.stateObjectsDirty {
 		if obj := s.stateObjects[addr]; !obj.deleted {
 			// Write any contract code associated with the state object
@@ -993,13 +1041,15 @@ func (s *StateDB) commit(deleteEmptyObjects bool, snaps *snapshot.Tree, blockHas
 				obj.dirtyCode = false
 			}
 			// Write any storage changes in the state object to its storage trie
-			committed, err := obj.CommitTrie(s.db)
-			if err != nil {
-				return common.Hash{}, err
+			if obj.updateTrie(s.db) != nil {
+				updated = append(updated, obj)
 			}
-			storageCommitted += committed
 		}
 	}
+	storageCommitted, err := s.commitStorageTries(updated)
+	if err != nil {
+		return common.Hash{}, err
+	}
 	if len(s.stateObjectsDirty) > 0 {
 		s.stateObjectsDirty = make(map[common.Address]struct{})
 	}
@@ -1057,6 +1107,95 @@ func (s *StateDB) commit(deleteEmptyObjects bool, snaps *snapshot.Tree, blockHas
 	return root, err
 }
 
+// SetStorageTrieWorkers sets the number of storage tries hashed and committed
+// concurrently to [workers], or to GOMAXPROCS if [workers] is less than 1.
+func (s *StateDB) SetStorageTrieWorkers(workers int) {
+	s.storageTrieWorkers = workers
+}
+
+// hashStorageTries hashes the updated storage tries of [objs] concurrently and
+// sets the storage roots of the objects.
+func (s *StateDB) hashStorageTries(objs []*stateObject) {
+	// Track the amount of time wasted on hashing the storage tries
+	if metrics.EnabledExpensive {
+		defer func(start time.Time) { s.StorageHashes += time.Since(start) }(time.Now())
+	}
+	s.forEachStorageTrie(objs, func(_ int, obj *stateObject) error {
+		obj.data.Root = obj.trie.Hash()
+		return nil
+	})
+}
+
+// commitStorageTries commits the updated storage tries of [objs] concurrently
+// and returns the number of committed trie nodes.
+func (s *StateDB) commitStorageTries(objs []*stateObject) (int, error) {
+	// Track the amount of time wasted on committing the storage tries
+	if metrics.EnabledExpensive {
+		defer func(start time.Time) { s.StorageCommits += time.Since(start) }(time.Now())
+	}
+	committed := make([]int, len(objs))
+	err := s.forEachStorageTrie(objs, func(i int, obj *stateObject) error {
+		n, err := obj.commitTrie()
+		committed[i] = n
+		return err
+	})
+	var total int
+	for _, n := range committed {
+		total += n
+	}
+	return total, err
+}
+
+// forEachStorageTrie calls [fn] with each of [objs] and its index from a
+// bounded pool of workers. The objects are sorted by address, and the error of
+// the first object whose call failed is returned, so that the result does not
+// depend on the scheduling of the workers.
+//
+// [fn] must only modify the object it is called with.
+func (s *StateDB) forEachStorageTrie(objs []*stateObject, fn func(i int, obj *stateObject) error) error {
+	sort.Slice(objs, func(i, j int) bool {
+		return bytes.Compare(objs[i].address[:], objs[j].address[:]) < 0
+	})
+	workers := s.storageTrieWorkers
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(objs) {
+		workers = len(objs)
+	}
+	errs := make([]error, len(objs))
+	if workers <= 1 {
+		for i, obj := range objs {
+			errs[i] = fn(i, obj)
+		}
+	} else {
+		var (
+			next int64 = -1
+			wg   sync.WaitGroup
+		)
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					i := int(atomic.AddInt64(&next, 1))
+					if i >= len(objs) {
+						return
+					}
+					errs[i] = fn(i, objs[i])
+				}
+			}()
+		}
+		wg.Wait()
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // PrepareAccessList handles the preparatory steps for executing a state transition with
 // regards to both EIP-2929 and EIP-2930:
 //
@@ -1073,3 +1073,97 @@ func TestGenerateMultiCoinAccounts(t *testing.T) {
 		t.Fatalf("Expected asset balance: %v, found %v", assetBalance, actualAssetBalance)
 	}
 }
+
+func TestTransientStorage(t *testing.T) {
+	state, _ := New(common.Hash{}, NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	key := common.Hash{0x01}
+	value := common.Hash{0x02}
+	addr := common.Address{}
+
+	state.SetTransientState(addr, key, value)
+	if exp, got := 1, state.journal.length(); exp != got {
+		t.Fatalf("journal length mismatch: have %d, want %d", got, exp)
+	}
+	// Setting the same value again should not add a journal entry
+	state.SetTransientState(addr, key, value)
+	if exp, got := 1, state.journal.length(); exp != got {
+		t.Fatalf("journal length mismatch: have %d, want %d", got, exp)
+	}
+	if got := state.GetTransientState(addr, key); got != value {
+		t.Fatalf("transient storage mismatch: have %x, want %x", got, value)
+	}
+
+	// Reverting the change should restore the empty value
+	state.journal.revert(state, 0)
+	if got, exp := state.GetTransientState(addr, key), (common.Hash{}); exp != got {
+		t.Fatalf("transient storage mismatch: have %x, want %x", got, exp)
+	}
+
+	// Transient storage is copied along with the state
+	state.SetTransientState(addr, key, value)
+	cpy := state.Copy()
+	if got := cpy.GetTransientState(addr, key); got != value {
+		t.Fatalf("transient storage mismatch: have %x, want %x", got, value)
+	}
+
+	// Transient storage is discarded at the end of the transaction
+	state.Finalise(true)
+	if got, exp := state.GetTransientState(addr, key), (common.Hash{}); exp != got {
+		t.Fatalf("transient storage mismatch after finalise: have %x, want %x", got, exp)
+	}
+	if got := cpy.GetTransientState(addr, key); got != value {
+		t.Fatalf("copied transient storage mismatch: have %x, want %x", got, value)
+	}
+}
+
+// Tests that hashing and committing storage tries concurrently yields the same
+// roots and trie nodes as doing so sequentially.
+func TestStorageTrieWorkers(t *testing.T) {
+	modify := func(state *StateDB, round byte) {
+		for i := byte(0); i < 200; i++ {
+			addr := common.Address{i}
+			state.SetBalance(addr, big.NewInt(int64(i)+1))
+			for j := byte(0); j < i%16; j++ {
+				state.SetState(addr, common.Hash{j, round}, common.Hash{i, j, round + 1})
+			}
+			if i%5 == round%5 {
+				state.SetState(addr, common.Hash{0, round - 1}, common.Hash{})
+			}
+		}
+	}
+	run := func(workers int) ([]common.Hash, int) {
+		db := NewDatabase(rawdb.NewMemoryDatabase())
+		var (
+			roots []common.Hash
+			root  common.Hash
+		)
+		for round := byte(1); round <= 3; round++ {
+			state, err := New(root, db, nil)
+			if err != nil {
+				t.Fatalf("failed to create state at round %d: %v", round, err)
+			}
+			state.SetStorageTrieWorkers(workers)
+			modify(state, round)
+			intermediate := state.IntermediateRoot(false)
+			if root, err = state.Commit(false); err != nil {
+				t.Fatalf("failed to commit state at round %d: %v", round, err)
+			}
+			if root != intermediate {
+				t.Fatalf("round %d: committed root %x does not match intermediate root %x", round, root, intermediate)
+			}
+			roots = append(roots, root)
+		}
+		nodes, _ := db.TrieDB().Size()
+		return roots, int(nodes)
+	}
+	expRoots, expNodes := run(1)
+	for _, workers := range []int{2, 16, 0} {
+		roots, nodes := run(workers)
+		if !reflect.DeepEqual(roots, expRoots) {
+			t.Fatalf("workers %d: roots mismatch: have %x, want %x", workers, roots, expRoots)
+		}
+		if nodes != expNodes {
+			t.Fatalf("workers %d: dirty trie node size mismatch: have %d, want %d", workers, nodes, expNodes)
+		}
+	}
+}
@@ -31,6 +31,7 @@ import (
 	"math/rand"
 	"time"
 
+	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
 	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
 	"github.com/sankar-boro/axia-network-v2-coreth/ethdb"
 	"github.com/ethereum/go-ethereum/common"
@@ -73,7 +74,9 @@ type TrieDB interface {
 }
 
 func NewTrieWriter(db TrieDB, config *CacheConfig) TrieWriter {
-	if config.Pruning {
+	// The path scheme keeps a single state on disk, which is replaced by each
+	// accepted state.
+	if config.Pruning && config.StateScheme != rawdb.PathScheme {
 		cm := &cappedMemoryTrieWriter{
 			TrieDB:           db,
 			memoryCap:        common.StorageSize(config.TrieDirtyLimit) * 1024 * 1024,
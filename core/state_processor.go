@@ -81,6 +81,24 @@ func (p *StateProcessor) Process(block *types.Block, parent *types.Header, state
 		misc.ApplyDAOHardFork(statedb)
 	}
 	blockContext := NewEVMBlockContext(header, p.bc, nil)
+	// Configure any stateful precompiles activated in this block
+	if err := vm.ConfigureStatefulPrecompiles(p.config, new(big.Int).SetUint64(parent.Time), blockContext, statedb); err != nil {
+		return nil, nil, 0, err
+	}
+	// Optimistically execute the transactions in parallel if configured to.
+	// Tracers observe the execution of each transaction in order, so they
+	// require the serial path.
+	if workers := p.parallelWorkers(); workers > 1 && !cfg.Debug && p.config.IsByzantium(blockNumber) {
+		receipts, allLogs, err := p.processParallel(block, statedb, cfg, workers, gp, usedGas)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		// Finalize the block, applying any consensus engine specific extras (e.g. block rewards)
+		if err := p.engine.Finalize(p.bc, block, parent, statedb, receipts); err != nil {
+			return nil, nil, 0, fmt.Errorf("engine finalization check failed: %w", err)
+		}
+		return receipts, allLogs, *usedGas, nil
+	}
 	vmenv := vm.NewEVM(blockContext, vm.TxContext{}, statedb, p.config, cfg)
 	// Iterate over and process the individual transactions
 	for i, tx := range block.Transactions() {
@@ -124,9 +142,15 @@ func applyTransaction(msg types.Message, config *params.ChainConfig, bc ChainCon
 	}
 	*usedGas += result.UsedGas
 
+	return newReceipt(msg, result, root, statedb, blockNumber, blockHash, tx, *usedGas), nil
+}
+
+// newReceipt creates the receipt of [tx], which was applied to [statedb]
+// with [result].
+func newReceipt(msg types.Message, result *ExecutionResult, root []byte, statedb *state.StateDB, blockNumber *big.Int, blockHash common.Hash, tx *types.Transaction, usedGas uint64) *types.Receipt {
 	// Create a new receipt for the transaction, storing the intermediate root and gas used
 	// by the tx.
-	receipt := &types.Receipt{Type: tx.Type(), PostState: root, CumulativeGasUsed: *usedGas}
+	receipt := &types.Receipt{Type: tx.Type(), PostState: root, CumulativeGasUsed: usedGas}
 	if result.Failed() {
 		receipt.Status = types.ReceiptStatusFailed
 	} else {
@@ -137,7 +161,7 @@ func applyTransaction(msg types.Message, config *params.ChainConfig, bc ChainCon
 
 	// If the transaction created a contract, store the creation address in the receipt.
 	if msg.To() == nil {
-		receipt.ContractAddress = crypto.CreateAddress(evm.TxContext.Origin, tx.Nonce())
+		receipt.ContractAddress = crypto.CreateAddress(msg.From(), tx.Nonce())
 	}
 
 	// Set the receipt logs and create the bloom filter.
@@ -146,7 +170,7 @@ func applyTransaction(msg types.Message, config *params.ChainConfig, bc ChainCon
 	receipt.BlockHash = blockHash
 	receipt.BlockNumber = blockNumber
 	receipt.TransactionIndex = uint(statedb.TxIndex())
-	return receipt, err
+	return receipt
 }
 
 // ApplyTransaction attempts to apply a transaction to the given state database
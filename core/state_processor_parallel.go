@@ -0,0 +1,161 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/core/state"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/vm"
+	"github.com/sankar-boro/axia-network-v2-coreth/metrics"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	parallelTxSpeculatedMeter  = metrics.NewRegisteredMeter("chain/parallel/speculated", nil)
+	parallelTxReexecutedMeter  = metrics.NewRegisteredMeter("chain/parallel/reexecuted", nil)
+	parallelTxConflictingMeter = metrics.NewRegisteredMeter("chain/parallel/conflicts", nil)
+)
+
+// speculativeResult is the outcome of executing a transaction on a view of
+// the state at the start of the block.
+type speculativeResult struct {
+	statedb *recordingStateDB
+	result  *ExecutionResult
+	err     error
+}
+
+// parallelWorkers returns the number of workers to use for optimistic
+// parallel transaction execution. Values below 2 disable it.
+func (p *StateProcessor) parallelWorkers() int {
+	if p.bc == nil || p.bc.cacheConfig == nil {
+		return 0
+	}
+	return p.bc.cacheConfig.ParallelExecutionWorkers
+}
+
+// processParallel applies the transactions of [block] to [statedb] by
+// optimistically executing them in parallel and committing the results in
+// order, producing the same state, receipts and logs as the serial execution.
+//
+// Each transaction is speculatively executed by one of [workers] goroutines on
+// its own copy of the state at the start of the block, while recording the state it
+// reads and the modifications it makes. The results are then committed in
+// transaction order: if no transaction committed before it wrote any of the
+// state read by a transaction, the speculative execution observed the same
+// state as the serial execution would, and its modifications are replayed on
+// [statedb]. Otherwise, the transaction is re-executed on [statedb].
+func (p *StateProcessor) processParallel(block *types.Block, statedb *state.StateDB, cfg vm.Config, workers int, gp *GasPool, usedGas *uint64) (types.Receipts, []*types.Log, error) {
+	var (
+		txs         = block.Transactions()
+		header      = block.Header()
+		blockHash   = block.Hash()
+		blockNumber = block.Number()
+		signer      = types.MakeSigner(p.config, header.Number, new(big.Int).SetUint64(header.Time))
+		msgs        = make([]types.Message, len(txs))
+		results     = make([]chan *speculativeResult, len(txs))
+		receipts    = make(types.Receipts, 0, len(txs))
+		allLogs     []*types.Log
+	)
+	for i, tx := range txs {
+		msg, err := tx.AsMessage(signer, header.BaseFee)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+		}
+		msgs[i] = msg
+		results[i] = make(chan *speculativeResult, 1)
+	}
+
+	// Speculatively execute the transactions. The workers copy their views
+	// from [base] instead of [statedb], which is modified as the transactions
+	// are committed.
+	var (
+		base     = statedb.Copy()
+		baseLock sync.Mutex // Copying may update the caches of [base]
+		tasks    = make(chan int, len(txs))
+		quit     = make(chan struct{})
+		wg       sync.WaitGroup
+	)
+	for i := range txs {
+		tasks <- i
+	}
+	close(tasks)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// The block context caches block hashes, so it must not be shared
+			// between goroutines.
+			blockContext := NewEVMBlockContext(header, p.bc, nil)
+			for i := range tasks {
+				select {
+				case <-quit:
+					return
+				default:
+				}
+				baseLock.Lock()
+				view := newRecordingStateDB(base.Copy(), true)
+				baseLock.Unlock()
+				view.Prepare(txs[i].Hash(), i)
+				vmenv := vm.NewEVM(blockContext, NewEVMTxContext(msgs[i]), view, p.config, cfg)
+				result, err := ApplyMessage(vmenv, msgs[i], new(GasPool).AddGas(block.GasLimit()))
+				results[i] <- &speculativeResult{statedb: view, result: result, err: err}
+			}
+		}()
+	}
+	defer func() {
+		close(quit)
+		wg.Wait()
+	}()
+
+	// Commit the results in order
+	var (
+		blockContext = NewEVMBlockContext(header, p.bc, nil)
+		written      = make(map[stateKey]struct{})
+		wiped        = make(map[common.Address]struct{})
+	)
+	for i, tx := range txs {
+		spec := <-results[i]
+		parallelTxSpeculatedMeter.Mark(1)
+
+		statedb.Prepare(tx.Hash(), i)
+		var (
+			view   = spec.statedb
+			result = spec.result
+		)
+		// A speculative error may be caused by reading stale state, so the
+		// transaction is re-executed to determine whether it is valid.
+		valid := spec.err == nil && view.replayable && gp.Gas() >= msgs[i].Gas()
+		if valid && view.conflicts(written, wiped) {
+			parallelTxConflictingMeter.Mark(1)
+			valid = false
+		}
+		if valid {
+			view.replay(statedb)
+			if err := gp.SubGas(result.UsedGas); err != nil {
+				return nil, nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+			}
+		} else {
+			parallelTxReexecutedMeter.Mark(1)
+			view = newRecordingStateDB(statedb, false)
+			vmenv := vm.NewEVM(blockContext, NewEVMTxContext(msgs[i]), view, p.config, cfg)
+			var err error
+			result, err = ApplyMessage(vmenv, msgs[i], gp)
+			if err != nil {
+				return nil, nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+			}
+		}
+		view.mergeWrites(written, wiped)
+		statedb.Finalise(true)
+		*usedGas += result.UsedGas
+
+		receipt := newReceipt(msgs[i], result, nil, statedb, blockNumber, blockHash, tx, *usedGas)
+		receipts = append(receipts, receipt)
+		allLogs = append(allLogs, receipt.Logs...)
+	}
+	return receipts, allLogs, nil
+}
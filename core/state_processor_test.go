@@ -27,12 +27,16 @@
 package core
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
 	"math/big"
 	"testing"
 
 	"github.com/sankar-boro/axia-network-v2-coreth/consensus"
 	"github.com/sankar-boro/axia-network-v2-coreth/consensus/dummy"
 	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/state"
 	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
 	"github.com/sankar-boro/axia-network-v2-coreth/core/vm"
 	"github.com/sankar-boro/axia-network-v2-coreth/params"
@@ -302,6 +306,115 @@ func TestStateProcessorErrors(t *testing.T) {
 			}
 		}
 	}
+
+	// ErrNotAllowedToTransact and ErrNotAllowedToDeploy, for these we need the
+	// allow list precompiles to be active
+	{
+		sender := common.HexToAddress("0x71562b71999873DB5b286dF957af199Ec94617F7")
+		for i, tt := range []struct {
+			txAllowList       params.AllowListConfig
+			deployerAllowList params.AllowListConfig
+			txs               []*types.Transaction
+			want              string
+		}{
+			{ // ErrNotAllowedToTransact
+				txs: []*types.Transaction{
+					makeTx(0, common.Address{}, big.NewInt(0), params.TxGas, big.NewInt(225000000000), nil),
+				},
+				want: "could not apply tx 0 [0x734d821c990099c6ae42d78072aadd3931c35328cf03ef4cf5b2a4ac9c398522]: sender is not allowed to issue transactions: address 0x71562b71999873DB5b286dF957af199Ec94617F7",
+			},
+			{ // ErrNotAllowedToDeploy
+				txAllowList: params.AllowListConfig{EnabledAddresses: []common.Address{sender}},
+				txs: func() []*types.Transaction {
+					tx, _ := types.SignTx(types.NewContractCreation(0, big.NewInt(0), 100000, big.NewInt(225000000000), nil), signer, testKey)
+					return []*types.Transaction{tx}
+				}(),
+				want: "could not apply tx 0 [0x7641f4faba15ad2b3549fe54ba8aa46add0a3a170e99ad92f85f7079f22c3107]: tx origin is not allowed to deploy contracts: address 0x71562b71999873DB5b286dF957af199Ec94617F7",
+			},
+		} {
+			chainConfig := *config
+			chainConfig.TxAllowListConfig = &params.TxAllowListConfig{
+				PrecompileUpgrade: params.PrecompileUpgrade{BlockTimestamp: big.NewInt(0)},
+				AllowListConfig:   tt.txAllowList,
+			}
+			chainConfig.ContractDeployerAllowListConfig = &params.ContractDeployerAllowListConfig{
+				PrecompileUpgrade: params.PrecompileUpgrade{BlockTimestamp: big.NewInt(0)},
+				AllowListConfig:   tt.deployerAllowList,
+			}
+			var (
+				db    = rawdb.NewMemoryDatabase()
+				gspec = &Genesis{
+					Config: &chainConfig,
+					Alloc: GenesisAlloc{
+						sender: GenesisAccount{
+							Balance: big.NewInt(2000000000000000000), // 2 ether
+							Nonce:   0,
+						},
+					},
+					GasLimit: params.ApricotPhase1GasLimit,
+				}
+				genesis       = gspec.MustCommit(db)
+				blockchain, _ = NewBlockChain(db, DefaultCacheConfig, gspec.Config, dummy.NewFaker(), vm.Config{}, common.Hash{})
+			)
+			block := GenerateBadBlock(genesis, dummy.NewFaker(), tt.txs, gspec.Config)
+			_, err := blockchain.InsertChain(types.Blocks{block})
+			blockchain.Stop()
+			if err == nil {
+				t.Fatal("block imported without errors")
+			}
+			if have, want := err.Error(), tt.want; have != want {
+				t.Errorf("test %d:\nhave \"%v\"\nwant \"%v\"\n", i, have, want)
+			}
+		}
+	}
+	// ErrMaxInitCodeSizeExceeded and the initcode metering of ErrIntrinsicGas,
+	// for these we need Apricot Phase 6 to be active
+	{
+		chainConfig := *config
+		chainConfig.ApricotPhase6BlockTimestamp = big.NewInt(0)
+		var (
+			db    = rawdb.NewMemoryDatabase()
+			gspec = &Genesis{
+				Config: &chainConfig,
+				Alloc: GenesisAlloc{
+					common.HexToAddress("0x71562b71999873DB5b286dF957af199Ec94617F7"): GenesisAccount{
+						Balance: big.NewInt(1000000000000000000), // 1 ether
+						Nonce:   0,
+					},
+				},
+				GasLimit: params.ApricotPhase1GasLimit,
+			}
+			genesis       = gspec.MustCommit(db)
+			blockchain, _ = NewBlockChain(db, DefaultCacheConfig, gspec.Config, dummy.NewFaker(), vm.Config{}, common.Hash{})
+			makeCreation  = func(gasLimit uint64, initCodeSize int) []*types.Transaction {
+				tx, _ := types.SignTx(types.NewContractCreation(0, big.NewInt(0), gasLimit, big.NewInt(225000000000), make([]byte, initCodeSize)), signer, testKey)
+				return []*types.Transaction{tx}
+			}
+		)
+		defer blockchain.Stop()
+		for i, tt := range []struct {
+			txs  []*types.Transaction
+			want string
+		}{
+			{ // ErrMaxInitCodeSizeExceeded
+				txs:  makeCreation(500000, params.MaxInitCodeSize+1),
+				want: "invalid transaction 0: max initcode size exceeded: code size 49153 limit 49152",
+			},
+			{ // ErrIntrinsicGas: 53000 + 320 * 4 for the zero bytes + 10 * 2 for the init code words
+				txs:  makeCreation(54299, 320),
+				want: "could not apply tx 0 [0x8db1b2a837e045f75e33cf312522d5d957023bf9e315b42fa48c46cdc3c2eceb]: intrinsic gas too low: have 54299, want 54300",
+			},
+		} {
+			block := GenerateBadBlock(genesis, dummy.NewFaker(), tt.txs, gspec.Config)
+			_, err := blockchain.InsertChain(types.Blocks{block})
+			if err == nil {
+				t.Fatal("block imported without errors")
+			}
+			if have, want := err.Error(), tt.want; have != want {
+				t.Errorf("test %d:\nhave \"%v\"\nwant \"%v\"\n", i, have, want)
+			}
+		}
+	}
 }
 
 // GenerateBadBlock constructs a "block" which contains the transactions. The transactions are not expected to be
@@ -312,7 +425,7 @@ func GenerateBadBlock(parent *types.Block, engine consensus.Engine, txs types.Tr
 	header := &types.Header{
 		ParentHash: parent.Hash(),
 		Coinbase:   parent.Coinbase(),
-		Difficulty: engine.CalcDifficulty(&fakeChainReader{config}, parent.Time()+10, &types.Header{
+		Difficulty: engine.CalcDifficulty(&fakeChainReader{config: config}, parent.Time()+10, &types.Header{
 			Number:     parent.Number(),
 			Time:       parent.Time(),
 			Difficulty: parent.Difficulty(),
@@ -324,7 +437,7 @@ func GenerateBadBlock(parent *types.Block, engine consensus.Engine, txs types.Tr
 		UncleHash: types.EmptyUncleHash,
 	}
 	if config.IsApricotPhase3(new(big.Int).SetUint64(header.Time)) {
-		header.Extra, header.BaseFee, _ = dummy.CalcBaseFee(config, parent.Header(), header.Time)
+		header.Extra, header.BaseFee, _ = dummy.CalcBaseFee(config, config.GetFeeConfig(), parent.Header(), header.Time)
 	}
 	if config.IsApricotPhase4(new(big.Int).SetUint64(header.Time)) {
 		header.BlockGasCost = big.NewInt(0)
@@ -349,3 +462,168 @@ func GenerateBadBlock(parent *types.Block, engine consensus.Engine, txs types.Tr
 	// Assemble and return the final block for sealing
 	return types.NewBlock(header, txs, nil, receipts, trie.NewStackTrie(nil), nil, true)
 }
+
+// TestParallelStateProcessor checks that the optimistic parallel execution of
+// transactions produces the same state and receipts as the serial execution,
+// including for transactions that conflict with each other.
+func TestParallelStateProcessor(t *testing.T) {
+	var (
+		config  = params.TestChainConfig
+		signer  = types.LatestSigner(config)
+		assetID = common.HexToHash("0xaa")
+		keys    = make([]*ecdsa.PrivateKey, 8)
+		addrs   = make([]common.Address, len(keys))
+		alloc   = make(GenesisAlloc)
+
+		// Increments the counter in slot 0 and logs the caller
+		counterAddr = common.HexToAddress("0xc0")
+		counterCode = []byte{
+			byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.PUSH1), 1, byte(vm.ADD), byte(vm.PUSH1), 0, byte(vm.SSTORE),
+			byte(vm.CALLER), byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.LOG1),
+		}
+		// Stores 1 in the slot of the caller
+		registryAddr = common.HexToAddress("0xc1")
+		registryCode = []byte{byte(vm.PUSH1), 1, byte(vm.CALLER), byte(vm.SSTORE)}
+		// Writes to slot 0 and reverts
+		revertAddr = common.HexToAddress("0xc2")
+		revertCode = []byte{byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.PUSH1), 0, byte(vm.DUP1), byte(vm.REVERT)}
+		// Destructs itself, sending its balance to the shared recipient
+		sharedAddr   = common.HexToAddress("0xdd")
+		suicideAddr  = common.HexToAddress("0xc3")
+		suicideCode  = append(append([]byte{byte(vm.PUSH20)}, sharedAddr.Bytes()...), byte(vm.SELFDESTRUCT))
+		createCode   = []byte{byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.RETURN)}
+		gasPrice     = big.NewInt(300 * params.GWei)
+		initialFunds = new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))
+	)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		addrs[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+		alloc[addrs[i]] = GenesisAccount{
+			Balance:   initialFunds,
+			MCBalance: GenesisMultiCoinBalance{assetID: big.NewInt(1000)},
+		}
+	}
+	alloc[counterAddr] = GenesisAccount{Balance: common.Big0, Code: counterCode}
+	alloc[registryAddr] = GenesisAccount{Balance: common.Big0, Code: registryCode}
+	alloc[revertAddr] = GenesisAccount{Balance: common.Big0, Code: revertCode}
+	alloc[suicideAddr] = GenesisAccount{Balance: big.NewInt(params.Ether), Code: suicideCode}
+	gspec := &Genesis{Config: config, Alloc: alloc}
+
+	genDB := rawdb.NewMemoryDatabase()
+	genesis := gspec.MustCommit(genDB)
+	chain, _, err := GenerateChain(config, genesis, dummy.NewFaker(), genDB, 4, 10, func(i int, gen *BlockGen) {
+		addTx := func(key *ecdsa.PrivateKey, to *common.Address, value *big.Int, gas uint64, data []byte) {
+			tx, err := types.SignTx(types.NewTx(&types.LegacyTx{
+				Nonce:    gen.TxNonce(crypto.PubkeyToAddress(key.PublicKey)),
+				To:       to,
+				Value:    value,
+				Gas:      gas,
+				GasPrice: gasPrice,
+				Data:     data,
+			}), signer, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			gen.AddTx(tx)
+		}
+		for j, key := range keys {
+			fresh := common.BigToAddress(big.NewInt(int64(1000*i + j + 1)))
+			switch (i + j) % 8 {
+			case 0: // Independent transfer to a new account
+				addTx(key, &fresh, big.NewInt(1), params.TxGas, nil)
+			case 1: // Transfer to a shared recipient
+				addTx(key, &sharedAddr, big.NewInt(1), params.TxGas, nil)
+			case 2: // Conflicting storage writes
+				addTx(key, &counterAddr, common.Big0, 100_000, nil)
+			case 3: // Independent storage writes
+				addTx(key, &registryAddr, common.Big0, 100_000, nil)
+			case 4: // Multicoin transfer to the next sender through nativeAssetCall
+				next := addrs[(j+1)%len(addrs)]
+				addTx(key, &vm.NativeAssetCallAddr, common.Big0, 100_000, vm.PackNativeAssetCallInput(next, assetID, big.NewInt(10), nil))
+			case 5: // Reverted execution
+				addTx(key, &revertAddr, common.Big0, 100_000, nil)
+			case 6: // Contract creation
+				addTx(key, nil, common.Big0, 100_000, createCode)
+			case 7: // Funding and destructing the same contract
+				addTx(key, &suicideAddr, big.NewInt(1), 100_000, nil)
+			}
+			// A second transaction of the sender depends on its first one
+			if j%3 == 0 {
+				addTx(key, &counterAddr, common.Big0, 100_000, nil)
+			}
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newChain := func(workers int) *BlockChain {
+		db := rawdb.NewMemoryDatabase()
+		gspec.MustCommit(db)
+		cacheConfig := *DefaultCacheConfig
+		cacheConfig.ParallelExecutionWorkers = workers
+		blockchain, err := NewBlockChain(db, &cacheConfig, config, dummy.NewFaker(), vm.Config{}, common.Hash{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The state root, receipt root, bloom and gas used of each block are
+		// verified against the blocks generated by serial execution.
+		if _, err := blockchain.InsertChain(chain); err != nil {
+			t.Fatal(err)
+		}
+		return blockchain
+	}
+	serial := newChain(0)
+	defer serial.Stop()
+	parallel := newChain(4)
+	defer parallel.Stop()
+
+	for _, block := range chain {
+		serialReceipts, err := json.Marshal(serial.GetReceiptsByHash(block.Hash()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		parallelReceipts, err := json.Marshal(parallel.GetReceiptsByHash(block.Hash()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(serialReceipts, parallelReceipts) {
+			t.Fatalf("receipts of block %d differ:\nserial:   %s\nparallel: %s", block.NumberU64(), serialReceipts, parallelReceipts)
+		}
+	}
+}
+
+// TestRecordingStateDBStorageKeys checks that reads of storage slots conflict
+// with writes to the same slot, regardless of how their keys are normalized.
+func TestRecordingStateDBStorageKeys(t *testing.T) {
+	var (
+		addr = common.HexToAddress("0xc0")
+		key  = common.HexToHash("0x01")
+		raw  = common.Hash{0x01} // Addresses a multicoin balance before normalization
+	)
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer := newRecordingStateDB(statedb.Copy(), false)
+	writer.SetState(addr, raw, common.Hash{0x01})
+	written, wiped := make(map[stateKey]struct{}), make(map[common.Address]struct{})
+	writer.mergeWrites(written, wiped)
+
+	for name, read := range map[string]func(*recordingStateDB){
+		"GetState":             func(r *recordingStateDB) { r.GetState(addr, raw) },
+		"GetCommittedState":    func(r *recordingStateDB) { r.GetCommittedState(addr, raw) },
+		"GetCommittedStateAP1": func(r *recordingStateDB) { r.GetCommittedStateAP1(addr, raw) },
+	} {
+		reader := newRecordingStateDB(statedb.Copy(), false)
+		read(reader)
+		if !reader.conflicts(written, wiped) {
+			t.Errorf("%s: read of written slot not detected", name)
+		}
+	}
+	reader := newRecordingStateDB(statedb.Copy(), false)
+	reader.GetState(addr, key)
+	if reader.conflicts(written, wiped) {
+		t.Error("read of other slot reported as conflict")
+	}
+}
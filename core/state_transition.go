@@ -125,7 +125,7 @@ func (result *ExecutionResult) Revert() []byte {
 }
 
 // IntrinsicGas computes the 'intrinsic gas' for a message with the given data.
-func IntrinsicGas(data []byte, accessList types.AccessList, isContractCreation bool, isHomestead, isEIP2028 bool) (uint64, error) {
+func IntrinsicGas(data []byte, accessList types.AccessList, isContractCreation bool, isHomestead, isEIP2028 bool, isEIP3860 bool) (uint64, error) {
 	// Set the starting gas for the raw transaction
 	var gas uint64
 	if isContractCreation && isHomestead {
@@ -157,6 +157,14 @@ func IntrinsicGas(data []byte, accessList types.AccessList, isContractCreation b
 			return 0, ErrGasUintOverflow
 		}
 		gas += z * params.TxDataZeroGas
+
+		if isContractCreation && isEIP3860 {
+			lenWords := toWordSize(uint64(len(data)))
+			if (math.MaxUint64-gas)/params.InitCodeWordGas < lenWords {
+				return 0, ErrGasUintOverflow
+			}
+			gas += lenWords * params.InitCodeWordGas
+		}
 	}
 	if accessList != nil {
 		gas += uint64(len(accessList)) * params.TxAccessListAddressGas
@@ -165,6 +173,15 @@ func IntrinsicGas(data []byte, accessList types.AccessList, isContractCreation b
 	return gas, nil
 }
 
+// toWordSize returns the ceiled word size required for init code payment calculation.
+func toWordSize(size uint64) uint64 {
+	if size > math.MaxUint64-31 {
+		return math.MaxUint64/32 + 1
+	}
+
+	return (size + 31) / 32
+}
+
 // NewStateTransition initialises and returns a new state transition object.
 func NewStateTransition(evm *vm.EVM, msg Message, gp *GasPool) *StateTransition {
 	return &StateTransition{
@@ -244,6 +261,17 @@ func (st *StateTransition) preCheck() error {
 		if st.msg.From() == st.evm.Context.Coinbase {
 			return fmt.Errorf("%w: address %v", vm.ErrNoSenderBlackhole, st.msg.From())
 		}
+		// Make sure the sender is allowed to issue transactions, and to deploy
+		// contracts if this is a contract creation
+		rules := st.evm.Rules()
+		if !vm.IsAllowedToTransact(rules, st.state, st.msg.From()) {
+			return fmt.Errorf("%w: address %v", vm.ErrNotAllowedToTransact, st.msg.From())
+		}
+		if st.msg.To() == nil {
+			if !vm.IsAllowedToDeploy(rules, st.state, st.msg.From()) {
+				return fmt.Errorf("%w: address %v", vm.ErrNotAllowedToDeploy, st.msg.From())
+			}
+		}
 	}
 	// Make sure that transaction gasFeeCap is greater than the baseFee (post london)
 	if st.evm.ChainConfig().IsApricotPhase3(st.evm.Context.Time) {
@@ -302,6 +330,7 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 	}
 	msg := st.msg
 	sender := vm.AccountRef(msg.From())
+	rules := st.evm.Rules()
 	homestead := st.evm.ChainConfig().IsHomestead(st.evm.Context.BlockNumber)
 	istanbul := st.evm.ChainConfig().IsIstanbul(st.evm.Context.BlockNumber)
 	apricotPhase1 := st.evm.ChainConfig().IsApricotPhase1(st.evm.Context.Time)
@@ -309,7 +338,7 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 	contractCreation := msg.To() == nil
 
 	// Check clauses 4-5, subtract intrinsic gas if everything is correct
-	gas, err := IntrinsicGas(st.data, st.msg.AccessList(), contractCreation, homestead, istanbul)
+	gas, err := IntrinsicGas(st.data, st.msg.AccessList(), contractCreation, homestead, istanbul, rules.IsApricotPhase6)
 	if err != nil {
 		return nil, err
 	}
@@ -323,10 +352,19 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 		return nil, fmt.Errorf("%w: address %v", ErrInsufficientFundsForTransfer, msg.From().Hex())
 	}
 
+	// Check whether the init code size has been exceeded.
+	if rules.IsApricotPhase6 && contractCreation && len(st.data) > params.MaxInitCodeSize {
+		return nil, fmt.Errorf("%w: code size %v limit %v", ErrMaxInitCodeSizeExceeded, len(st.data), params.MaxInitCodeSize)
+	}
+
 	// Set up the initial access list.
-	if rules := st.evm.ChainConfig().AxiaRules(st.evm.Context.BlockNumber, st.evm.Context.Time); rules.IsApricotPhase2 {
+	if rules.IsApricotPhase2 {
 		st.state.PrepareAccessList(msg.From(), msg.To(), vm.ActivePrecompiles(rules), msg.AccessList())
 	}
+	// As of Apricot Phase 6, the coinbase is warm (EIP-3651).
+	if rules.IsApricotPhase6 {
+		st.state.AddAddressToAccessList(st.evm.Context.Coinbase)
+	}
 	var (
 		ret   []byte
 		vmerr error // vm errors do not effect consensus and are therefore not assigned to err
@@ -39,6 +39,7 @@ import (
 	"github.com/sankar-boro/axia-network-v2-coreth/consensus/dummy"
 	"github.com/sankar-boro/axia-network-v2-coreth/core/state"
 	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/vm"
 	"github.com/sankar-boro/axia-network-v2-coreth/metrics"
 	"github.com/sankar-boro/axia-network-v2-coreth/params"
 	"github.com/ethereum/go-ethereum/common"
@@ -262,6 +263,8 @@ type TxPool struct {
 	eip2718  bool // Fork indicator whether we are using EIP-2718 type transactions.
 	eip1559  bool // Fork indicator whether we are using EIP-1559 type transactions.
 
+	rules params.Rules // Rules of the block following the current head, as of the timestamp of the current head
+
 	currentHead *types.Header
 	// [currentState] is the state of the blockchain head. It is reset whenever
 	// head changes.
@@ -642,6 +645,11 @@ func (pool *TxPool) checkTxState(from common.Address, tx *types.Transaction) err
 		return fmt.Errorf("%w: address %s have (%d) want (%d)", ErrInsufficientFunds, from.Hex(), balance, cost)
 	}
 
+	// Ensure the sender is allowed to issue transactions
+	if !vm.IsAllowedToTransact(pool.rules, pool.currentState, from) {
+		return fmt.Errorf("%w: address %s", vm.ErrNotAllowedToTransact, from.Hex())
+	}
+
 	txNonce := tx.Nonce()
 	// Ensure the transaction adheres to nonce ordering
 	if currentNonce := pool.currentState.GetNonce(from); currentNonce > txNonce {
@@ -706,8 +714,12 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	}
 	// Transactor should have enough funds to cover the costs
 
+	// Check whether the init code size has been exceeded.
+	if pool.rules.IsApricotPhase6 && tx.To() == nil && len(tx.Data()) > params.MaxInitCodeSize {
+		return fmt.Errorf("%w: code size %v limit %v", ErrMaxInitCodeSizeExceeded, len(tx.Data()), params.MaxInitCodeSize)
+	}
 	// Ensure the transaction has more gas than the basic tx fee.
-	intrGas, err := IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, true, pool.istanbul)
+	intrGas, err := IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, true, pool.istanbul, pool.rules.IsApricotPhase6)
 	if err != nil {
 		return err
 	}
@@ -1257,7 +1269,7 @@ func (pool *TxPool) runReorg(done chan struct{}, reset *txpoolResetRequest, dirt
 	if reset != nil {
 		pool.demoteUnexecutables()
 		if reset.newHead != nil && pool.chainconfig.IsApricotPhase3(new(big.Int).SetUint64(reset.newHead.Time)) {
-			_, baseFeeEstimate, err := dummy.EstimateNextBaseFee(pool.chainconfig, reset.newHead, uint64(time.Now().Unix()))
+			_, baseFeeEstimate, err := dummy.EstimateNextBaseFee(pool.chainconfig, pool.feeConfig(), reset.newHead, uint64(time.Now().Unix()))
 			if err == nil {
 				pool.priced.SetBaseFee(baseFeeEstimate)
 			}
@@ -1394,6 +1406,7 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	timestamp := new(big.Int).SetUint64(newHead.Time)
 	pool.eip2718 = pool.chainconfig.IsApricotPhase2(timestamp)
 	pool.eip1559 = pool.chainconfig.IsApricotPhase3(timestamp)
+	pool.rules = pool.chainconfig.AxiaRules(next, timestamp)
 }
 
 // promoteExecutables moves transactions that have become processable from the
@@ -1700,7 +1713,7 @@ func (pool *TxPool) updateBaseFee() {
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
 
-	_, baseFeeEstimate, err := dummy.EstimateNextBaseFee(pool.chainconfig, pool.currentHead, uint64(time.Now().Unix()))
+	_, baseFeeEstimate, err := dummy.EstimateNextBaseFee(pool.chainconfig, pool.feeConfig(), pool.currentHead, uint64(time.Now().Unix()))
 	if err == nil {
 		pool.priced.SetBaseFee(baseFeeEstimate)
 	} else {
@@ -1708,6 +1721,15 @@ func (pool *TxPool) updateBaseFee() {
 	}
 }
 
+// feeConfig returns the fee config in effect for the block following the
+// current head.
+func (pool *TxPool) feeConfig() params.FeeConfig {
+	pool.currentStateLock.Lock()
+	defer pool.currentStateLock.Unlock()
+
+	return vm.FeeConfigAt(pool.chainconfig, pool.currentHead.Time, pool.currentState)
+}
+
 // addressByHeartbeat is an account address tagged with its last activity timestamp.
 type addressByHeartbeat struct {
 	address   common.Address
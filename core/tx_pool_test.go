@@ -42,6 +42,7 @@ import (
 	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
 	"github.com/sankar-boro/axia-network-v2-coreth/core/state"
 	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/vm"
 	"github.com/sankar-boro/axia-network-v2-coreth/params"
 	"github.com/sankar-boro/axia-network-v2-coreth/trie"
 	"github.com/ethereum/go-ethereum/common"
@@ -352,6 +353,47 @@ func TestInvalidTransactions(t *testing.T) {
 	}
 }
 
+func TestInitCodeIntrinsicGas(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPoolWithConfig(params.TestApricotPhase6Config)
+	defer pool.Stop()
+
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, from, big.NewInt(params.Ether))
+
+	// The init code words are charged as intrinsic gas as of Apricot Phase 6.
+	tx, _ := types.SignTx(types.NewContractCreation(0, big.NewInt(0), 53_000+320*params.TxDataZeroGas, big.NewInt(1), make([]byte, 320)), types.HomesteadSigner{}, key)
+	if err := pool.AddRemote(tx); !errors.Is(err, ErrIntrinsicGas) {
+		t.Error("expected", ErrIntrinsicGas, "got", err)
+	}
+}
+
+func TestTxAllowList(t *testing.T) {
+	t.Parallel()
+
+	config := *params.TestChainConfig
+	config.TxAllowListConfig = &params.TxAllowListConfig{
+		PrecompileUpgrade: params.PrecompileUpgrade{BlockTimestamp: big.NewInt(0)},
+	}
+	pool, key := setupTxPoolWithConfig(&config)
+	defer pool.Stop()
+
+	tx := transaction(0, 100000, key)
+	from, _ := deriveSender(tx)
+	testAddBalance(pool, from, big.NewInt(0xffffffffffffff))
+	if err := pool.AddRemote(tx); !errors.Is(err, vm.ErrNotAllowedToTransact) {
+		t.Error("expected", vm.ErrNotAllowedToTransact, "got", err)
+	}
+
+	pool.mu.Lock()
+	vm.SetAllowListRole(pool.currentState, params.TxAllowListAddress, from, vm.AllowListEnabled)
+	pool.mu.Unlock()
+	if err := pool.AddRemote(tx); err != nil {
+		t.Error("expected", nil, "got", err)
+	}
+}
+
 func TestTransactionQueue(t *testing.T) {
 	t.Parallel()
 
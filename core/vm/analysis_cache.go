@@ -0,0 +1,50 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"github.com/sankar-boro/axia-network-v2-coreth/metrics"
+	"github.com/ethereum/go-ethereum/common"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// jumpdestCacheSize is the maximum number of JUMPDEST analysis results kept in
+// the process-wide cache. Since deployed code is limited to [params.MaxCodeSize]
+// bytes, a single bitmap is at most ~3KB, bounding the cache to ~12MB.
+const jumpdestCacheSize = 4096
+
+var (
+	jumpdestCacheHitMeter  = metrics.NewRegisteredMeter("vm/jumpdest/cache/hit", nil)
+	jumpdestCacheMissMeter = metrics.NewRegisteredMeter("vm/jumpdest/cache/miss", nil)
+
+	// jumpdestCache holds the JUMPDEST analysis of deployed code keyed by code
+	// hash, so that hot contracts are not re-analysed in every transaction.
+	// It is shared by all EVM instances in the process, which includes block
+	// processing, eth_call and tracing.
+	jumpdestCache = newJumpdestAnalysisCache(jumpdestCacheSize)
+)
+
+// jumpdestAnalysisCache is a bounded, thread safe LRU cache of JUMPDEST
+// analysis results keyed by code hash. Cached bitmaps must not be modified.
+type jumpdestAnalysisCache struct {
+	cache *lru.Cache
+}
+
+func newJumpdestAnalysisCache(size int) *jumpdestAnalysisCache {
+	cache, _ := lru.New(size)
+	return &jumpdestAnalysisCache{cache: cache}
+}
+
+// get returns the JUMPDEST analysis of the code with [codeHash], computing and
+// caching it from [code] if it is not present yet.
+func (c *jumpdestAnalysisCache) get(codeHash common.Hash, code []byte) bitvec {
+	if analysis, ok := c.cache.Get(codeHash); ok {
+		jumpdestCacheHitMeter.Mark(1)
+		return analysis.(bitvec)
+	}
+	jumpdestCacheMissMeter.Mark(1)
+	analysis := codeBitmap(code)
+	c.cache.Add(codeHash, analysis)
+	return analysis
+}
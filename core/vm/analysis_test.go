@@ -27,6 +27,7 @@
 package vm
 
 import (
+	"bytes"
 	"math/bits"
 	"testing"
 
@@ -71,6 +72,36 @@ func TestJumpDestAnalysis(t *testing.T) {
 
 const analysisCodeSize = 1200 * 1024
 
+func TestJumpdestAnalysisCache(t *testing.T) {
+	cache := newJumpdestAnalysisCache(2)
+	code := []byte{byte(PUSH1), byte(JUMPDEST), byte(JUMPDEST)}
+	hash := crypto.Keccak256Hash(code)
+
+	analysis := cache.get(hash, code)
+	if !bytes.Equal(analysis, codeBitmap(code)) {
+		t.Fatalf("unexpected analysis %x", analysis)
+	}
+	if analysis.codeSegment(1) || !analysis.codeSegment(2) {
+		t.Fatalf("unexpected code segments in analysis %x", analysis)
+	}
+	// The second lookup must be served from the cache without the code
+	if cached := cache.get(hash, nil); !bytes.Equal(cached, analysis) {
+		t.Fatalf("expected cached analysis %x, got %x", analysis, cached)
+	}
+
+	// Analysing more code than fits in the cache evicts the oldest entry
+	for i := 0; i < 2; i++ {
+		code := []byte{byte(PUSH1), byte(i), byte(JUMPDEST)}
+		cache.get(crypto.Keccak256Hash(code), code)
+	}
+	if cache.cache.Len() != 2 {
+		t.Fatalf("expected cache to be bounded to 2 entries, got %d", cache.cache.Len())
+	}
+	if cache.cache.Contains(hash) {
+		t.Fatal("expected least recently used analysis to be evicted")
+	}
+}
+
 func BenchmarkJumpdestAnalysis_1200k(bench *testing.B) {
 	// 1.4 ms
 	code := make([]byte, analysisCodeSize)
@@ -129,16 +129,26 @@ func init() {
 
 // ActivePrecompiles returns the precompiles enabled with the current configuration.
 func ActivePrecompiles(rules params.Rules) []common.Address {
+	var precompiles []common.Address
 	switch {
 	case rules.IsApricotPhase2:
-		return PrecompiledAddressesApricotPhase2
+		precompiles = PrecompiledAddressesApricotPhase2
 	case rules.IsIstanbul:
-		return PrecompiledAddressesIstanbul
+		precompiles = PrecompiledAddressesIstanbul
 	case rules.IsByzantium:
-		return PrecompiledAddressesByzantium
+		precompiles = PrecompiledAddressesByzantium
 	default:
-		return PrecompiledAddressesHomestead
+		precompiles = PrecompiledAddressesHomestead
 	}
+	if len(rules.PrecompileAddresses) == 0 {
+		return precompiles
+	}
+
+	// Add the addresses of the active stateful precompile modules without
+	// modifying the shared default address lists.
+	active := make([]common.Address, len(precompiles), len(precompiles)+len(rules.PrecompileAddresses))
+	copy(active, precompiles)
+	return append(active, rules.PrecompileAddresses...)
 }
 
 // RunPrecompiledContract runs and evaluates the output of a precompiled contract.
@@ -0,0 +1,269 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
+	"github.com/sankar-boro/axia-network-v2-coreth/params"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// AllowListRole is the role of an address in an allow list precompile.
+type AllowListRole uint64
+
+const (
+	AllowListNoRole  AllowListRole = iota // Address is not allowed
+	AllowListEnabled                      // Address is allowed
+	AllowListAdmin                        // Address is allowed and can modify the allow list
+)
+
+// IsEnabled returns true if [r] allows the address.
+func (r AllowListRole) IsEnabled() bool {
+	return r == AllowListEnabled || r == AllowListAdmin
+}
+
+// IsAdmin returns true if [r] allows the address to modify the allow list.
+func (r AllowListRole) IsAdmin() bool {
+	return r == AllowListAdmin
+}
+
+// String implements the fmt.Stringer interface.
+func (r AllowListRole) String() string {
+	switch r {
+	case AllowListNoRole:
+		return "none"
+	case AllowListEnabled:
+		return "enabled"
+	case AllowListAdmin:
+		return "admin"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint64(r))
+	}
+}
+
+// Function selectors of the allow list interface, see
+// accounts/abi/bind/allowlist/IAllowList.sol.
+var (
+	setAdminSignature      = functionSelector("setAdmin(address)")
+	setEnabledSignature    = functionSelector("setEnabled(address)")
+	setNoneSignature       = functionSelector("setNone(address)")
+	readAllowListSignature = functionSelector("readAllowList(address)")
+)
+
+// Topic of the RoleSet(uint256 indexed role, address indexed account,
+// address indexed sender, uint256 oldRole) event that is emitted whenever an
+// admin modifies the allow list.
+var allowListRoleSetTopic = crypto.Keccak256Hash([]byte("RoleSet(uint256,address,address,uint256)"))
+
+// allowListModifyGas is the gas charged for modifying a role, including the
+// cost of the RoleSet event.
+const allowListModifyGas = params.AllowListModifyGas + params.LogGas + 4*params.LogTopicGas + common.HashLength*params.LogDataGas
+
+// functionSelector returns the ABI selector of the function with [signature].
+func functionSelector(signature string) [4]byte {
+	var selector [4]byte
+	copy(selector[:], crypto.Keccak256([]byte(signature)))
+	return selector
+}
+
+// GetAllowListRole returns the role of [address] in the allow list precompile
+// at [precompileAddr].
+func GetAllowListRole(state StateDB, precompileAddr, address common.Address) AllowListRole {
+	role := state.GetState(precompileAddr, address.Hash())
+	return AllowListRole(role.Big().Uint64())
+}
+
+// SetAllowListRole sets the role of [address] in the allow list precompile at
+// [precompileAddr] to [role].
+func SetAllowListRole(state StateDB, precompileAddr, address common.Address, role AllowListRole) {
+	state.SetState(precompileAddr, address.Hash(), common.BigToHash(new(big.Int).SetUint64(uint64(role))))
+}
+
+// PackAllowListInput packs the arguments into the required input data for a
+// call to the allow list function with [selector].
+func PackAllowListInput(selector [4]byte, address common.Address) []byte {
+	input := make([]byte, 4+common.HashLength)
+	copy(input, selector[:])
+	copy(input[4:], address.Hash().Bytes())
+	return input
+}
+
+// unpackAllowListInput attempts to unpack [input] into the selector and the
+// address argument of a call to an allow list precompile.
+func unpackAllowListInput(input []byte) ([4]byte, common.Address, error) {
+	var selector [4]byte
+	if len(input) != 4+common.HashLength {
+		return selector, common.Address{}, fmt.Errorf("allow list input had unexpected length %d", len(input))
+	}
+	copy(selector[:], input)
+	arg := common.BytesToHash(input[4:])
+	address := common.BytesToAddress(arg.Bytes())
+	if address.Hash() != arg {
+		return selector, common.Address{}, fmt.Errorf("allow list input has invalid address argument %s", arg)
+	}
+	return selector, address, nil
+}
+
+// configureAllowList assigns the roles of [config] in the allow list
+// precompile at [precompileAddr].
+func configureAllowList(state StateDB, precompileAddr common.Address, config params.AllowListConfig) {
+	for _, address := range config.EnabledAddresses {
+		SetAllowListRole(state, precompileAddr, address, AllowListEnabled)
+	}
+	for _, address := range config.AdminAddresses {
+		SetAllowListRole(state, precompileAddr, address, AllowListAdmin)
+	}
+}
+
+// allowList is a precompiled contract that stores the role of addresses.
+// Admins can change the role of any address, and anyone can read roles.
+//
+// If [emitRoleSet] is set, every role change emits a RoleSet event, whose cost
+// is charged along with the role change. The contract deployer allow list was
+// activated before the event was introduced, so it does not emit it to keep
+// the gas cost and logs of its transactions unchanged.
+type allowList struct {
+	emitRoleSet bool
+}
+
+// modifyGas returns the gas charged for modifying a role.
+func (a allowList) modifyGas() uint64 {
+	if a.emitRoleSet {
+		return allowListModifyGas
+	}
+	return params.AllowListModifyGas
+}
+
+// Run implements StatefulPrecompiledContract
+func (a allowList) Run(evm *EVM, caller ContractRef, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	selector, address, err := unpackAllowListInput(input)
+	if err != nil {
+		return nil, suppliedGas, ErrExecutionReverted
+	}
+
+	var role AllowListRole
+	switch selector {
+	case readAllowListSignature:
+		if suppliedGas < params.AllowListReadGas {
+			return nil, 0, ErrOutOfGas
+		}
+		current := GetAllowListRole(evm.StateDB, addr, address)
+		return common.BigToHash(new(big.Int).SetUint64(uint64(current))).Bytes(), suppliedGas - params.AllowListReadGas, nil
+	case setAdminSignature:
+		role = AllowListAdmin
+	case setEnabledSignature:
+		role = AllowListEnabled
+	case setNoneSignature:
+		role = AllowListNoRole
+	default:
+		return nil, suppliedGas, ErrExecutionReverted
+	}
+
+	modifyGas := a.modifyGas()
+	if suppliedGas < modifyGas {
+		return nil, 0, ErrOutOfGas
+	}
+	remainingGas = suppliedGas - modifyGas
+	if readOnly {
+		return nil, remainingGas, ErrWriteProtection
+	}
+	if !GetAllowListRole(evm.StateDB, addr, caller.Address()).IsAdmin() {
+		return nil, remainingGas, fmt.Errorf("%w: %s", ErrNotAllowListAdmin, caller.Address())
+	}
+	oldRole := GetAllowListRole(evm.StateDB, addr, address)
+	SetAllowListRole(evm.StateDB, addr, address, role)
+	if !a.emitRoleSet {
+		return nil, remainingGas, nil
+	}
+	evm.StateDB.AddLog(&types.Log{
+		Address: addr,
+		Topics: []common.Hash{
+			allowListRoleSetTopic,
+			common.BigToHash(new(big.Int).SetUint64(uint64(role))),
+			address.Hash(),
+			caller.Address().Hash(),
+		},
+		Data:        common.BigToHash(new(big.Int).SetUint64(uint64(oldRole))).Bytes(),
+		BlockNumber: evm.Context.BlockNumber.Uint64(),
+	})
+	return nil, remainingGas, nil
+}
+
+// contractDeployerAllowList is the stateful precompile module restricting
+// which transaction origins may deploy contracts.
+type contractDeployerAllowList struct{}
+
+func init() {
+	RegisterStatefulPrecompileModule(contractDeployerAllowList{})
+}
+
+// Address implements StatefulPrecompileModule
+func (contractDeployerAllowList) Address() common.Address {
+	return params.ContractDeployerAllowListAddress
+}
+
+// Contract implements StatefulPrecompileModule
+func (contractDeployerAllowList) Contract() StatefulPrecompiledContract {
+	return allowList{}
+}
+
+// Configure implements StatefulPrecompileModule
+func (contractDeployerAllowList) Configure(chainConfig *params.ChainConfig, config params.StatefulPrecompileConfig, state StateDB, blockContext BlockContext) error {
+	deployerConfig, ok := config.(*params.ContractDeployerAllowListConfig)
+	if !ok {
+		return fmt.Errorf("unexpected config type %T for contract deployer allow list", config)
+	}
+	configureAllowList(state, params.ContractDeployerAllowListAddress, deployerConfig.AllowListConfig)
+	return nil
+}
+
+// IsAllowedToDeploy returns true if [origin] may deploy contracts under
+// [rules].
+func IsAllowedToDeploy(rules params.Rules, state StateDB, origin common.Address) bool {
+	if !rules.IsStatefulPrecompileEnabled(params.ContractDeployerAllowListAddress) {
+		return true
+	}
+	return GetAllowListRole(state, params.ContractDeployerAllowListAddress, origin).IsEnabled()
+}
+
+// txAllowList is the stateful precompile module restricting which addresses
+// may issue transactions.
+type txAllowList struct{}
+
+func init() {
+	RegisterStatefulPrecompileModule(txAllowList{})
+}
+
+// Address implements StatefulPrecompileModule
+func (txAllowList) Address() common.Address {
+	return params.TxAllowListAddress
+}
+
+// Contract implements StatefulPrecompileModule
+func (txAllowList) Contract() StatefulPrecompiledContract {
+	return allowList{emitRoleSet: true}
+}
+
+// Configure implements StatefulPrecompileModule
+func (txAllowList) Configure(chainConfig *params.ChainConfig, config params.StatefulPrecompileConfig, state StateDB, blockContext BlockContext) error {
+	txConfig, ok := config.(*params.TxAllowListConfig)
+	if !ok {
+		return fmt.Errorf("unexpected config type %T for tx allow list", config)
+	}
+	configureAllowList(state, params.TxAllowListAddress, txConfig.AllowListConfig)
+	return nil
+}
+
+// IsAllowedToTransact returns true if [sender] may issue transactions under
+// [rules].
+func IsAllowedToTransact(rules params.Rules, state StateDB, sender common.Address) bool {
+	if !rules.IsStatefulPrecompileEnabled(params.TxAllowListAddress) {
+		return true
+	}
+	return GetAllowListRole(state, params.TxAllowListAddress, sender).IsEnabled()
+}
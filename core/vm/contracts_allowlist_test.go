@@ -0,0 +1,199 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/state"
+	"github.com/sankar-boro/axia-network-v2-coreth/params"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func newAllowListTestEVM(t *testing.T, origin common.Address) *EVM {
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := *params.TestChainConfig
+	allowListConfig := params.AllowListConfig{
+		AdminAddresses: []common.Address{common.BytesToAddress([]byte("admin"))},
+	}
+	config.ContractDeployerAllowListConfig = &params.ContractDeployerAllowListConfig{
+		PrecompileUpgrade: params.PrecompileUpgrade{BlockTimestamp: big.NewInt(0)},
+		AllowListConfig:   allowListConfig,
+	}
+	config.TxAllowListConfig = &params.TxAllowListConfig{
+		PrecompileUpgrade: params.PrecompileUpgrade{BlockTimestamp: big.NewInt(0)},
+		AllowListConfig:   allowListConfig,
+	}
+	blockContext := BlockContext{
+		BlockNumber: big.NewInt(0),
+		Time:        big.NewInt(0),
+		CanTransfer: CanTransfer,
+		Transfer:    Transfer,
+	}
+	if err := ConfigureStatefulPrecompiles(&config, nil, blockContext, statedb); err != nil {
+		t.Fatal(err)
+	}
+	return NewEVM(blockContext, TxContext{Origin: origin}, statedb, &config, Config{})
+}
+
+func TestAllowListPrecompile(t *testing.T) {
+	adminAddr := common.BytesToAddress([]byte("admin"))
+	userAddr := common.BytesToAddress([]byte("user"))
+	precompileAddr := params.TxAllowListAddress
+
+	tests := map[string]struct {
+		caller       common.Address
+		input        []byte
+		suppliedGas  uint64
+		readOnly     bool
+		expectedErr  error
+		expectedRet  []byte
+		expectedRole AllowListRole
+	}{
+		"admin sets enabled": {
+			caller:       adminAddr,
+			input:        PackAllowListInput(setEnabledSignature, userAddr),
+			suppliedGas:  allowListModifyGas,
+			expectedRole: AllowListEnabled,
+		},
+		"admin sets admin": {
+			caller:       adminAddr,
+			input:        PackAllowListInput(setAdminSignature, userAddr),
+			suppliedGas:  allowListModifyGas,
+			expectedRole: AllowListAdmin,
+		},
+		"non-admin cannot modify": {
+			caller:       userAddr,
+			input:        PackAllowListInput(setEnabledSignature, userAddr),
+			suppliedGas:  allowListModifyGas,
+			expectedErr:  ErrNotAllowListAdmin,
+			expectedRole: AllowListNoRole,
+		},
+		"modify in read only mode": {
+			caller:       adminAddr,
+			input:        PackAllowListInput(setEnabledSignature, userAddr),
+			suppliedGas:  allowListModifyGas,
+			readOnly:     true,
+			expectedErr:  ErrWriteProtection,
+			expectedRole: AllowListNoRole,
+		},
+		"modify out of gas": {
+			caller:       adminAddr,
+			input:        PackAllowListInput(setEnabledSignature, userAddr),
+			suppliedGas:  allowListModifyGas - 1,
+			expectedErr:  ErrOutOfGas,
+			expectedRole: AllowListNoRole,
+		},
+		"read admin role": {
+			caller:       userAddr,
+			input:        PackAllowListInput(readAllowListSignature, adminAddr),
+			suppliedGas:  params.AllowListReadGas,
+			readOnly:     true,
+			expectedRet:  common.BigToHash(big.NewInt(int64(AllowListAdmin))).Bytes(),
+			expectedRole: AllowListNoRole,
+		},
+		"invalid input": {
+			caller:       adminAddr,
+			input:        PackAllowListInput(setEnabledSignature, userAddr)[:10],
+			suppliedGas:  allowListModifyGas,
+			expectedErr:  ErrExecutionReverted,
+			expectedRole: AllowListNoRole,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			evm := newAllowListTestEVM(t, test.caller)
+			ret, _, err := allowList{emitRoleSet: true}.Run(evm, AccountRef(test.caller), precompileAddr, test.input, test.suppliedGas, test.readOnly)
+			if !errors.Is(err, test.expectedErr) {
+				t.Fatalf("expected error %v, got %v", test.expectedErr, err)
+			}
+			assert.Equal(t, test.expectedRet, ret)
+			assert.Equal(t, test.expectedRole, GetAllowListRole(evm.StateDB, precompileAddr, userAddr))
+			assert.Equal(t, AllowListAdmin, GetAllowListRole(evm.StateDB, precompileAddr, adminAddr))
+
+			// Every successful role change emits a RoleSet event.
+			logs := evm.StateDB.(*state.StateDB).Logs()
+			if err != nil || len(ret) > 0 {
+				assert.Empty(t, logs)
+				return
+			}
+			if assert.Len(t, logs, 1) {
+				assert.Equal(t, precompileAddr, logs[0].Address)
+				assert.Equal(t, []common.Hash{
+					allowListRoleSetTopic,
+					common.BigToHash(big.NewInt(int64(test.expectedRole))),
+					userAddr.Hash(),
+					adminAddr.Hash(),
+				}, logs[0].Topics)
+				assert.Equal(t, common.BigToHash(big.NewInt(int64(AllowListNoRole))).Bytes(), logs[0].Data)
+			}
+		})
+	}
+}
+
+func TestContractDeployerAllowList(t *testing.T) {
+	adminAddr := common.BytesToAddress([]byte("admin"))
+	userAddr := common.BytesToAddress([]byte("user"))
+	// PUSH1 0x00 PUSH1 0x00 RETURN
+	initCode := []byte{byte(PUSH1), 0x00, byte(PUSH1), 0x00, byte(RETURN)}
+
+	evm := newAllowListTestEVM(t, userAddr)
+	_, _, gas, err := evm.Create(AccountRef(userAddr), initCode, 100000, big.NewInt(0))
+	assert.ErrorIs(t, err, ErrNotAllowedToDeploy)
+	assert.Equal(t, uint64(100000), gas, "gas should not be consumed")
+
+	// Enable the user through the precompile and try again.
+	_, _, err = evm.Call(AccountRef(adminAddr), params.ContractDeployerAllowListAddress, PackAllowListInput(setEnabledSignature, userAddr), 100000, big.NewInt(0))
+	assert.NoError(t, err)
+	_, _, _, err = evm.Create(AccountRef(userAddr), initCode, 100000, big.NewInt(0))
+	assert.NoError(t, err)
+}
+
+// Tests that modifying the contract deployer allow list costs the same gas and
+// emits no logs, as before the RoleSet event was introduced.
+func TestContractDeployerAllowListGasAndLogs(t *testing.T) {
+	adminAddr := common.BytesToAddress([]byte("admin"))
+	userAddr := common.BytesToAddress([]byte("user"))
+
+	evm := newAllowListTestEVM(t, adminAddr)
+	_, remainingGas, err := evm.Call(AccountRef(adminAddr), params.ContractDeployerAllowListAddress, PackAllowListInput(setEnabledSignature, userAddr), 100000, big.NewInt(0))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(100000)-params.AllowListModifyGas, remainingGas)
+	assert.Empty(t, evm.StateDB.(*state.StateDB).Logs())
+	assert.Equal(t, AllowListEnabled, GetAllowListRole(evm.StateDB, params.ContractDeployerAllowListAddress, userAddr))
+
+	// The tx allow list charges for and emits the RoleSet event
+	_, remainingGas, err = evm.Call(AccountRef(adminAddr), params.TxAllowListAddress, PackAllowListInput(setEnabledSignature, userAddr), 100000, big.NewInt(0))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(100000)-allowListModifyGas, remainingGas)
+	assert.Len(t, evm.StateDB.(*state.StateDB).Logs(), 1)
+}
+
+func TestTxAllowList(t *testing.T) {
+	adminAddr := common.BytesToAddress([]byte("admin"))
+	userAddr := common.BytesToAddress([]byte("user"))
+
+	evm := newAllowListTestEVM(t, adminAddr)
+	assert.True(t, IsAllowedToTransact(evm.chainRules, evm.StateDB, adminAddr))
+	assert.False(t, IsAllowedToTransact(evm.chainRules, evm.StateDB, userAddr))
+
+	_, _, err := evm.Call(AccountRef(adminAddr), params.TxAllowListAddress, PackAllowListInput(setEnabledSignature, userAddr), 100000, big.NewInt(0))
+	assert.NoError(t, err)
+	assert.True(t, IsAllowedToTransact(evm.chainRules, evm.StateDB, userAddr))
+	assert.False(t, IsAllowedToDeploy(evm.chainRules, evm.StateDB, userAddr), "allow lists should be independent")
+
+	_, _, err = evm.Call(AccountRef(adminAddr), params.TxAllowListAddress, PackAllowListInput(setNoneSignature, userAddr), 100000, big.NewInt(0))
+	assert.NoError(t, err)
+	assert.False(t, IsAllowedToTransact(evm.chainRules, evm.StateDB, userAddr))
+
+	// Without an active tx allow list every sender is allowed.
+	assert.True(t, IsAllowedToTransact(params.TestChainConfig.AxiaRules(common.Big0, common.Big0), evm.StateDB, userAddr))
+}
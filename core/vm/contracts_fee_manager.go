@@ -0,0 +1,249 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
+	"github.com/sankar-boro/axia-network-v2-coreth/params"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// feeConfigNumFields is the number of words used to encode a fee config in the
+// input and output of the fee config manager and in its storage.
+const feeConfigNumFields = 7
+
+// Function selectors of the fee config manager interface in addition to the
+// allow list interface, see accounts/abi/bind/feemanager/IFeeConfigManager.sol.
+var (
+	setFeeConfigSignature              = functionSelector("setFeeConfig(uint256,uint256,uint256,uint256,uint256,uint256,uint256)")
+	getFeeConfigSignature              = functionSelector("getFeeConfig()")
+	getFeeConfigLastChangedAtSignature = functionSelector("getFeeConfigLastChangedAt()")
+)
+
+var (
+	// Topic of the FeeConfigChanged(address indexed sender, ...) event that
+	// is emitted with the new fee config whenever it is changed.
+	feeConfigChangedTopic = crypto.Keccak256Hash([]byte("FeeConfigChanged(address,uint256,uint256,uint256,uint256,uint256,uint256,uint256)"))
+
+	// Storage keys of the fee config manager. The fee config is stored in
+	// [feeConfigNumFields] consecutive slots starting at [feeConfigKey]. The
+	// keys cannot collide with the keys of the allow list roles, which are
+	// addresses.
+	feeConfigKey              = crypto.Keccak256Hash([]byte("feeConfig")).Big()
+	feeConfigLastChangedAtKey = crypto.Keccak256Hash([]byte("feeConfigLastChangedAt"))
+)
+
+const (
+	getFeeConfigGas              = feeConfigNumFields * params.FeeConfigManagerReadGas
+	getFeeConfigLastChangedAtGas = params.FeeConfigManagerReadGas
+	// setFeeConfigGas includes the cost of the FeeConfigChanged event.
+	setFeeConfigGas = (feeConfigNumFields+1)*params.FeeConfigManagerWriteGas + params.LogGas + 2*params.LogTopicGas + feeConfigNumFields*common.HashLength*params.LogDataGas
+)
+
+// feeConfigWords returns the encoding of [feeConfig] as a sequence of words.
+func feeConfigWords(feeConfig params.FeeConfig) []common.Hash {
+	return []common.Hash{
+		common.BigToHash(feeConfig.TargetGas),
+		common.BigToHash(feeConfig.MinBaseFee),
+		common.BigToHash(feeConfig.BaseFeeChangeDenominator),
+		common.BigToHash(new(big.Int).SetUint64(feeConfig.TargetBlockRate)),
+		common.BigToHash(feeConfig.MinBlockGasCost),
+		common.BigToHash(feeConfig.MaxBlockGasCost),
+		common.BigToHash(feeConfig.BlockGasCostStep),
+	}
+}
+
+// feeConfigFromWords decodes a fee config encoded by feeConfigWords. The
+// result is not verified.
+func feeConfigFromWords(words []common.Hash) (params.FeeConfig, error) {
+	targetBlockRate := words[3].Big()
+	if !targetBlockRate.IsUint64() {
+		return params.FeeConfig{}, fmt.Errorf("fee config targetBlockRate (%d) is not a uint64", targetBlockRate)
+	}
+	return params.FeeConfig{
+		TargetGas:                words[0].Big(),
+		MinBaseFee:               words[1].Big(),
+		BaseFeeChangeDenominator: words[2].Big(),
+		TargetBlockRate:          targetBlockRate.Uint64(),
+		MinBlockGasCost:          words[4].Big(),
+		MaxBlockGasCost:          words[5].Big(),
+		BlockGasCostStep:         words[6].Big(),
+	}, nil
+}
+
+func feeConfigSlot(i int) common.Hash {
+	return common.BigToHash(new(big.Int).Add(feeConfigKey, big.NewInt(int64(i))))
+}
+
+// PackSetFeeConfigInput packs [feeConfig] into the required input data for a
+// call to setFeeConfig of the fee config manager.
+func PackSetFeeConfigInput(feeConfig params.FeeConfig) []byte {
+	input := make([]byte, 0, 4+feeConfigNumFields*common.HashLength)
+	input = append(input, setFeeConfigSignature[:]...)
+	for _, word := range feeConfigWords(feeConfig) {
+		input = append(input, word.Bytes()...)
+	}
+	return input
+}
+
+// unpackSetFeeConfigInput unpacks the arguments of a call to setFeeConfig,
+// excluding the selector, and verifies the resulting fee config.
+func unpackSetFeeConfigInput(input []byte) (params.FeeConfig, error) {
+	if len(input) != feeConfigNumFields*common.HashLength {
+		return params.FeeConfig{}, fmt.Errorf("fee config input had unexpected length %d", len(input))
+	}
+	words := make([]common.Hash, feeConfigNumFields)
+	for i := range words {
+		words[i] = common.BytesToHash(input[i*common.HashLength : (i+1)*common.HashLength])
+	}
+	feeConfig, err := feeConfigFromWords(words)
+	if err != nil {
+		return params.FeeConfig{}, err
+	}
+	if err := feeConfig.Verify(); err != nil {
+		return params.FeeConfig{}, err
+	}
+	return feeConfig, nil
+}
+
+// GetStoredFeeConfig returns the fee config stored in the fee config manager
+// precompile in [state].
+func GetStoredFeeConfig(state StateDB) params.FeeConfig {
+	words := make([]common.Hash, feeConfigNumFields)
+	for i := range words {
+		words[i] = state.GetState(params.FeeConfigManagerAddress, feeConfigSlot(i))
+	}
+	// The fee config is verified before it is stored, so it always decodes.
+	feeConfig, _ := feeConfigFromWords(words)
+	return feeConfig
+}
+
+// GetFeeConfigLastChangedAt returns the number of the block in which the fee
+// config stored in [state] was last changed.
+func GetFeeConfigLastChangedAt(state StateDB) *big.Int {
+	return state.GetState(params.FeeConfigManagerAddress, feeConfigLastChangedAtKey).Big()
+}
+
+// storeFeeConfig stores [feeConfig] in the fee config manager precompile in
+// [state] and records [blockNumber] as the block of the change.
+func storeFeeConfig(state StateDB, feeConfig params.FeeConfig, blockNumber *big.Int) {
+	for i, word := range feeConfigWords(feeConfig) {
+		state.SetState(params.FeeConfigManagerAddress, feeConfigSlot(i), word)
+	}
+	state.SetState(params.FeeConfigManagerAddress, feeConfigLastChangedAtKey, common.BigToHash(blockNumber))
+}
+
+// FeeConfigAt returns the fee config in effect for the child of the block
+// with [parentTimestamp], where [parentState] is the state after that block.
+// The fee config only changes from the block following a change, so that it
+// is known before the block is executed.
+func FeeConfigAt(config *params.ChainConfig, parentTimestamp uint64, parentState StateDB) params.FeeConfig {
+	if !config.IsStatefulPrecompileEnabled(params.FeeConfigManagerAddress, new(big.Int).SetUint64(parentTimestamp)) {
+		return config.GetFeeConfig()
+	}
+	return GetStoredFeeConfig(parentState)
+}
+
+// feeConfigManager is a precompiled contract that stores the fee config of the
+// chain. Enabled addresses can change the fee config and admins can modify
+// the allow list of the precompile.
+type feeConfigManager struct{}
+
+// Run implements StatefulPrecompiledContract
+func (feeConfigManager) Run(evm *EVM, caller ContractRef, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if len(input) < 4 {
+		return nil, suppliedGas, ErrExecutionReverted
+	}
+	var selector [4]byte
+	copy(selector[:], input)
+
+	switch selector {
+	case getFeeConfigSignature:
+		if suppliedGas < getFeeConfigGas {
+			return nil, 0, ErrOutOfGas
+		}
+		ret = make([]byte, 0, feeConfigNumFields*common.HashLength)
+		for _, word := range feeConfigWords(GetStoredFeeConfig(evm.StateDB)) {
+			ret = append(ret, word.Bytes()...)
+		}
+		return ret, suppliedGas - getFeeConfigGas, nil
+	case getFeeConfigLastChangedAtSignature:
+		if suppliedGas < getFeeConfigLastChangedAtGas {
+			return nil, 0, ErrOutOfGas
+		}
+		return common.BigToHash(GetFeeConfigLastChangedAt(evm.StateDB)).Bytes(), suppliedGas - getFeeConfigLastChangedAtGas, nil
+	case setFeeConfigSignature:
+	default:
+		return allowList{emitRoleSet: true}.Run(evm, caller, addr, input, suppliedGas, readOnly)
+	}
+
+	if suppliedGas < setFeeConfigGas {
+		return nil, 0, ErrOutOfGas
+	}
+	remainingGas = suppliedGas - setFeeConfigGas
+	if readOnly {
+		return nil, remainingGas, ErrWriteProtection
+	}
+	if !GetAllowListRole(evm.StateDB, addr, caller.Address()).IsEnabled() {
+		return nil, remainingGas, fmt.Errorf("%w: %s", ErrNotAllowedToSetFeeConfig, caller.Address())
+	}
+	feeConfig, err := unpackSetFeeConfigInput(input[4:])
+	if err != nil {
+		return nil, remainingGas, fmt.Errorf("invalid fee config: %w", err)
+	}
+	storeFeeConfig(evm.StateDB, feeConfig, evm.Context.BlockNumber)
+
+	data := make([]byte, 0, feeConfigNumFields*common.HashLength)
+	for _, word := range feeConfigWords(feeConfig) {
+		data = append(data, word.Bytes()...)
+	}
+	evm.StateDB.AddLog(&types.Log{
+		Address:     addr,
+		Topics:      []common.Hash{feeConfigChangedTopic, caller.Address().Hash()},
+		Data:        data,
+		BlockNumber: evm.Context.BlockNumber.Uint64(),
+	})
+	return nil, remainingGas, nil
+}
+
+// feeConfigManagerModule is the stateful precompile module that allows
+// changing the fee config of the chain.
+type feeConfigManagerModule struct{}
+
+func init() {
+	RegisterStatefulPrecompileModule(feeConfigManagerModule{})
+}
+
+// Address implements StatefulPrecompileModule
+func (feeConfigManagerModule) Address() common.Address {
+	return params.FeeConfigManagerAddress
+}
+
+// Contract implements StatefulPrecompileModule
+func (feeConfigManagerModule) Contract() StatefulPrecompiledContract {
+	return feeConfigManager{}
+}
+
+// Configure implements StatefulPrecompileModule
+func (feeConfigManagerModule) Configure(chainConfig *params.ChainConfig, config params.StatefulPrecompileConfig, state StateDB, blockContext BlockContext) error {
+	managerConfig, ok := config.(*params.FeeConfigManagerConfig)
+	if !ok {
+		return fmt.Errorf("unexpected config type %T for fee config manager", config)
+	}
+	configureAllowList(state, params.FeeConfigManagerAddress, managerConfig.AllowListConfig)
+
+	feeConfig := chainConfig.GetFeeConfig()
+	if managerConfig.InitialFeeConfig != nil {
+		feeConfig = *managerConfig.InitialFeeConfig
+	}
+	if err := feeConfig.Verify(); err != nil {
+		return err
+	}
+	storeFeeConfig(state, feeConfig, blockContext.BlockNumber)
+	return nil
+}
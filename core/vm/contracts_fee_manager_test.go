@@ -0,0 +1,180 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/state"
+	"github.com/sankar-boro/axia-network-v2-coreth/params"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+var testFeeConfig = params.FeeConfig{
+	TargetGas:                big.NewInt(20_000_000),
+	MinBaseFee:               big.NewInt(50 * params.GWei),
+	BaseFeeChangeDenominator: big.NewInt(48),
+	TargetBlockRate:          3,
+	MinBlockGasCost:          big.NewInt(0),
+	MaxBlockGasCost:          big.NewInt(2_000_000),
+	BlockGasCostStep:         big.NewInt(100_000),
+}
+
+func newFeeConfigManagerTestEVM(t *testing.T, initialFeeConfig *params.FeeConfig) *EVM {
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := *params.TestChainConfig
+	config.FeeConfigManagerConfig = &params.FeeConfigManagerConfig{
+		PrecompileUpgrade: params.PrecompileUpgrade{BlockTimestamp: big.NewInt(0)},
+		AllowListConfig: params.AllowListConfig{
+			AdminAddresses: []common.Address{common.BytesToAddress([]byte("admin"))},
+		},
+		InitialFeeConfig: initialFeeConfig,
+	}
+	blockContext := BlockContext{
+		BlockNumber: big.NewInt(0),
+		Time:        big.NewInt(0),
+		CanTransfer: CanTransfer,
+		Transfer:    Transfer,
+	}
+	if err := ConfigureStatefulPrecompiles(&config, nil, blockContext, statedb); err != nil {
+		t.Fatal(err)
+	}
+	blockContext.BlockNumber = big.NewInt(5)
+	return NewEVM(blockContext, TxContext{}, statedb, &config, Config{})
+}
+
+func TestFeeConfigManagerConfigure(t *testing.T) {
+	evm := newFeeConfigManagerTestEVM(t, nil)
+	defaultFeeConfig := GetStoredFeeConfig(evm.StateDB)
+	assert.True(t, defaultFeeConfig.Equal(&params.DefaultFeeConfig))
+	assert.Zero(t, GetFeeConfigLastChangedAt(evm.StateDB).Sign())
+
+	evm = newFeeConfigManagerTestEVM(t, &testFeeConfig)
+	storedFeeConfig := GetStoredFeeConfig(evm.StateDB)
+	assert.True(t, storedFeeConfig.Equal(&testFeeConfig))
+
+	// The stored fee config is only used once the precompile is enabled.
+	assert.Equal(t, params.DefaultFeeConfig, FeeConfigAt(params.TestChainConfig, 0, evm.StateDB))
+	assert.Equal(t, storedFeeConfig, FeeConfigAt(evm.chainConfig, 0, evm.StateDB))
+}
+
+func TestFeeConfigManagerPrecompile(t *testing.T) {
+	adminAddr := common.BytesToAddress([]byte("admin"))
+	userAddr := common.BytesToAddress([]byte("user"))
+	precompileAddr := params.FeeConfigManagerAddress
+
+	invalidFeeConfig := testFeeConfig
+	invalidFeeConfig.TargetBlockRate = 0
+
+	tests := map[string]struct {
+		caller            common.Address
+		input             []byte
+		suppliedGas       uint64
+		readOnly          bool
+		expectedErr       error
+		expectedFeeConfig params.FeeConfig
+	}{
+		"enabled address sets fee config": {
+			caller:            adminAddr,
+			input:             PackSetFeeConfigInput(testFeeConfig),
+			suppliedGas:       setFeeConfigGas,
+			expectedFeeConfig: testFeeConfig,
+		},
+		"address without role cannot set fee config": {
+			caller:            userAddr,
+			input:             PackSetFeeConfigInput(testFeeConfig),
+			suppliedGas:       setFeeConfigGas,
+			expectedErr:       ErrNotAllowedToSetFeeConfig,
+			expectedFeeConfig: params.DefaultFeeConfig,
+		},
+		"set fee config in read only mode": {
+			caller:            adminAddr,
+			input:             PackSetFeeConfigInput(testFeeConfig),
+			suppliedGas:       setFeeConfigGas,
+			readOnly:          true,
+			expectedErr:       ErrWriteProtection,
+			expectedFeeConfig: params.DefaultFeeConfig,
+		},
+		"set fee config out of gas": {
+			caller:            adminAddr,
+			input:             PackSetFeeConfigInput(testFeeConfig),
+			suppliedGas:       setFeeConfigGas - 1,
+			expectedErr:       ErrOutOfGas,
+			expectedFeeConfig: params.DefaultFeeConfig,
+		},
+		"set invalid fee config": {
+			caller:            adminAddr,
+			input:             PackSetFeeConfigInput(invalidFeeConfig),
+			suppliedGas:       setFeeConfigGas,
+			expectedErr:       errors.New("fee config targetBlockRate must be positive"),
+			expectedFeeConfig: params.DefaultFeeConfig,
+		},
+		"set fee config with short input": {
+			caller:            adminAddr,
+			input:             PackSetFeeConfigInput(testFeeConfig)[:100],
+			suppliedGas:       setFeeConfigGas,
+			expectedErr:       errors.New("fee config input had unexpected length 96"),
+			expectedFeeConfig: params.DefaultFeeConfig,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			evm := newFeeConfigManagerTestEVM(t, nil)
+			_, _, err := feeConfigManager{}.Run(evm, AccountRef(test.caller), precompileAddr, test.input, test.suppliedGas, test.readOnly)
+			switch {
+			case test.expectedErr == nil:
+				assert.NoError(t, err)
+			case errors.Is(err, test.expectedErr):
+			case err == nil:
+				t.Fatalf("expected error %v, got nil", test.expectedErr)
+			default:
+				assert.Contains(t, err.Error(), test.expectedErr.Error())
+			}
+			storedFeeConfig := GetStoredFeeConfig(evm.StateDB)
+			assert.True(t, storedFeeConfig.Equal(&test.expectedFeeConfig), "unexpected fee config %+v", storedFeeConfig)
+
+			logs := evm.StateDB.(*state.StateDB).Logs()
+			if err != nil {
+				assert.Empty(t, logs)
+				assert.Zero(t, GetFeeConfigLastChangedAt(evm.StateDB).Sign())
+				return
+			}
+			assert.Equal(t, uint64(5), GetFeeConfigLastChangedAt(evm.StateDB).Uint64())
+			if assert.Len(t, logs, 1) {
+				assert.Equal(t, precompileAddr, logs[0].Address)
+				assert.Equal(t, []common.Hash{feeConfigChangedTopic, test.caller.Hash()}, logs[0].Topics)
+				assert.Equal(t, PackSetFeeConfigInput(test.expectedFeeConfig)[4:], logs[0].Data)
+			}
+		})
+	}
+}
+
+func TestFeeConfigManagerGetters(t *testing.T) {
+	adminAddr := common.BytesToAddress([]byte("admin"))
+	evm := newFeeConfigManagerTestEVM(t, &testFeeConfig)
+
+	ret, remainingGas, err := evm.StaticCall(AccountRef(adminAddr), params.FeeConfigManagerAddress, getFeeConfigSignature[:], getFeeConfigGas)
+	assert.NoError(t, err)
+	assert.Zero(t, remainingGas)
+	assert.Equal(t, PackSetFeeConfigInput(testFeeConfig)[4:], ret)
+
+	_, _, err = evm.Call(AccountRef(adminAddr), params.FeeConfigManagerAddress, PackSetFeeConfigInput(params.DefaultFeeConfig), setFeeConfigGas, big.NewInt(0))
+	assert.NoError(t, err)
+
+	ret, _, err = evm.StaticCall(AccountRef(adminAddr), params.FeeConfigManagerAddress, getFeeConfigLastChangedAtSignature[:], getFeeConfigLastChangedAtGas)
+	assert.NoError(t, err)
+	assert.Equal(t, common.BigToHash(big.NewInt(5)).Bytes(), ret)
+
+	// The allow list interface is available on the fee config manager.
+	ret, _, err = evm.StaticCall(AccountRef(adminAddr), params.FeeConfigManagerAddress, PackAllowListInput(readAllowListSignature, adminAddr), params.AllowListReadGas)
+	assert.NoError(t, err)
+	assert.Equal(t, common.BigToHash(big.NewInt(int64(AllowListAdmin))).Bytes(), ret)
+}
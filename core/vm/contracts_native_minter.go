@@ -0,0 +1,166 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
+	"github.com/sankar-boro/axia-network-v2-coreth/params"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Function selectors of the native minter interface in addition to the allow
+// list interface, see accounts/abi/bind/nativeminter/INativeMinter.sol.
+var (
+	mintNativeCoinSignature  = functionSelector("mintNativeCoin(address,uint256)")
+	mintNativeAssetSignature = functionSelector("mintNativeAsset(address,bytes32,uint256)")
+)
+
+var (
+	// Topic of the NativeCoinMinted(address indexed sender, address indexed
+	// recipient, uint256 amount) event.
+	nativeCoinMintedTopic = crypto.Keccak256Hash([]byte("NativeCoinMinted(address,address,uint256)"))
+	// Topic of the NativeAssetMinted(address indexed sender, address indexed
+	// recipient, bytes32 indexed assetID, uint256 amount) event.
+	nativeAssetMintedTopic = crypto.Keccak256Hash([]byte("NativeAssetMinted(address,address,bytes32,uint256)"))
+)
+
+// The gas charged for minting includes the cost of the emitted event.
+const (
+	mintNativeCoinGas  = params.NativeMinterMintGas + params.LogGas + 3*params.LogTopicGas + common.HashLength*params.LogDataGas
+	mintNativeAssetGas = params.NativeMinterMintGas + params.LogGas + 4*params.LogTopicGas + common.HashLength*params.LogDataGas
+)
+
+// PackMintNativeCoinInput packs the arguments into the required input data for
+// a call to mintNativeCoin of the native minter.
+// Assumes that [amount] is non-nil.
+func PackMintNativeCoinInput(recipient common.Address, amount *big.Int) []byte {
+	input := make([]byte, 4+2*common.HashLength)
+	copy(input, mintNativeCoinSignature[:])
+	copy(input[4:36], recipient.Hash().Bytes())
+	amount.FillBytes(input[36:68])
+	return input
+}
+
+// PackMintNativeAssetInput packs the arguments into the required input data
+// for a call to mintNativeAsset of the native minter.
+// Assumes that [amount] is non-nil.
+func PackMintNativeAssetInput(recipient common.Address, assetID common.Hash, amount *big.Int) []byte {
+	input := make([]byte, 4+3*common.HashLength)
+	copy(input, mintNativeAssetSignature[:])
+	copy(input[4:36], recipient.Hash().Bytes())
+	copy(input[36:68], assetID.Bytes())
+	amount.FillBytes(input[68:100])
+	return input
+}
+
+// unpackMintInput unpacks the arguments of a call to mintNativeCoin or
+// mintNativeAsset, excluding the selector. [assetID] is only decoded if
+// [withAssetID] is true.
+func unpackMintInput(input []byte, withAssetID bool) (recipient common.Address, assetID common.Hash, amount *big.Int, err error) {
+	numArgs := 2
+	if withAssetID {
+		numArgs = 3
+	}
+	if len(input) != numArgs*common.HashLength {
+		return common.Address{}, common.Hash{}, nil, fmt.Errorf("mint input had unexpected length %d", len(input))
+	}
+	arg := common.BytesToHash(input[:common.HashLength])
+	recipient = common.BytesToAddress(arg.Bytes())
+	if recipient.Hash() != arg {
+		return common.Address{}, common.Hash{}, nil, fmt.Errorf("mint input has invalid address argument %s", arg)
+	}
+	if withAssetID {
+		assetID = common.BytesToHash(input[common.HashLength : 2*common.HashLength])
+	}
+	// Note: decoding a byte slice into a *big.Int always returns a positive value.
+	amount = new(big.Int).SetBytes(input[(numArgs-1)*common.HashLength:])
+	return recipient, assetID, amount, nil
+}
+
+// nativeMinter is a precompiled contract that mints native coins and
+// multicoin assets to a recipient. Only enabled addresses can mint and admins
+// can modify the allow list of the precompile.
+type nativeMinter struct{}
+
+// Run implements StatefulPrecompiledContract
+func (nativeMinter) Run(evm *EVM, caller ContractRef, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if len(input) < 4 {
+		return nil, suppliedGas, ErrExecutionReverted
+	}
+	var selector [4]byte
+	copy(selector[:], input)
+
+	var gasCost uint64
+	switch selector {
+	case mintNativeCoinSignature:
+		gasCost = mintNativeCoinGas
+	case mintNativeAssetSignature:
+		gasCost = mintNativeAssetGas
+	default:
+		return allowList{emitRoleSet: true}.Run(evm, caller, addr, input, suppliedGas, readOnly)
+	}
+
+	if suppliedGas < gasCost {
+		return nil, 0, ErrOutOfGas
+	}
+	remainingGas = suppliedGas - gasCost
+	if readOnly {
+		return nil, remainingGas, ErrWriteProtection
+	}
+	isAsset := selector == mintNativeAssetSignature
+	recipient, assetID, amount, err := unpackMintInput(input[4:], isAsset)
+	if err != nil {
+		return nil, remainingGas, ErrExecutionReverted
+	}
+	if !GetAllowListRole(evm.StateDB, addr, caller.Address()).IsEnabled() {
+		return nil, remainingGas, fmt.Errorf("%w: %s", ErrNotAllowedToMint, caller.Address())
+	}
+
+	log := &types.Log{
+		Address:     addr,
+		Data:        common.BigToHash(amount).Bytes(),
+		BlockNumber: evm.Context.BlockNumber.Uint64(),
+	}
+	if isAsset {
+		evm.StateDB.AddBalanceMultiCoin(recipient, assetID, amount)
+		log.Topics = []common.Hash{nativeAssetMintedTopic, caller.Address().Hash(), recipient.Hash(), assetID}
+	} else {
+		evm.StateDB.AddBalance(recipient, amount)
+		log.Topics = []common.Hash{nativeCoinMintedTopic, caller.Address().Hash(), recipient.Hash()}
+	}
+	evm.StateDB.AddLog(log)
+	return nil, remainingGas, nil
+}
+
+// nativeMinterModule is the stateful precompile module that allows minting
+// native coins and multicoin assets.
+type nativeMinterModule struct{}
+
+func init() {
+	RegisterStatefulPrecompileModule(nativeMinterModule{})
+}
+
+// Address implements StatefulPrecompileModule
+func (nativeMinterModule) Address() common.Address {
+	return params.ContractNativeMinterAddress
+}
+
+// Contract implements StatefulPrecompileModule
+func (nativeMinterModule) Contract() StatefulPrecompiledContract {
+	return nativeMinter{}
+}
+
+// Configure implements StatefulPrecompileModule
+func (nativeMinterModule) Configure(chainConfig *params.ChainConfig, config params.StatefulPrecompileConfig, state StateDB, blockContext BlockContext) error {
+	minterConfig, ok := config.(*params.ContractNativeMinterConfig)
+	if !ok {
+		return fmt.Errorf("unexpected config type %T for native minter", config)
+	}
+	configureAllowList(state, params.ContractNativeMinterAddress, minterConfig.AllowListConfig)
+	return nil
+}
@@ -0,0 +1,179 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/state"
+	"github.com/sankar-boro/axia-network-v2-coreth/params"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func newNativeMinterTestEVM(t *testing.T) *EVM {
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := *params.TestChainConfig
+	config.ContractNativeMinterConfig = &params.ContractNativeMinterConfig{
+		PrecompileUpgrade: params.PrecompileUpgrade{BlockTimestamp: big.NewInt(0)},
+		AllowListConfig: params.AllowListConfig{
+			AdminAddresses:   []common.Address{common.BytesToAddress([]byte("admin"))},
+			EnabledAddresses: []common.Address{common.BytesToAddress([]byte("enabled"))},
+		},
+	}
+	blockContext := BlockContext{
+		BlockNumber: big.NewInt(0),
+		Time:        big.NewInt(0),
+		CanTransfer: CanTransfer,
+		Transfer:    Transfer,
+	}
+	if err := ConfigureStatefulPrecompiles(&config, nil, blockContext, statedb); err != nil {
+		t.Fatal(err)
+	}
+	return NewEVM(blockContext, TxContext{}, statedb, &config, Config{})
+}
+
+func TestNativeMinterPrecompile(t *testing.T) {
+	adminAddr := common.BytesToAddress([]byte("admin"))
+	enabledAddr := common.BytesToAddress([]byte("enabled"))
+	userAddr := common.BytesToAddress([]byte("user"))
+	recipient := common.BytesToAddress([]byte("recipient"))
+	assetID := common.HexToHash("0xdeadbeef")
+	precompileAddr := params.ContractNativeMinterAddress
+	amount := big.NewInt(1000)
+
+	invalidAddressInput := PackMintNativeCoinInput(recipient, amount)
+	invalidAddressInput[4] = 0x01
+
+	tests := map[string]struct {
+		caller        common.Address
+		input         []byte
+		suppliedGas   uint64
+		readOnly      bool
+		expectedErr   error
+		expectedCoin  *big.Int
+		expectedAsset *big.Int
+		expectedTopic common.Hash
+	}{
+		"admin mints native coin": {
+			caller:        adminAddr,
+			input:         PackMintNativeCoinInput(recipient, amount),
+			suppliedGas:   mintNativeCoinGas,
+			expectedCoin:  amount,
+			expectedAsset: common.Big0,
+			expectedTopic: nativeCoinMintedTopic,
+		},
+		"enabled address mints native coin": {
+			caller:        enabledAddr,
+			input:         PackMintNativeCoinInput(recipient, amount),
+			suppliedGas:   mintNativeCoinGas,
+			expectedCoin:  amount,
+			expectedAsset: common.Big0,
+			expectedTopic: nativeCoinMintedTopic,
+		},
+		"enabled address mints native asset": {
+			caller:        enabledAddr,
+			input:         PackMintNativeAssetInput(recipient, assetID, amount),
+			suppliedGas:   mintNativeAssetGas,
+			expectedCoin:  common.Big0,
+			expectedAsset: amount,
+			expectedTopic: nativeAssetMintedTopic,
+		},
+		"address without role cannot mint": {
+			caller:        userAddr,
+			input:         PackMintNativeCoinInput(recipient, amount),
+			suppliedGas:   mintNativeCoinGas,
+			expectedErr:   ErrNotAllowedToMint,
+			expectedCoin:  common.Big0,
+			expectedAsset: common.Big0,
+		},
+		"mint in read only mode": {
+			caller:        adminAddr,
+			input:         PackMintNativeCoinInput(recipient, amount),
+			suppliedGas:   mintNativeCoinGas,
+			readOnly:      true,
+			expectedErr:   ErrWriteProtection,
+			expectedCoin:  common.Big0,
+			expectedAsset: common.Big0,
+		},
+		"mint native asset out of gas": {
+			caller:        adminAddr,
+			input:         PackMintNativeAssetInput(recipient, assetID, amount),
+			suppliedGas:   mintNativeAssetGas - 1,
+			expectedErr:   ErrOutOfGas,
+			expectedCoin:  common.Big0,
+			expectedAsset: common.Big0,
+		},
+		"mint with short input": {
+			caller:        adminAddr,
+			input:         PackMintNativeAssetInput(recipient, assetID, amount)[:68],
+			suppliedGas:   mintNativeAssetGas,
+			expectedErr:   ErrExecutionReverted,
+			expectedCoin:  common.Big0,
+			expectedAsset: common.Big0,
+		},
+		"mint with invalid address": {
+			caller:        adminAddr,
+			input:         invalidAddressInput,
+			suppliedGas:   mintNativeCoinGas,
+			expectedErr:   ErrExecutionReverted,
+			expectedCoin:  common.Big0,
+			expectedAsset: common.Big0,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			evm := newNativeMinterTestEVM(t)
+			_, _, err := nativeMinter{}.Run(evm, AccountRef(test.caller), precompileAddr, test.input, test.suppliedGas, test.readOnly)
+			if !errors.Is(err, test.expectedErr) {
+				t.Fatalf("expected error %v, got %v", test.expectedErr, err)
+			}
+			assert.Zero(t, test.expectedCoin.Cmp(evm.StateDB.GetBalance(recipient)))
+			assert.Zero(t, test.expectedAsset.Cmp(evm.StateDB.GetBalanceMultiCoin(recipient, assetID)))
+
+			logs := evm.StateDB.(*state.StateDB).Logs()
+			if err != nil {
+				assert.Empty(t, logs)
+				return
+			}
+			if assert.Len(t, logs, 1) {
+				expectedTopics := []common.Hash{test.expectedTopic, test.caller.Hash(), recipient.Hash()}
+				if test.expectedTopic == nativeAssetMintedTopic {
+					expectedTopics = append(expectedTopics, assetID)
+				}
+				assert.Equal(t, precompileAddr, logs[0].Address)
+				assert.Equal(t, expectedTopics, logs[0].Topics)
+				assert.Equal(t, common.BigToHash(amount).Bytes(), logs[0].Data)
+			}
+		})
+	}
+}
+
+func TestNativeMinterAllowList(t *testing.T) {
+	adminAddr := common.BytesToAddress([]byte("admin"))
+	userAddr := common.BytesToAddress([]byte("user"))
+	amount := big.NewInt(1000)
+
+	evm := newNativeMinterTestEVM(t)
+	_, _, err := evm.Call(AccountRef(userAddr), params.ContractNativeMinterAddress, PackMintNativeCoinInput(userAddr, amount), 100000, big.NewInt(0))
+	assert.ErrorIs(t, err, ErrNotAllowedToMint)
+
+	// Enable the user through the allow list interface of the precompile.
+	_, _, err = evm.Call(AccountRef(adminAddr), params.ContractNativeMinterAddress, PackAllowListInput(setEnabledSignature, userAddr), 100000, big.NewInt(0))
+	assert.NoError(t, err)
+	_, _, err = evm.Call(AccountRef(userAddr), params.ContractNativeMinterAddress, PackMintNativeCoinInput(userAddr, amount), 100000, big.NewInt(0))
+	assert.NoError(t, err)
+	assert.Equal(t, amount, evm.StateDB.GetBalance(userAddr))
+
+	// Minting cannot be done through a static call.
+	_, _, err = evm.StaticCall(AccountRef(userAddr), params.ContractNativeMinterAddress, PackMintNativeCoinInput(userAddr, amount), 100000)
+	assert.ErrorIs(t, err, ErrWriteProtection)
+	assert.Equal(t, amount, evm.StateDB.GetBalance(userAddr))
+}
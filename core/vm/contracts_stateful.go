@@ -34,6 +34,95 @@ type StatefulPrecompiledContract interface {
 	Run(evm *EVM, caller ContractRef, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error)
 }
 
+// StatefulPrecompileModule is a stateful precompiled contract that is installed
+// at its own address once its config section in params.ChainConfig activates it.
+type StatefulPrecompileModule interface {
+	// Address returns the address the module is installed at.
+	Address() common.Address
+	// Contract returns the contract that is run when [Address] is called.
+	Contract() StatefulPrecompiledContract
+	// Configure seeds the state of the module in the block that activates [config].
+	Configure(chainConfig *params.ChainConfig, config params.StatefulPrecompileConfig, state StateDB, blockContext BlockContext) error
+}
+
+// statefulPrecompileModules is the registry of stateful precompile modules
+// keyed by address.
+var statefulPrecompileModules = make(map[common.Address]StatefulPrecompileModule)
+
+// RegisterStatefulPrecompileModule adds [module] to the registry of stateful
+// precompile modules. It must be called from an init function and panics if
+// the address of [module] is already in use.
+func RegisterStatefulPrecompileModule(module StatefulPrecompileModule) {
+	address := module.Address()
+	if _, ok := PrecompiledContractsApricotPhase2[address]; ok {
+		panic(fmt.Sprintf("stateful precompile module address %s conflicts with a default precompile", address))
+	}
+	if _, ok := statefulPrecompileModules[address]; ok {
+		panic(fmt.Sprintf("stateful precompile module already registered at address %s", address))
+	}
+	statefulPrecompileModules[address] = module
+}
+
+// statefulPrecompileModuleContract returns the contract of the module
+// registered at [address].
+func statefulPrecompileModuleContract(address common.Address) (StatefulPrecompiledContract, bool) {
+	module, ok := statefulPrecompileModules[address]
+	if !ok {
+		return nil, false
+	}
+	return module.Contract(), true
+}
+
+// ConfigureStatefulPrecompiles runs the Configure hook of every stateful
+// precompile module that is activated in the block described by
+// [blockContext], whose parent has timestamp [parentTimestamp]. If
+// [parentTimestamp] is nil, the block is treated as the genesis block and
+// every module activated at or before its timestamp is configured.
+//
+// This must be called before any transaction in the block is applied.
+func ConfigureStatefulPrecompiles(chainConfig *params.ChainConfig, parentTimestamp *big.Int, blockContext BlockContext, state StateDB) error {
+	return configureStatefulPrecompiles(chainConfig, chainConfig.StatefulPrecompileConfigs(), parentTimestamp, blockContext, state)
+}
+
+func configureStatefulPrecompiles(chainConfig *params.ChainConfig, configs []params.StatefulPrecompileConfig, parentTimestamp *big.Int, blockContext BlockContext, state StateDB) error {
+	for _, config := range configs {
+		if !isActivation(config.Timestamp(), parentTimestamp, blockContext.Time) {
+			continue
+		}
+		address := config.Address()
+		module, ok := statefulPrecompileModules[address]
+		if !ok {
+			return fmt.Errorf("no stateful precompile module registered at address %s", address)
+		}
+		initStatefulPrecompileAccount(state, address)
+		if err := module.Configure(chainConfig, config, state, blockContext); err != nil {
+			return fmt.Errorf("failed to configure stateful precompile at address %s: %w", address, err)
+		}
+	}
+	return nil
+}
+
+// initStatefulPrecompileAccount prepares the account of the stateful precompile
+// at [address] when the precompile is activated.
+func initStatefulPrecompileAccount(state StateDB, address common.Address) {
+	// The nonce marks the account as non-empty, so that its storage is not
+	// removed when the state is finalised (EIP-158).
+	state.SetNonce(address, 1)
+	// The code is never run, as calls to [address] run the precompile, but
+	// Solidity checks that the callee has code (extcodesize) before calling it
+	// through an interface.
+	state.SetCode(address, []byte{0x1})
+}
+
+// isActivation returns true if a fork scheduled at [timestamp] is active at
+// [currentTimestamp] but was not active at [parentTimestamp].
+func isActivation(timestamp, parentTimestamp, currentTimestamp *big.Int) bool {
+	if timestamp == nil || timestamp.Cmp(currentTimestamp) > 0 {
+		return false
+	}
+	return parentTimestamp == nil || timestamp.Cmp(parentTimestamp) > 0
+}
+
 // wrappedPrecompiledContract implements StatefulPrecompiledContract by wrapping stateless native precompiled contracts
 // in Ethereum.
 type wrappedPrecompiledContract struct {
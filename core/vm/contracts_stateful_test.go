@@ -485,3 +485,108 @@ func TestStatefulPrecompile(t *testing.T) {
 		})
 	}
 }
+
+var testModuleAddr = common.HexToAddress("0x0300000000000000000000000000000000000001")
+
+// testModuleConfig is the config section of [testModule].
+type testModuleConfig struct {
+	params.PrecompileUpgrade
+	InitialValue common.Hash
+}
+
+func (*testModuleConfig) Address() common.Address { return testModuleAddr }
+
+// testModule returns the value stored by its Configure hook.
+type testModule struct{}
+
+func (testModule) Address() common.Address               { return testModuleAddr }
+func (testModule) Contract() StatefulPrecompiledContract { return testModule{} }
+
+func (testModule) Configure(chainConfig *params.ChainConfig, config params.StatefulPrecompileConfig, state StateDB, blockContext BlockContext) error {
+	state.SetState(testModuleAddr, common.Hash{}, config.(*testModuleConfig).InitialValue)
+	return nil
+}
+
+func (testModule) Run(evm *EVM, caller ContractRef, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	return evm.StateDB.GetState(addr, common.Hash{}).Bytes(), suppliedGas, nil
+}
+
+func init() {
+	RegisterStatefulPrecompileModule(testModule{})
+}
+
+func TestRegisterStatefulPrecompileModuleConflicts(t *testing.T) {
+	assert.Panics(t, func() { RegisterStatefulPrecompileModule(testModule{}) })
+	assert.Panics(t, func() { RegisterStatefulPrecompileModule(&conflictingModule{address: NativeAssetCallAddr}) })
+}
+
+type conflictingModule struct {
+	testModule
+	address common.Address
+}
+
+func (m *conflictingModule) Address() common.Address { return m.address }
+
+func TestStatefulPrecompileModule(t *testing.T) {
+	config := &testModuleConfig{
+		PrecompileUpgrade: params.PrecompileUpgrade{BlockTimestamp: big.NewInt(10)},
+		InitialValue:      common.HexToHash("0x1234"),
+	}
+	configs := []params.StatefulPrecompileConfig{config}
+
+	tests := map[string]struct {
+		parentTimestamp *big.Int
+		timestamp       *big.Int
+		configured      bool
+	}{
+		"before activation":   {parentTimestamp: big.NewInt(5), timestamp: big.NewInt(9), configured: false},
+		"activation block":    {parentTimestamp: big.NewInt(9), timestamp: big.NewInt(10), configured: true},
+		"after activation":    {parentTimestamp: big.NewInt(10), timestamp: big.NewInt(11), configured: false},
+		"active at genesis":   {parentTimestamp: nil, timestamp: big.NewInt(10), configured: true},
+		"genesis before fork": {parentTimestamp: nil, timestamp: big.NewInt(9), configured: false},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			blockContext := BlockContext{BlockNumber: big.NewInt(1), Time: test.timestamp}
+			if err := configureStatefulPrecompiles(params.TestChainConfig, configs, test.parentTimestamp, blockContext, statedb); err != nil {
+				t.Fatal(err)
+			}
+			if test.configured {
+				assert.Equal(t, config.InitialValue, statedb.GetState(testModuleAddr, common.Hash{}))
+				assert.Equal(t, uint64(1), statedb.GetNonce(testModuleAddr))
+			} else {
+				assert.Equal(t, common.Hash{}, statedb.GetState(testModuleAddr, common.Hash{}))
+			}
+		})
+	}
+
+	// Calls to the module's address run its contract once it is active.
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockContext := BlockContext{BlockNumber: big.NewInt(1), Time: big.NewInt(10), CanTransfer: CanTransfer, Transfer: Transfer}
+	evm := NewEVM(blockContext, TxContext{}, statedb, params.TestChainConfig, Config{})
+	caller := AccountRef(common.HexToAddress("0x0200000000000000000000000000000000000001"))
+	ret, _, err := evm.Call(caller, testModuleAddr, nil, 100000, big.NewInt(0))
+	assert.NoError(t, err)
+	assert.Empty(t, ret, "module should not run before it is active")
+
+	// Configuring the module deploys its code, which cannot be called as a
+	// regular contract, so the module is only configured once it is active.
+	if err := configureStatefulPrecompiles(params.TestChainConfig, configs, nil, blockContext, statedb); err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEmpty(t, statedb.GetCode(testModuleAddr), "active module should have code")
+	evm.chainRules.Precompiles = map[common.Address]params.StatefulPrecompileConfig{testModuleAddr: config}
+	evm.chainRules.PrecompileAddresses = []common.Address{testModuleAddr}
+	ret, _, err = evm.Call(caller, testModuleAddr, nil, 100000, big.NewInt(0))
+	assert.NoError(t, err)
+	assert.Equal(t, config.InitialValue.Bytes(), ret)
+	assert.Contains(t, ActivePrecompiles(evm.chainRules), testModuleAddr)
+	assert.NotContains(t, PrecompiledAddressesApricotPhase2, testModuleAddr)
+}
@@ -47,6 +47,11 @@ var (
 	ErrInvalidCode              = errors.New("invalid code: must not begin with 0xef")
 	ErrNonceUintOverflow        = errors.New("nonce uint64 overflow")
 	ErrNoSenderBlackhole        = errors.New("blackhole address cannot be used as sender")
+	ErrNotAllowListAdmin        = errors.New("non-admin cannot modify allow list")
+	ErrNotAllowedToDeploy       = errors.New("tx origin is not allowed to deploy contracts")
+	ErrNotAllowedToTransact     = errors.New("sender is not allowed to issue transactions")
+	ErrNotAllowedToSetFeeConfig = errors.New("non-enabled address cannot change fee config")
+	ErrNotAllowedToMint         = errors.New("non-enabled address cannot mint")
 
 	// errStopToken is an internal token indicating interpreter loop termination,
 	// never returned to outside callers.
@@ -27,6 +27,7 @@
 package vm
 
 import (
+	"fmt"
 	"math/big"
 	"sync/atomic"
 	"time"
@@ -66,6 +67,9 @@ func (evm *EVM) precompile(addr common.Address) (StatefulPrecompiledContract, bo
 		precompiles = PrecompiledContractsHomestead
 	}
 	p, ok := precompiles[addr]
+	if !ok && evm.chainRules.IsStatefulPrecompileEnabled(addr) {
+		p, ok = statefulPrecompileModuleContract(addr)
+	}
 	return p, ok
 }
 
@@ -519,6 +523,11 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 	if address == evm.Context.Coinbase {
 		return nil, common.Address{}, gas, ErrNoSenderBlackhole
 	}
+	// If the contract deployer allow list is active, only allowed transaction
+	// origins may create contracts.
+	if !IsAllowedToDeploy(evm.chainRules, evm.StateDB, evm.TxContext.Origin) {
+		return nil, common.Address{}, gas, fmt.Errorf("%w: %s", ErrNotAllowedToDeploy, evm.TxContext.Origin)
+	}
 	nonce := evm.StateDB.GetNonce(caller.Address())
 	if nonce+1 < nonce {
 		return nil, common.Address{}, gas, ErrNonceUintOverflow
@@ -620,3 +629,6 @@ func (evm *EVM) Create2(caller ContractRef, code []byte, gas uint64, endowment *
 
 // ChainConfig returns the environment's chain configuration
 func (evm *EVM) ChainConfig() *params.ChainConfig { return evm.chainConfig }
+
+// Rules returns the environment's chain rules
+func (evm *EVM) Rules() params.Rules { return evm.chainRules }
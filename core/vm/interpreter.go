@@ -89,6 +89,10 @@ func NewEVMInterpreter(evm *EVM, cfg Config) *EVMInterpreter {
 	// If jump table was not initialised we set the default one.
 	if cfg.JumpTable == nil {
 		switch {
+		case evm.chainRules.IsApricotPhase7:
+			cfg.JumpTable = &apricotPhase7InstructionSet
+		case evm.chainRules.IsApricotPhase6:
+			cfg.JumpTable = &apricotPhase6InstructionSet
 		case evm.chainRules.IsApricotPhase3:
 			cfg.JumpTable = &apricotPhase3InstructionSet
 		case evm.chainRules.IsApricotPhase2:
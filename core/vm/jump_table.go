@@ -65,6 +65,8 @@ var (
 	apricotPhase1InstructionSet    = newApricotPhase1InstructionSet()
 	apricotPhase2InstructionSet    = newApricotPhase2InstructionSet()
 	apricotPhase3InstructionSet    = newApricotPhase3InstructionSet()
+	apricotPhase6InstructionSet    = newApricotPhase6InstructionSet()
+	apricotPhase7InstructionSet    = newApricotPhase7InstructionSet()
 )
 
 // JumpTable contains the EVM opcodes supported at a given fork.
@@ -88,6 +90,24 @@ func validate(jt JumpTable) JumpTable {
 	return jt
 }
 
+// newApricotPhase6InstructionSet returns the frontier, homestead, byzantium,
+// contantinople, istanbul, petersburg, apricotPhase1, 2, 3, and 6 instructions.
+func newApricotPhase6InstructionSet() JumpTable {
+	instructionSet := newApricotPhase3InstructionSet()
+	enable3855(&instructionSet) // PUSH0 instruction https://eips.ethereum.org/EIPS/eip-3855
+	enable3860(&instructionSet) // Limit and meter initcode https://eips.ethereum.org/EIPS/eip-3860
+	return validate(instructionSet)
+}
+
+// newApricotPhase7InstructionSet returns the frontier, homestead, byzantium,
+// contantinople, istanbul, petersburg, apricotPhase1, 2, 3, 6, and 7 instructions.
+func newApricotPhase7InstructionSet() JumpTable {
+	instructionSet := newApricotPhase6InstructionSet()
+	enable1153(&instructionSet) // Transient storage opcodes https://eips.ethereum.org/EIPS/eip-1153
+	enable5656(&instructionSet) // MCOPY instruction https://eips.ethereum.org/EIPS/eip-5656
+	return validate(instructionSet)
+}
+
 // newApricotPhase3InstructionSet returns the frontier, homestead, byzantium,
 // contantinople, istanbul, petersburg, apricotPhase1, 2, and 3 instructions.
 func newApricotPhase3InstructionSet() JumpTable {
@@ -71,6 +71,15 @@ func (m *Memory) Set32(offset uint64, val *uint256.Int) {
 	val.WriteToSlice(m.store[offset:])
 }
 
+// Copy copies [length] bytes from [src] to [dst]. The source and destination
+// areas may overlap. The memory must be resized PRIOR to copying.
+func (m *Memory) Copy(dst, src, length uint64) {
+	if length == 0 {
+		return
+	}
+	copy(m.store[dst:], m.store[src:src+length])
+}
+
 // Resize resizes the memory to size
 func (m *Memory) Resize(size uint64) {
 	if uint64(m.Len()) < size {
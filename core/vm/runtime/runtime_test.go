@@ -27,6 +27,7 @@
 package runtime
 
 import (
+	"bytes"
 	"fmt"
 	"math/big"
 	"os"
@@ -713,3 +714,123 @@ func TestColdAccountAccessCost(t *testing.T) {
 		}
 	}
 }
+
+func TestTransientStorage(t *testing.T) {
+	var (
+		statedb, _ = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		tstoreAddr = common.HexToAddress("0x0a")
+		tloadAddr  = common.HexToAddress("0x0b")
+	)
+	// Stores 0x2a in transient slot 0 and returns the value loaded from it
+	statedb.SetCode(tstoreAddr, []byte{
+		byte(vm.PUSH1), 0x2a,
+		byte(vm.PUSH1), 0,
+		byte(vm.TSTORE),
+		byte(vm.PUSH1), 0,
+		byte(vm.TLOAD),
+		byte(vm.PUSH1), 0,
+		byte(vm.MSTORE),
+		byte(vm.PUSH1), 32,
+		byte(vm.PUSH1), 0,
+		byte(vm.RETURN),
+	})
+	// Static calls the tstore contract and returns the success flag
+	statedb.SetCode(tloadAddr, []byte{
+		byte(vm.PUSH1), 0,
+		byte(vm.DUP1), byte(vm.DUP1), byte(vm.DUP1),
+		byte(vm.PUSH1), 0x0a,
+		byte(vm.GAS),
+		byte(vm.STATICCALL),
+		byte(vm.PUSH1), 0,
+		byte(vm.MSTORE),
+		byte(vm.PUSH1), 32,
+		byte(vm.PUSH1), 0,
+		byte(vm.RETURN),
+	})
+
+	// Transient storage opcodes are invalid prior to Apricot Phase 7
+	_, _, err := Call(tstoreAddr, nil, &Config{ChainConfig: params.TestApricotPhase6Config, State: statedb})
+	if _, ok := err.(*vm.ErrInvalidOpCode); !ok {
+		t.Fatalf("expected invalid opcode error, got %v", err)
+	}
+
+	ret, _, err := Call(tstoreAddr, nil, &Config{ChainConfig: params.TestApricotPhase7Config, State: statedb})
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if num := new(big.Int).SetBytes(ret); num.Cmp(big.NewInt(0x2a)) != 0 {
+		t.Error("Expected 42, got", num)
+	}
+	// The value is readable for the rest of the transaction
+	if have := statedb.GetTransientState(tstoreAddr, common.Hash{}); have != common.BigToHash(big.NewInt(0x2a)) {
+		t.Errorf("unexpected transient storage %x", have)
+	}
+	// but not persisted to storage or carried over to the next transaction
+	if have := statedb.GetState(tstoreAddr, common.Hash{}); have != (common.Hash{}) {
+		t.Errorf("unexpected storage %x", have)
+	}
+	statedb.Finalise(true)
+	if have := statedb.GetTransientState(tstoreAddr, common.Hash{}); have != (common.Hash{}) {
+		t.Errorf("unexpected transient storage after finalise %x", have)
+	}
+
+	// TSTORE is not allowed in a static context
+	ret, _, err = Call(tloadAddr, nil, &Config{ChainConfig: params.TestApricotPhase7Config, State: statedb})
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if num := new(big.Int).SetBytes(ret); num.Sign() != 0 {
+		t.Error("Expected static call to fail, got", num)
+	}
+	if have := statedb.GetTransientState(tstoreAddr, common.Hash{}); have != (common.Hash{}) {
+		t.Errorf("unexpected transient storage after static call %x", have)
+	}
+}
+
+func TestMcopy(t *testing.T) {
+	word := make([]byte, 32)
+	for i := range word {
+		word[i] = byte(i + 1)
+	}
+	// Stores the word at offset 0, copies it to the overlapping offset 1
+	// and returns the first 33 bytes of memory
+	code := []byte{byte(vm.PUSH32)}
+	code = append(code, word...)
+	code = append(code,
+		byte(vm.PUSH1), 0,
+		byte(vm.MSTORE),
+		byte(vm.PUSH1), 32, // length
+		byte(vm.PUSH1), 0, // source
+		byte(vm.PUSH1), 1, // destination
+		byte(vm.MCOPY),
+		byte(vm.PUSH1), 33,
+		byte(vm.PUSH1), 0,
+		byte(vm.RETURN),
+	)
+
+	tracer := logger.NewStructLogger(nil)
+	ret, _, err := Execute(code, nil, &Config{
+		ChainConfig: params.TestApricotPhase7Config,
+		EVMConfig: vm.Config{
+			Debug:  true,
+			Tracer: tracer,
+		},
+	})
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if want := append([]byte{1}, word...); !bytes.Equal(ret, want) {
+		t.Fatalf("unexpected memory, have %x want %x", ret, want)
+	}
+	// 3 for the opcode, 3 for copying a single word and 3 for expanding the
+	// memory by a single word.
+	if have, want := tracer.StructLogs()[6].GasCost, uint64(9); have != want {
+		t.Fatalf("unexpected MCOPY gas cost, have %d want %d", have, want)
+	}
+
+	// MCOPY is invalid prior to Apricot Phase 7
+	_, _, err = Execute(code, nil, &Config{ChainConfig: params.TestApricotPhase6Config})
+	if _, ok := err.(*vm.ErrInvalidOpCode); !ok {
+		t.Fatalf("expected invalid opcode error, got %v", err)
+	}
+}
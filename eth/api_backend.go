@@ -402,6 +402,14 @@ func (b *EthAPIBackend) BloomStatus() (uint64, uint64) {
 	return params.BloomBitsBlocks, sections
 }
 
+func (b *EthAPIBackend) LogIndexStatus() (uint64, uint64, bool) {
+	if b.eth.logIndexer == nil {
+		return 0, 0, false
+	}
+	sections, _, _ := b.eth.logIndexer.Sections()
+	return core.LogIndexRange(b.eth.chainDb, sections*params.BloomBitsBlocks)
+}
+
 func (b *EthAPIBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {
 	for i := 0; i < bloomFilterThreads; i++ {
 		go session.Multiplex(bloomRetrievalBatch, bloomRetrievalWait, b.eth.bloomRequests)
@@ -431,3 +439,7 @@ func (b *EthAPIBackend) StateAtTransaction(ctx context.Context, block *types.Blo
 func (b *EthAPIBackend) MinRequiredTip(ctx context.Context, header *types.Header) (*big.Int, error) {
 	return dummy.MinRequiredTip(b.ChainConfig(), header)
 }
+
+func (b *EthAPIBackend) GetFeeConfigAt(parent *types.Header) (params.FeeConfig, error) {
+	return b.eth.blockchain.GetFeeConfigAt(parent)
+}
@@ -88,6 +88,7 @@ type Ethereum struct {
 
 	bloomRequests     chan chan *bloombits.Retrieval // Channel receiving bloom data retrieval requests
 	bloomIndexer      *core.ChainIndexer             // Bloom indexer operating during block imports
+	logIndexer        *core.ChainIndexer             // Log indexer rebuilding the log index of the blocks not indexed by the acceptor (nil if disabled)
 	closeBloomHandler chan struct{}
 
 	APIBackend *EthAPIBackend
@@ -151,6 +152,13 @@ func New(
 		"dirty", common.StorageSize(config.TrieDirtyCache)*1024*1024,
 	)
 
+	// The state scheme must be recorded before the genesis state is written
+	scheme, err := rawdb.ParseStateScheme(config.StateScheme, chainDb)
+	if err != nil {
+		return nil, err
+	}
+	config.StateScheme = scheme
+
 	chainConfig, genesisErr := core.SetupGenesisBlock(chainDb, config.Genesis)
 	if genesisErr != nil {
 		return nil, genesisErr
@@ -217,6 +225,21 @@ func New(
 			SnapshotVerify:                  config.SnapshotVerify,
 			SkipSnapshotRebuild:             config.SkipSnapshotRebuild,
 			Preimages:                       config.Preimages,
+			ParallelExecutionWorkers:        config.ParallelExecutionWorkers,
+			StorageTrieWorkers:              config.StorageTrieWorkers,
+
+			OnlinePruning:            config.OnlinePruning,
+			OnlinePruningInterval:    config.OnlinePruningInterval,
+			OnlinePruningBloomSize:   config.OnlinePruningBloomFilterSize,
+			OnlinePruningBatchSize:   config.OnlinePruningBatchSize,
+			OnlinePruningBatchDelay:  config.OnlinePruningBatchDelay,
+			StateSyncSummaryInterval: config.StateSyncSummaryInterval,
+
+			StateScheme:  config.StateScheme,
+			StateHistory: config.StateHistory,
+
+			HistoryDiffs: config.HistoryDiffs,
+			LogIndex:     config.LogIndex,
 		}
 	)
 
@@ -224,7 +247,6 @@ func New(
 		return nil, err
 	}
 
-	var err error
 	eth.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, chainConfig, eth.engine, vmConfig, lastAcceptedHash)
 	if err != nil {
 		return nil, err
@@ -235,6 +257,10 @@ func New(
 	}
 
 	eth.bloomIndexer.Start(eth.blockchain)
+	if config.LogIndex {
+		eth.logIndexer = core.NewLogIndexer(chainDb, chainConfig, params.BloomBitsBlocks, params.BloomConfirms)
+		eth.logIndexer.Start(eth.blockchain)
+	}
 
 	config.TxPool.Journal = ""
 	eth.txPool = core.NewTxPool(config.TxPool, chainConfig, eth.blockchain)
@@ -378,7 +404,11 @@ func (s *Ethereum) Start() {
 // FIXME remove error from type if this will never return an error
 func (s *Ethereum) Stop() error {
 	s.bloomIndexer.Close()
+	if s.logIndexer != nil {
+		s.logIndexer.Close()
+	}
 	close(s.closeBloomHandler)
+	s.miner.Stop()
 	s.txPool.Stop()
 	s.blockchain.Stop()
 	s.engine.Close()
@@ -93,6 +93,8 @@ type Config struct {
 	SnapshotAsync                   bool    // Whether to generate the initial snapshot in async mode
 	SnapshotVerify                  bool    // Whether to verify generated snapshots
 	SkipSnapshotRebuild             bool    // Whether to skip rebuilding the snapshot in favor of returning an error (only set to true for tests)
+	ParallelExecutionWorkers        int     // Number of workers to optimistically execute transactions in parallel (< 2 = serial execution)
+	StorageTrieWorkers              int     // Number of workers hashing and committing storage tries concurrently (< 1 = GOMAXPROCS)
 
 	// Whitelist of required block number -> hash values to accept
 	Whitelist map[uint64]common.Hash `toml:"-"`
@@ -159,4 +161,32 @@ type Config struct {
 	OfflinePruning                bool
 	OfflinePruningBloomFilterSize uint64
 	OfflinePruningDataDirectory   string
+
+	// OnlinePruning enables the deletion of unreachable trie nodes in the background
+	// every OnlinePruningInterval, which requires Pruning.
+	OnlinePruning                bool
+	OnlinePruningInterval        time.Duration
+	OnlinePruningBloomFilterSize uint64
+	OnlinePruningBatchSize       int
+	OnlinePruningBatchDelay      time.Duration
+
+	// StateSyncSummaryInterval is the interval of the blocks served as state
+	// summaries, whose state is retained by the online pruner.
+	StateSyncSummaryInterval uint64
+
+	// StateScheme is the scheme used to store the trie nodes of the state
+	// (rawdb.HashScheme or rawdb.PathScheme). It can only be chosen when the
+	// database is created and defaults to the scheme recorded in the database.
+	// StateHistory is the number of most recently accepted states kept by the
+	// path scheme.
+	StateScheme  string
+	StateHistory uint64
+
+	// HistoryDiffs enables indexing the state changes of accepted blocks to
+	// serve their state once it has been pruned.
+	HistoryDiffs bool
+
+	// LogIndex enables indexing the logs of accepted blocks by address and
+	// topic, to filter logs without the false positives of the bloom bits.
+	LogIndex bool
 }
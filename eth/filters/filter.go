@@ -31,11 +31,13 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 
 	"github.com/sankar-boro/axia-network-v2-coreth/core/vm"
 
 	"github.com/sankar-boro/axia-network-v2-coreth/core"
 	"github.com/sankar-boro/axia-network-v2-coreth/core/bloombits"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
 	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
 	"github.com/sankar-boro/axia-network-v2-coreth/ethdb"
 	"github.com/sankar-boro/axia-network-v2-coreth/rpc"
@@ -64,6 +66,10 @@ type Backend interface {
 	BloomStatus() (uint64, uint64)
 	ServiceFilter(ctx context.Context, session *bloombits.MatcherSession)
 
+	// LogIndexStatus returns the range of blocks whose logs are indexed by
+	// address and topic, or false if the log index is disabled or empty.
+	LogIndexStatus() (uint64, uint64, bool)
+
 	// Added to the backend interface to support limiting of logs requests
 	GetVMConfig() *vm.Config
 	LastAcceptedBlock() *types.Block
@@ -199,8 +205,40 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	if maxBlocks := f.backend.GetMaxBlocksPerRequest(); int64(end)-f.begin > maxBlocks && maxBlocks > 0 {
 		return nil, fmt.Errorf("requested too many blocks from %d to %d, maximum is set to %d", f.begin, int64(end), maxBlocks)
 	}
-	// Gather all indexed logs, and finish with non indexed ones
+	// Gather the logs of the blocks covered by the log index from the index,
+	// and the logs of the other blocks with the bloom bits
+	from, to, ok := f.backend.LogIndexStatus()
+	if !ok || !f.exactIndexable() || uint64(f.begin) > to || end < from {
+		return f.bloomLogs(ctx, end)
+	}
 	var logs []*types.Log
+	if uint64(f.begin) < from {
+		if logs, err = f.bloomLogs(ctx, from-1); err != nil {
+			return logs, err
+		}
+	}
+	if to > end {
+		to = end
+	}
+	found, err := f.exactLogs(ctx, to)
+	logs = append(logs, found...)
+	if err != nil || to == end {
+		return logs, err
+	}
+	rest, err := f.bloomLogs(ctx, end)
+	logs = append(logs, rest...)
+	return logs, err
+}
+
+// bloomLogs returns the logs matching the filter criteria up to [end], based
+// on the bloom bits where they are indexed and on raw block iteration
+// otherwise.
+func (f *Filter) bloomLogs(ctx context.Context, end uint64) ([]*types.Log, error) {
+	// Gather all indexed logs, and finish with non indexed ones
+	var (
+		logs []*types.Log
+		err  error
+	)
 	size, sections := f.backend.BloomStatus()
 	if indexed := sections * size; indexed > uint64(f.begin) {
 		if indexed > end {
@@ -217,6 +255,206 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	return logs, err
 }
 
+// exactIndexable returns whether the filter criteria constrain the address or
+// a topic of the logs, so that the matching logs can be looked up in the log
+// index.
+func (f *Filter) exactIndexable() bool {
+	if len(f.addresses) > 0 {
+		return true
+	}
+	for _, topics := range f.topics {
+		if len(topics) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// exactLogs returns the logs matching the filter criteria up to [end] based on
+// the log index, which must cover the blocks from the start of the filter.
+func (f *Filter) exactLogs(ctx context.Context, end uint64) ([]*types.Log, error) {
+	matcher := f.newExactMatcher(uint64(f.begin), end)
+	defer matcher.release()
+
+	var logs []*types.Log
+	for {
+		number, ok, err := matcher.next(ctx)
+		if err != nil {
+			return logs, err
+		}
+		if !ok {
+			break
+		}
+		// Retrieve the matching block and pull its matching logs, which also
+		// skips blocks indexed before they were reorged out of the chain
+		header, err := f.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+		if header == nil || err != nil {
+			return logs, err
+		}
+		found, err := f.checkMatches(ctx, header)
+		if err != nil {
+			return logs, err
+		}
+		logs = append(logs, found...)
+		f.begin = int64(number) + 1
+	}
+	f.begin = int64(end) + 1
+	return logs, nil
+}
+
+// logPositionCursor is a LogPositionIterator along with whether it is
+// positioned at a log.
+type logPositionCursor struct {
+	it    *rawdb.LogPositionIterator
+	valid bool
+}
+
+func (c *logPositionCursor) next() {
+	c.valid = c.it.Next()
+}
+
+// exactMatcher finds the blocks with logs matching the address and topic
+// criteria of a filter in the log index. The positions matching each value of
+// the criteria are streamed from the index and merged block by block, so that
+// at most the positions of a single block are held in memory.
+type exactMatcher struct {
+	criteria [][]*logPositionCursor // Cursors of the values of each criterion
+}
+
+// newExactMatcher returns an exactMatcher for the blocks from [begin] to
+// [end]. The matcher must be released when done.
+func (f *Filter) newExactMatcher(begin, end uint64) *exactMatcher {
+	m := new(exactMatcher)
+	if len(f.addresses) > 0 {
+		cursors := make([]*logPositionCursor, len(f.addresses))
+		for i, address := range f.addresses {
+			cursors[i] = &logPositionCursor{it: rawdb.NewAddressLogPositionIterator(f.db, address, begin, end)}
+		}
+		m.criteria = append(m.criteria, cursors)
+	}
+	for i, topics := range f.topics {
+		if len(topics) == 0 {
+			continue // wildcard
+		}
+		cursors := make([]*logPositionCursor, len(topics))
+		for j, topic := range topics {
+			cursors[j] = &logPositionCursor{it: rawdb.NewTopicLogPositionIterator(f.db, i, topic, begin, end)}
+		}
+		m.criteria = append(m.criteria, cursors)
+	}
+	for _, cursors := range m.criteria {
+		for _, c := range cursors {
+			c.next()
+		}
+	}
+	return m
+}
+
+// next returns the number of the next block with logs matching every
+// criterion, or false if there is none. An error is returned if [ctx] is done
+// or the log index cannot be iterated.
+func (m *exactMatcher) next(ctx context.Context) (uint64, bool, error) {
+	if len(m.criteria) == 0 {
+		return 0, false, nil // not indexable
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return 0, false, err
+		}
+		// The candidate block is the first block with logs matching each
+		// criterion on its own
+		var number uint64
+		for _, cursors := range m.criteria {
+			first, ok := uint64(0), false
+			for _, c := range cursors {
+				if c.valid && (!ok || c.it.Position().Number < first) {
+					first, ok = c.it.Position().Number, true
+				}
+			}
+			if !ok {
+				return 0, false, m.err()
+			}
+			if first > number {
+				number = first
+			}
+		}
+		// Intersect the positions of the logs of the candidate block matching
+		// each criterion, where the positions matching a criterion are those
+		// matching any of its values. The positions of the earlier blocks are
+		// skipped.
+		var positions []rawdb.LogPosition
+		for i, cursors := range m.criteria {
+			var matches []rawdb.LogPosition
+			for _, c := range cursors {
+				for ; c.valid && c.it.Position().Number <= number; c.next() {
+					if c.it.Position().Number == number {
+						matches = append(matches, c.it.Position())
+					}
+				}
+			}
+			sort.Slice(matches, func(i, j int) bool { return lessLogPosition(matches[i], matches[j]) })
+			if i == 0 {
+				positions = matches
+			} else {
+				positions = intersectLogPositions(positions, matches)
+			}
+		}
+		if len(positions) > 0 {
+			return number, true, nil
+		}
+	}
+}
+
+// err returns the first failure to iterate the log index, if any.
+func (m *exactMatcher) err() error {
+	for _, cursors := range m.criteria {
+		for _, c := range cursors {
+			if err := c.it.Error(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// release releases the iterators of the matcher.
+func (m *exactMatcher) release() {
+	for _, cursors := range m.criteria {
+		for _, c := range cursors {
+			c.it.Release()
+		}
+	}
+}
+
+func lessLogPosition(a, b rawdb.LogPosition) bool {
+	if a.Number != b.Number {
+		return a.Number < b.Number
+	}
+	if a.TxIndex != b.TxIndex {
+		return a.TxIndex < b.TxIndex
+	}
+	return a.Index < b.Index
+}
+
+// intersectLogPositions returns the positions in both of the sorted [a] and
+// [b].
+func intersectLogPositions(a, b []rawdb.LogPosition) []rawdb.LogPosition {
+	var res []rawdb.LogPosition
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case lessLogPosition(a[i], b[j]):
+			i++
+		case lessLogPosition(b[j], a[i]):
+			j++
+		default:
+			res = append(res, a[i])
+			i++
+			j++
+		}
+	}
+	return res
+}
+
 // indexedLogs returns the logs matching the filter criteria based on the bloom
 // bits indexed available locally or via the network.
 func (f *Filter) indexedLogs(ctx context.Context, end uint64) ([]*types.Log, error) {
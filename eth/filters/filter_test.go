@@ -0,0 +1,234 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package filters
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/consensus/dummy"
+	"github.com/sankar-boro/axia-network-v2-coreth/core"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/vm"
+	"github.com/sankar-boro/axia-network-v2-coreth/ethdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/params"
+	"github.com/sankar-boro/axia-network-v2-coreth/rpc"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// testBackend serves the logs of an accepted chain, with the log index
+// covering the blocks [from, to] if [logIndex] is set. The methods of Backend
+// not used to filter logs panic.
+type testBackend struct {
+	Backend
+
+	db    ethdb.Database
+	chain *core.BlockChain
+
+	logIndex bool
+	from, to uint64
+
+	headerRequests int // Number of headers requested by number
+}
+
+func (b *testBackend) ChainDb() ethdb.Database { return b.db }
+
+func (b *testBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	b.headerRequests++
+	if number == rpc.LatestBlockNumber {
+		return b.chain.LastAcceptedBlock().Header(), nil
+	}
+	return b.chain.GetHeaderByNumber(uint64(number)), nil
+}
+
+func (b *testBackend) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	return b.chain.GetHeaderByHash(hash), nil
+}
+
+func (b *testBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	return b.chain.GetReceiptsByHash(hash), nil
+}
+
+func (b *testBackend) GetLogs(ctx context.Context, hash common.Hash) ([][]*types.Log, error) {
+	receipts := b.chain.GetReceiptsByHash(hash)
+	logs := make([][]*types.Log, len(receipts))
+	for i, receipt := range receipts {
+		logs[i] = receipt.Logs
+	}
+	return logs, nil
+}
+
+func (b *testBackend) BloomStatus() (uint64, uint64) { return params.BloomBitsBlocks, 0 }
+
+func (b *testBackend) LogIndexStatus() (uint64, uint64, bool) { return b.from, b.to, b.logIndex }
+
+func (b *testBackend) GetVMConfig() *vm.Config { return b.chain.GetVMConfig() }
+
+func (b *testBackend) LastAcceptedBlock() *types.Block { return b.chain.LastAcceptedBlock() }
+
+func (b *testBackend) GetMaxBlocksPerRequest() int64 { return 0 }
+
+// Tests that the logs filtered with the log index are the same as the logs
+// filtered with the bloom bits, for blocks covered by the index or not.
+func TestFilterLogIndex(t *testing.T) {
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr     = crypto.PubkeyToAddress(key.PublicKey)
+		emitter1 = common.Address{0xaa} // Emits a log with the block number as topic
+		emitter2 = common.Address{0xbb} // Emits a log with the block number and the caller as topics
+		genDB    = rawdb.NewMemoryDatabase()
+		db       = rawdb.NewMemoryDatabase()
+		signer   = types.HomesteadSigner{}
+	)
+	gspec := &core.Genesis{
+		Config: &params.ChainConfig{HomesteadBlock: new(big.Int)},
+		Alloc: core.GenesisAlloc{
+			addr: {Balance: big.NewInt(params.Ether)},
+			emitter1: {
+				Balance: new(big.Int),
+				Code:    []byte{byte(vm.NUMBER), byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.LOG1)},
+			},
+			emitter2: {
+				Balance: new(big.Int),
+				Code:    []byte{byte(vm.CALLER), byte(vm.NUMBER), byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.LOG2)},
+			},
+		},
+	}
+	genesis := gspec.MustCommit(genDB)
+	_ = gspec.MustCommit(db)
+
+	cacheConfig := *core.DefaultCacheConfig
+	cacheConfig.LogIndex = true
+	chain, err := core.NewBlockChain(db, &cacheConfig, gspec.Config, dummy.NewFaker(), vm.Config{}, common.Hash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Stop()
+
+	blocks, _, err := core.GenerateChain(gspec.Config, genesis, dummy.NewFaker(), genDB, 20, 10, func(i int, gen *core.BlockGen) {
+		if i%2 == 0 {
+			tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr), emitter1, new(big.Int), 100000, nil, nil), signer, key)
+			gen.AddTx(tx)
+		}
+		if i%3 != 0 {
+			tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr), emitter2, new(big.Int), 100000, nil, nil), signer, key)
+			gen.AddTx(tx)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+	for _, block := range blocks {
+		if err := chain.Accept(block); err != nil {
+			t.Fatal(err)
+		}
+	}
+	chain.DrainAcceptorQueue()
+
+	from, to, ok := core.LogIndexRange(db, 0)
+	if !ok || from != 1 || to != 20 {
+		t.Fatalf("log index range mismatch: have [%d, %d] (%t), want [1, 20]", from, to, ok)
+	}
+
+	var (
+		caller = common.BytesToHash(addr[:])
+		number = func(n int64) common.Hash { return common.BigToHash(big.NewInt(n)) }
+	)
+	criteria := []struct {
+		addresses []common.Address
+		topics    [][]common.Hash
+	}{
+		{[]common.Address{emitter1}, nil},
+		{[]common.Address{emitter2}, [][]common.Hash{nil, {caller}}},
+		{nil, [][]common.Hash{{number(3), number(4)}}},
+		{[]common.Address{emitter1, emitter2}, [][]common.Hash{{number(5), number(8)}}},
+		{nil, [][]common.Hash{{number(5)}, {caller}}},
+		{nil, [][]common.Hash{nil, nil, {caller}}},
+		{[]common.Address{emitter1}, [][]common.Hash{nil, {caller}}},
+		{[]common.Address{{0xcc}}, nil},
+		{nil, nil},
+	}
+	ranges := [][2]int64{{0, -1}, {2, 7}, {5, 5}, {-1, -1}}
+	indexes := []struct {
+		from, to uint64
+	}{
+		{from, to}, // the whole chain
+		{5, 12},    // blocks before and after the index
+		{0, 3},     // blocks after the index
+		{15, 30},   // blocks before the index
+	}
+	var matched bool
+	for i, crit := range criteria {
+		for _, rng := range ranges {
+			backend := &testBackend{db: db, chain: chain}
+			filter, err := NewRangeFilter(backend, rng[0], rng[1], crit.addresses, crit.topics)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := filter.Logs(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			matched = matched || len(want) > 0
+
+			for _, index := range indexes {
+				backend := &testBackend{db: db, chain: chain, logIndex: true, from: index.from, to: index.to}
+				filter, err := NewRangeFilter(backend, rng[0], rng[1], crit.addresses, crit.topics)
+				if err != nil {
+					t.Fatal(err)
+				}
+				have, err := filter.Logs(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(have, want) {
+					t.Fatalf("criteria %d, range %v, index [%d, %d]: logs mismatch: have %v, want %v", i, rng, index.from, index.to, have, want)
+				}
+			}
+		}
+	}
+	if !matched {
+		t.Fatal("no logs matched by the filters")
+	}
+
+	// Only the latest header is retrieved if no indexed log matches, whereas
+	// every header is retrieved without the index
+	for _, logIndex := range []bool{true, false} {
+		backend := &testBackend{db: db, chain: chain, logIndex: logIndex, from: from, to: to}
+		filter, err := NewRangeFilter(backend, 1, -1, []common.Address{{0xcc}}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := filter.Logs(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		want := 1
+		if !logIndex {
+			want += len(blocks)
+		}
+		if backend.headerRequests != want {
+			t.Fatalf("log index %t: header requests mismatch: have %d, want %d", logIndex, backend.headerRequests, want)
+		}
+	}
+
+	// Looking up the log index is aborted once the context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	backend := &testBackend{db: db, chain: chain, logIndex: true, from: from, to: to}
+	filter, err := NewRangeFilter(backend, 1, -1, []common.Address{emitter1}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := filter.Logs(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+}
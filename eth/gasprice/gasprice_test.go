@@ -158,6 +158,10 @@ func (b *testBackend) MinRequiredTip(ctx context.Context, header *types.Header)
 	return dummy.MinRequiredTip(b.chain.Config(), header)
 }
 
+func (b *testBackend) GetFeeConfigAt(parent *types.Header) (params.FeeConfig, error) {
+	return b.chain.GetFeeConfigAt(parent)
+}
+
 func (b *testBackend) CurrentHeader() *types.Header {
 	return b.chain.CurrentHeader()
 }
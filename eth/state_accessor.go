@@ -187,6 +187,11 @@ func (eth *Ethereum) stateAtTransaction(block *types.Block, txIndex int, reexec
 	if err != nil {
 		return nil, vm.BlockContext{}, nil, err
 	}
+	// Configure any stateful precompiles activated in this block
+	context := core.NewEVMBlockContext(block.Header(), eth.blockchain, nil)
+	if err := vm.ConfigureStatefulPrecompiles(eth.blockchain.Config(), new(big.Int).SetUint64(parent.Time()), context, statedb); err != nil {
+		return nil, vm.BlockContext{}, nil, err
+	}
 	if txIndex == 0 && len(block.Transactions()) == 0 {
 		return nil, vm.BlockContext{}, statedb, nil
 	}
@@ -196,7 +201,6 @@ func (eth *Ethereum) stateAtTransaction(block *types.Block, txIndex int, reexec
 		// Assemble the transaction call message and return if the requested offset
 		msg, _ := tx.AsMessage(signer, block.BaseFee())
 		txContext := core.NewEVMTxContext(msg)
-		context := core.NewEVMBlockContext(block.Header(), eth.blockchain, nil)
 		if idx == txIndex {
 			return msg, context, statedb, nil
 		}
@@ -393,10 +393,17 @@ func (api *API) traceChain(ctx context.Context, start, end *types.Block, config
 				failed = err
 				break
 			}
+			// Configure any stateful precompiles activated in the traced block
+			taskState := statedb.Copy()
+			blockCtx := core.NewEVMBlockContext(next.Header(), api.chainContext(localctx), nil)
+			if err := vm.ConfigureStatefulPrecompiles(api.backend.ChainConfig(), new(big.Int).SetUint64(block.Time()), blockCtx, taskState); err != nil {
+				failed = err
+				break
+			}
 			// Send the block over to the concurrent tracers (if not in the fast-forward phase)
 			txs := next.Transactions()
 			select {
-			case tasks <- &blockTraceTask{statedb: statedb.Copy(), block: next, rootref: block.Root(), results: make([]*txTraceResult, len(txs))}:
+			case tasks <- &blockTraceTask{statedb: taskState, block: next, rootref: block.Root(), results: make([]*txTraceResult, len(txs))}:
 			case <-notifier.Closed():
 				return
 			}
@@ -508,6 +515,10 @@ func (api *API) IntermediateRoots(ctx context.Context, hash common.Hash, config
 	if err != nil {
 		return nil, err
 	}
+	// Configure any stateful precompiles activated in this block
+	if err := vm.ConfigureStatefulPrecompiles(api.backend.ChainConfig(), new(big.Int).SetUint64(parent.Time()), core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil), statedb); err != nil {
+		return nil, err
+	}
 	var (
 		roots              []common.Hash
 		signer             = types.MakeSigner(api.backend.ChainConfig(), block.Number(), new(big.Int).SetUint64(block.Time()))
@@ -558,6 +569,10 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 	if err != nil {
 		return nil, err
 	}
+	// Configure any stateful precompiles activated in this block
+	if err := vm.ConfigureStatefulPrecompiles(api.backend.ChainConfig(), new(big.Int).SetUint64(parent.Time()), core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil), statedb); err != nil {
+		return nil, err
+	}
 	// Execute all the transaction contained within the block concurrently
 	var (
 		signer  = types.MakeSigner(api.backend.ChainConfig(), block.Number(), new(big.Int).SetUint64(block.Time()))
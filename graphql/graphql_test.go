@@ -0,0 +1,255 @@
+// (c) 2022, Axia Systems, Inc.
+//
+// This file is a derived work, based on the go-ethereum library whose original
+// notices appear below.
+//
+// It is distributed under a license compatible with the licensing terms of the
+// original code from which it is derived.
+//
+// Much love to the original authors for their work.
+// **********
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/sankar-boro/axia-network-v2/utils/timer/mockable"
+	"github.com/sankar-boro/axia-network-v2-coreth/consensus/dummy"
+	"github.com/sankar-boro/axia-network-v2-coreth/core"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
+	"github.com/sankar-boro/axia-network-v2-coreth/eth"
+	"github.com/sankar-boro/axia-network-v2-coreth/eth/ethconfig"
+	"github.com/sankar-boro/axia-network-v2-coreth/node"
+	"github.com/sankar-boro/axia-network-v2-coreth/params"
+	"github.com/sankar-boro/axia-network-v2-coreth/rpc"
+)
+
+var (
+	testKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr    = crypto.PubkeyToAddress(testKey.PublicKey)
+	testCoinID  = common.HexToHash("0x01")
+	testAtomic  = NewAtomicTx("2Z4UkgWmVdKUFXNQaNt1ahA7F9pTaFk7PmNrmETLeAHmKeLnJs", []byte{0x01, 0x02}, 11230)
+	testBalance = big.NewInt(1000000000000000000)
+)
+
+// testBackend serves [testAtomic] as the only atomic transaction of every
+// block.
+type testBackend struct {
+	*eth.EthAPIBackend
+}
+
+func (testBackend) AtomicTxs(block *types.Block) ([]*AtomicTx, error) {
+	return []*AtomicTx{testAtomic}, nil
+}
+
+func TestBuildSchema(t *testing.T) {
+	// Make sure the schema can be parsed and matched up to the object model.
+	if _, err := NewHandler(nil, 0, nil); err != nil {
+		t.Errorf("Could not construct GraphQL handler: %v", err)
+	}
+}
+
+// deadlineBackend records the deadline of the context of the gas price
+// requests, which it fails.
+type deadlineBackend struct {
+	Backend
+	deadline time.Time
+}
+
+func (b *deadlineBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	b.deadline, _ = ctx.Deadline()
+	return nil, errors.New("no gas price")
+}
+
+func TestGraphQLMaximumDuration(t *testing.T) {
+	backend := new(deadlineBackend)
+	handler, err := NewHandler(backend, time.Minute, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"query": "{gasPrice}"}`)))
+	if backend.deadline.Before(start.Add(time.Minute)) || backend.deadline.After(time.Now().Add(time.Minute)) {
+		t.Fatalf("query deadline %v not a minute after the request at %v", backend.deadline, start)
+	}
+}
+
+func TestGraphQLRateLimit(t *testing.T) {
+	rateLimiter, err := rpc.NewRateLimiter(rpc.RateLimitConfig{RefillRate: 0.001, MaxStored: 2, MethodCosts: map[string]uint64{"graphql": 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler, err := NewHandler(nil, 0, rateLimiter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []struct {
+		code int
+		body string
+	}{
+		{http.StatusOK, `{"data":{"__typename":"Query"}}`},
+		{http.StatusTooManyRequests, `{"errors":[{"message":"rate limit exceeded for method graphql"}]}`},
+	} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"query": "{__typename}"}`)))
+		if recorder.Code != want.code || recorder.Body.String() != want.body {
+			t.Fatalf("request %d: have %d %s, want %d %s", i, recorder.Code, recorder.Body, want.code, want.body)
+		}
+		if have := recorder.Header().Get("Content-Type"); have != "application/json" {
+			t.Fatalf("request %d: wrong content type %q", i, have)
+		}
+	}
+}
+
+func TestGraphQLBlockSerialization(t *testing.T) {
+	server := newTestServer(t, 10)
+
+	for i, tt := range []struct {
+		body string
+		want string
+		code int
+	}{
+		{ // Should return latest block
+			body: `{"query": "{block{number}}","variables": null}`,
+			want: `{"data":{"block":{"number":10}}}`,
+			code: 200,
+		},
+		{
+			body: `{"query": "{block(number:0){number,gasUsed,gasLimit}}","variables": null}`,
+			want: `{"data":{"block":{"number":0,"gasUsed":0,"gasLimit":8000000}}}`,
+			code: 200,
+		},
+		{
+			body: `{"query": "{block(number:-1){number,gasUsed,gasLimit}}","variables": null}`,
+			want: `{"data":{"block":null}}`,
+			code: 200,
+		},
+		{
+			body: `{"query": "{block(number:\"a\"){number,gasUsed,gasLimit}}","variables": null}`,
+			want: `{"errors":[{"message":"strconv.ParseInt: parsing \"a\": invalid syntax"}],"data":{}}`,
+			code: 400,
+		},
+		{
+			body: `{"query": "{bleh{number}}","variables": null}"`,
+			want: `{"errors":[{"message":"Cannot query field \"bleh\" on type \"Query\".","locations":[{"line":1,"column":2}]}]}`,
+			code: 400,
+		},
+		{
+			body: `{"query": "{blocks(from:9){number}}","variables": null}`,
+			want: `{"data":{"blocks":[{"number":9},{"number":10}]}}`,
+			code: 200,
+		},
+		{
+			body: `{"query": "{blocks(to:1){number}}","variables": null}`,
+			want: `{"errors":[{"message":"from block number must be specified","path":["blocks"]}],"data":null}`,
+			code: 400,
+		},
+		{
+			body: `{"query": "{blocks(from:0,to:1025){number}}","variables": null}`,
+			want: `{"errors":[{"message":"requested too many blocks from 0 to 1025, maximum is set to 1024","path":["blocks"]}],"data":null}`,
+			code: 400,
+		},
+		{ // Coreth specific block fields
+			body: `{"query": "{block(number:1){extDataGasUsed,blockGasCost,atomicTransactions{id,raw,gasUsed}}}","variables": null}`,
+			want: `{"data":{"block":{"extDataGasUsed":"0x0","blockGasCost":"0x0","atomicTransactions":[{"id":"2Z4UkgWmVdKUFXNQaNt1ahA7F9pTaFk7PmNrmETLeAHmKeLnJs","raw":"0x0102","gasUsed":11230}]}}}`,
+			code: 200,
+		},
+		{ // Native and multicoin balances
+			body: `{"query": "{block(number:0){account(address:\"` + testAddr.Hex() + `\"){balance,balanceMultiCoin(coinID:\"` + testCoinID.Hex() + `\")}}}","variables": null}`,
+			want: `{"data":{"block":{"account":{"balance":"0xde0b6b3a7640000","balanceMultiCoin":"0x64"}}}}`,
+			code: 200,
+		},
+	} {
+		resp, err := http.Post(server.URL, "application/json", strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatalf("could not post: %v", err)
+		}
+		bodyBytes, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("could not read from response body: %v", err)
+		}
+		if have := string(bodyBytes); have != tt.want {
+			t.Errorf("testcase %d %s,\nhave:\n%v\nwant:\n%v", i, tt.body, have, tt.want)
+		}
+		if tt.code != resp.StatusCode {
+			t.Errorf("testcase %d %s,\nwrong statuscode, have: %v, want: %v", i, tt.body, resp.StatusCode, tt.code)
+		}
+	}
+}
+
+// newTestServer returns a GraphQL server backed by a chain of [numBlocks]
+// accepted blocks.
+func newTestServer(t *testing.T, numBlocks int) *httptest.Server {
+	stack, err := node.New(&node.Config{})
+	if err != nil {
+		t.Fatalf("could not create node: %v", err)
+	}
+	ethConf := ethconfig.NewDefaultConfig()
+	ethConf.Genesis = &core.Genesis{
+		Config:   params.TestChainConfig,
+		GasLimit: params.ApricotPhase1GasLimit,
+		Alloc: core.GenesisAlloc{
+			testAddr: {
+				Balance:   testBalance,
+				MCBalance: core.GenesisMultiCoinBalance{testCoinID: big.NewInt(100)},
+			},
+		},
+	}
+	ethBackend, err := eth.New(stack, &ethConf, new(dummy.ConsensusCallbacks), rawdb.NewMemoryDatabase(), eth.DefaultSettings, common.Hash{}, &mockable.Clock{})
+	if err != nil {
+		t.Fatalf("could not create eth backend: %v", err)
+	}
+	ethBackend.Start()
+	t.Cleanup(func() { ethBackend.Stop() })
+
+	// Create some blocks and import them
+	blockchain := ethBackend.BlockChain()
+	chain, _, err := core.GenerateChain(params.TestChainConfig, blockchain.Genesis(), dummy.NewFaker(), ethBackend.ChainDb(), numBlocks, 10, func(i int, gen *core.BlockGen) {})
+	if err != nil {
+		t.Fatalf("could not generate blocks: %v", err)
+	}
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatalf("could not import blocks: %v", err)
+	}
+	for _, block := range chain {
+		if err := blockchain.Accept(block); err != nil {
+			t.Fatalf("could not accept block: %v", err)
+		}
+	}
+	blockchain.DrainAcceptorQueue()
+
+	handler, err := NewHandler(testBackend{ethBackend.APIBackend}, 0, nil)
+	if err != nil {
+		t.Fatalf("could not create graphql handler: %v", err)
+	}
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
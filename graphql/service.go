@@ -0,0 +1,115 @@
+// (c) 2022, Axia Systems, Inc.
+//
+// This file is a derived work, based on the go-ethereum library whose original
+// notices appear below.
+//
+// It is distributed under a license compatible with the licensing terms of the
+// original code from which it is derived.
+//
+// Much love to the original authors for their work.
+// **********
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
+	"github.com/sankar-boro/axia-network-v2-coreth/eth/filters"
+	"github.com/sankar-boro/axia-network-v2-coreth/internal/ethapi"
+	"github.com/sankar-boro/axia-network-v2-coreth/rpc"
+)
+
+// Backend is the interface the GraphQL resolvers use to access the chain.
+type Backend interface {
+	ethapi.Backend
+	filters.Backend
+
+	// AtomicTxs returns the atomic transactions included in [block].
+	AtomicTxs(block *types.Block) ([]*AtomicTx, error)
+}
+
+// graphQLMethod is the method name the GraphQL queries are rate limited as.
+const graphQLMethod = "graphql"
+
+type handler struct {
+	Schema *graphql.Schema
+
+	maximumDuration time.Duration
+	rateLimiter     *rpc.RateLimiter
+}
+
+func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := h.rateLimiter.Take(r, graphQLMethod); err != nil {
+		responseJSON, _ := json.Marshal(&graphql.Response{Errors: []*errors.QueryError{errors.Errorf("%s", err)}})
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write(responseJSON)
+		return
+	}
+
+	var params struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName"`
+		Variables     map[string]interface{} `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	if h.maximumDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.maximumDuration)
+		defer cancel()
+	}
+	response := h.Schema.Exec(ctx, params.Query, params.OperationName, params.Variables)
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(response.Errors) > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	w.Write(responseJSON)
+}
+
+// NewHandler returns a new `http.Handler` that will answer GraphQL queries
+// against [backend]. If [maximumDuration] > 0, each query is cancelled after
+// [maximumDuration]. Each query is charged against the quota of its client in
+// [rateLimiter] as a call to the "graphql" method, unless [rateLimiter] is nil.
+func NewHandler(backend Backend, maximumDuration time.Duration, rateLimiter *rpc.RateLimiter) (http.Handler, error) {
+	q := Resolver{backend}
+
+	s, err := graphql.ParseSchema(schema, &q)
+	if err != nil {
+		return nil, err
+	}
+	return handler{
+		Schema:          s,
+		maximumDuration: maximumDuration,
+		rateLimiter:     rateLimiter,
+	}, nil
+}
@@ -35,7 +35,9 @@ func (g gatherer) Gather() ([]*dto.MetricFamily, error) {
 	mfs := make([]*dto.MetricFamily, 0, len(names))
 	for _, name := range names {
 		mIntf := g.reg.Get(name)
-		name := strings.Replace(name, "/", "_", -1)
+		name, labels := parseLabels(name)
+		name = strings.Replace(name, "/", "_", -1)
+		numFamilies := len(mfs)
 
 		switch m := mIntf.(type) {
 		case metrics.Counter:
@@ -176,11 +178,49 @@ func (g gatherer) Gather() ([]*dto.MetricFamily, error) {
 				}},
 			})
 		}
+
+		// Attach the labels to the new metric and merge it into the family
+		// of the previous metric if they share a name. Names are sorted, so
+		// all metrics of a family are adjacent.
+		if len(labels) == 0 || len(mfs) == numFamilies {
+			continue
+		}
+		mf := mfs[numFamilies]
+		mf.Metric[0].Label = labels
+		if numFamilies > 0 {
+			prev := mfs[numFamilies-1]
+			if prev.GetName() == mf.GetName() && prev.GetType() == mf.GetType() {
+				prev.Metric = append(prev.Metric, mf.Metric...)
+				mfs = mfs[:numFamilies]
+			}
+		}
 	}
 
 	return mfs, nil
 }
 
+// parseLabels splits a metric name of the form "name{key=value,...}" into its
+// base name and label pairs. Names without labels are returned unchanged.
+func parseLabels(name string) (string, []*dto.LabelPair) {
+	start := strings.IndexByte(name, '{')
+	if start < 0 || !strings.HasSuffix(name, "}") {
+		return name, nil
+	}
+	var labels []*dto.LabelPair
+	for _, pair := range strings.Split(name[start+1:len(name)-1], ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		key, value := kv[0], kv[1]
+		labels = append(labels, &dto.LabelPair{
+			Name:  &key,
+			Value: &value,
+		})
+	}
+	return name[:start], labels
+}
+
 func Gatherer(reg metrics.Registry) prometheus.Gatherer {
 	return gatherer{reg: reg}
 }
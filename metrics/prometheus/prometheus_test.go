@@ -85,3 +85,37 @@ func TestGatherer(t *testing.T) {
 	_, err = g.Gather()
 	assert.NoError(t, err)
 }
+
+func TestGathererLabels(t *testing.T) {
+	registry := metrics.NewRegistry()
+
+	for _, name := range []string{
+		"test/latency{method=a,status=success}",
+		"test/latency{method=a,status=failure}",
+		"test/latency{method=b,status=success}",
+	} {
+		histogram := metrics.NewHistogram(metrics.NewUniformSample(1028))
+		histogram.Update(10)
+		err := registry.Register(name, histogram)
+		assert.NoError(t, err)
+	}
+	counter := metrics.NewCounter()
+	err := registry.Register("test/latency_total", counter)
+	assert.NoError(t, err)
+
+	mfs, err := Gatherer(registry).Gather()
+	assert.NoError(t, err)
+	assert.Len(t, mfs, 2)
+
+	// Metrics are sorted by their full name, so the labeled family comes last.
+	assert.Equal(t, "test_latency_total", mfs[0].GetName())
+	family := mfs[1]
+	assert.Equal(t, "test_latency", family.GetName())
+	assert.Len(t, family.Metric, 3)
+	for _, metric := range family.Metric {
+		labels := metric.GetLabel()
+		assert.Len(t, labels, 2)
+		assert.Equal(t, "method", labels[0].GetName())
+		assert.Equal(t, "status", labels[1].GetName())
+	}
+}
@@ -45,7 +45,8 @@ type Backend interface {
 
 // Config is the configuration parameters of mining.
 type Config struct {
-	Etherbase common.Address `toml:",omitempty"` // Public address for block mining rewards (default = first account)
+	Etherbase      common.Address `toml:",omitempty"` // Public address for block mining rewards (default = first account)
+	PrefetchBudget float64        // Fraction of a CPU core used to prefetch the state of pending txs (0 = disabled)
 }
 
 type Miner struct {
@@ -58,6 +59,11 @@ func New(eth Backend, config *Config, chainConfig *params.ChainConfig, mux *even
 	}
 }
 
+// Stop terminates any background processing of the miner.
+func (miner *Miner) Stop() {
+	miner.worker.stop()
+}
+
 func (miner *Miner) SetEtherbase(addr common.Address) {
 	miner.worker.setEtherbase(addr)
 }
@@ -0,0 +1,235 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/consensus/dummy"
+	"github.com/sankar-boro/axia-network-v2-coreth/core"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/state"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/vm"
+	"github.com/sankar-boro/axia-network-v2-coreth/metrics"
+	"github.com/sankar-boro/axia-network-v2-coreth/params"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// txPrefetchBatchSize is the maximum number of transactions executed
+	// before the prefetcher yields to honour its CPU budget.
+	txPrefetchBatchSize = 64
+	// txPrefetchQueueLimit is the maximum number of transactions queued for
+	// prefetching. Transactions arriving when the queue is full are dropped.
+	txPrefetchQueueLimit = 4096
+	// txPrefetchHeadLimit is the maximum number of transactions executed on
+	// top of the same head, which bounds the memory held by the prefetch state.
+	txPrefetchHeadLimit = 8192
+	// txChanSize is the size of channel listening to NewTxsEvent.
+	txChanSize = 4096
+	// chainHeadChanSize is the size of channel listening to ChainHeadEvent.
+	chainHeadChanSize = 10
+)
+
+var (
+	txPrefetchMeter        = metrics.NewRegisteredMeter("miner/prefetcher/txs", nil)
+	txPrefetchDroppedMeter = metrics.NewRegisteredMeter("miner/prefetcher/dropped", nil)
+	txPrefetchTimer        = metrics.NewRegisteredTimer("miner/prefetcher/time", nil)
+)
+
+// txPrefetcher warms the trie prefetcher and snapshot caches used during block
+// building by speculatively executing transactions as they are added to the
+// tx pool on top of the current head, so that the accounts and storage slots
+// they touch are no longer cold when the block is built. All changes made by
+// the execution are discarded.
+//
+// The prefetcher is bounded to spend [budget] of a CPU core: after executing a
+// batch of transactions it sleeps for long enough to honour the budget.
+type txPrefetcher struct {
+	chainConfig *params.ChainConfig
+	chain       *core.BlockChain
+	coinbase    func() common.Address
+	budget      float64
+
+	txsCh   chan core.NewTxsEvent
+	txsSub  event.Subscription
+	headCh  chan core.ChainHeadEvent
+	headSub event.Subscription
+
+	queue []*types.Transaction
+
+	// State of the current head the queued transactions are executed on
+	header  *types.Header
+	statedb *state.StateDB
+	evm     *vm.EVM
+	count   int // Number of transactions executed on [statedb]
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newTxPrefetcher creates and starts a txPrefetcher using at most [budget] of
+// a CPU core, which must be in (0, 1].
+func newTxPrefetcher(chainConfig *params.ChainConfig, chain *core.BlockChain, txPool *core.TxPool, coinbase func() common.Address, budget float64) *txPrefetcher {
+	p := &txPrefetcher{
+		chainConfig: chainConfig,
+		chain:       chain,
+		coinbase:    coinbase,
+		budget:      budget,
+		txsCh:       make(chan core.NewTxsEvent, txChanSize),
+		headCh:      make(chan core.ChainHeadEvent, chainHeadChanSize),
+		quit:        make(chan struct{}),
+	}
+	p.txsSub = txPool.SubscribeNewTxsEvent(p.txsCh)
+	p.headSub = chain.SubscribeChainHeadEvent(p.headCh)
+
+	p.wg.Add(1)
+	go p.loop()
+	return p
+}
+
+// stop terminates the prefetcher and waits for it to exit.
+func (p *txPrefetcher) stop() {
+	close(p.quit)
+	p.wg.Wait()
+}
+
+func (p *txPrefetcher) loop() {
+	defer p.wg.Done()
+	defer p.txsSub.Unsubscribe()
+	defer p.headSub.Unsubscribe()
+	defer p.reset()
+
+	var (
+		next  time.Time        // Earliest time the next batch may be executed within the budget
+		ready <-chan time.Time // Fires when the next batch is due, nil if none is scheduled
+	)
+	for {
+		if ready == nil && len(p.queue) > 0 {
+			ready = time.After(time.Until(next))
+		}
+		select {
+		case ev := <-p.txsCh:
+			txs := ev.Txs
+			if room := txPrefetchQueueLimit - len(p.queue); len(txs) > room {
+				txPrefetchDroppedMeter.Mark(int64(len(txs) - room))
+				txs = txs[:room]
+			}
+			p.queue = append(p.queue, txs...)
+
+		case <-p.headCh:
+			// Transactions are executed on the new head from now on
+			p.reset()
+
+		case <-ready:
+			ready = nil
+			start := time.Now()
+			p.prefetchBatch()
+			elapsed := time.Since(start)
+			txPrefetchTimer.Update(elapsed)
+			next = time.Now().Add(time.Duration(float64(elapsed) * (1 - p.budget) / p.budget))
+
+		case <-p.txsSub.Err():
+			return
+		case <-p.headSub.Err():
+			return
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+// reset discards the state the queued transactions are executed on.
+func (p *txPrefetcher) reset() {
+	if p.statedb != nil {
+		p.statedb.StopPrefetcher()
+	}
+	p.header, p.statedb, p.evm, p.count = nil, nil, nil, 0
+}
+
+// prefetchBatch executes up to [txPrefetchBatchSize] queued transactions.
+func (p *txPrefetcher) prefetchBatch() {
+	batch := p.queue
+	if len(batch) > txPrefetchBatchSize {
+		batch = batch[:txPrefetchBatchSize]
+	}
+	p.queue = p.queue[len(batch):]
+	if len(p.queue) == 0 {
+		p.queue = nil
+	}
+
+	if p.statedb == nil || p.count >= txPrefetchHeadLimit {
+		if err := p.prepare(); err != nil {
+			log.Debug("Failed to prepare state for prefetching", "err", err)
+			return
+		}
+	}
+	signer := types.MakeSigner(p.chainConfig, p.header.Number, new(big.Int).SetUint64(p.header.Time))
+	for _, tx := range batch {
+		msg, err := tx.AsMessage(signer, p.header.BaseFee)
+		if err != nil {
+			continue
+		}
+		p.statedb.Prepare(tx.Hash(), p.count)
+		p.evm.Reset(core.NewEVMTxContext(msg), p.statedb)
+		// Failing transactions still touch state worth prefetching, so the
+		// error is ignored.
+		_, _ = core.ApplyMessage(p.evm, msg, new(core.GasPool).AddGas(p.header.GasLimit))
+		// Finalising schedules the touched accounts and slots with the trie
+		// prefetcher.
+		p.statedb.Finalise(true)
+		p.count++
+	}
+	txPrefetchMeter.Mark(int64(len(batch)))
+	// Hashing the state pulls the trie nodes of the touched accounts and
+	// slots into the trie cache, using the tries loaded by the prefetcher.
+	// This terminates the trie prefetcher, so it is restarted for the next
+	// batch.
+	p.statedb.IntermediateRoot(true)
+	p.statedb.StartPrefetcher("miner")
+}
+
+// prepare opens the state of the current head and the environment to execute
+// transactions in the block built on top of it.
+func (p *txPrefetcher) prepare() error {
+	p.reset()
+	head := p.chain.CurrentBlock()
+	statedb, err := p.chain.StateAt(head.Root())
+	if err != nil {
+		return err
+	}
+	timestamp := uint64(time.Now().Unix())
+	if head.Time() > timestamp {
+		timestamp = head.Time()
+	}
+	header := &types.Header{
+		ParentHash: head.Hash(),
+		Number:     new(big.Int).Add(head.Number(), common.Big1),
+		GasLimit:   head.GasLimit(),
+		Time:       timestamp,
+		Coinbase:   p.coinbase(),
+		Difficulty: common.Big1,
+	}
+	if p.chainConfig.IsApricotPhase1(new(big.Int).SetUint64(timestamp)) {
+		header.GasLimit = params.ApricotPhase1GasLimit
+	}
+	if p.chainConfig.IsApricotPhase3(new(big.Int).SetUint64(timestamp)) {
+		feeConfig, err := p.chain.GetFeeConfigAt(head.Header())
+		if err != nil {
+			return err
+		}
+		header.Extra, header.BaseFee, err = dummy.CalcBaseFee(p.chainConfig, feeConfig, head.Header(), timestamp)
+		if err != nil {
+			return err
+		}
+	}
+	statedb.StartPrefetcher("miner")
+	p.header, p.statedb = header, statedb
+	p.evm = vm.NewEVM(core.NewEVMBlockContext(header, p.chain, nil), vm.TxContext{}, statedb, p.chainConfig, *p.chain.GetVMConfig())
+	return nil
+}
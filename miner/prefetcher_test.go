@@ -0,0 +1,135 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"math/big"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/consensus/dummy"
+	"github.com/sankar-boro/axia-network-v2-coreth/core"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/vm"
+	"github.com/sankar-boro/axia-network-v2-coreth/ethdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/params"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// trieReadCounter counts the trie nodes read from the wrapped database.
+type trieReadCounter struct {
+	ethdb.Database
+	reads int64
+}
+
+func (db *trieReadCounter) Get(key []byte) ([]byte, error) {
+	if len(key) == common.HashLength {
+		atomic.AddInt64(&db.reads, 1)
+	}
+	return db.Database.Get(key)
+}
+
+func TestTxPrefetcher(t *testing.T) {
+	var (
+		key, _  = crypto.GenerateKey()
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		config  = params.TestChainConfig
+		signer  = types.LatestSigner(config)
+		db      = &trieReadCounter{Database: rawdb.NewMemoryDatabase()}
+		storage = common.HexToAddress("0xc0")
+		idle    = common.HexToAddress("0xc1")
+		code    = []byte{byte(vm.CALLER), byte(vm.PUSH1), 0, byte(vm.SSTORE)}
+	)
+	gspec := &core.Genesis{
+		Config: config,
+		Alloc: core.GenesisAlloc{
+			addr: {Balance: new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))},
+			// Stores the caller in slot 0
+			storage: {Balance: common.Big0, Code: code, Storage: map[common.Hash]common.Hash{{}: {0x01}, {0x01}: {0x01}}},
+			// Same as [storage] with different storage, but never called
+			idle: {Balance: common.Big0, Code: code, Storage: map[common.Hash]common.Hash{{}: {0x02}, {0x01}: {0x02}}},
+		},
+	}
+	gspec.MustCommit(db)
+	// Disable the snapshot, so that the state is read through the trie cache
+	cacheConfig := *core.DefaultCacheConfig
+	cacheConfig.SnapshotLimit = 0
+	chain, err := core.NewBlockChain(db, &cacheConfig, config, dummy.NewFaker(), vm.Config{}, common.Hash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Stop()
+	poolConfig := core.DefaultTxPoolConfig
+	poolConfig.Journal = ""
+	pool := core.NewTxPool(poolConfig, config, chain)
+	defer pool.Stop()
+
+	numTxs := txPrefetchBatchSize + 1
+	txs := make([]*types.Transaction, numTxs)
+	for i := range txs {
+		tx, err := types.SignTx(types.NewTransaction(uint64(i), storage, common.Big0, 100_000, big.NewInt(300*params.GWei), nil), signer, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		txs[i] = tx
+	}
+
+	// The prefetcher can be started and stopped with the pool
+	p := newTxPrefetcher(config, chain, pool, func() common.Address { return common.Address{} }, 1)
+	p.stop()
+
+	// Execute the queued transactions in batches on top of the head
+	p = &txPrefetcher{
+		chainConfig: config,
+		chain:       chain,
+		coinbase:    func() common.Address { return common.Address{} },
+		budget:      1,
+		queue:       txs,
+	}
+	p.prefetchBatch()
+	if len(p.queue) != 1 {
+		t.Fatalf("expected a single queued transaction after the first batch, got %d", len(p.queue))
+	}
+	if nonce := p.statedb.GetNonce(addr); nonce != txPrefetchBatchSize {
+		t.Fatalf("expected the prefetch state to include %d transactions, got nonce %d", txPrefetchBatchSize, nonce)
+	}
+	if have := p.statedb.GetState(storage, common.Hash{}); have != addr.Hash() {
+		t.Fatalf("unexpected prefetched storage %x", have)
+	}
+	p.prefetchBatch()
+	if len(p.queue) != 0 || p.count != numTxs {
+		t.Fatalf("expected all %d transactions to be executed, executed %d with %d queued", numTxs, p.count, len(p.queue))
+	}
+
+	// Prefetching does not modify the state of the chain
+	statedb, err := chain.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nonce := statedb.GetNonce(addr); nonce != 0 {
+		t.Fatalf("expected the chain state to be unmodified, got nonce %d", nonce)
+	}
+	p.reset()
+
+	// The storage touched by the prefetched transactions is read from the
+	// warmed trie cache, whereas the untouched storage is read from disk. The
+	// accounts are loaded first, so that only storage trie nodes are counted.
+	statedb.GetBalance(storage)
+	statedb.GetBalance(idle)
+	atomic.StoreInt64(&db.reads, 0)
+	if have := statedb.GetState(storage, common.Hash{0x01}); have != (common.Hash{0x01}) {
+		t.Fatalf("unexpected storage %x", have)
+	}
+	if reads := atomic.LoadInt64(&db.reads); reads != 0 {
+		t.Fatalf("expected the prefetched storage to be cached, read %d trie nodes from disk", reads)
+	}
+	if have := statedb.GetState(idle, common.Hash{0x01}); have != (common.Hash{0x02}) {
+		t.Fatalf("unexpected storage %x", have)
+	}
+	if reads := atomic.LoadInt64(&db.reads); reads == 0 {
+		t.Fatal("expected the storage not prefetched to be read from disk")
+	}
+}
@@ -32,6 +32,7 @@ package miner
 import (
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
 	"sync"
 	"time"
@@ -44,6 +45,7 @@ import (
 	"github.com/sankar-boro/axia-network-v2-coreth/core"
 	"github.com/sankar-boro/axia-network-v2-coreth/core/state"
 	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/vm"
 	"github.com/sankar-boro/axia-network-v2-coreth/params"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/event"
@@ -89,6 +91,8 @@ type worker struct {
 	mu       sync.RWMutex   // The lock used to protect the coinbase and extra fields
 	coinbase common.Address
 	clock    *mockable.Clock // Allows us mock the clock for testing
+
+	prefetcher *txPrefetcher // Warms the state of pending txs, nil if disabled
 }
 
 func newWorker(config *Config, chainConfig *params.ChainConfig, engine consensus.Engine, eth Backend, mux *event.TypeMux, clock *mockable.Clock) *worker {
@@ -101,10 +105,27 @@ func newWorker(config *Config, chainConfig *params.ChainConfig, engine consensus
 		chain:       eth.BlockChain(),
 		clock:       clock,
 	}
+	if budget := config.PrefetchBudget; budget > 0 {
+		worker.prefetcher = newTxPrefetcher(chainConfig, worker.chain, eth.TxPool(), worker.etherbase, math.Min(budget, 1))
+	}
 
 	return worker
 }
 
+// stop terminates the background prefetching of the worker.
+func (w *worker) stop() {
+	if w.prefetcher != nil {
+		w.prefetcher.stop()
+	}
+}
+
+// etherbase returns the address used as the block coinbase field.
+func (w *worker) etherbase() common.Address {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.coinbase
+}
+
 // setEtherbase sets the etherbase used to initialize the block coinbase field.
 func (w *worker) setEtherbase(addr common.Address) {
 	w.mu.Lock()
@@ -146,8 +167,11 @@ func (w *worker) commitNewWork() (*types.Block, error) {
 	// Set BaseFee and Extra data field if we are post ApricotPhase3
 	bigTimestamp := big.NewInt(timestamp)
 	if w.chainConfig.IsApricotPhase3(bigTimestamp) {
-		var err error
-		header.Extra, header.BaseFee, err = dummy.CalcBaseFee(w.chainConfig, parent.Header(), uint64(timestamp))
+		feeConfig, err := w.chain.GetFeeConfigAt(parent.Header())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get fee config: %w", err)
+		}
+		header.Extra, header.BaseFee, err = dummy.CalcBaseFee(w.chainConfig, feeConfig, parent.Header(), uint64(timestamp))
 		if err != nil {
 			return nil, fmt.Errorf("failed to calculate new base fee: %w", err)
 		}
@@ -167,6 +191,11 @@ func (w *worker) commitNewWork() (*types.Block, error) {
 	if w.chainConfig.DAOForkSupport && w.chainConfig.DAOForkBlock != nil && w.chainConfig.DAOForkBlock.Cmp(header.Number) == 0 {
 		misc.ApplyDAOHardFork(env.state)
 	}
+	// Configure any stateful precompiles activated in this block
+	blockContext := core.NewEVMBlockContext(header, w.chain, nil)
+	if err := vm.ConfigureStatefulPrecompiles(w.chainConfig, new(big.Int).SetUint64(parent.Time()), blockContext, env.state); err != nil {
+		return nil, fmt.Errorf("failed to configure stateful precompiles: %w", err)
+	}
 
 	// Fill the block with all available pending transactions.
 	pending := w.eth.TxPool().Pending(true)
@@ -114,13 +114,15 @@ var (
 		ApricotPhase5BlockTimestamp: big.NewInt(0),
 	}
 
-	TestChainConfig         = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0)}
-	TestLaunchConfig        = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil}
-	TestApricotPhase1Config = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil}
-	TestApricotPhase2Config = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil}
-	TestApricotPhase3Config = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil}
-	TestApricotPhase4Config = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil}
-	TestApricotPhase5Config = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0)}
+	TestChainConfig         = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, nil}
+	TestLaunchConfig        = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}
+	TestApricotPhase1Config = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}
+	TestApricotPhase2Config = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}
+	TestApricotPhase3Config = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, nil, nil, nil}
+	TestApricotPhase4Config = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, nil, nil}
+	TestApricotPhase5Config = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, nil}
+	TestApricotPhase6Config = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil}
+	TestApricotPhase7Config = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil}
 	TestRules               = TestChainConfig.AxiaRules(new(big.Int), new(big.Int))
 )
 
@@ -161,11 +163,25 @@ type ChainConfig struct {
 	ApricotPhase4BlockTimestamp *big.Int `json:"apricotPhase4BlockTimestamp,omitempty"`
 	// Apricot Phase 5 introduces a batch of atomic transactions with a maximum atomic gas limit per block. (nil = no fork, 0 = already activated)
 	ApricotPhase5BlockTimestamp *big.Int `json:"apricotPhase5BlockTimestamp,omitempty"`
+	// Apricot Phase 6 introduces the opcode and initcode changes of the Shanghai Hard Fork from Ethereum:
+	// PUSH0 (EIP-3855), initcode size limit and metering (EIP-3860) and warm coinbase (EIP-3651). (nil = no fork, 0 = already activated)
+	ApricotPhase6BlockTimestamp *big.Int `json:"apricotPhase6BlockTimestamp,omitempty"`
+	// Apricot Phase 7 introduces transient storage (EIP-1153) and the MCOPY opcode (EIP-5656) from the Cancun Hard Fork from Ethereum. (nil = no fork, 0 = already activated)
+	ApricotPhase7BlockTimestamp *big.Int `json:"apricotPhase7BlockTimestamp,omitempty"`
+
+	// Parameters of the dynamic fee algorithm as of Apricot Phase 5 (nil = DefaultFeeConfig)
+	FeeConfig *FeeConfig `json:"feeConfig,omitempty"`
+
+	// Stateful precompile modules (nil = not configured)
+	ContractDeployerAllowListConfig *ContractDeployerAllowListConfig `json:"contractDeployerAllowListConfig,omitempty"` // Restricts which addresses may deploy contracts
+	TxAllowListConfig               *TxAllowListConfig               `json:"txAllowListConfig,omitempty"`               // Restricts which addresses may issue transactions
+	FeeConfigManagerConfig          *FeeConfigManagerConfig          `json:"feeConfigManagerConfig,omitempty"`          // Allows admins to change the fee config
+	ContractNativeMinterConfig      *ContractNativeMinterConfig      `json:"contractNativeMinterConfig,omitempty"`      // Allows enabled addresses to mint native coins and assets
 }
 
 // String implements the fmt.Stringer interface.
 func (c *ChainConfig) String() string {
-	return fmt.Sprintf("{ChainID: %v Homestead: %v DAO: %v DAOSupport: %v EIP150: %v EIP155: %v EIP158: %v Byzantium: %v Constantinople: %v Petersburg: %v Istanbul: %v, Muir Glacier: %v, Apricot Phase 1: %v, Apricot Phase 2: %v, Apricot Phase 3: %v, Apricot Phase 4: %v, Apricot Phase 5: %v, Engine: Dummy Consensus Engine}",
+	return fmt.Sprintf("{ChainID: %v Homestead: %v DAO: %v DAOSupport: %v EIP150: %v EIP155: %v EIP158: %v Byzantium: %v Constantinople: %v Petersburg: %v Istanbul: %v, Muir Glacier: %v, Apricot Phase 1: %v, Apricot Phase 2: %v, Apricot Phase 3: %v, Apricot Phase 4: %v, Apricot Phase 5: %v, Apricot Phase 6: %v, Apricot Phase 7: %v, Engine: Dummy Consensus Engine}",
 		c.ChainID,
 		c.HomesteadBlock,
 		c.DAOForkBlock,
@@ -183,6 +199,8 @@ func (c *ChainConfig) String() string {
 		c.ApricotPhase3BlockTimestamp,
 		c.ApricotPhase4BlockTimestamp,
 		c.ApricotPhase5BlockTimestamp,
+		c.ApricotPhase6BlockTimestamp,
+		c.ApricotPhase7BlockTimestamp,
 	)
 }
 
@@ -270,6 +288,18 @@ func (c *ChainConfig) IsApricotPhase5(blockTimestamp *big.Int) bool {
 	return isForked(c.ApricotPhase5BlockTimestamp, blockTimestamp)
 }
 
+// IsApricotPhase6 returns whether [blockTimestamp] represents a block
+// with a timestamp after the Apricot Phase 6 upgrade time.
+func (c *ChainConfig) IsApricotPhase6(blockTimestamp *big.Int) bool {
+	return isForked(c.ApricotPhase6BlockTimestamp, blockTimestamp)
+}
+
+// IsApricotPhase7 returns whether [blockTimestamp] represents a block
+// with a timestamp after the Apricot Phase 7 upgrade time.
+func (c *ChainConfig) IsApricotPhase7(blockTimestamp *big.Int) bool {
+	return isForked(c.ApricotPhase7BlockTimestamp, blockTimestamp)
+}
+
 // CheckCompatible checks whether scheduled fork transitions have been imported
 // with a mismatching chain configuration.
 func (c *ChainConfig) CheckCompatible(newcfg *ChainConfig, height uint64, timestamp uint64) *ConfigCompatError {
@@ -344,6 +374,8 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 		{name: "apricotPhase2BlockTimestamp", block: c.ApricotPhase2BlockTimestamp},
 		{name: "apricotPhase3BlockTimestamp", block: c.ApricotPhase3BlockTimestamp},
 		{name: "apricotPhase4BlockTimestamp", block: c.ApricotPhase4BlockTimestamp},
+		{name: "apricotPhase6BlockTimestamp", block: c.ApricotPhase6BlockTimestamp},
+		{name: "apricotPhase7BlockTimestamp", block: c.ApricotPhase7BlockTimestamp},
 	} {
 		if lastFork.name != "" {
 			// Next one must be higher number
@@ -367,7 +399,12 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 	// additional change: require that block number hard forks are either 0 or nil since they should not
 	// be enabled at a specific block number.
 
-	return nil
+	if c.FeeConfig != nil {
+		if err := c.FeeConfig.Verify(); err != nil {
+			return err
+		}
+	}
+	return c.verifyStatefulPrecompiles()
 }
 
 func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, headHeight *big.Int, headTimestamp *big.Int) *ConfigCompatError {
@@ -426,6 +463,23 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, headHeight *big.Int,
 	if isForkIncompatible(c.ApricotPhase5BlockTimestamp, newcfg.ApricotPhase5BlockTimestamp, headTimestamp) {
 		return newCompatError("ApricotPhase5 fork block timestamp", c.ApricotPhase5BlockTimestamp, newcfg.ApricotPhase5BlockTimestamp)
 	}
+	if isForkIncompatible(c.ApricotPhase6BlockTimestamp, newcfg.ApricotPhase6BlockTimestamp, headTimestamp) {
+		return newCompatError("ApricotPhase6 fork block timestamp", c.ApricotPhase6BlockTimestamp, newcfg.ApricotPhase6BlockTimestamp)
+	}
+	if isForkIncompatible(c.ApricotPhase7BlockTimestamp, newcfg.ApricotPhase7BlockTimestamp, headTimestamp) {
+		return newCompatError("ApricotPhase7 fork block timestamp", c.ApricotPhase7BlockTimestamp, newcfg.ApricotPhase7BlockTimestamp)
+	}
+	// The fee config takes effect at ApricotPhase5, so the blocks since its
+	// activation must be reprocessed if it changes. A missing fee config is
+	// the same as the default fee config.
+	if isForked(c.ApricotPhase5BlockTimestamp, headTimestamp) {
+		if storedFeeConfig, newFeeConfig := c.GetFeeConfig(), newcfg.GetFeeConfig(); !storedFeeConfig.Equal(&newFeeConfig) {
+			return newCompatError("fee config", c.feeConfigTimestamp(), newcfg.feeConfigTimestamp())
+		}
+	}
+	if err := c.checkStatefulPrecompilesCompatible(newcfg, headTimestamp); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -496,7 +550,20 @@ type Rules struct {
 	IsByzantium, IsConstantinople, IsPetersburg, IsIstanbul bool
 
 	// Rules for Axia releases
-	IsApricotPhase1, IsApricotPhase2, IsApricotPhase3, IsApricotPhase4, IsApricotPhase5 bool
+	IsApricotPhase1, IsApricotPhase2, IsApricotPhase3, IsApricotPhase4, IsApricotPhase5, IsApricotPhase6, IsApricotPhase7 bool
+
+	// Precompiles maps the addresses of the active stateful precompile modules
+	// to their config sections, and PrecompileAddresses lists these addresses
+	// in ascending order.
+	Precompiles         map[common.Address]StatefulPrecompileConfig
+	PrecompileAddresses []common.Address
+}
+
+// IsStatefulPrecompileEnabled returns whether the stateful precompile at
+// [address] is active.
+func (r *Rules) IsStatefulPrecompileEnabled(address common.Address) bool {
+	_, ok := r.Precompiles[address]
+	return ok
 }
 
 // Rules ensures c's ChainID is not nil.
@@ -528,5 +595,8 @@ func (c *ChainConfig) AxiaRules(blockNum, blockTimestamp *big.Int) Rules {
 	rules.IsApricotPhase3 = c.IsApricotPhase3(blockTimestamp)
 	rules.IsApricotPhase4 = c.IsApricotPhase4(blockTimestamp)
 	rules.IsApricotPhase5 = c.IsApricotPhase5(blockTimestamp)
+	rules.IsApricotPhase6 = c.IsApricotPhase6(blockTimestamp)
+	rules.IsApricotPhase7 = c.IsApricotPhase7(blockTimestamp)
+	rules.Precompiles, rules.PrecompileAddresses = c.statefulPrecompileRules(blockTimestamp)
 	return rules
 }
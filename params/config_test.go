@@ -27,9 +27,12 @@
 package params
 
 import (
+	"encoding/json"
 	"math/big"
 	"reflect"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
 )
 
 func TestCheckCompatible(t *testing.T) {
@@ -136,3 +139,144 @@ func TestCheckCompatible(t *testing.T) {
 		}
 	}
 }
+
+func TestContractDeployerAllowListConfigJSON(t *testing.T) {
+	data := []byte(`{
+		"chainId": 1,
+		"contractDeployerAllowListConfig": {
+			"blockTimestamp": 10,
+			"adminAddresses": ["0x0000000000000000000000000000000000000001"]
+		}
+	}`)
+	var config ChainConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatal(err)
+	}
+	want := &ContractDeployerAllowListConfig{
+		PrecompileUpgrade: PrecompileUpgrade{BlockTimestamp: big.NewInt(10)},
+		AllowListConfig: AllowListConfig{
+			AdminAddresses: []common.Address{common.HexToAddress("0x01")},
+		},
+	}
+	if !reflect.DeepEqual(config.ContractDeployerAllowListConfig, want) {
+		t.Fatalf("wrong config %+v, want %+v", config.ContractDeployerAllowListConfig, want)
+	}
+	if config.IsStatefulPrecompileEnabled(ContractDeployerAllowListAddress, big.NewInt(9)) {
+		t.Fatal("allow list enabled before activation")
+	}
+	if !config.IsStatefulPrecompileEnabled(ContractDeployerAllowListAddress, big.NewInt(10)) {
+		t.Fatal("allow list not enabled at activation")
+	}
+
+	// Moving the activation of an active precompile is incompatible.
+	moved := config
+	moved.ContractDeployerAllowListConfig = &ContractDeployerAllowListConfig{
+		PrecompileUpgrade: PrecompileUpgrade{BlockTimestamp: big.NewInt(20)},
+	}
+	if err := config.CheckCompatible(&moved, 0, 15); err == nil {
+		t.Fatal("expected incompatible config error")
+	}
+	if err := config.CheckCompatible(&moved, 0, 5); err != nil {
+		t.Fatalf("unexpected error before activation: %v", err)
+	}
+}
+
+func TestStatefulPrecompileRules(t *testing.T) {
+	config := &ChainConfig{
+		ContractDeployerAllowListConfig: &ContractDeployerAllowListConfig{PrecompileUpgrade: PrecompileUpgrade{BlockTimestamp: big.NewInt(10)}},
+		FeeConfigManagerConfig:          &FeeConfigManagerConfig{PrecompileUpgrade: PrecompileUpgrade{BlockTimestamp: big.NewInt(0)}},
+		ContractNativeMinterConfig:      &ContractNativeMinterConfig{PrecompileUpgrade: PrecompileUpgrade{BlockTimestamp: big.NewInt(0)}},
+	}
+	if rules := (&ChainConfig{}).AxiaRules(common.Big0, common.Big0); rules.Precompiles != nil || rules.PrecompileAddresses != nil {
+		t.Fatalf("unexpected active precompiles %v", rules.PrecompileAddresses)
+	}
+	for _, test := range []struct {
+		timestamp *big.Int
+		want      []common.Address
+	}{
+		{big.NewInt(0), []common.Address{ContractNativeMinterAddress, FeeConfigManagerAddress}},
+		{big.NewInt(10), []common.Address{ContractDeployerAllowListAddress, ContractNativeMinterAddress, FeeConfigManagerAddress}},
+	} {
+		rules := config.AxiaRules(common.Big0, test.timestamp)
+		if !reflect.DeepEqual(rules.PrecompileAddresses, test.want) {
+			t.Fatalf("timestamp %d: have precompiles %v, want %v", test.timestamp, rules.PrecompileAddresses, test.want)
+		}
+		if len(rules.Precompiles) != len(test.want) {
+			t.Fatalf("timestamp %d: have %d precompile configs, want %d", test.timestamp, len(rules.Precompiles), len(test.want))
+		}
+	}
+}
+
+func TestFeeConfig(t *testing.T) {
+	if err := DefaultFeeConfig.Verify(); err != nil {
+		t.Fatalf("default fee config is invalid: %v", err)
+	}
+	invalid := DefaultFeeConfig
+	invalid.MinBlockGasCost = big.NewInt(2_000_000)
+	if err := invalid.Verify(); err == nil {
+		t.Fatal("expected error for minBlockGasCost > maxBlockGasCost")
+	}
+	invalid = DefaultFeeConfig
+	invalid.TargetGas = nil
+	if err := invalid.Verify(); err == nil {
+		t.Fatal("expected error for missing targetGas")
+	}
+
+	data := []byte(`{
+		"chainId": 1,
+		"apricotPhase5BlockTimestamp": 0,
+		"feeConfig": {
+			"targetGas": 20000000,
+			"minBaseFee": 1000000000,
+			"baseFeeChangeDenominator": 48,
+			"targetBlockRate": 3,
+			"minBlockGasCost": 0,
+			"maxBlockGasCost": 2000000,
+			"blockGasCostStep": 100000
+		}
+	}`)
+	var config ChainConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatal(err)
+	}
+	feeConfig := config.GetFeeConfig()
+	if err := feeConfig.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	if feeConfig.TargetBlockRate != 3 || feeConfig.MinBaseFee.Cmp(big.NewInt(1_000_000_000)) != 0 {
+		t.Fatalf("wrong fee config %+v", feeConfig)
+	}
+	if defaultFeeConfig := TestChainConfig.GetFeeConfig(); !defaultFeeConfig.Equal(&DefaultFeeConfig) {
+		t.Fatal("chain config without fee config should use the default fee config")
+	}
+
+	// The fee config cannot be changed once Apricot Phase 5 is active.
+	changed := config
+	changed.FeeConfig = &DefaultFeeConfig
+	if err := config.CheckCompatible(&changed, 0, 10); err == nil {
+		t.Fatal("expected incompatible config error")
+	} else if err.StoredConfig.Cmp(config.ApricotPhase5BlockTimestamp) != 0 || err.NewConfig.Cmp(changed.ApricotPhase5BlockTimestamp) != 0 {
+		t.Fatalf("wrong fee config timestamps in error: %v", err)
+	}
+	removed := config
+	removed.FeeConfig = nil
+	if err := config.CheckCompatible(&removed, 0, 10); err == nil {
+		t.Fatal("expected incompatible config error")
+	} else if err.StoredConfig.Cmp(config.ApricotPhase5BlockTimestamp) != 0 || err.NewConfig != nil {
+		t.Fatalf("wrong fee config timestamps in error: %v", err)
+	}
+	if err := config.CheckCompatible(&config, 0, 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A missing fee config is compatible with the default fee config.
+	implicit := *TestChainConfig
+	explicit := *TestChainConfig
+	explicit.FeeConfig = &DefaultFeeConfig
+	if err := implicit.CheckCompatible(&explicit, 0, 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := explicit.CheckCompatible(&implicit, 0, 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
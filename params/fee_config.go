@@ -0,0 +1,113 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package params
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// FeeConfig specifies the parameters of the dynamic fee algorithm as of Apricot
+// Phase 5. If FeeConfig is set in ChainConfig, it replaces the Apricot Phase 5
+// constants, otherwise [DefaultFeeConfig] is used.
+type FeeConfig struct {
+	// TargetGas is the amount of gas that may be consumed within the rollup
+	// window without increasing the base fee.
+	TargetGas *big.Int `json:"targetGas,omitempty"`
+	// MinBaseFee is the lower bound of the base fee.
+	MinBaseFee *big.Int `json:"minBaseFee,omitempty"`
+	// BaseFeeChangeDenominator bounds the change of the base fee between
+	// blocks. The higher the value, the slower the base fee responds.
+	BaseFeeChangeDenominator *big.Int `json:"baseFeeChangeDenominator,omitempty"`
+
+	// TargetBlockRate is the target time between blocks in seconds. Blocks
+	// produced faster than this rate increase the block gas cost.
+	TargetBlockRate uint64 `json:"targetBlockRate,omitempty"`
+	// MinBlockGasCost and MaxBlockGasCost bound the block gas cost.
+	MinBlockGasCost *big.Int `json:"minBlockGasCost,omitempty"`
+	MaxBlockGasCost *big.Int `json:"maxBlockGasCost,omitempty"`
+	// BlockGasCostStep is the change of the block gas cost per second that a
+	// block deviates from [TargetBlockRate].
+	BlockGasCostStep *big.Int `json:"blockGasCostStep,omitempty"`
+}
+
+// DefaultFeeConfig is the fee config of Apricot Phase 5.
+var DefaultFeeConfig = FeeConfig{
+	TargetGas:                new(big.Int).SetUint64(ApricotPhase5TargetGas),
+	MinBaseFee:               big.NewInt(ApricotPhase4MinBaseFee),
+	BaseFeeChangeDenominator: new(big.Int).SetUint64(ApricotPhase5BaseFeeChangeDenominator),
+
+	TargetBlockRate:  2,
+	MinBlockGasCost:  big.NewInt(0),
+	MaxBlockGasCost:  big.NewInt(1_000_000),
+	BlockGasCostStep: big.NewInt(200_000),
+}
+
+// Verify returns an error if [f] cannot be used by the dynamic fee algorithm.
+// All values have to fit in a uint64.
+func (f *FeeConfig) Verify() error {
+	for _, field := range []struct {
+		name  string
+		value *big.Int
+	}{
+		{name: "targetGas", value: f.TargetGas},
+		{name: "minBaseFee", value: f.MinBaseFee},
+		{name: "baseFeeChangeDenominator", value: f.BaseFeeChangeDenominator},
+		{name: "minBlockGasCost", value: f.MinBlockGasCost},
+		{name: "maxBlockGasCost", value: f.MaxBlockGasCost},
+		{name: "blockGasCostStep", value: f.BlockGasCostStep},
+	} {
+		if field.value == nil {
+			return fmt.Errorf("fee config %s is not set", field.name)
+		}
+		if !field.value.IsUint64() {
+			return fmt.Errorf("fee config %s (%d) is not a uint64", field.name, field.value)
+		}
+	}
+	switch {
+	case f.TargetGas.Sign() == 0:
+		return fmt.Errorf("fee config targetGas must be positive")
+	case f.BaseFeeChangeDenominator.Sign() == 0:
+		return fmt.Errorf("fee config baseFeeChangeDenominator must be positive")
+	case f.TargetBlockRate == 0:
+		return fmt.Errorf("fee config targetBlockRate must be positive")
+	case f.MinBlockGasCost.Cmp(f.MaxBlockGasCost) > 0:
+		return fmt.Errorf("fee config minBlockGasCost (%d) exceeds maxBlockGasCost (%d)", f.MinBlockGasCost, f.MaxBlockGasCost)
+	}
+	return nil
+}
+
+// Equal returns true if [f] and [other] specify the same fee parameters.
+func (f *FeeConfig) Equal(other *FeeConfig) bool {
+	if f == nil || other == nil {
+		return f == other
+	}
+	return configNumEqual(f.TargetGas, other.TargetGas) &&
+		configNumEqual(f.MinBaseFee, other.MinBaseFee) &&
+		configNumEqual(f.BaseFeeChangeDenominator, other.BaseFeeChangeDenominator) &&
+		f.TargetBlockRate == other.TargetBlockRate &&
+		configNumEqual(f.MinBlockGasCost, other.MinBlockGasCost) &&
+		configNumEqual(f.MaxBlockGasCost, other.MaxBlockGasCost) &&
+		configNumEqual(f.BlockGasCostStep, other.BlockGasCostStep)
+}
+
+// GetFeeConfig returns the fee config of [c] as specified in the genesis. The
+// fee config in effect for a block may differ if the fee config manager
+// precompile is active.
+func (c *ChainConfig) GetFeeConfig() FeeConfig {
+	if c.FeeConfig == nil {
+		return DefaultFeeConfig
+	}
+	return *c.FeeConfig
+}
+
+// feeConfigTimestamp returns the timestamp as of which the fee config specified
+// in [c] takes effect, which is the activation of Apricot Phase 5, or nil if
+// [c] does not specify a fee config.
+func (c *ChainConfig) feeConfigTimestamp() *big.Int {
+	if c.FeeConfig == nil {
+		return nil
+	}
+	return c.ApricotPhase5BlockTimestamp
+}
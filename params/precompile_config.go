@@ -0,0 +1,195 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package params
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Addresses of the stateful precompile modules
+var (
+	ContractDeployerAllowListAddress = common.HexToAddress("0x0200000000000000000000000000000000000000")
+	ContractNativeMinterAddress      = common.HexToAddress("0x0200000000000000000000000000000000000001")
+	TxAllowListAddress               = common.HexToAddress("0x0200000000000000000000000000000000000002")
+	FeeConfigManagerAddress          = common.HexToAddress("0x0200000000000000000000000000000000000003")
+)
+
+// StatefulPrecompileConfig is implemented by the config section of each
+// stateful precompile module in ChainConfig.
+type StatefulPrecompileConfig interface {
+	// Address returns the address the precompile is installed at.
+	Address() common.Address
+	// Timestamp returns the block timestamp at which the precompile is
+	// activated (nil = never activated).
+	Timestamp() *big.Int
+}
+
+// PrecompileUpgrade contains the activation timestamp that is part of the
+// config section of every stateful precompile module.
+type PrecompileUpgrade struct {
+	BlockTimestamp *big.Int `json:"blockTimestamp,omitempty"` // Activation block timestamp (nil = no fork, 0 = already activated)
+}
+
+// Timestamp implements StatefulPrecompileConfig
+func (p *PrecompileUpgrade) Timestamp() *big.Int {
+	return p.BlockTimestamp
+}
+
+// AllowListConfig specifies the roles that are assigned in an allow list
+// precompile when it is activated.
+type AllowListConfig struct {
+	AdminAddresses   []common.Address `json:"adminAddresses,omitempty"`   // Addresses that can modify the allow list
+	EnabledAddresses []common.Address `json:"enabledAddresses,omitempty"` // Addresses that are allowed without being able to modify the allow list
+}
+
+// ContractDeployerAllowListConfig is the config section of the allow list
+// precompile restricting which addresses may deploy contracts.
+type ContractDeployerAllowListConfig struct {
+	PrecompileUpgrade
+	AllowListConfig
+}
+
+// Address implements StatefulPrecompileConfig
+func (c *ContractDeployerAllowListConfig) Address() common.Address {
+	return ContractDeployerAllowListAddress
+}
+
+// TxAllowListConfig is the config section of the allow list precompile
+// restricting which addresses may issue transactions.
+type TxAllowListConfig struct {
+	PrecompileUpgrade
+	AllowListConfig
+}
+
+// Address implements StatefulPrecompileConfig
+func (c *TxAllowListConfig) Address() common.Address {
+	return TxAllowListAddress
+}
+
+// FeeConfigManagerConfig is the config section of the precompile that allows
+// enabled addresses to change the fee config of the chain.
+type FeeConfigManagerConfig struct {
+	PrecompileUpgrade
+	AllowListConfig
+	// InitialFeeConfig is stored when the precompile is activated
+	// (nil = the fee config of the chain config).
+	InitialFeeConfig *FeeConfig `json:"initialFeeConfig,omitempty"`
+}
+
+// Address implements StatefulPrecompileConfig
+func (c *FeeConfigManagerConfig) Address() common.Address {
+	return FeeConfigManagerAddress
+}
+
+// ContractNativeMinterConfig is the config section of the precompile that
+// allows enabled addresses to mint native coins and multicoin assets.
+type ContractNativeMinterConfig struct {
+	PrecompileUpgrade
+	AllowListConfig
+}
+
+// Address implements StatefulPrecompileConfig
+func (c *ContractNativeMinterConfig) Address() common.Address {
+	return ContractNativeMinterAddress
+}
+
+// StatefulPrecompileConfigs returns the config sections of the stateful
+// precompile modules that are set in [c].
+func (c *ChainConfig) StatefulPrecompileConfigs() []StatefulPrecompileConfig {
+	configs := make([]StatefulPrecompileConfig, 0)
+	if c.ContractDeployerAllowListConfig != nil {
+		configs = append(configs, c.ContractDeployerAllowListConfig)
+	}
+	if c.TxAllowListConfig != nil {
+		configs = append(configs, c.TxAllowListConfig)
+	}
+	if c.FeeConfigManagerConfig != nil {
+		configs = append(configs, c.FeeConfigManagerConfig)
+	}
+	if c.ContractNativeMinterConfig != nil {
+		configs = append(configs, c.ContractNativeMinterConfig)
+	}
+	return configs
+}
+
+// IsStatefulPrecompileEnabled returns whether the stateful precompile at
+// [address] is active at [blockTimestamp].
+func (c *ChainConfig) IsStatefulPrecompileEnabled(address common.Address, blockTimestamp *big.Int) bool {
+	for _, config := range c.StatefulPrecompileConfigs() {
+		if config.Address() == address {
+			return isForked(config.Timestamp(), blockTimestamp)
+		}
+	}
+	return false
+}
+
+// verifyStatefulPrecompiles checks that no two stateful precompile modules
+// are installed at the same address.
+func (c *ChainConfig) verifyStatefulPrecompiles() error {
+	addresses := make(map[common.Address]struct{})
+	for _, config := range c.StatefulPrecompileConfigs() {
+		if _, ok := addresses[config.Address()]; ok {
+			return fmt.Errorf("duplicate stateful precompile at address %s", config.Address())
+		}
+		addresses[config.Address()] = struct{}{}
+	}
+	if c.FeeConfigManagerConfig != nil && c.FeeConfigManagerConfig.InitialFeeConfig != nil {
+		if err := c.FeeConfigManagerConfig.InitialFeeConfig.Verify(); err != nil {
+			return fmt.Errorf("invalid initial fee config: %w", err)
+		}
+	}
+	return nil
+}
+
+// checkStatefulPrecompilesCompatible returns an error if the activation of a
+// stateful precompile in [newcfg] would alter the chain before [headTimestamp].
+func (c *ChainConfig) checkStatefulPrecompilesCompatible(newcfg *ChainConfig, headTimestamp *big.Int) *ConfigCompatError {
+	stored := make(map[common.Address]*big.Int)
+	for _, config := range c.StatefulPrecompileConfigs() {
+		stored[config.Address()] = config.Timestamp()
+	}
+	for _, config := range newcfg.StatefulPrecompileConfigs() {
+		storedTimestamp := stored[config.Address()]
+		delete(stored, config.Address())
+		if isForkIncompatible(storedTimestamp, config.Timestamp(), headTimestamp) {
+			return newCompatError(fmt.Sprintf("stateful precompile %s block timestamp", config.Address()), storedTimestamp, config.Timestamp())
+		}
+	}
+	// Precompiles that were removed from the config
+	for address, storedTimestamp := range stored {
+		if isForkIncompatible(storedTimestamp, nil, headTimestamp) {
+			return newCompatError(fmt.Sprintf("stateful precompile %s block timestamp", address), storedTimestamp, nil)
+		}
+	}
+	return nil
+}
+
+// statefulPrecompileRules returns the config sections of the stateful
+// precompiles that are active at [blockTimestamp], keyed by address, along with
+// their addresses in ascending order. Both are nil if none is active.
+func (c *ChainConfig) statefulPrecompileRules(blockTimestamp *big.Int) (map[common.Address]StatefulPrecompileConfig, []common.Address) {
+	var (
+		precompiles map[common.Address]StatefulPrecompileConfig
+		addresses   []common.Address
+	)
+	for _, config := range c.StatefulPrecompileConfigs() {
+		if !isForked(config.Timestamp(), blockTimestamp) {
+			continue
+		}
+		if precompiles == nil {
+			precompiles = make(map[common.Address]StatefulPrecompileConfig)
+		}
+		precompiles[config.Address()] = config
+		addresses = append(addresses, config.Address())
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		return bytes.Compare(addresses[i][:], addresses[j][:]) < 0
+	})
+	return precompiles, addresses
+}
@@ -131,7 +131,10 @@ const (
 	// Introduced in Tangerine Whistle (Eip 150)
 	CreateBySelfdestructGas uint64 = 25000
 
-	MaxCodeSize = 24576 // Maximum bytecode to permit for a contract
+	MaxCodeSize     = 24576           // Maximum bytecode to permit for a contract
+	MaxInitCodeSize = 2 * MaxCodeSize // Maximum initcode to permit in a creation transaction and create instructions
+
+	InitCodeWordGas uint64 = 2 // Once per word of the init code when creating a contract.
 
 	// Precompiled contract gas prices
 
@@ -169,6 +172,19 @@ const (
 	// asset transfer itself, which is a write to state storage. The cost of creating a new account and
 	// normal value transfer is assessed separately from this cost.
 	AssetCallApricot uint64 = 20000
+	// Gas price for reading the role of an address from an allow list precompile. Based on the cost
+	// of an SLOAD operation since roles are kept in the precompile's state storage.
+	AllowListReadGas uint64 = 2100
+	// Gas price for modifying the role of an address in an allow list precompile. Based on the cost
+	// of an SSTORE operation setting a new storage slot.
+	AllowListModifyGas uint64 = 20000
+	// Gas price for reading the fee config from the fee config manager precompile, per storage slot.
+	FeeConfigManagerReadGas uint64 = 2100
+	// Gas price for changing the fee config in the fee config manager precompile, per storage slot.
+	FeeConfigManagerWriteGas uint64 = 20000
+	// Gas price for minting native coins or assets. Based on the cost of the balance update of the
+	// recipient, which is a write to state storage.
+	NativeMinterMintGas uint64 = 30000
 )
 
 // Gas discount table for BLS12-381 G1 and G2 multi exponentiation operations
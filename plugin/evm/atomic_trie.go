@@ -79,6 +79,11 @@ type AtomicTrie interface {
 	// Syncer creates and returns a new Syncer object that can be used to sync the
 	// state of the atomic trie from peers
 	Syncer(client syncclient.LeafClient, targetRoot common.Hash, targetHeight uint64) Syncer
+
+	// Prune deletes the nodes of the trie that are not part of the trie at its
+	// last commit or at [summaryHeight], using a bloom filter of [bloomSize]
+	// megabytes written to [datadir] to resume if interrupted.
+	Prune(datadir string, bloomSize uint64, summaryHeight uint64) error
 }
 
 // AtomicTrieIterator is a stateful iterator that iterates the leafs of an AtomicTrie
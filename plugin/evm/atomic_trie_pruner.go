@@ -0,0 +1,308 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sankar-boro/axia-network-v2/database/prefixdb"
+	"github.com/sankar-boro/axia-network-v2/database/versiondb"
+	"github.com/sankar-boro/axia-network-v2/utils/wrappers"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/state/pruner"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
+	"github.com/sankar-boro/axia-network-v2-coreth/ethdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/trie"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// atomicTrieBloomFilePrefix is the filename prefix of the bloom filter of
+	// the atomic trie nodes retained by offline pruning.
+	atomicTrieBloomFilePrefix = "atomictriebloom"
+
+	// atomicTrieBloomFileSuffix is the filename suffix of the bloom filter of
+	// the atomic trie nodes retained by offline pruning.
+	atomicTrieBloomFileSuffix = "bf.gz"
+
+	// atomicTrieBloomFileTempSuffix is the filename suffix of the bloom filter
+	// while it is being written to disk.
+	atomicTrieBloomFileTempSuffix = ".tmp"
+)
+
+// atomicTrieOfflinePruningKey marks in the atomic trie metadata database that
+// offline pruning of the atomic trie completed, so that it is not run again
+// before the node is started with it disabled.
+var atomicTrieOfflinePruningKey = []byte("atomicTrieOfflinePruning")
+
+// atomicTrieBloom is the subset of the state bloom of the offline pruner used
+// to prune the atomic trie.
+type atomicTrieBloom interface {
+	Put(key []byte, value []byte) error
+	Contain(key []byte) (bool, error)
+}
+
+// handleAtomicTriePruning runs offline pruning of the atomic trie if enabled by
+// the config, keeping the trie at its last commit and at the height of the
+// last state summary below [lastAcceptedHeight].
+func (vm *VM) handleAtomicTriePruning(lastAcceptedHeight uint64) error {
+	metadataDB := Database{prefixdb.New(atomicTrieMetaDBPrefix, vm.db)}
+	if !vm.config.OfflinePruningAtomicTrie {
+		// Delete the marker to indicate that the node started with atomic trie
+		// offline pruning disabled.
+		if err := rawdb.DeleteTimeMarker(metadataDB, atomicTrieOfflinePruningKey); err != nil {
+			return fmt.Errorf("failed to delete atomic trie offline pruning marker: %w", err)
+		}
+		return vm.db.Commit()
+	}
+	if lastRun, err := rawdb.ReadTimeMarker(metadataDB, atomicTrieOfflinePruningKey); err == nil {
+		log.Error("Atomic trie offline pruning is not meant to be left enabled permanently. Please disable it and allow your node to start successfully before running it again.")
+		return fmt.Errorf("cannot start chain with atomic trie offline pruning enabled on consecutive starts (last=%v)", lastRun)
+	}
+	summaryHeight := lastAcceptedHeight - lastAcceptedHeight%vm.config.StateSyncCommitInterval
+	return vm.atomicTrie.Prune(vm.config.OfflinePruningDataDirectory, vm.config.OfflinePruningBloomFilterSize, summaryHeight)
+}
+
+// Prune deletes the nodes of the atomic trie that are not part of the trie at
+// its last commit or at [summaryHeight], along with the metadata of the pruned
+// roots. The bloom filter of the retained nodes is written to [datadir], so
+// that an interrupted run is resumed by recoverAtomicTriePruning.
+func (a *atomicTrie) Prune(datadir string, bloomSize uint64, summaryHeight uint64) error {
+	// If the bloom filter was committed by an interrupted run, part of the trie
+	// may already be deleted, so the run must be resumed with it.
+	bloomPath, _, err := findAtomicTrieBloomFilter(datadir)
+	if err != nil {
+		return err
+	}
+	if bloomPath != "" {
+		return recoverAtomicTriePruning(datadir, a.db)
+	}
+
+	start := time.Now()
+	bloom, bloomPath, err := a.commitPruningBloom(datadir, bloomSize, summaryHeight)
+	if err != nil {
+		return err
+	}
+	log.Info("Committed atomic trie bloom filter", "path", bloomPath, "elapsed", common.PrettyDuration(time.Since(start)))
+	return pruneAtomicTrie(a.db, bloom, bloomPath, start)
+}
+
+// commitPruningBloom adds the nodes of the atomic trie at its last commit and
+// at [summaryHeight] to a new bloom filter of [bloomSize] megabytes, and writes
+// it to [datadir]. It returns the bloom filter and its path.
+func (a *atomicTrie) commitPruningBloom(datadir string, bloomSize uint64, summaryHeight uint64) (atomicTrieBloom, string, error) {
+	roots := []common.Hash{a.lastCommittedHash}
+	summaryRoot, err := a.Root(summaryHeight)
+	if err != nil {
+		return nil, "", err
+	}
+	if summaryRoot != (common.Hash{}) && summaryRoot != a.lastCommittedHash {
+		roots = append(roots, summaryRoot)
+	}
+	bloom, err := pruner.NewStateBloomWithSize(bloomSize)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, root := range roots {
+		if root == (common.Hash{}) || root == types.EmptyRootHash {
+			continue
+		}
+		t, err := trie.New(root, a.trieDB)
+		if err != nil {
+			return nil, "", err
+		}
+		it := t.NodeIterator(nil)
+		for it.Next(true) {
+			// Embedded nodes don't have hash.
+			if hash := it.Hash(); hash != (common.Hash{}) {
+				bloom.Put(hash[:], nil)
+			}
+		}
+		if err := it.Error(); err != nil {
+			return nil, "", fmt.Errorf("failed to iterate atomic trie at root %s: %w", root, err)
+		}
+	}
+	bloomPath := atomicTrieBloomFilterName(datadir, a.lastCommittedHash)
+	if err := bloom.Commit(bloomPath, bloomPath+atomicTrieBloomFileTempSuffix); err != nil {
+		return nil, "", err
+	}
+	return bloom, bloomPath, nil
+}
+
+// recoverAtomicTriePruning resumes offline pruning of the atomic trie in [db]
+// if a previous run was interrupted after committing its bloom filter to
+// [datadir]. It must be called before the atomic trie is loaded, since the
+// trie may be missing nodes until pruning completes.
+func recoverAtomicTriePruning(datadir string, db *versiondb.Database) error {
+	if datadir == "" {
+		return nil
+	}
+	bloomPath, bloomRoot, err := findAtomicTrieBloomFilter(datadir)
+	if err != nil {
+		return err
+	}
+	if bloomPath == "" {
+		return nil // nothing to recover
+	}
+	root, _, err := lastCommittedRootIfExists(prefixdb.New(atomicTrieMetaDBPrefix, db))
+	if err != nil {
+		return err
+	}
+	if bloomRoot != root {
+		return fmt.Errorf("cannot recover atomic trie pruning to bloom root: %s, with last committed root: %s", bloomRoot, root)
+	}
+	bloom, err := pruner.NewStateBloomFromDisk(bloomPath)
+	if err != nil {
+		return err
+	}
+	log.Info("Loaded atomic trie bloom filter", "path", bloomPath)
+	return pruneAtomicTrie(db, bloom, bloomPath, time.Now())
+}
+
+// pruneAtomicTrie deletes the atomic trie nodes in [db] that are not contained
+// in [bloom], and the roots of the heights whose trie was pruned. The bloom
+// filter at [bloomPath] is removed once pruning completes.
+func pruneAtomicTrie(db *versiondb.Database, bloom atomicTrieBloom, bloomPath string, start time.Time) error {
+	var (
+		atomicTrieDB = prefixdb.New(atomicTrieDBPrefix, db)
+		metadataDB   = prefixdb.New(atomicTrieMetaDBPrefix, db)
+		nodes        int
+		size         common.StorageSize
+		roots        int
+		pstart       = time.Now()
+		logged       = time.Now()
+	)
+	it := atomicTrieDB.NewIterator()
+	batch := atomicTrieDB.NewBatch()
+	for it.Next() {
+		key := common.CopyBytes(it.Key())
+		if len(key) != common.HashLength {
+			continue
+		}
+		if ok, err := bloom.Contain(key); err != nil {
+			it.Release()
+			return err
+		} else if ok {
+			continue
+		}
+		if err := batch.Delete(key); err != nil {
+			it.Release()
+			return err
+		}
+		nodes++
+		size += common.StorageSize(len(key) + len(it.Value()))
+
+		if batch.Size() >= ethdb.IdealBatchSize {
+			// The deletions are flushed to disk as they are made, so the
+			// iterator is recreated over the updated database.
+			it.Release()
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+			if err := db.Commit(); err != nil {
+				return err
+			}
+			it = atomicTrieDB.NewIteratorWithStart(key)
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Pruning atomic trie nodes", "nodes", nodes, "size", size, "elapsed", common.PrettyDuration(time.Since(pstart)))
+			logged = time.Now()
+		}
+	}
+	err := it.Error()
+	it.Release()
+	if err != nil {
+		return fmt.Errorf("failed to iterate atomic trie during pruning: %w", err)
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+
+	// Delete the roots of the heights whose trie was pruned, so that they are
+	// no longer served to state sync.
+	it = metadataDB.NewIterator()
+	batch = metadataDB.NewBatch()
+	for it.Next() {
+		if len(it.Key()) != wrappers.LongLen || len(it.Value()) != common.HashLength {
+			continue
+		}
+		root := common.BytesToHash(it.Value())
+		if root == types.EmptyRootHash {
+			continue
+		}
+		if ok, err := bloom.Contain(root[:]); err != nil {
+			it.Release()
+			return err
+		} else if ok {
+			continue
+		}
+		if err := batch.Delete(it.Key()); err != nil {
+			it.Release()
+			return err
+		}
+		roots++
+	}
+	err = it.Error()
+	it.Release()
+	if err != nil {
+		return fmt.Errorf("failed to iterate atomic trie metadata during pruning: %w", err)
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	log.Info("Pruned atomic trie", "nodes", nodes, "size", size, "roots", roots, "elapsed", common.PrettyDuration(time.Since(pstart)))
+
+	// Write marker to DB to indicate offline pruning finished successfully. We
+	// write before removing the bloom filter to guarantee that if the node dies
+	// midway through pruning, then this will run during recovery.
+	if err := rawdb.WriteTimeMarker(Database{metadataDB}, atomicTrieOfflinePruningKey); err != nil {
+		return fmt.Errorf("failed to write atomic trie offline pruning success marker: %w", err)
+	}
+	if err := db.Commit(); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(bloomPath); err != nil {
+		return fmt.Errorf("failed to remove atomic trie bloom filter from disk: %w", err)
+	}
+	log.Info("Atomic trie pruning successful", "pruned", size, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+func atomicTrieBloomFilterName(datadir string, hash common.Hash) string {
+	return filepath.Join(datadir, fmt.Sprintf("%s.%s.%s", atomicTrieBloomFilePrefix, hash.Hex(), atomicTrieBloomFileSuffix))
+}
+
+func isAtomicTrieBloomFilter(filename string) (bool, common.Hash) {
+	filename = filepath.Base(filename)
+	if strings.HasPrefix(filename, atomicTrieBloomFilePrefix) && strings.HasSuffix(filename, atomicTrieBloomFileSuffix) {
+		return true, common.HexToHash(filename[len(atomicTrieBloomFilePrefix)+1 : len(filename)-len(atomicTrieBloomFileSuffix)-1])
+	}
+	return false, common.Hash{}
+}
+
+func findAtomicTrieBloomFilter(datadir string) (string, common.Hash, error) {
+	var (
+		bloomPath string
+		bloomRoot common.Hash
+	)
+	if err := filepath.Walk(datadir, func(path string, info os.FileInfo, err error) error {
+		if info != nil && !info.IsDir() {
+			if ok, root := isAtomicTrieBloomFilter(path); ok {
+				bloomPath = path
+				bloomRoot = root
+			}
+		}
+		return nil
+	}); err != nil {
+		return "", common.Hash{}, err
+	}
+	return bloomPath, bloomRoot, nil
+}
@@ -0,0 +1,84 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sankar-boro/axia-network-v2/chains/atomic"
+	"github.com/sankar-boro/axia-network-v2/database/memdb"
+	"github.com/sankar-boro/axia-network-v2/database/versiondb"
+	"github.com/sankar-boro/axia-network-v2/ids"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestPruneAtomicTrie(t *testing.T) {
+	const (
+		lastAcceptedHeight = 105
+		commitInterval     = 10
+		summaryHeight      = 80
+	)
+	for _, interrupted := range []bool{false, true} {
+		t.Run(fmt.Sprintf("interrupted=%t", interrupted), func(t *testing.T) {
+			var (
+				db            = versiondb.New(memdb.New())
+				codec         = testTxCodec()
+				operationsMap = make(map[uint64]map[ids.ID]*atomic.Requests)
+				roots         = make(map[uint64]common.Hash)
+				datadir       = t.TempDir()
+			)
+			txRepo, err := NewAtomicTxRepository(versiondb.New(memdb.New()), codec, 0)
+			assert.NoError(t, err)
+			writeTxs(t, txRepo, 1, lastAcceptedHeight+1, constTxsPerHeight(2), nil, operationsMap)
+
+			// Index the operations block by block, so that the trie is committed
+			// at every interval
+			repo, err := NewAtomicTxRepository(db, codec, 0)
+			assert.NoError(t, err)
+			atomicTrie, err := newAtomicTrie(db, testSharedMemory(), nil, repo, codec, 0, commitInterval)
+			assert.NoError(t, err)
+			for height := uint64(1); height <= lastAcceptedHeight; height++ {
+				assert.NoError(t, atomicTrie.Index(height, operationsMap[height]))
+				if height%commitInterval == 0 {
+					roots[height], _ = atomicTrie.LastCommitted()
+				}
+			}
+			assert.NoError(t, db.Commit())
+
+			if interrupted {
+				// Simulate a run interrupted after committing its bloom filter
+				_, _, err = atomicTrie.commitPruningBloom(datadir, 1, summaryHeight)
+				assert.NoError(t, err)
+				assert.NoError(t, recoverAtomicTriePruning(datadir, db))
+			} else {
+				assert.NoError(t, atomicTrie.Prune(datadir, 1, summaryHeight))
+			}
+			bloomPath, _, err := findAtomicTrieBloomFilter(datadir)
+			assert.NoError(t, err)
+			assert.Empty(t, bloomPath)
+			_, err = rawdb.ReadTimeMarker(Database{atomicTrie.metadataDB}, atomicTrieOfflinePruningKey)
+			assert.NoError(t, err)
+
+			// Only the tries at the last commit and the summary height remain
+			for height, root := range roots {
+				storedRoot, err := atomicTrie.Root(height)
+				assert.NoError(t, err)
+				if height == lastAcceptedHeight-lastAcceptedHeight%commitInterval || height == summaryHeight {
+					assert.Equal(t, root, storedRoot)
+					verifyOperations(t, atomicTrie, codec, root, 1, height, operationsMap)
+					continue
+				}
+				assert.Equal(t, common.Hash{}, storedRoot)
+				has, err := atomicTrie.atomicTrieDB.Has(root[:])
+				assert.NoError(t, err)
+				assert.False(t, has, "root at height %d not pruned", height)
+			}
+		})
+	}
+}
@@ -8,7 +8,9 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
 	"github.com/sankar-boro/axia-network-v2-coreth/eth"
+	"github.com/sankar-boro/axia-network-v2-coreth/rpc"
 	"github.com/spf13/cast"
 )
 
@@ -22,18 +24,33 @@ const (
 	defaultRpcTxFeeCap                            = 100        // 100 AXC
 	defaultMetricsExpensiveEnabled                = false
 	defaultApiMaxDuration                         = 0 // Default to no maximum API call duration
+	defaultApiSlowRequestThreshold                = 0 // Default to not logging slow API calls
 	defaultWsCpuRefillRate                        = 0 // Default to no maximum WS CPU usage
 	defaultWsCpuMaxStored                         = 0 // Default to no maximum WS CPU usage
 	defaultMaxBlocksPerRequest                    = 0 // Default to no maximum on the number of blocks per getLogs request
+	defaultRpcRateLimitRefillRate                 = 0 // Default to no per-client RPC rate limiting
+	defaultRpcRateLimitMaxStored                  = 0 // Default to no per-client RPC rate limiting
+	defaultRpcRateLimitMaxClients                 = 4096
+	defaultBatchRequestLimit                      = 0 // Default to no maximum on the number of items in a JSON-RPC batch
+	defaultBatchResponseMaxSize                   = 0 // Default to no maximum on the size of the responses to a JSON-RPC batch
 	defaultContinuousProfilerFrequency            = 15 * time.Minute
 	defaultContinuousProfilerMaxFiles             = 5
 	defaultTxRegossipFrequency                    = 1 * time.Minute
 	defaultTxRegossipMaxSize                      = 15
+	defaultBuildBlockPrefetchBudget               = 0   // Prefetching for block building is opt-in
 	defaultOfflinePruningBloomFilterSize   uint64 = 512 // Default size (MB) for the offline pruner to use
 	defaultLogLevel                               = "info"
 	defaultPopulateMissingTriesParallelism        = 1024
 	defaultMaxOutboundActiveRequests              = 8
-	defaultStateSyncServerTrieCache               = 64 // MB
+	defaultStateSyncServerTrieCache               = 64  // MB
+	defaultDatabaseCache                          = 512 // MB
+	defaultDatabaseHandles                        = 1024
+	defaultFreezerThreshold                uint64 = 90000
+	defaultOnlinePruningInterval                  = 24 * time.Hour
+	defaultOnlinePruningBloomFilterSize    uint64 = 512 // Default size (MB) for the online pruner to use
+	defaultOnlinePruningBatchSize                 = 10_000
+	defaultOnlinePruningBatchDelay                = 50 * time.Millisecond
+	defaultStateHistory                    uint64 = 128 // Default number of recent accepted states kept by the path scheme
 
 	// defaultStateSyncMinBlocks is the minimum number of blocks the blockchain
 	// should be ahead of local last accepted to perform state sync.
@@ -55,6 +72,19 @@ var defaultEnabledAPIs = []string{
 	"internal-public-transaction-pool",
 }
 
+// defaultRPCRateLimitMethodCosts is the number of rate limit tokens charged
+// for methods that are more expensive to serve than a typical call. Methods
+// that are not listed cost a single token.
+var defaultRPCRateLimitMethodCosts = map[string]uint64{
+	"eth_call":                 5,
+	"eth_estimateGas":          5,
+	"eth_getLogs":              10,
+	"debug_traceCall":          20,
+	"debug_traceTransaction":   20,
+	"debug_traceBlockByNumber": 50,
+	"debug_traceBlockByHash":   50,
+}
+
 type Duration struct {
 	time.Duration
 }
@@ -65,6 +95,7 @@ type Config struct {
 	SnowmanAPIEnabled     bool   `json:"snowman-api-enabled"`
 	CorethAdminAPIEnabled bool   `json:"coreth-admin-api-enabled"`
 	CorethAdminAPIDir     string `json:"coreth-admin-api-dir"`
+	GraphQLEnabled        bool   `json:"graphql-enabled"`
 
 	// EnabledEthAPIs is a list of Ethereum services that should be enabled
 	// If none is specified, then we use the default list [defaultEnabledAPIs]
@@ -92,18 +123,35 @@ type Config struct {
 	PopulateMissingTries            *uint64 `json:"populate-missing-tries,omitempty"`   // Sets the starting point for re-populating missing tries. Disables re-generation if nil.
 	PopulateMissingTriesParallelism int     `json:"populate-missing-tries-parallelism"` // Number of concurrent readers to use when re-populating missing tries on startup.
 
+	// Execution Settings
+	ParallelExecutionWorkers int     `json:"parallel-execution-workers"`  // Number of workers to optimistically execute the transactions of a block in parallel (< 2 = serial execution)
+	BuildBlockPrefetchBudget float64 `json:"build-block-prefetch-budget"` // Fraction of a CPU core used to prefetch the state of pending txs for block building (0 = disabled)
+	StorageTrieWorkers       int     `json:"storage-trie-workers"`        // Number of workers hashing and committing the storage tries of a block concurrently (< 1 = GOMAXPROCS)
+
 	// Metric Settings
 	MetricsExpensiveEnabled bool `json:"metrics-expensive-enabled"` // Debug-level metrics that might impact runtime performance
 
 	// API Settings
 	LocalTxsEnabled         bool     `json:"local-txs-enabled"`
 	APIMaxDuration          Duration `json:"api-max-duration"`
+	APISlowRequestThreshold Duration `json:"api-slow-request-threshold"` // API calls that take at least this long are logged (0 disables logging)
 	WSCPURefillRate         Duration `json:"ws-cpu-refill-rate"`
 	WSCPUMaxStored          Duration `json:"ws-cpu-max-stored"`
 	MaxBlocksPerRequest     int64    `json:"api-max-blocks-per-request"`
 	AllowUnfinalizedQueries bool     `json:"allow-unfinalized-queries"`
 	AllowUnprotectedTxs     bool     `json:"allow-unprotected-txs"`
 
+	// RPC Rate Limit Settings
+	RPCRateLimitRefillRate   float64           `json:"rpc-rate-limit-refill-rate"`    // Tokens added to each client's bucket per second (0 disables rate limiting)
+	RPCRateLimitMaxStored    uint64            `json:"rpc-rate-limit-max-stored"`     // Maximum number of tokens a client's bucket can hold
+	RPCRateLimitMethodCosts  map[string]uint64 `json:"rpc-rate-limit-method-costs"`   // Tokens charged per call of a method, merged with the defaults
+	RPCRateLimitAPIKeyHeader string            `json:"rpc-rate-limit-api-key-header"` // HTTP header identifying a client (falls back to the remote IP)
+	RPCRateLimitMaxClients   int               `json:"rpc-rate-limit-max-clients"`    // Maximum number of client buckets to track
+
+	// JSON-RPC Batch Settings
+	BatchRequestLimit    int `json:"batch-request-limit"`     // Maximum number of items in a batch (0 disables the limit)
+	BatchResponseMaxSize int `json:"batch-response-max-size"` // Maximum cumulative size in bytes of the responses to a batch (0 disables the limit)
+
 	// Keystore Settings
 	KeystoreDirectory             string `json:"keystore-directory"` // both absolute and relative supported
 	KeystoreExternalSigner        string `json:"keystore-external-signer"`
@@ -121,6 +169,30 @@ type Config struct {
 	OfflinePruning                bool   `json:"offline-pruning-enabled"`
 	OfflinePruningBloomFilterSize uint64 `json:"offline-pruning-bloom-filter-size"`
 	OfflinePruningDataDirectory   string `json:"offline-pruning-data-directory"`
+	OfflinePruningAtomicTrie      bool   `json:"offline-pruning-atomic-trie-enabled"` // If enabled, the atomic trie is pruned to the roots needed for state sync on startup
+
+	// Online Pruning Settings
+	OnlinePruning                bool     `json:"online-pruning-enabled"`           // If enabled, trie nodes unreachable from the retained roots are deleted in the background
+	OnlinePruningInterval        Duration `json:"online-pruning-interval"`          // Time between the starts of two online pruning runs
+	OnlinePruningBloomFilterSize uint64   `json:"online-pruning-bloom-filter-size"` // Size (MB) of the bloom filter of live trie nodes
+	OnlinePruningBatchSize       int      `json:"online-pruning-batch-size"`        // Maximum number of trie nodes deleted in one batch
+	OnlinePruningBatchDelay      Duration `json:"online-pruning-batch-delay"`       // Time to wait between two batches of deletions
+
+	// State storage settings
+	StateScheme  string `json:"state-scheme"`  // Scheme of the trie nodes on disk ("hash" or "path"), can only be chosen for a new database
+	StateHistory uint64 `json:"state-history"` // Number of most recent accepted states kept by the path scheme
+	HistoryDiffs bool   `json:"history-diffs"` // Whether to index the state changes of accepted blocks to serve historical state with pruning enabled
+	LogIndex     bool   `json:"log-index"`     // Whether to index the logs of accepted blocks by address and topic to serve eth_getLogs without bloom bits false positives
+
+	// Database settings
+	DatabaseType    string `json:"database-type"`    // If set to "leveldb" or "pebble", chain data is stored in a database managed by Coreth instead of the node's database
+	DatabasePath    string `json:"database-path"`    // Directory of the database managed by Coreth
+	DatabaseCache   int    `json:"database-cache"`   // Megabytes of memory allocated to the caches of the database managed by Coreth
+	DatabaseHandles int    `json:"database-handles"` // Number of files the database managed by Coreth may keep open
+
+	// Freezer settings
+	FreezerDirectory string `json:"freezer-directory"` // If set, accepted blocks are moved from the chain database into flat files in this directory
+	FreezerThreshold uint64 `json:"freezer-threshold"` // Number of most recent accepted blocks kept in the chain database
 
 	// VM2VM network
 	MaxOutboundActiveRequests int64 `json:"max-outbound-active-requests"`
@@ -144,15 +216,39 @@ func (c Config) EthBackendSettings() eth.Settings {
 	return eth.Settings{MaxBlocksPerRequest: c.MaxBlocksPerRequest}
 }
 
+// RPCRateLimitConfig returns the per-client rate limiting settings of the
+// RPC server.
+func (c Config) RPCRateLimitConfig() rpc.RateLimitConfig {
+	return rpc.RateLimitConfig{
+		RefillRate:   c.RPCRateLimitRefillRate,
+		MaxStored:    c.RPCRateLimitMaxStored,
+		MethodCosts:  c.RPCRateLimitMethodCosts,
+		APIKeyHeader: c.RPCRateLimitAPIKeyHeader,
+		MaxClients:   c.RPCRateLimitMaxClients,
+	}
+}
+
 func (c *Config) SetDefaults() {
 	c.EnabledEthAPIs = defaultEnabledAPIs
 	c.RPCGasCap = defaultRpcGasCap
 	c.RPCTxFeeCap = defaultRpcTxFeeCap
 	c.MetricsExpensiveEnabled = defaultMetricsExpensiveEnabled
 	c.APIMaxDuration.Duration = defaultApiMaxDuration
+	c.APISlowRequestThreshold.Duration = defaultApiSlowRequestThreshold
 	c.WSCPURefillRate.Duration = defaultWsCpuRefillRate
 	c.WSCPUMaxStored.Duration = defaultWsCpuMaxStored
 	c.MaxBlocksPerRequest = defaultMaxBlocksPerRequest
+	c.RPCRateLimitRefillRate = defaultRpcRateLimitRefillRate
+	c.RPCRateLimitMaxStored = defaultRpcRateLimitMaxStored
+	c.RPCRateLimitMaxClients = defaultRpcRateLimitMaxClients
+	c.BatchRequestLimit = defaultBatchRequestLimit
+	c.BatchResponseMaxSize = defaultBatchResponseMaxSize
+	// Copy the default costs so overrides parsed into the map do not modify
+	// the defaults.
+	c.RPCRateLimitMethodCosts = make(map[string]uint64, len(defaultRPCRateLimitMethodCosts))
+	for method, cost := range defaultRPCRateLimitMethodCosts {
+		c.RPCRateLimitMethodCosts[method] = cost
+	}
 	c.ContinuousProfilerFrequency.Duration = defaultContinuousProfilerFrequency
 	c.ContinuousProfilerMaxFiles = defaultContinuousProfilerMaxFiles
 	c.Pruning = defaultPruningEnabled
@@ -160,11 +256,20 @@ func (c *Config) SetDefaults() {
 	c.SnapshotAsync = defaultSnapshotAsync
 	c.TxRegossipFrequency.Duration = defaultTxRegossipFrequency
 	c.TxRegossipMaxSize = defaultTxRegossipMaxSize
+	c.BuildBlockPrefetchBudget = defaultBuildBlockPrefetchBudget
 	c.OfflinePruningBloomFilterSize = defaultOfflinePruningBloomFilterSize
+	c.OnlinePruningInterval.Duration = defaultOnlinePruningInterval
+	c.OnlinePruningBloomFilterSize = defaultOnlinePruningBloomFilterSize
+	c.OnlinePruningBatchSize = defaultOnlinePruningBatchSize
+	c.OnlinePruningBatchDelay.Duration = defaultOnlinePruningBatchDelay
+	c.StateHistory = defaultStateHistory
 	c.LogLevel = defaultLogLevel
 	c.PopulateMissingTriesParallelism = defaultPopulateMissingTriesParallelism
 	c.MaxOutboundActiveRequests = defaultMaxOutboundActiveRequests
 	c.StateSyncServerTrieCache = defaultStateSyncServerTrieCache
+	c.DatabaseCache = defaultDatabaseCache
+	c.DatabaseHandles = defaultDatabaseHandles
+	c.FreezerThreshold = defaultFreezerThreshold
 	c.CommitInterval = defaultCommitInterval
 	c.StateSyncCommitInterval = defaultSyncableCommitInterval
 	c.StateSyncMinBlocks = defaultStateSyncMinBlocks
@@ -191,10 +296,64 @@ func (c *Config) Validate() error {
 	if !c.Pruning && c.OfflinePruning {
 		return fmt.Errorf("cannot run offline pruning while pruning is disabled")
 	}
+	if c.OfflinePruningAtomicTrie && c.OfflinePruningDataDirectory == "" {
+		return fmt.Errorf("cannot run atomic trie offline pruning without a data directory")
+	}
+	if !c.Pruning && c.OnlinePruning {
+		return fmt.Errorf("cannot run online pruning while pruning is disabled")
+	}
+	if c.OnlinePruning && c.OnlinePruningInterval.Duration <= 0 {
+		return fmt.Errorf("cannot use online pruning interval of %s", c.OnlinePruningInterval.Duration)
+	}
+	switch c.StateScheme {
+	case "", rawdb.HashScheme:
+	case rawdb.PathScheme:
+		// The path scheme overwrites trie nodes in place, so it cannot retain
+		// all historical states and has no unreachable trie nodes to prune.
+		if !c.Pruning {
+			return fmt.Errorf("cannot use %s state scheme while pruning is disabled", rawdb.PathScheme)
+		}
+		if c.OfflinePruning || c.OnlinePruning {
+			return fmt.Errorf("cannot run offline pruning (enabled: %t)/online pruning (enabled: %t) with %s state scheme", c.OfflinePruning, c.OnlinePruning, rawdb.PathScheme)
+		}
+		if c.StateHistory == 0 {
+			return fmt.Errorf("cannot use state history of 0 with %s state scheme", rawdb.PathScheme)
+		}
+	default:
+		return fmt.Errorf("unknown state scheme %q", c.StateScheme)
+	}
+	if c.HistoryDiffs && !c.Pruning {
+		return fmt.Errorf("cannot use history diffs while pruning is disabled")
+	}
 	// If pruning is enabled, the commit interval must be non-zero so the node commits state tries every CommitInterval blocks.
 	if c.Pruning && c.CommitInterval == 0 {
 		return fmt.Errorf("cannot use commit interval of 0 with pruning enabled")
 	}
 
+	if c.RPCRateLimitRefillRate < 0 {
+		return fmt.Errorf("cannot use negative rpc rate limit refill rate (%f)", c.RPCRateLimitRefillRate)
+	}
+	if c.RPCRateLimitRefillRate > 0 && c.RPCRateLimitMaxStored == 0 {
+		return fmt.Errorf("cannot enable rpc rate limiting with a max stored of 0")
+	}
+	if c.BuildBlockPrefetchBudget < 0 || c.BuildBlockPrefetchBudget > 1 {
+		return fmt.Errorf("build block prefetch budget must be between 0 and 1 (budget: %f)", c.BuildBlockPrefetchBudget)
+	}
+	switch c.DatabaseType {
+	case "":
+	case rawdb.DBLeveldb, rawdb.DBPebble:
+		if len(c.DatabasePath) == 0 {
+			return fmt.Errorf("cannot use database type %s without a database path", c.DatabaseType)
+		}
+	default:
+		return fmt.Errorf("unknown database type %s", c.DatabaseType)
+	}
+	if len(c.FreezerDirectory) > 0 && c.FreezerThreshold == 0 {
+		return fmt.Errorf("cannot use freezer threshold of 0 with the freezer enabled")
+	}
+	if c.BatchRequestLimit < 0 || c.BatchResponseMaxSize < 0 {
+		return fmt.Errorf("cannot use negative batch limits (request limit: %d, response max size: %d)", c.BatchRequestLimit, c.BatchResponseMaxSize)
+	}
+
 	return nil
 }
@@ -8,9 +8,9 @@ import (
 	"github.com/sankar-boro/axia-network-v2-coreth/ethdb"
 )
 
-var _ ethdb.Database = &Database{}
+var _ ethdb.KeyValueStore = &Database{}
 
-// Database implements ethdb.Database
+// Database implements ethdb.KeyValueStore
 type Database struct{ database.Database }
 
 // Stat implements ethdb.Database
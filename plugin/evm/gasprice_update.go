@@ -8,12 +8,17 @@ import (
 	"sync"
 	"time"
 
+	"github.com/sankar-boro/axia-network-v2-coreth/core"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
 	"github.com/sankar-boro/axia-network-v2-coreth/params"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
 )
 
 type gasPriceUpdater struct {
 	setter       gasPriceSetter
 	chainConfig  *params.ChainConfig
+	chain        feeConfigReader
 	shutdownChan <-chan struct{}
 
 	wg *sync.WaitGroup
@@ -24,11 +29,20 @@ type gasPriceSetter interface {
 	SetMinFee(price *big.Int)
 }
 
+// feeConfigReader reads the fee config in effect after the head of the chain,
+// which the fee config manager precompile may change.
+type feeConfigReader interface {
+	CurrentBlock() *types.Block
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+	GetFeeConfigAt(parent *types.Header) (params.FeeConfig, error)
+}
+
 // handleGasPriceUpdates creates and runs an instance of
 func (vm *VM) handleGasPriceUpdates() {
 	gpu := &gasPriceUpdater{
 		setter:       vm.chain.GetTxPool(),
 		chainConfig:  vm.chainConfig,
+		chain:        vm.chain.BlockChain(),
 		shutdownChan: vm.shutdownChan,
 		wg:           &vm.shutdownWg,
 	}
@@ -53,7 +67,58 @@ func (gpu *gasPriceUpdater) start() {
 		return
 	}
 	// Updates to the minimum gas price as of ApricotPhase4 if it's already in effect or starts a goroutine to enable it at the correct time
-	gpu.handleUpdate(gpu.setter.SetMinFee, gpu.chainConfig.ApricotPhase4BlockTimestamp, big.NewInt(params.ApricotPhase4MinBaseFee))
+	if disabled := gpu.handleUpdate(gpu.setter.SetMinFee, gpu.chainConfig.ApricotPhase4BlockTimestamp, big.NewInt(params.ApricotPhase4MinBaseFee)); disabled {
+		return
+	}
+	// Updates to the minimum base fee of the fee config as of ApricotPhase5 if the chain config specifies one
+	if gpu.chainConfig.FeeConfig != nil {
+		if disabled := gpu.handleUpdate(gpu.setter.SetMinFee, gpu.chainConfig.ApricotPhase5BlockTimestamp, gpu.chainConfig.FeeConfig.MinBaseFee); disabled {
+			return
+		}
+	}
+	// Follows the minimum base fee of the fee config stored by the fee config manager precompile once it is active
+	if gpu.chainConfig.FeeConfigManagerConfig != nil {
+		gpu.wg.Add(1)
+		go gpu.followFeeConfig()
+	}
+}
+
+// followFeeConfig updates the minimum fee to the minimum base fee of the fee
+// config in effect after every new head at which the fee config manager
+// precompile is active, until the [shutdownChan] is closed.
+func (gpu *gasPriceUpdater) followFeeConfig() {
+	defer gpu.wg.Done()
+
+	headCh := make(chan core.ChainHeadEvent, 1)
+	sub := gpu.chain.SubscribeChainHeadEvent(headCh)
+	defer sub.Unsubscribe()
+
+	var minFee *big.Int
+	update := func(head *types.Header) {
+		if !gpu.chainConfig.IsStatefulPrecompileEnabled(params.FeeConfigManagerAddress, new(big.Int).SetUint64(head.Time)) {
+			return
+		}
+		feeConfig, err := gpu.chain.GetFeeConfigAt(head)
+		if err != nil {
+			log.Warn("Failed to read fee config", "number", head.Number, "hash", head.Hash(), "err", err)
+			return
+		}
+		if minFee == nil || minFee.Cmp(feeConfig.MinBaseFee) != 0 {
+			minFee = feeConfig.MinBaseFee
+			gpu.setter.SetMinFee(minFee)
+		}
+	}
+	update(gpu.chain.CurrentBlock().Header())
+	for {
+		select {
+		case ev := <-headCh:
+			update(ev.Block.Header())
+		case <-sub.Err():
+			return
+		case <-gpu.shutdownChan:
+			return
+		}
+	}
 }
 
 // handleUpdate handles calling update(price) at the appropriate time based on
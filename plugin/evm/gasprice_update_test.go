@@ -9,7 +9,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/sankar-boro/axia-network-v2-coreth/core"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
 	"github.com/sankar-boro/axia-network-v2-coreth/params"
+	"github.com/ethereum/go-ethereum/event"
 )
 
 type mockGasPriceSetter struct {
@@ -139,3 +142,67 @@ func TestUpdateGasPriceUpdatesPrice(t *testing.T) {
 		t.Fatalf("Expected min fee to match minimum fee for apricotPhase4, but found: %d", minFee)
 	}
 }
+
+// mockFeeConfigChain serves the minimum base fee [minBaseFees] at the height
+// of a header as the fee config in effect after it.
+type mockFeeConfigChain struct {
+	current     *types.Block
+	headFeed    event.Feed
+	minBaseFees map[uint64]*big.Int
+}
+
+func (m *mockFeeConfigChain) CurrentBlock() *types.Block { return m.current }
+
+func (m *mockFeeConfigChain) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return m.headFeed.Subscribe(ch)
+}
+
+func (m *mockFeeConfigChain) GetFeeConfigAt(parent *types.Header) (params.FeeConfig, error) {
+	feeConfig := params.DefaultFeeConfig
+	feeConfig.MinBaseFee = m.minBaseFees[parent.Number.Uint64()]
+	return feeConfig, nil
+}
+
+func TestUpdateGasPriceFollowsFeeConfig(t *testing.T) {
+	shutdownChan := make(chan struct{})
+	wg := &sync.WaitGroup{}
+	config := *params.TestChainConfig
+	config.FeeConfigManagerConfig = &params.FeeConfigManagerConfig{
+		PrecompileUpgrade: params.PrecompileUpgrade{BlockTimestamp: big.NewInt(0)},
+	}
+	chain := &mockFeeConfigChain{
+		current:     types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)}),
+		minBaseFees: map[uint64]*big.Int{1: big.NewInt(10), 2: big.NewInt(20)},
+	}
+	setter := &mockGasPriceSetter{price: big.NewInt(1)}
+	gpu := &gasPriceUpdater{
+		setter:       setter,
+		chainConfig:  &config,
+		chain:        chain,
+		shutdownChan: shutdownChan,
+		wg:           wg,
+	}
+
+	gpu.start()
+	waitMinFee := func(want *big.Int) {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			if _, minFee := setter.GetStatus(); minFee != nil && minFee.Cmp(want) == 0 {
+				return
+			}
+		}
+		_, minFee := setter.GetStatus()
+		t.Fatalf("Expected min fee %d of the stored fee config, but found: %d", want, minFee)
+	}
+	// The fee config after the current block applies on start
+	waitMinFee(big.NewInt(10))
+
+	// Fee config changes are applied as the head moves
+	for chain.headFeed.Send(core.ChainHeadEvent{Block: types.NewBlockWithHeader(&types.Header{Number: big.NewInt(2)})}) == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	waitMinFee(big.NewInt(20))
+
+	close(shutdownChan)
+	attemptAwait(t, wg, 5*time.Second)
+}
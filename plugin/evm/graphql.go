@@ -0,0 +1,40 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
+	"github.com/sankar-boro/axia-network-v2-coreth/eth"
+	"github.com/sankar-boro/axia-network-v2-coreth/graphql"
+)
+
+const graphQLEndpoint = "/graphql"
+
+// graphQLBackend extends the eth API backend with access to the atomic
+// transactions included in a block's extra data.
+type graphQLBackend struct {
+	*eth.EthAPIBackend
+	vm *VM
+}
+
+// AtomicTxs returns the atomic transactions included in [block].
+func (b *graphQLBackend) AtomicTxs(block *types.Block) ([]*graphql.AtomicTx, error) {
+	isApricotPhase5 := b.vm.chainConfig.IsApricotPhase5(new(big.Int).SetUint64(block.Time()))
+	txs, err := ExtractAtomicTxs(block.ExtData(), isApricotPhase5, b.vm.codec)
+	if err != nil {
+		return nil, err
+	}
+	atomicTxs := make([]*graphql.AtomicTx, 0, len(txs))
+	for _, tx := range txs {
+		gasUsed, err := tx.GasUsed(isApricotPhase5)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate gas used of atomic tx %s: %w", tx.ID(), err)
+		}
+		atomicTxs = append(atomicTxs, graphql.NewAtomicTx(tx.ID().String(), tx.Bytes(), gasUsed))
+	}
+	return atomicTxs, nil
+}
@@ -0,0 +1,178 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/sankar-boro/axia-network-v2/codec"
+	"github.com/sankar-boro/axia-network-v2/database"
+	"github.com/sankar-boro/axia-network-v2/database/prefixdb"
+	"github.com/sankar-boro/axia-network-v2/database/versiondb"
+	"github.com/sankar-boro/axia-network-v2/ids"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/ethdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/params"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// vmDBPrefix is the prefix under which the node stores the database of the VM
+// of a blockchain, within the prefix of the blockchain's ID.
+var vmDBPrefix = []byte("vm")
+
+var errSharedMemoryCursorSet = errors.New("atomic operations have not been applied to shared memory, start the VM to apply them first")
+
+// NewVMDatabase returns the database of the VM of the blockchain [chainID] in
+// the database [nodeDB] of a node. It is used to access the databases of a VM
+// that is not running, such as with cmd/coreth-db.
+func NewVMDatabase(nodeDB database.Database, chainID ids.ID) database.Database {
+	return prefixdb.New(vmDBPrefix, prefixdb.New(chainID[:], nodeDB))
+}
+
+// NewChainKeyValueStore returns the key-value store of the chain database kept
+// in the database [vmDB] of a VM, which is used unless the database-type config
+// option is set.
+func NewChainKeyValueStore(vmDB database.Database) ethdb.KeyValueStore {
+	return Database{prefixdb.NewNested(ethDBPrefix, vmDB)}
+}
+
+// ReadLastAccepted returns the hash and height of the last accepted block of
+// the VM with the database [vmDB] and the chain database [chaindb].
+func ReadLastAccepted(vmDB database.Database, chaindb ethdb.Reader) (common.Hash, uint64, error) {
+	genesisHash := rawdb.ReadCanonicalHash(chaindb, 0)
+	if genesisHash == (common.Hash{}) {
+		return common.Hash{}, 0, errors.New("genesis block not found")
+	}
+	return readLastAccepted(prefixdb.NewNested(acceptedPrefix, vmDB), chaindb, genesisHash)
+}
+
+// InspectAtomicDatabase traverses the tables kept by the VM in [vmDB] alongside
+// the chain database, including the atomic trie and the atomic repository, and
+// prints their sizes.
+func InspectAtomicDatabase(vmDB database.Database) error {
+	var (
+		stats [][]string
+		total common.StorageSize
+	)
+	for _, table := range []struct {
+		prefix []byte
+		name   string
+	}{
+		{atomicTrieDBPrefix, "Atomic trie nodes"},
+		{atomicTrieMetaDBPrefix, "Atomic trie metadata"},
+		{atomicTxIDDBPrefix, "Atomic txs by ID"},
+		{atomicHeightTxDBPrefix, "Atomic txs by height"},
+		{atomicRepoMetadataDBPrefix, "Atomic repository metadata"},
+		{acceptedPrefix, "Accepted blocks"},
+		{metadataPrefix, "VM metadata"},
+	} {
+		var (
+			size  common.StorageSize
+			count uint64
+		)
+		it := prefixdb.NewNested(table.prefix, vmDB).NewIterator()
+		for it.Next() {
+			size += common.StorageSize(len(it.Key()) + len(it.Value()))
+			count++
+		}
+		err := it.Error()
+		it.Release()
+		if err != nil {
+			return fmt.Errorf("failed to inspect %s: %w", table.name, err)
+		}
+		total += size
+		stats = append(stats, []string{"VM database", table.name, size.String(), fmt.Sprintf("%d", count)})
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Database", "Category", "Size", "Items"})
+	table.SetFooter([]string{"", "Total", total.String(), " "})
+	table.AppendBulk(stats)
+	table.Render()
+	return nil
+}
+
+// RepairAtomicTrie discards the atomic trie kept in [vmDB] and rebuilds it from
+// the atomic repository up to [lastAcceptedHeight], committing it every
+// [commitInterval] blocks. It returns the root and height of the last commit.
+// The rebuilt trie is held in memory and written to [vmDB] at once, so [vmDB]
+// is left unchanged if the rebuild fails.
+//
+// The atomic operations of the rebuilt trie are not applied to shared memory,
+// so the trie must not be repaired while operations indexed by state sync are
+// pending application.
+func RepairAtomicTrie(
+	vmDB database.Database, codec codec.Manager, chainID *big.Int,
+	lastAcceptedHeight uint64, commitInterval uint64,
+) (common.Hash, uint64, error) {
+	metadataDB := prefixdb.NewNested(atomicTrieMetaDBPrefix, vmDB)
+	switch pending, err := metadataDB.Has(appliedSharedMemoryCursorKey); {
+	case err != nil:
+		return common.Hash{}, 0, err
+	case pending:
+		return common.Hash{}, 0, errSharedMemoryCursorSet
+	}
+	// The old trie is cleared and the new one built in memory, so that [vmDB]
+	// keeps the old trie unless the rebuild succeeds. The atomic trie commits
+	// [db] as it progresses, which only flushes its changes into [repairDB].
+	repairDB := versiondb.New(vmDB)
+	for _, prefix := range [][]byte{atomicTrieDBPrefix, atomicTrieMetaDBPrefix} {
+		if err := clearDatabase(prefixdb.NewNested(prefix, repairDB)); err != nil {
+			return common.Hash{}, 0, err
+		}
+	}
+	db := versiondb.New(repairDB)
+	repo, err := NewAtomicTxRepository(db, codec, lastAcceptedHeight)
+	if err != nil {
+		return common.Hash{}, 0, fmt.Errorf("failed to create atomic repository: %w", err)
+	}
+	bonusBlockHeights := make(map[uint64]ids.ID)
+	if chainID.Cmp(params.AxiaMainnetChainID) == 0 {
+		bonusBlockHeights = bonusBlockMainnetHeights
+	}
+	atomicTrie, err := newAtomicTrie(db, nil, bonusBlockHeights, repo, codec, lastAcceptedHeight, commitInterval)
+	if err != nil {
+		return common.Hash{}, 0, fmt.Errorf("failed to rebuild atomic trie: %w", err)
+	}
+	if err := db.Commit(); err != nil {
+		return common.Hash{}, 0, err
+	}
+	if err := repairDB.Commit(); err != nil {
+		return common.Hash{}, 0, err
+	}
+	root, height := atomicTrie.LastCommitted()
+	return root, height, nil
+}
+
+// clearDatabase deletes every key of [db].
+func clearDatabase(db database.Database) error {
+	it := db.NewIterator()
+	defer it.Release()
+
+	batch := db.NewBatch()
+	deleted := 0
+	for it.Next() {
+		if err := batch.Delete(it.Key()); err != nil {
+			return err
+		}
+		deleted++
+		if batch.Size() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	log.Info("Cleared database", "deleted", deleted)
+	return batch.Write()
+}
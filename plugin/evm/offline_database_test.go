@@ -0,0 +1,109 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sankar-boro/axia-network-v2/chains/atomic"
+	"github.com/sankar-boro/axia-network-v2/database"
+	"github.com/sankar-boro/axia-network-v2/database/memdb"
+	"github.com/sankar-boro/axia-network-v2/database/prefixdb"
+	"github.com/sankar-boro/axia-network-v2/database/versiondb"
+	"github.com/sankar-boro/axia-network-v2/ids"
+)
+
+func TestRepairAtomicTrie(t *testing.T) {
+	const (
+		lastAcceptedHeight = 105
+		commitInterval     = 10
+	)
+	var (
+		vmDB          = memdb.New()
+		db            = versiondb.New(vmDB)
+		codec         = testTxCodec()
+		operationsMap = make(map[uint64]map[ids.ID]*atomic.Requests)
+	)
+	repo, err := NewAtomicTxRepository(db, codec, lastAcceptedHeight)
+	assert.NoError(t, err)
+	writeTxs(t, repo, 1, lastAcceptedHeight+1, constTxsPerHeight(2), nil, operationsMap)
+	atomicTrie, err := newAtomicTrie(db, testSharedMemory(), nil, repo, codec, lastAcceptedHeight, commitInterval)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Commit())
+	root, height := atomicTrie.LastCommitted()
+
+	// Corrupt the atomic trie by deleting its nodes
+	assert.NoError(t, clearDatabase(prefixdb.NewNested(atomicTrieDBPrefix, vmDB)))
+
+	repairedRoot, repairedHeight, err := RepairAtomicTrie(vmDB, codec, big.NewInt(1), lastAcceptedHeight, commitInterval)
+	assert.NoError(t, err)
+	assert.Equal(t, root, repairedRoot)
+	assert.Equal(t, height, repairedHeight)
+
+	// The repaired trie is loaded by the VM and contains all operations
+	db = versiondb.New(vmDB)
+	repo, err = NewAtomicTxRepository(db, codec, lastAcceptedHeight)
+	assert.NoError(t, err)
+	atomicTrie, err = newAtomicTrie(db, testSharedMemory(), nil, repo, codec, lastAcceptedHeight, commitInterval)
+	assert.NoError(t, err)
+	loadedRoot, loadedHeight := atomicTrie.LastCommitted()
+	assert.Equal(t, root, loadedRoot)
+	assert.Equal(t, height, loadedHeight)
+	verifyOperations(t, atomicTrie, codec, root, 1, height, operationsMap)
+
+	// The trie is not repaired while operations are pending application to
+	// shared memory
+	assert.NoError(t, atomicTrie.MarkApplyToSharedMemoryCursor(height))
+	assert.NoError(t, db.Commit())
+	_, _, err = RepairAtomicTrie(vmDB, codec, big.NewInt(1), lastAcceptedHeight, commitInterval)
+	assert.ErrorIs(t, err, errSharedMemoryCursorSet)
+}
+
+// Tests that a failed repair leaves the atomic trie in place.
+func TestRepairAtomicTrieFailure(t *testing.T) {
+	const (
+		lastAcceptedHeight = 105
+		commitInterval     = 10
+	)
+	var (
+		vmDB  = memdb.New()
+		db    = versiondb.New(vmDB)
+		codec = testTxCodec()
+	)
+	repo, err := NewAtomicTxRepository(db, codec, lastAcceptedHeight)
+	assert.NoError(t, err)
+	writeTxs(t, repo, 1, lastAcceptedHeight+1, constTxsPerHeight(2), nil, make(map[uint64]map[ids.ID]*atomic.Requests))
+	_, err = newAtomicTrie(db, testSharedMemory(), nil, repo, codec, lastAcceptedHeight, commitInterval)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Commit())
+
+	// Corrupt the atomic txs of a height, so that the rebuild fails
+	height := make([]byte, 8)
+	binary.BigEndian.PutUint64(height, 50)
+	assert.NoError(t, prefixdb.New(atomicHeightTxDBPrefix, vmDB).Put(height, []byte{0xff}))
+	before := dumpAtomicTrie(t, vmDB)
+
+	_, _, err = RepairAtomicTrie(vmDB, codec, big.NewInt(1), lastAcceptedHeight, commitInterval)
+	assert.Error(t, err)
+	assert.Equal(t, before, dumpAtomicTrie(t, vmDB))
+}
+
+// dumpAtomicTrie returns the atomic trie nodes and metadata kept in [vmDB].
+func dumpAtomicTrie(t *testing.T, vmDB database.Database) map[string]string {
+	dump := make(map[string]string)
+	for _, prefix := range [][]byte{atomicTrieDBPrefix, atomicTrieMetaDBPrefix} {
+		it := prefixdb.NewNested(prefix, vmDB).NewIterator()
+		for it.Next() {
+			dump[string(prefix)+string(it.Key())] = string(it.Value())
+		}
+		assert.NoError(t, it.Error())
+		it.Release()
+	}
+	assert.NotEmpty(t, dump)
+	return dump
+}
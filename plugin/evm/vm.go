@@ -27,6 +27,7 @@ import (
 	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
 	"github.com/sankar-boro/axia-network-v2-coreth/eth/ethconfig"
 	"github.com/sankar-boro/axia-network-v2-coreth/ethdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/graphql"
 	corethPrometheus "github.com/sankar-boro/axia-network-v2-coreth/metrics/prometheus"
 	"github.com/sankar-boro/axia-network-v2-coreth/node"
 	"github.com/sankar-boro/axia-network-v2-coreth/params"
@@ -374,7 +375,32 @@ func (vm *VM) Initialize(
 	baseDB := dbManager.Current().Database
 	// Use NewNested rather than New so that the structure of the database
 	// remains the same regardless of the provided baseDB type.
-	vm.chaindb = Database{prefixdb.NewNested(ethDBPrefix, baseDB)}
+	if len(vm.config.DatabaseType) == 0 {
+		kvdb := Database{prefixdb.NewNested(ethDBPrefix, baseDB)}
+		if len(vm.config.FreezerDirectory) == 0 {
+			vm.chaindb = rawdb.NewDatabase(kvdb)
+		} else {
+			vm.chaindb, err = rawdb.NewDatabaseWithFreezer(kvdb, vm.config.FreezerDirectory, "eth/db/chaindata/", false, vm.config.FreezerThreshold)
+			if err != nil {
+				return fmt.Errorf("failed to open freezer at %s: %w", vm.config.FreezerDirectory, err)
+			}
+		}
+	} else {
+		// The chain data is stored in a database managed by Coreth, while the
+		// VM's own metadata remains in the database provided by the node.
+		vm.chaindb, err = rawdb.Open(rawdb.OpenOptions{
+			Type:              vm.config.DatabaseType,
+			Directory:         vm.config.DatabasePath,
+			Namespace:         "eth/db/chaindata/",
+			Cache:             vm.config.DatabaseCache,
+			Handles:           vm.config.DatabaseHandles,
+			AncientsDirectory: vm.config.FreezerDirectory,
+			FreezerThreshold:  vm.config.FreezerThreshold,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to open %s database at %s: %w", vm.config.DatabaseType, vm.config.DatabasePath, err)
+		}
+	}
 	vm.db = versiondb.New(baseDB)
 	vm.acceptedBlockDB = prefixdb.New(acceptedPrefix, vm.db)
 	vm.metadataDB = prefixdb.New(metadataPrefix, vm.db)
@@ -436,7 +462,20 @@ func (vm *VM) Initialize(
 	vm.ethConfig.OfflinePruning = vm.config.OfflinePruning
 	vm.ethConfig.OfflinePruningBloomFilterSize = vm.config.OfflinePruningBloomFilterSize
 	vm.ethConfig.OfflinePruningDataDirectory = vm.config.OfflinePruningDataDirectory
+	vm.ethConfig.OnlinePruning = vm.config.OnlinePruning
+	vm.ethConfig.OnlinePruningInterval = vm.config.OnlinePruningInterval.Duration
+	vm.ethConfig.OnlinePruningBloomFilterSize = vm.config.OnlinePruningBloomFilterSize
+	vm.ethConfig.OnlinePruningBatchSize = vm.config.OnlinePruningBatchSize
+	vm.ethConfig.OnlinePruningBatchDelay = vm.config.OnlinePruningBatchDelay.Duration
+	vm.ethConfig.StateScheme = vm.config.StateScheme
+	vm.ethConfig.StateHistory = vm.config.StateHistory
+	vm.ethConfig.HistoryDiffs = vm.config.HistoryDiffs
+	vm.ethConfig.LogIndex = vm.config.LogIndex
+	vm.ethConfig.StateSyncSummaryInterval = vm.config.StateSyncCommitInterval
 	vm.ethConfig.CommitInterval = vm.config.CommitInterval
+	vm.ethConfig.ParallelExecutionWorkers = vm.config.ParallelExecutionWorkers
+	vm.ethConfig.StorageTrieWorkers = vm.config.StorageTrieWorkers
+	vm.ethConfig.Miner.PrefetchBudget = vm.config.BuildBlockPrefetchBudget
 
 	// Create directory for offline pruning
 	if len(vm.ethConfig.OfflinePruningDataDirectory) != 0 {
@@ -493,10 +532,18 @@ func (vm *VM) Initialize(
 	); err != nil {
 		return fmt.Errorf("failed to repair atomic repository: %w", err)
 	}
+	// An interrupted run of atomic trie offline pruning must complete before
+	// the trie is loaded, since the trie may be missing nodes until then.
+	if err := recoverAtomicTriePruning(vm.config.OfflinePruningDataDirectory, vm.db); err != nil {
+		return fmt.Errorf("failed to recover atomic trie pruning: %w", err)
+	}
 	vm.atomicTrie, err = NewAtomicTrie(vm.db, vm.ctx.SharedMemory, bonusBlockHeights, vm.atomicTxRepository, vm.codec, lastAcceptedHeight, vm.config.CommitInterval)
 	if err != nil {
 		return fmt.Errorf("failed to create atomic trie: %w", err)
 	}
+	if err := vm.handleAtomicTriePruning(lastAcceptedHeight); err != nil {
+		return fmt.Errorf("failed to prune atomic trie: %w", err)
+	}
 
 	go vm.ctx.Log.RecoverAndPanic(vm.startContinuousProfiler)
 
@@ -1121,6 +1168,13 @@ func newHandler(name string, service interface{}, lockOption ...commonEng.LockOp
 // CreateHandlers makes new http handlers that can handle API calls
 func (vm *VM) CreateHandlers() (map[string]*commonEng.HTTPHandler, error) {
 	handler := vm.chain.NewRPCHandler(vm.config.APIMaxDuration.Duration)
+	rateLimiter, err := rpc.NewRateLimiter(vm.config.RPCRateLimitConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rpc rate limiter: %w", err)
+	}
+	handler.SetRateLimiter(rateLimiter)
+	handler.SetBatchLimits(vm.config.BatchRequestLimit, vm.config.BatchResponseMaxSize)
+	handler.SetSlowRequestThreshold(vm.config.APISlowRequestThreshold.Duration)
 	enabledAPIs := vm.config.EthAPIs()
 	if err := vm.chain.AttachEthService(handler, enabledAPIs); err != nil {
 		return nil, err
@@ -1154,6 +1208,18 @@ func (vm *VM) CreateHandlers() (map[string]*commonEng.HTTPHandler, error) {
 		enabledAPIs = append(enabledAPIs, "snowman")
 	}
 
+	if vm.config.GraphQLEnabled {
+		graphQLHandler, err := graphql.NewHandler(&graphQLBackend{EthAPIBackend: vm.chain.APIBackend(), vm: vm}, vm.config.APIMaxDuration.Duration, rateLimiter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create graphql handler due to %w", err)
+		}
+		apis[graphQLEndpoint] = &commonEng.HTTPHandler{
+			LockOptions: commonEng.NoLock,
+			Handler:     graphQLHandler,
+		}
+		enabledAPIs = append(enabledAPIs, "graphql")
+	}
+
 	log.Info(fmt.Sprintf("Enabled APIs: %s", strings.Join(enabledAPIs, ", ")))
 	apis[ethRPCEndpoint] = &commonEng.HTTPHandler{
 		LockOptions: commonEng.NoLock,
@@ -1325,7 +1391,11 @@ func (vm *VM) verifyTxAtTip(tx *Tx) error {
 	timestamp := vm.clock.Time().Unix()
 	bigTimestamp := big.NewInt(timestamp)
 	if vm.chainConfig.IsApricotPhase3(bigTimestamp) {
-		_, nextBaseFee, err = dummy.EstimateNextBaseFee(vm.chainConfig, parentHeader, uint64(timestamp))
+		feeConfig, err := vm.chain.BlockChain().GetFeeConfigAt(parentHeader)
+		if err != nil {
+			return fmt.Errorf("failed to get fee config at tip: %w", err)
+		}
+		_, nextBaseFee, err = dummy.EstimateNextBaseFee(vm.chainConfig, feeConfig, parentHeader, uint64(timestamp))
 		if err != nil {
 			// Return extremely detailed error since CalcBaseFee should never encounter an issue here
 			return fmt.Errorf("failed to calculate base fee with parent timestamp (%d), parent ExtraData: (0x%x), and current timestamp (%d): %w", parentHeader.Time, parentHeader.Extra, timestamp, err)
@@ -1728,20 +1798,26 @@ func repairAtomicRepositoryForBonusBlockTxs(
 // on [chain].
 // Note: assumes chaindb, ethConfig, and genesisHash have been initialized.
 func (vm *VM) readLastAccepted() (common.Hash, uint64, error) {
+	return readLastAccepted(vm.acceptedBlockDB, vm.chaindb, vm.genesisHash)
+}
+
+// readLastAccepted reads the last accepted hash from [acceptedBlockDB] and its
+// height from [chaindb], or returns [genesisHash] if no block has been accepted.
+func readLastAccepted(acceptedBlockDB database.Database, chaindb ethdb.KeyValueReader, genesisHash common.Hash) (common.Hash, uint64, error) {
 	// Attempt to load last accepted block to determine if it is necessary to
 	// initialize state with the genesis block.
-	lastAcceptedBytes, lastAcceptedErr := vm.acceptedBlockDB.Get(lastAcceptedKey)
+	lastAcceptedBytes, lastAcceptedErr := acceptedBlockDB.Get(lastAcceptedKey)
 	switch {
 	case lastAcceptedErr == database.ErrNotFound:
 		// If there is nothing in the database, return the genesis block hash and height
-		return vm.genesisHash, 0, nil
+		return genesisHash, 0, nil
 	case lastAcceptedErr != nil:
 		return common.Hash{}, 0, fmt.Errorf("failed to get last accepted block ID due to: %w", lastAcceptedErr)
 	case len(lastAcceptedBytes) != common.HashLength:
 		return common.Hash{}, 0, fmt.Errorf("last accepted bytes should have been length %d, but found %d", common.HashLength, len(lastAcceptedBytes))
 	default:
 		lastAcceptedHash := common.BytesToHash(lastAcceptedBytes)
-		height := rawdb.ReadHeaderNumber(vm.chaindb, lastAcceptedHash)
+		height := rawdb.ReadHeaderNumber(chaindb, lastAcceptedHash)
 		if height == nil {
 			return common.Hash{}, 0, fmt.Errorf("failed to retrieve header number of last accepted block: %s", lastAcceptedHash)
 		}
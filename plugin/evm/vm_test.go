@@ -46,6 +46,7 @@ import (
 
 	"github.com/sankar-boro/axia-network-v2-coreth/consensus/dummy"
 	"github.com/sankar-boro/axia-network-v2-coreth/core"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
 	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
 	"github.com/sankar-boro/axia-network-v2-coreth/eth"
 	"github.com/sankar-boro/axia-network-v2-coreth/params"
@@ -331,6 +332,47 @@ func TestVMContinuosProfiler(t *testing.T) {
 	assert.NoError(t, err, "Expected continuous profiler to generate the first CPU profile at %s", expectedFileName)
 }
 
+func TestVMManagedDatabase(t *testing.T) {
+	for _, dbType := range []string{rawdb.DBLeveldb, rawdb.DBPebble} {
+		t.Run(dbType, func(t *testing.T) {
+			dbPath := t.TempDir()
+			configJSON := fmt.Sprintf("{\"database-type\": %q,\"database-path\": %q}", dbType, dbPath)
+			_, vm, _, _, _ := GenesisVM(t, false, genesisJSONApricotPhase0, configJSON, "")
+			genesisHash := vm.chain.GetGenesisBlock().Hash()
+			assert.NoError(t, vm.Shutdown())
+
+			// The chain data must have been written to the managed database
+			assert.Equal(t, dbType, rawdb.PreexistingDatabase(dbPath))
+			db, err := rawdb.Open(rawdb.OpenOptions{Directory: dbPath, ReadOnly: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			assert.Equal(t, genesisHash, rawdb.ReadCanonicalHash(db, 0))
+		})
+	}
+}
+
+func TestVMFreezer(t *testing.T) {
+	for _, dbType := range []string{"", rawdb.DBLeveldb} {
+		t.Run(fmt.Sprintf("database-type=%q", dbType), func(t *testing.T) {
+			freezerPath := t.TempDir()
+			configJSON := fmt.Sprintf("{\"database-type\": %q,\"database-path\": %q,\"freezer-directory\": %q}", dbType, t.TempDir(), freezerPath)
+			_, vm, _, _, _ := GenesisVM(t, false, genesisJSONApricotPhase0, configJSON, "")
+			assert.Equal(t, defaultFreezerThreshold, vm.config.FreezerThreshold)
+
+			// The chain database must be backed by the freezer
+			frozen, err := vm.chaindb.Ancients()
+			assert.NoError(t, err)
+			assert.Zero(t, frozen)
+			assert.NoError(t, vm.Shutdown())
+
+			_, err = os.Stat(filepath.Join(freezerPath, "hashes.ridx"))
+			assert.NoError(t, err)
+		})
+	}
+}
+
 func TestVMUpgrades(t *testing.T) {
 	genesisTests := []struct {
 		name             string
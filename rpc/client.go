@@ -118,7 +118,7 @@ type clientConn struct {
 	handler *handler
 }
 
-func (c *Client) newClientConn(conn ServerCodec, apiMaxDuration, refillRate, maxStored time.Duration) *clientConn {
+func (c *Client) newClientConn(conn ServerCodec, apiMaxDuration, refillRate, maxStored time.Duration, config handlerConfig) *clientConn {
 	ctx := context.Background()
 	ctx = context.WithValue(ctx, clientContextKey{}, c)
 	ctx = context.WithValue(ctx, peerInfoContextKey{}, conn.peerInfo())
@@ -128,6 +128,7 @@ func (c *Client) newClientConn(conn ServerCodec, apiMaxDuration, refillRate, max
 	// all client invocations of this function), it is ignored.
 	handler.deadlineContext = apiMaxDuration
 	handler.addLimiter(refillRate, maxStored)
+	handler.handlerConfig = config
 	return &clientConn{conn, handler}
 }
 
@@ -213,12 +214,12 @@ func newClient(initctx context.Context, connect reconnectFunc) (*Client, error)
 	if err != nil {
 		return nil, err
 	}
-	c := initClient(conn, randomIDGenerator(), new(serviceRegistry), 0, 0, 0)
+	c := initClient(conn, randomIDGenerator(), new(serviceRegistry), 0, 0, 0, handlerConfig{})
 	c.reconnectFunc = connect
 	return c, nil
 }
 
-func initClient(conn ServerCodec, idgen func() ID, services *serviceRegistry, apiMaxDuration, refillRate, maxStored time.Duration) *Client {
+func initClient(conn ServerCodec, idgen func() ID, services *serviceRegistry, apiMaxDuration, refillRate, maxStored time.Duration, config handlerConfig) *Client {
 	_, isHTTP := conn.(*httpConn)
 	c := &Client{
 		idgen:       idgen,
@@ -236,7 +237,7 @@ func initClient(conn ServerCodec, idgen func() ID, services *serviceRegistry, ap
 		reqTimeout:  make(chan *requestOp),
 	}
 	if !c.isHTTP {
-		go c.dispatch(conn, apiMaxDuration, refillRate, maxStored)
+		go c.dispatch(conn, apiMaxDuration, refillRate, maxStored, config)
 	}
 	return c
 }
@@ -552,11 +553,11 @@ func (c *Client) reconnect(ctx context.Context) error {
 // dispatch is the main loop of the client.
 // It sends read messages to waiting calls to Call and BatchCall
 // and subscription notifications to registered subscriptions.
-func (c *Client) dispatch(codec ServerCodec, apiMaxDuration, refillRate, maxStored time.Duration) {
+func (c *Client) dispatch(codec ServerCodec, apiMaxDuration, refillRate, maxStored time.Duration, config handlerConfig) {
 	var (
 		lastOp      *requestOp  // tracks last send operation
 		reqInitLock = c.reqInit // nil while the send lock is held
-		conn        = c.newClientConn(codec, apiMaxDuration, refillRate, maxStored)
+		conn        = c.newClientConn(codec, apiMaxDuration, refillRate, maxStored, config)
 		reading     = true
 	)
 	defer func() {
@@ -603,7 +604,7 @@ func (c *Client) dispatch(codec ServerCodec, apiMaxDuration, refillRate, maxStor
 			}
 			go c.read(newcodec)
 			reading = true
-			conn = c.newClientConn(newcodec, apiMaxDuration, refillRate, maxStored)
+			conn = c.newClientConn(newcodec, apiMaxDuration, refillRate, maxStored, config)
 			// Re-register the in-flight request on the new handler
 			// because that's where it will be sent.
 			conn.handler.addRequestOp(lastOp)
@@ -29,6 +29,7 @@ package rpc
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
@@ -79,8 +80,22 @@ type handler struct {
 
 	deadlineContext time.Duration // limits execution after some time.Duration
 	limiter         *rate.Limiter
+
+	handlerConfig
+}
+
+// handlerConfig holds the server settings that apply to a handler.
+type handlerConfig struct {
+	quota                  *clientQuota  // per-client request quota, nil if unlimited
+	batchItemLimit         int           // maximum number of items in a batch, 0 if unlimited
+	batchResponseSizeLimit int           // maximum cumulative response size of a batch, 0 if unlimited
+	slowRequestThreshold   time.Duration // minimum duration of calls that are logged as slow, 0 if disabled
 }
 
+// maxSlowRequestParamsLength is the maximum number of bytes of the params of a
+// slow request that are logged.
+const maxSlowRequestParamsLength = 256
+
 type callProc struct {
 	ctx       context.Context
 	notifiers []*Notifier
@@ -131,6 +146,15 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 		return
 	}
 
+	// Reject batches with too many items:
+	if h.batchItemLimit > 0 && len(msgs) > h.batchItemLimit {
+		batchTooLargeCounter.Inc(1)
+		h.startCallProc(func(cp *callProc) {
+			h.respondBatchTooLarge(cp, msgs)
+		})
+		return
+	}
+
 	// Handle non-call messages first:
 	calls := make([]*jsonrpcMessage, 0, len(msgs))
 	for _, msg := range msgs {
@@ -143,10 +167,27 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 	}
 	// Process calls on a goroutine because they may block indefinitely:
 	h.startCallProc(func(cp *callProc) {
-		answers := make([]*jsonrpcMessage, 0, len(msgs))
+		var (
+			answers      = make([]*jsonrpcMessage, 0, len(msgs))
+			responseSize int
+			tooLarge     bool
+		)
 		for _, msg := range calls {
+			// Once the response size limit is exceeded, answer the remaining
+			// calls with an error instead of executing them.
+			if tooLarge {
+				if msg.isCall() {
+					answers = append(answers, msg.errorResponse(&responseTooLargeError{}))
+				}
+				continue
+			}
 			if answer := h.handleCallMsg(cp, msg); answer != nil {
 				answers = append(answers, answer)
+				responseSize += answer.size()
+				if h.batchResponseSizeLimit > 0 && responseSize > h.batchResponseSizeLimit {
+					tooLarge = true
+					batchResponseTooLargeCounter.Inc(1)
+				}
 			}
 		}
 		h.addSubscriptions(cp.notifiers)
@@ -159,6 +200,20 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 	})
 }
 
+// respondBatchTooLarge answers a batch that exceeds the item limit with a
+// single error. The error carries the ID of the first call in the batch, if
+// any, so that clients can associate it with the batch.
+func (h *handler) respondBatchTooLarge(cp *callProc, msgs []*jsonrpcMessage) {
+	resp := errorMessage(&invalidRequestError{fmt.Sprintf("batch too large (%d>%d)", len(msgs), h.batchItemLimit)})
+	for _, msg := range msgs {
+		if msg.isCall() {
+			resp.ID = msg.ID
+			break
+		}
+	}
+	h.conn.writeJSONSkipDeadline(cp.ctx, []*jsonrpcMessage{resp}, h.deadlineContext > 0)
+}
+
 // handleMsg handles a single message.
 func (h *handler) handleMsg(msg *jsonrpcMessage) {
 	if ok := h.handleImmediate(msg); ok {
@@ -427,6 +482,9 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 	if callb == nil {
 		return msg.errorResponse(&methodNotFoundError{method: msg.Method})
 	}
+	if err := h.quota.take(msg.Method); err != nil {
+		return msg.errorResponse(err)
+	}
 	args, err := parsePositionalArguments(msg.Params, callb.argTypes)
 	if err != nil {
 		return msg.errorResponse(&invalidParamsError{err.Error()})
@@ -443,14 +501,36 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 		} else {
 			successfulRequestGauge.Inc(1)
 		}
-		rpcServingTimer.UpdateSince(start)
+		elapsed := time.Since(start)
+		rpcServingTimer.Update(elapsed)
+		newRPCLatencyHistogram(msg.Method, answer.Error == nil).Update(int64(elapsed))
 		if metrics.EnabledExpensive {
-			newRPCServingTimer(msg.Method, answer.Error == nil).UpdateSince(start)
+			newRPCServingTimer(msg.Method, answer.Error == nil).Update(elapsed)
 		}
+		h.logSlowRequest(cp.ctx, msg, elapsed)
 	}
 	return answer
 }
 
+// logSlowRequest logs [msg] if serving it took at least the configured slow
+// request threshold.
+func (h *handler) logSlowRequest(ctx context.Context, msg *jsonrpcMessage, elapsed time.Duration) {
+	if h.slowRequestThreshold <= 0 || elapsed < h.slowRequestThreshold {
+		return
+	}
+	slowRequestCounter.Inc(1)
+	params := string(msg.Params)
+	if len(params) > maxSlowRequestParamsLength {
+		params = params[:maxSlowRequestParamsLength] + "..."
+	}
+	h.log.Warn("Served slow RPC request",
+		"method", msg.Method,
+		"params", params,
+		"duration", elapsed,
+		"remote", PeerInfoFromContext(ctx).RemoteAddr,
+	)
+}
+
 // handleSubscribe processes *_subscribe method calls.
 func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
 	if !h.allowSubscribe {
@@ -467,6 +547,9 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 	if callb == nil {
 		return msg.errorResponse(&subscriptionNotFoundError{namespace, name})
 	}
+	if err := h.quota.take(msg.Method); err != nil {
+		return msg.errorResponse(err)
+	}
 
 	// Parse subscription name arg too, but remove it before calling the callback.
 	argTypes := append([]reflect.Type{stringType}, callb.argTypes...)
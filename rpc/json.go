@@ -101,6 +101,16 @@ func (msg *jsonrpcMessage) String() string {
 	return string(b)
 }
 
+// size returns an estimate of the encoded size of msg in bytes. Only the
+// variable length fields are taken into account.
+func (msg *jsonrpcMessage) size() int {
+	size := len(msg.ID) + len(msg.Method) + len(msg.Params) + len(msg.Result)
+	if msg.Error != nil {
+		size += len(msg.Error.Message)
+	}
+	return size
+}
+
 func (msg *jsonrpcMessage) errorResponse(err error) *jsonrpcMessage {
 	resp := errorMessage(err)
 	resp.ID = msg.ID
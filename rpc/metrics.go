@@ -37,6 +37,16 @@ var (
 	successfulRequestGauge = metrics.NewRegisteredGauge("rpc/success", nil)
 	failedRequestGauge     = metrics.NewRegisteredGauge("rpc/failure", nil)
 	rpcServingTimer        = metrics.NewRegisteredTimer("rpc/duration/all", nil)
+
+	rateLimitAllowedCounter  = metrics.NewRegisteredCounter("rpc/ratelimit/allowed", nil)
+	rateLimitRejectedCounter = metrics.NewRegisteredCounter("rpc/ratelimit/rejected", nil)
+	rateLimitTokensCounter   = metrics.NewRegisteredCounter("rpc/ratelimit/tokens", nil)
+	rateLimitClientsGauge    = metrics.NewRegisteredGauge("rpc/ratelimit/clients", nil)
+
+	batchTooLargeCounter         = metrics.NewRegisteredCounter("rpc/batch/rejected/items", nil)
+	batchResponseTooLargeCounter = metrics.NewRegisteredCounter("rpc/batch/rejected/response", nil)
+
+	slowRequestCounter = metrics.NewRegisteredCounter("rpc/slow", nil)
 )
 
 func newRPCServingTimer(method string, valid bool) metrics.Timer {
@@ -47,3 +57,22 @@ func newRPCServingTimer(method string, valid bool) metrics.Timer {
 	m := fmt.Sprintf("rpc/duration/%s/%s", method, flag)
 	return metrics.GetOrRegisterTimer(m, nil)
 }
+
+// newRPCLatencyHistogram returns the histogram of the serving latency, in
+// nanoseconds, of [method]. The method and whether the call succeeded are
+// exported as labels.
+func newRPCLatencyHistogram(method string, valid bool) metrics.Histogram {
+	flag := "success"
+	if !valid {
+		flag = "failure"
+	}
+	m := fmt.Sprintf("rpc/latency{method=%s,status=%s}", method, flag)
+	return metrics.GetOrRegisterHistogramLazy(m, nil, func() metrics.Sample {
+		return metrics.NewExpDecaySample(1028, 0.015)
+	})
+}
+
+func newRateLimitRejectedCounter(method string) metrics.Counter {
+	m := fmt.Sprintf("rpc/ratelimit/rejected/%s", method)
+	return metrics.GetOrRegisterCounter(m, nil)
+}
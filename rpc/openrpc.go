@@ -0,0 +1,308 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	// OpenRPCVersion is the version of the OpenRPC specification that
+	// documents returned by rpc_discover conform to.
+	OpenRPCVersion = "1.2.6"
+
+	openRPCSchemaRefPrefix = "#/components/schemas/"
+)
+
+var (
+	bigIntType          = reflect.TypeOf(big.Int{})
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// OpenRPCDocument describes the methods served by a Server.
+// See https://spec.open-rpc.org for the specification.
+type OpenRPCDocument struct {
+	OpenRPC    string             `json:"openrpc"`
+	Info       OpenRPCInfo        `json:"info"`
+	Methods    []*OpenRPCMethod   `json:"methods"`
+	Components *OpenRPCComponents `json:"components,omitempty"`
+}
+
+// OpenRPCInfo contains metadata about the API.
+type OpenRPCInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// OpenRPCMethod describes a single RPC method.
+type OpenRPCMethod struct {
+	Name           string                      `json:"name"`
+	Description    string                      `json:"description,omitempty"`
+	ParamStructure string                      `json:"paramStructure"`
+	Params         []*OpenRPCContentDescriptor `json:"params"`
+	Result         *OpenRPCContentDescriptor   `json:"result"`
+}
+
+// OpenRPCContentDescriptor describes a parameter or the result of a method.
+type OpenRPCContentDescriptor struct {
+	Name     string         `json:"name"`
+	Required bool           `json:"required,omitempty"`
+	Schema   *OpenRPCSchema `json:"schema"`
+}
+
+// OpenRPCComponents holds the schemas referenced by the methods of a document.
+type OpenRPCComponents struct {
+	Schemas map[string]*OpenRPCSchema `json:"schemas,omitempty"`
+}
+
+// OpenRPCSchema is the subset of JSON Schema used to describe the Go types
+// of method parameters and results.
+type OpenRPCSchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Title                string                    `json:"title,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Enum                 []string                  `json:"enum,omitempty"`
+	Items                *OpenRPCSchema            `json:"items,omitempty"`
+	Properties           map[string]*OpenRPCSchema `json:"properties,omitempty"`
+	AdditionalProperties *OpenRPCSchema            `json:"additionalProperties,omitempty"`
+}
+
+// Discover returns an OpenRPC document describing the methods and
+// subscriptions currently registered on the server.
+func (s *RPCService) Discover() *OpenRPCDocument {
+	return s.server.services.openRPCDocument()
+}
+
+// openRPCDocument generates an OpenRPC document from the registered services.
+// Methods are sorted by name so that the document is deterministic.
+func (r *serviceRegistry) openRPCDocument() *OpenRPCDocument {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	g := &openRPCSchemaGenerator{schemas: make(map[string]*OpenRPCSchema)}
+	doc := &OpenRPCDocument{
+		OpenRPC: OpenRPCVersion,
+		Info: OpenRPCInfo{
+			Title:   "Coreth JSON-RPC API",
+			Version: "1.0",
+		},
+		Methods: make([]*OpenRPCMethod, 0),
+	}
+	for namespace, svc := range r.services {
+		for name, cb := range svc.callbacks {
+			method := namespace + serviceMethodSeparator + name
+			if strings.HasSuffix(method, subscribeMethodSuffix) || strings.HasSuffix(method, unsubscribeMethodSuffix) {
+				continue // shadowed by the subscription methods, see handleCall
+			}
+			doc.Methods = append(doc.Methods, g.method(method, cb))
+		}
+		if len(svc.subscriptions) > 0 {
+			doc.Methods = append(doc.Methods, g.subscribeMethod(namespace, svc.subscriptions), g.unsubscribeMethod(namespace))
+		}
+	}
+	sort.Slice(doc.Methods, func(i, j int) bool {
+		return doc.Methods[i].Name < doc.Methods[j].Name
+	})
+	if len(g.schemas) > 0 {
+		doc.Components = &OpenRPCComponents{Schemas: g.schemas}
+	}
+	return doc
+}
+
+// openRPCSchemaGenerator derives JSON schemas from Go types. Named struct types
+// are stored once in [schemas] and referenced from every place they are used.
+type openRPCSchemaGenerator struct {
+	schemas map[string]*OpenRPCSchema
+}
+
+// method describes the callback [cb] served under [name].
+func (g *openRPCSchemaGenerator) method(name string, cb *callback) *OpenRPCMethod {
+	m := &OpenRPCMethod{
+		Name:           name,
+		ParamStructure: "by-position",
+		Params:         make([]*OpenRPCContentDescriptor, len(cb.argTypes)),
+		Result:         &OpenRPCContentDescriptor{Name: "result", Schema: &OpenRPCSchema{Type: "null"}},
+	}
+	for i, argType := range cb.argTypes {
+		m.Params[i] = &OpenRPCContentDescriptor{
+			Name: fmt.Sprintf("arg%d", i),
+			// Missing trailing arguments are only accepted for pointer types,
+			// see parsePositionalArguments.
+			Required: argType.Kind() != reflect.Ptr,
+			Schema:   g.schema(argType),
+		}
+	}
+	if fntype := cb.fn.Type(); cb.errPos != 0 && fntype.NumOut() > 0 {
+		m.Result.Schema = g.schema(fntype.Out(0))
+	}
+	return m
+}
+
+// subscribeMethod describes the subscribe method of [namespace]. The first
+// parameter selects one of the [subscriptions] of the namespace.
+func (g *openRPCSchemaGenerator) subscribeMethod(namespace string, subscriptions map[string]*callback) *OpenRPCMethod {
+	names := make([]string, 0, len(subscriptions))
+	for name := range subscriptions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return &OpenRPCMethod{
+		Name:           namespace + subscribeMethodSuffix,
+		Description:    "Subscribes to notifications. The arguments following the subscription name depend on the subscription.",
+		ParamStructure: "by-position",
+		Params: []*OpenRPCContentDescriptor{{
+			Name:     "subscription",
+			Required: true,
+			Schema:   &OpenRPCSchema{Type: "string", Enum: names},
+		}},
+		Result: &OpenRPCContentDescriptor{Name: "subscriptionID", Schema: &OpenRPCSchema{Type: "string"}},
+	}
+}
+
+// unsubscribeMethod describes the unsubscribe method of [namespace].
+func (g *openRPCSchemaGenerator) unsubscribeMethod(namespace string) *OpenRPCMethod {
+	return &OpenRPCMethod{
+		Name:           namespace + unsubscribeMethodSuffix,
+		Description:    "Cancels a subscription.",
+		ParamStructure: "by-position",
+		Params: []*OpenRPCContentDescriptor{{
+			Name:     "subscriptionID",
+			Required: true,
+			Schema:   &OpenRPCSchema{Type: "string"},
+		}},
+		Result: &OpenRPCContentDescriptor{Name: "result", Schema: &OpenRPCSchema{Type: "boolean"}},
+	}
+}
+
+// schema returns the JSON schema of values of type [t] as encoded by
+// encoding/json.
+func (g *openRPCSchemaGenerator) schema(t reflect.Type) *OpenRPCSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == bigIntType:
+		return &OpenRPCSchema{Type: "integer"}
+	case implements(t, textMarshalerType) && !implements(t, jsonMarshalerType),
+		implements(t, textUnmarshalerType) && !implements(t, jsonUnmarshalerType):
+		// Hashes, addresses and hex encoded numbers are all encoded as strings.
+		return &OpenRPCSchema{Title: t.Name(), Type: "string"}
+	case implements(t, jsonMarshalerType) || implements(t, jsonUnmarshalerType):
+		// The encoding is custom, so the type can only be described if its
+		// fields are visible.
+		if t.Kind() != reflect.Struct || !hasExportedFields(t) {
+			return &OpenRPCSchema{Title: t.Name()}
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &OpenRPCSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &OpenRPCSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &OpenRPCSchema{Type: "number"}
+	case reflect.String:
+		return &OpenRPCSchema{Type: "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// Byte slices are base64 encoded.
+			return &OpenRPCSchema{Type: "string"}
+		}
+		return &OpenRPCSchema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Array:
+		return &OpenRPCSchema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &OpenRPCSchema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		key := schemaKey(t)
+		if _, ok := g.schemas[key]; !ok {
+			// Reserve the key before generating the schema so that recursive
+			// types refer back to it instead of recursing forever.
+			g.schemas[key] = nil
+			g.schemas[key] = g.structSchema(t)
+		}
+		return &OpenRPCSchema{Ref: openRPCSchemaRefPrefix + key}
+	default:
+		// Interfaces can hold any value.
+		return &OpenRPCSchema{}
+	}
+}
+
+// structSchema returns the schema of the struct type [t], following the
+// field naming and embedding rules of encoding/json.
+func (g *openRPCSchemaGenerator) structSchema(t reflect.Type) *OpenRPCSchema {
+	s := &OpenRPCSchema{Title: t.Name(), Type: "object", Properties: make(map[string]*OpenRPCSchema)}
+	g.addFields(s, t)
+	return s
+}
+
+func (g *openRPCSchemaGenerator) addFields(s *OpenRPCSchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if idx := strings.Index(tag, ","); idx != -1 {
+			name, opts = tag[:idx], tag[idx+1:]
+		}
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			// Fields of embedded structs are promoted.
+			g.addFields(s, fieldType)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue // field not exported
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if strings.Contains(","+opts+",", ",string,") {
+			s.Properties[name] = &OpenRPCSchema{Type: "string"}
+			continue
+		}
+		s.Properties[name] = g.schema(field.Type)
+	}
+}
+
+// schemaKey returns the component key of the named type [t], which is unique
+// among the types of different packages with the same name. The slashes of
+// the package path are replaced, as component keys may only contain
+// alphanumeric characters, dots, dashes and underscores.
+func schemaKey(t reflect.Type) string {
+	return strings.ReplaceAll(t.PkgPath(), "/", "_") + "." + t.Name()
+}
+
+// implements returns true if [t] or a pointer to [t] implements [iface].
+func implements(t, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PtrTo(t).Implements(iface)
+}
+
+func hasExportedFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).PkgPath == "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import (
+	htmltemplate "html/template"
+	"reflect"
+	"testing"
+	texttemplate "text/template"
+)
+
+// testSchemaKeyPrefix is the prefix of the component keys of the types of
+// this package.
+const testSchemaKeyPrefix = "github.com_sankar-boro_axia-network-v2-coreth_rpc."
+
+func TestSchemaKey(t *testing.T) {
+	// Types with the same name in packages with the same name have different
+	// keys
+	text, html := schemaKey(reflect.TypeOf(texttemplate.Template{})), schemaKey(reflect.TypeOf(htmltemplate.Template{}))
+	if text != "text_template.Template" || html != "html_template.Template" {
+		t.Fatalf("wrong schema keys %q and %q", text, html)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	var doc OpenRPCDocument
+	if err := client.Call(&doc, "rpc_discover"); err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenRPC != OpenRPCVersion {
+		t.Fatalf("wrong openrpc version %q", doc.OpenRPC)
+	}
+
+	methods := make(map[string]*OpenRPCMethod)
+	for i, m := range doc.Methods {
+		if i > 0 && doc.Methods[i-1].Name >= m.Name {
+			t.Fatalf("methods not sorted: %s before %s", doc.Methods[i-1].Name, m.Name)
+		}
+		methods[m.Name] = m
+	}
+	for _, name := range []string{"rpc_discover", "rpc_modules", "test_echo", "nftest_subscribe", "nftest_unsubscribe"} {
+		if methods[name] == nil {
+			t.Fatalf("method %s missing from document", name)
+		}
+	}
+	if methods["test_invalidRets1"] != nil {
+		t.Fatal("unsuitable callback included in document")
+	}
+
+	// test_echo(string, int, *echoArgs) echoResult
+	echo := methods["test_echo"]
+	var (
+		required []bool
+		types    []string
+	)
+	for _, p := range echo.Params {
+		required = append(required, p.Required)
+		types = append(types, p.Schema.Type+p.Schema.Ref)
+	}
+	if want := []bool{true, true, false}; !reflect.DeepEqual(required, want) {
+		t.Fatalf("wrong required params %v, want %v", required, want)
+	}
+	if want := []string{"string", "integer", "#/components/schemas/" + testSchemaKeyPrefix + "echoArgs"}; !reflect.DeepEqual(types, want) {
+		t.Fatalf("wrong param types %v, want %v", types, want)
+	}
+	if ref := echo.Result.Schema.Ref; ref != "#/components/schemas/"+testSchemaKeyPrefix+"echoResult" {
+		t.Fatalf("wrong result schema ref %q", ref)
+	}
+	result := doc.Components.Schemas[testSchemaKeyPrefix+"echoResult"]
+	if result == nil {
+		t.Fatal("echoResult schema missing from components")
+	}
+	if len(result.Properties) != 3 || result.Properties["String"].Type != "string" || result.Properties["Args"].Ref != "#/components/schemas/"+testSchemaKeyPrefix+"echoArgs" {
+		t.Fatalf("wrong echoResult schema %+v", result)
+	}
+
+	// Methods without a result value return null.
+	if typ := methods["test_noArgsRets"].Result.Schema.Type; typ != "null" {
+		t.Fatalf("wrong result type %q for method without result", typ)
+	}
+
+	subscribe := methods["nftest_subscribe"]
+	if want := []string{"hangSubscription", "someSubscription"}; !reflect.DeepEqual(subscribe.Params[0].Schema.Enum, want) {
+		t.Fatalf("wrong subscriptions %v, want %v", subscribe.Params[0].Schema.Enum, want)
+	}
+}
+
+func TestOpenRPCSchema(t *testing.T) {
+	type inner struct {
+		Value uint64 `json:"value,string"`
+	}
+	type recursive struct {
+		Next *recursive `json:"next"`
+	}
+	type outer struct {
+		inner
+		Name    string            `json:"name,omitempty"`
+		Skipped int               `json:"-"`
+		Data    []byte            `json:"data"`
+		Tags    map[string]string `json:"tags"`
+		Any     interface{}       `json:"any"`
+		Self    *recursive        `json:"self"`
+		ID      ID                `json:"id"`
+		private int
+	}
+
+	g := &openRPCSchemaGenerator{schemas: make(map[string]*OpenRPCSchema)}
+	if ref := g.schema(reflect.TypeOf(&outer{})).Ref; ref != "#/components/schemas/"+testSchemaKeyPrefix+"outer" {
+		t.Fatalf("wrong ref %q", ref)
+	}
+	s := g.schemas[testSchemaKeyPrefix+"outer"]
+	want := map[string]string{
+		"value": "string",
+		"name":  "string",
+		"data":  "string",
+		"tags":  "object",
+		"any":   "",
+		"self":  "",
+		"id":    "string",
+	}
+	if len(s.Properties) != len(want) {
+		t.Fatalf("wrong properties %v", s.Properties)
+	}
+	for name, typ := range want {
+		if s.Properties[name].Type != typ {
+			t.Fatalf("property %s has type %q, want %q", name, s.Properties[name].Type, typ)
+		}
+	}
+	if ref := g.schemas[testSchemaKeyPrefix+"recursive"].Properties["next"].Ref; ref != "#/components/schemas/"+testSchemaKeyPrefix+"recursive" {
+		t.Fatalf("wrong recursive ref %q", ref)
+	}
+}
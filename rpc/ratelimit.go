@@ -0,0 +1,183 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/sankar-boro/axia-network-v2-coreth/metrics"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// defaultRateLimitMaxClients is the number of client buckets tracked when
+	// no explicit limit is configured.
+	defaultRateLimitMaxClients = 4096
+
+	// defaultMethodCost is the number of tokens charged for a method that is
+	// not listed in [RateLimitConfig.MethodCosts].
+	defaultMethodCost = 1
+)
+
+// RateLimitConfig configures the per-client token bucket rate limiting of a
+// Server.
+type RateLimitConfig struct {
+	// RefillRate is the number of tokens added to each client bucket per
+	// second. A value of 0 disables rate limiting.
+	RefillRate float64
+	// MaxStored is the maximum number of tokens a client bucket can hold.
+	MaxStored uint64
+	// MethodCosts maps a method name (e.g. "eth_getLogs") to the number of
+	// tokens charged for each call. Methods that are not present cost
+	// [defaultMethodCost].
+	MethodCosts map[string]uint64
+	// APIKeyHeader is the name of the HTTP header that identifies a client. If
+	// empty, or if a request does not set the header, the client is
+	// identified by its remote IP address.
+	APIKeyHeader string
+	// MaxClients is the maximum number of client buckets to track. The least
+	// recently used bucket is evicted once this limit is reached.
+	MaxClients int
+}
+
+// RateLimiter enforces per-client request quotas. Each client, identified by
+// API key or remote IP, is assigned a token bucket that is refilled at a fixed
+// rate. Every call consumes the configured cost of the called method.
+type RateLimiter struct {
+	refillRate   rate.Limit
+	maxStored    int
+	methodCosts  map[string]uint64
+	apiKeyHeader string
+	clients      *lru.Cache // client ID -> *rate.Limiter
+}
+
+// NewRateLimiter returns a RateLimiter configured by [config]. If [config]
+// disables rate limiting, nil is returned.
+func NewRateLimiter(config RateLimitConfig) (*RateLimiter, error) {
+	if config.RefillRate <= 0 {
+		return nil, nil
+	}
+	if config.MaxStored == 0 {
+		return nil, fmt.Errorf("rate limit max stored must be non-zero when refill rate (%f) is set", config.RefillRate)
+	}
+	maxClients := config.MaxClients
+	if maxClients <= 0 {
+		maxClients = defaultRateLimitMaxClients
+	}
+	clients, err := lru.New(maxClients)
+	if err != nil {
+		return nil, err
+	}
+	methodCosts := make(map[string]uint64, len(config.MethodCosts))
+	for method, cost := range config.MethodCosts {
+		methodCosts[method] = cost
+	}
+	return &RateLimiter{
+		refillRate:   rate.Limit(config.RefillRate),
+		maxStored:    int(config.MaxStored),
+		methodCosts:  methodCosts,
+		apiKeyHeader: config.APIKeyHeader,
+		clients:      clients,
+	}, nil
+}
+
+// clientID returns the identifier of the client that sent [r].
+func (rl *RateLimiter) clientID(r *http.Request) string {
+	if rl.apiKeyHeader != "" {
+		if key := r.Header.Get(rl.apiKeyHeader); key != "" {
+			return "key:" + key
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// cost returns the number of tokens charged for a call to [method].
+func (rl *RateLimiter) cost(method string) int {
+	cost, ok := rl.methodCosts[method]
+	if !ok {
+		cost = defaultMethodCost
+	}
+	// A call must always be satisfiable by a full bucket.
+	if cost > uint64(rl.maxStored) {
+		return rl.maxStored
+	}
+	return int(cost)
+}
+
+// limiter returns the token bucket of [clientID], creating it if needed.
+func (rl *RateLimiter) limiter(clientID string) *rate.Limiter {
+	if l, ok := rl.clients.Get(clientID); ok {
+		return l.(*rate.Limiter)
+	}
+	l := rate.NewLimiter(rl.refillRate, rl.maxStored)
+	// If another goroutine added the bucket concurrently, prefer theirs.
+	if existing, ok, _ := rl.clients.PeekOrAdd(clientID, l); ok {
+		return existing.(*rate.Limiter)
+	}
+	rateLimitClientsGauge.Update(int64(rl.clients.Len()))
+	return l
+}
+
+// take charges [clientID] for a call to [method]. A non-nil error is returned
+// if the client has exhausted its quota.
+func (rl *RateLimiter) take(clientID string, method string) error {
+	cost := rl.cost(method)
+	if !rl.limiter(clientID).AllowN(time.Now(), cost) {
+		rateLimitRejectedCounter.Inc(1)
+		if metrics.EnabledExpensive {
+			newRateLimitRejectedCounter(method).Inc(1)
+		}
+		return &rateLimitedError{method: method}
+	}
+	rateLimitAllowedCounter.Inc(1)
+	rateLimitTokensCounter.Inc(int64(cost))
+	return nil
+}
+
+// Take charges the client that sent [r] for a call to [method], so that the
+// requests served outside of a Server, such as GraphQL queries, share the
+// quotas of the RPC clients. A non-nil error is returned if the client has
+// exhausted its quota. It is safe to call on a nil RateLimiter, in which case
+// no limit is enforced.
+func (rl *RateLimiter) Take(r *http.Request, method string) error {
+	if rl == nil {
+		return nil
+	}
+	return rl.take(rl.clientID(r), method)
+}
+
+// clientQuota binds a RateLimiter to a single client.
+type clientQuota struct {
+	limiter  *RateLimiter
+	clientID string
+}
+
+// newClientQuota returns the quota of the client that sent [r], or nil if
+// [rl] is nil.
+func (rl *RateLimiter) newClientQuota(r *http.Request) *clientQuota {
+	if rl == nil {
+		return nil
+	}
+	return &clientQuota{
+		limiter:  rl,
+		clientID: rl.clientID(r),
+	}
+}
+
+// take charges the client for a call to [method]. It is safe to call on a nil
+// quota, in which case no limit is enforced.
+func (q *clientQuota) take(method string) error {
+	if q == nil {
+		return nil
+	}
+	return q.limiter.take(q.clientID, method)
+}
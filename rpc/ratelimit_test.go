@@ -0,0 +1,140 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newRateLimitedTestServer(t *testing.T, config RateLimitConfig) *httptest.Server {
+	t.Helper()
+
+	limiter, err := NewRateLimiter(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newTestServer()
+	server.SetRateLimiter(limiter)
+	t.Cleanup(server.Stop)
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func dialTestClient(t *testing.T, url, apiKey string) *Client {
+	t.Helper()
+
+	c, err := DialHTTP(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if apiKey != "" {
+		c.SetHeader("X-API-Key", apiKey)
+	}
+	t.Cleanup(c.Close)
+	return c
+}
+
+func assertRateLimited(t *testing.T, err error) {
+	t.Helper()
+
+	var rpcErr Error
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+	if code := rpcErr.ErrorCode(); code != -32005 {
+		t.Fatalf("expected error code -32005, got %d (%v)", code, err)
+	}
+}
+
+func TestNewRateLimiterDisabled(t *testing.T) {
+	limiter, err := NewRateLimiter(RateLimitConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limiter != nil {
+		t.Fatal("expected nil limiter when refill rate is 0")
+	}
+	if _, err := NewRateLimiter(RateLimitConfig{RefillRate: 1}); err == nil {
+		t.Fatal("expected error when max stored is 0")
+	}
+}
+
+func TestRateLimitPerClient(t *testing.T) {
+	ts := newRateLimitedTestServer(t, RateLimitConfig{
+		RefillRate:   0.001, // effectively no refill during the test
+		MaxStored:    2,
+		APIKeyHeader: "X-API-Key",
+	})
+
+	first := dialTestClient(t, ts.URL, "first")
+	for i := 0; i < 2; i++ {
+		if err := first.Call(nil, "test_noArgsRets"); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+	}
+	assertRateLimited(t, first.Call(nil, "test_noArgsRets"))
+
+	// A client with a different API key has its own quota.
+	second := dialTestClient(t, ts.URL, "second")
+	if err := second.Call(nil, "test_noArgsRets"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Clients without an API key are identified by IP address.
+	anonymous := dialTestClient(t, ts.URL, "")
+	if err := anonymous.Call(nil, "test_noArgsRets"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRateLimitMethodCosts(t *testing.T) {
+	ts := newRateLimitedTestServer(t, RateLimitConfig{
+		RefillRate:  0.001,
+		MaxStored:   10,
+		MethodCosts: map[string]uint64{"test_echo": 6},
+	})
+
+	c := dialTestClient(t, ts.URL, "")
+	var res echoResult
+	if err := c.Call(&res, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	assertRateLimited(t, c.Call(&res, "test_echo", "x", 1))
+
+	// The remaining tokens can still pay for cheaper methods.
+	for i := 0; i < 4; i++ {
+		if err := c.Call(nil, "test_noArgsRets"); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+	}
+	assertRateLimited(t, c.Call(nil, "test_noArgsRets"))
+}
+
+func TestRateLimitWebsocket(t *testing.T) {
+	limiter, err := NewRateLimiter(RateLimitConfig{RefillRate: 0.001, MaxStored: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newTestServer()
+	server.SetRateLimiter(limiter)
+	defer server.Stop()
+	ts := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer ts.Close()
+
+	c, err := DialWebsocket(context.Background(), "ws"+strings.TrimPrefix(ts.URL, "http"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if err := c.Call(nil, "test_noArgsRets"); err != nil {
+		t.Fatal(err)
+	}
+	assertRateLimited(t, c.Call(nil, "test_noArgsRets"))
+}
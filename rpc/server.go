@@ -29,6 +29,7 @@ package rpc
 import (
 	"context"
 	"io"
+	"net/http"
 	"sync/atomic"
 	"time"
 
@@ -58,6 +59,11 @@ type Server struct {
 	run             int32
 	codecs          mapset.Set
 	maximumDuration time.Duration
+	rateLimiter     *RateLimiter
+
+	batchItemLimit         int
+	batchResponseSizeLimit int
+	slowRequestThreshold   time.Duration
 }
 
 // NewServer creates a new server instance with no registered handlers.
@@ -87,12 +93,60 @@ func (s *Server) RegisterName(name string, receiver interface{}) error {
 	return s.services.registerName(name, receiver)
 }
 
+// SetRateLimiter limits the requests of each client served by the server
+// according to [limiter]. If [limiter] is nil, requests are not limited.
+//
+// SetRateLimiter must be called before the server starts serving requests.
+func (s *Server) SetRateLimiter(limiter *RateLimiter) {
+	s.rateLimiter = limiter
+}
+
+// SetBatchLimits limits the number of items in a batch to [itemLimit] and the
+// cumulative size in bytes of the responses to a batch to [responseSizeLimit].
+// Once [responseSizeLimit] is exceeded, the remaining items of the batch are
+// answered with an error. A limit of 0 disables the respective check.
+//
+// SetBatchLimits must be called before the server starts serving requests.
+func (s *Server) SetBatchLimits(itemLimit, responseSizeLimit int) {
+	s.batchItemLimit = itemLimit
+	s.batchResponseSizeLimit = responseSizeLimit
+}
+
+// SetSlowRequestThreshold logs every call that takes at least [threshold] to
+// serve, along with its params and the address of the client. A threshold of
+// 0 disables logging slow requests.
+//
+// SetSlowRequestThreshold must be called before the server starts serving
+// requests.
+func (s *Server) SetSlowRequestThreshold(threshold time.Duration) {
+	s.slowRequestThreshold = threshold
+}
+
+// handlerConfig returns the configuration of handlers serving the client that
+// sent [r]. If [r] is nil, no per-client request quota is applied.
+func (s *Server) handlerConfig(r *http.Request) handlerConfig {
+	config := handlerConfig{
+		batchItemLimit:         s.batchItemLimit,
+		batchResponseSizeLimit: s.batchResponseSizeLimit,
+		slowRequestThreshold:   s.slowRequestThreshold,
+	}
+	if r != nil {
+		config.quota = s.rateLimiter.newClientQuota(r)
+	}
+	return config
+}
+
 // ServeCodec reads incoming requests from codec, calls the appropriate callback and writes
 // the response back using the given codec. It will block until the codec is closed or the
 // server is stopped. In either case the codec is closed.
 //
 // Note that codec options are no longer supported.
 func (s *Server) ServeCodec(codec ServerCodec, options CodecOption, apiMaxDuration, refillRate, maxStored time.Duration) {
+	s.serveCodec(codec, apiMaxDuration, refillRate, maxStored, s.handlerConfig(nil))
+}
+
+// serveCodec is ServeCodec with the connection's handler configured by [config].
+func (s *Server) serveCodec(codec ServerCodec, apiMaxDuration, refillRate, maxStored time.Duration, config handlerConfig) {
 	defer codec.close()
 
 	// Don't serve if server is stopped.
@@ -104,15 +158,15 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption, apiMaxDurati
 	s.codecs.Add(codec)
 	defer s.codecs.Remove(codec)
 
-	c := initClient(codec, s.idgen, &s.services, apiMaxDuration, refillRate, maxStored)
+	c := initClient(codec, s.idgen, &s.services, apiMaxDuration, refillRate, maxStored, config)
 	<-codec.closed()
 	c.Close()
 }
 
 // serveSingleRequest reads and processes a single RPC request from the given codec. This
 // is used to serve HTTP connections. Subscriptions and reverse calls are not allowed in
-// this mode.
-func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec) {
+// this mode. The handler serving the request is configured by [config].
+func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec, config handlerConfig) {
 	// Don't serve if server is stopped.
 	if atomic.LoadInt32(&s.run) == 0 {
 		return
@@ -121,6 +175,7 @@ func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec) {
 	h := newHandler(ctx, codec, s.idgen, &s.services)
 	h.deadlineContext = s.maximumDuration
 	h.allowSubscribe = false
+	h.handlerConfig = config
 	defer h.close(io.EOF, nil)
 
 	reqs, batch, err := codec.readBatch()
@@ -33,8 +33,11 @@ import (
 	"net"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/ethereum/go-ethereum/log"
 )
 
 func TestServerRegisterName(t *testing.T) {
@@ -78,17 +81,19 @@ func TestServer(t *testing.T) {
 }
 
 func runTestScript(t *testing.T, file string) {
-	server := newTestServer()
 	content, err := ioutil.ReadFile(file)
 	if err != nil {
 		t.Fatal(err)
 	}
+	runTestScriptWithServer(t, newTestServer(), string(content))
+}
 
+func runTestScriptWithServer(t *testing.T, server *Server, content string) {
 	clientConn, serverConn := net.Pipe()
 	defer clientConn.Close()
 	go server.ServeCodec(NewCodec(serverConn), 0, 0, 0, 0)
 	readbuf := bufio.NewReader(clientConn)
-	for _, line := range strings.Split(string(content), "\n") {
+	for _, line := range strings.Split(content, "\n") {
 		line = strings.TrimSpace(line)
 		switch {
 		case len(line) == 0 || strings.HasPrefix(line, "//"):
@@ -120,6 +125,78 @@ func runTestScript(t *testing.T, file string) {
 	}
 }
 
+func TestServerBatchItemLimit(t *testing.T) {
+	server := newTestServer()
+	server.SetBatchLimits(2, 0)
+	defer server.Stop()
+
+	runTestScriptWithServer(t, server, `
+		--> [{"jsonrpc":"2.0","id":1,"method":"test_noArgsRets"},{"jsonrpc":"2.0","id":2,"method":"test_noArgsRets"}]
+		<-- [{"jsonrpc":"2.0","id":1,"result":null},{"jsonrpc":"2.0","id":2,"result":null}]
+		--> [{"jsonrpc":"2.0","id":3,"method":"test_noArgsRets"},{"jsonrpc":"2.0","id":4,"method":"test_noArgsRets"},{"jsonrpc":"2.0","id":5,"method":"test_noArgsRets"}]
+		<-- [{"jsonrpc":"2.0","id":3,"error":{"code":-32600,"message":"batch too large (3\u003e2)"}}]
+	`)
+}
+
+func TestServerBatchResponseSizeLimit(t *testing.T) {
+	server := newTestServer()
+	if err := server.RegisterName("large", largeRespService{10}); err != nil {
+		t.Fatal(err)
+	}
+	server.SetBatchLimits(0, 20)
+	defer server.Stop()
+
+	// Each response contributes its ID and its 12 byte result to the total
+	// size, so the limit is exceeded by the second response.
+	runTestScriptWithServer(t, server, `
+		--> [{"jsonrpc":"2.0","id":1,"method":"large_largeResp"},{"jsonrpc":"2.0","id":2,"method":"large_largeResp"},{"jsonrpc":"2.0","id":3,"method":"large_largeResp"}]
+		<-- [{"jsonrpc":"2.0","id":1,"result":"xxxxxxxxxx"},{"jsonrpc":"2.0","id":2,"result":"xxxxxxxxxx"},{"jsonrpc":"2.0","id":3,"error":{"code":-32003,"message":"response too large"}}]
+	`)
+}
+
+func TestServerSlowRequestLog(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		records []*log.Record
+	)
+	handler := log.Root().GetHandler()
+	defer log.Root().SetHandler(handler)
+	log.Root().SetHandler(log.FuncHandler(func(r *log.Record) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Msg == "Served slow RPC request" {
+			records = append(records, r)
+		}
+		return nil
+	}))
+
+	server := newTestServer()
+	server.SetSlowRequestThreshold(time.Nanosecond)
+	defer server.Stop()
+
+	longArg := strings.Repeat("x", 2*maxSlowRequestParamsLength)
+	runTestScriptWithServer(t, server, `
+		--> {"jsonrpc":"2.0","id":1,"method":"test_echo","params":["`+longArg+`",1]}
+		<-- {"jsonrpc":"2.0","id":1,"result":{"String":"`+longArg+`","Int":1,"Args":null}}
+	`)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(records) != 1 {
+		t.Fatalf("expected 1 slow request log, got %d", len(records))
+	}
+	ctx := make(map[interface{}]interface{})
+	for i := 0; i+1 < len(records[0].Ctx); i += 2 {
+		ctx[records[0].Ctx[i]] = records[0].Ctx[i+1]
+	}
+	if method := ctx["method"]; method != "test_echo" {
+		t.Fatalf("unexpected method %v", method)
+	}
+	if params := ctx["params"].(string); len(params) != maxSlowRequestParamsLength+len("...") {
+		t.Fatalf("expected params to be truncated, got %d bytes", len(params))
+	}
+}
+
 // // This test checks that responses are delivered for very short-lived connections that
 // // only carry a single request.
 // func TestServerShortLivedConn(t *testing.T) {
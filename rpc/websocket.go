@@ -75,7 +75,7 @@ func (s *Server) WebsocketHandlerWithDuration(allowedOrigins []string, apiMaxDur
 			return
 		}
 		codec := newWebsocketCodec(conn, r.Host, r.Header)
-		s.ServeCodec(codec, 0, apiMaxDuration, refillRate, maxStored)
+		s.serveCodec(codec, apiMaxDuration, refillRate, maxStored, s.handlerConfig(r))
 	})
 }
 
@@ -143,7 +143,7 @@ func parseLeafsResponse(codec codec.Manager, reqIntf message.Request, data []byt
 		return nil, 0, fmt.Errorf("empty key response must include merkle proof")
 	}
 
-	var proof ethdb.Database
+	var proof ethdb.KeyValueStore
 	// Populate proof when ProofKeys are present in the response. Its ok to pass it as nil to the trie.VerifyRangeProof
 	// function as it will assert that all the leaves belonging to the specified root are present.
 	if len(leafsResponse.ProofKeys) > 0 {
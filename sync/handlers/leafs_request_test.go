@@ -620,7 +620,7 @@ func assertRangeProofIsValid(t *testing.T, request *message.LeafsRequest, respon
 		end = response.Keys[len(response.Vals)-1]
 	}
 
-	var proof ethdb.Database
+	var proof ethdb.KeyValueStore
 	if len(response.ProofKeys) > 0 {
 		proof = memorydb.New()
 		defer proof.Close()
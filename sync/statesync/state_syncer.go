@@ -43,6 +43,29 @@ func NewTrieProgress(db ethdb.Batcher, batchSize int, eta *syncETA) *TrieProgres
 	}
 }
 
+// NewPathTrieProgress returns a TrieProgress for the trie of [owner] (the zero
+// hash for the main trie), which writes the trie nodes with the path scheme.
+func NewPathTrieProgress(db ethdb.Batcher, owner common.Hash, batchSize int, eta *syncETA) *TrieProgress {
+	batch := db.NewBatch()
+	return &TrieProgress{
+		batch:     batch,
+		batchSize: batchSize,
+		trie: trie.NewStackTrieWithOwner(owner, func(owner common.Hash, path []byte, _ common.Hash, blob []byte) {
+			rawdb.WriteTrieNodeWithPath(batch, owner, path, blob)
+		}),
+		eta: eta,
+	}
+}
+
+// newTrieProgress returns a TrieProgress for the trie of [owner], writing the
+// trie nodes with the state scheme of [db].
+func newTrieProgress(db ethdb.Database, owner common.Hash, batchSize int, eta *syncETA) *TrieProgress {
+	if rawdb.ReadStateScheme(db) == rawdb.PathScheme {
+		return NewPathTrieProgress(db, owner, batchSize, eta)
+	}
+	return NewTrieProgress(db, batchSize, eta)
+}
+
 type StorageTrieProgress struct {
 	*TrieProgress
 	Account            common.Hash
@@ -102,13 +125,13 @@ func NewEVMStateSyncer(config *EVMStateSyncerConfig) (*stateSyncer, error) {
 	}
 
 	// initialise tries in the progress marker
-	progressMarker.MainTrie = NewTrieProgress(config.DB, config.BatchSize, eta)
+	progressMarker.MainTrie = newTrieProgress(config.DB, common.Hash{}, config.BatchSize, eta)
 	if err := restoreMainTrieProgressFromSnapshot(config.DB, progressMarker.MainTrie); err != nil {
 		return nil, err
 	}
 
 	for _, storageProgress := range progressMarker.StorageTries {
-		storageProgress.TrieProgress = NewTrieProgress(config.DB, config.BatchSize, eta)
+		storageProgress.TrieProgress = newTrieProgress(config.DB, storageProgress.Account, config.BatchSize, eta)
 		// the first account's storage snapshot contains the key/value pairs we need to restore
 		// the stack trie. if other in-progress accounts happen to share the same storage root,
 		// their storage snapshot remains empty until the storage trie is fully synced, then it
@@ -262,7 +285,7 @@ func (s *stateSyncer) createStorageTrieTask(accountHash common.Hash, storageRoot
 	}
 
 	progress := &StorageTrieProgress{
-		TrieProgress: NewTrieProgress(s.db, s.batchSize, s.eta),
+		TrieProgress: newTrieProgress(s.db, accountHash, s.batchSize, s.eta),
 		Account:      accountHash,
 	}
 	s.progressMarker.StorageTries[storageRoot] = progress
@@ -275,7 +298,7 @@ func (s *stateSyncer) createStorageTrieTask(accountHash common.Hash, storageRoot
 		OnSyncFailure: s.onSyncFailure,
 		OnStart: func(common.Hash) (bool, error) {
 			// check if this storage root is on disk
-			storageTrie, err := trie.New(storageRoot, s.trieDB)
+			storageTrie, err := trie.NewWithOwner(accountHash, storageRoot, s.trieDB)
 			if err != nil {
 				return false, nil
 			}
@@ -346,6 +369,19 @@ func (s *stateSyncer) onFinish(root common.Hash) error {
 		); err != nil {
 			return err
 		}
+		// With the path scheme, each account stores its own copy of the
+		// storage trie nodes.
+		if s.trieDB.Scheme() == rawdb.PathScheme {
+			if err := copyStorageTrieNodes(
+				s.db,
+				storageTrieProgress.Account,
+				storageTrieProgress.batch,
+				storageTrieProgress.batchSize,
+				storageTrieProgress.AdditionalAccounts,
+			); err != nil {
+				return err
+			}
+		}
 	}
 	delete(s.progressMarker.StorageTries, root)
 	// clear the progress marker on completion of the trie
@@ -380,6 +416,13 @@ func (s *stateSyncer) checkAllDone() error {
 	if err := mainTrie.batch.Write(); err != nil {
 		return err
 	}
+	// The reverse diffs of the state replaced by the synced state can no
+	// longer be applied with the path scheme.
+	if s.trieDB.Scheme() == rawdb.PathScheme {
+		if err := rawdb.DeleteReverseDiffs(s.db); err != nil {
+			return err
+		}
+	}
 	// remove the main trie storage marker, after which there should be none in the db.
 	return removeInProgressTrie(s.db, mainTrieRoot, common.Hash{})
 }
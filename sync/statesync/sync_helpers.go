@@ -69,6 +69,30 @@ func copyStorageSnapshot(db ethdb.Iteratee, account common.Hash, batch ethdb.Bat
 	return batch.Write()
 }
 
+// copyStorageTrieNodes iterates [db] to find all path scheme trie nodes of the storage trie of [account]
+// and copies them to the storage trie of each account in [dstAccounts].
+// Note: assumes that the storage trie of [account] is already complete on disk.
+func copyStorageTrieNodes(db ethdb.Iteratee, account common.Hash, batch ethdb.Batch, batchSize int, dstAccounts []common.Hash) error {
+	it := rawdb.IterateStorageTrieNodes(db, account)
+	defer it.Release()
+	for it.Next() {
+		path := it.Key()[rawdb.StorageTrieNodePathOffset:]
+		for _, accnt := range dstAccounts {
+			rawdb.WriteStorageTrieNode(batch, accnt, path, it.Value())
+		}
+		if batch.ValueSize() > batchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
 // restoreMainTrieProgressFromSnapshot iterates the account snapshots from [db] and adds
 // full RLP representations as leafs to the stack trie in [tr]. Also sets [tr.startsFrom]
 // to the key that syncing can begin from.
@@ -95,6 +95,13 @@ func waitFor(t *testing.T, result <-chan error, expected error, timeout time.Dur
 	}
 }
 
+// newPathSchemeDB returns a new database storing the trie nodes by path.
+func newPathSchemeDB() ethdb.Database {
+	db := rawdb.NewMemoryDatabase()
+	rawdb.WriteStateScheme(db, rawdb.PathScheme)
+	return db
+}
+
 func TestSimpleSyncCases(t *testing.T) {
 	clientErr := errors.New("dummy client error")
 	tests := map[string]syncTest{
@@ -102,7 +109,7 @@ func TestSimpleSyncCases(t *testing.T) {
 			prepareForTest: func(t *testing.T) (ethdb.Database, *trie.Database, common.Hash) {
 				serverTrieDB := trie.NewDatabase(memorydb.New())
 				root, _ := trie.FillAccounts(t, serverTrieDB, common.Hash{}, 1000, nil)
-				return memorydb.New(), serverTrieDB, root
+				return rawdb.NewMemoryDatabase(), serverTrieDB, root
 			},
 		},
 		"accounts with code": {
@@ -122,14 +129,14 @@ func TestSimpleSyncCases(t *testing.T) {
 					}
 					return account
 				})
-				return memorydb.New(), serverTrieDB, root
+				return rawdb.NewMemoryDatabase(), serverTrieDB, root
 			},
 		},
 		"accounts with code and storage": {
 			prepareForTest: func(t *testing.T) (ethdb.Database, *trie.Database, common.Hash) {
 				serverTrieDB := trie.NewDatabase(memorydb.New())
 				root := fillAccountsWithStorage(t, serverTrieDB, common.Hash{}, 1000)
-				return memorydb.New(), serverTrieDB, root
+				return rawdb.NewMemoryDatabase(), serverTrieDB, root
 			},
 		},
 		"accounts with storage": {
@@ -142,21 +149,41 @@ func TestSimpleSyncCases(t *testing.T) {
 
 					return account
 				})
-				return memorydb.New(), serverTrieDB, root
+				return rawdb.NewMemoryDatabase(), serverTrieDB, root
 			},
 		},
 		"accounts with overlapping storage": {
 			prepareForTest: func(t *testing.T) (ethdb.Database, *trie.Database, common.Hash) {
 				serverTrieDB := trie.NewDatabase(memorydb.New())
 				root, _ := FillAccountsWithOverlappingStorage(t, serverTrieDB, common.Hash{}, 1000, 3)
-				return memorydb.New(), serverTrieDB, root
+				return rawdb.NewMemoryDatabase(), serverTrieDB, root
+			},
+		},
+		"accounts with storage (path scheme)": {
+			prepareForTest: func(t *testing.T) (ethdb.Database, *trie.Database, common.Hash) {
+				serverTrieDB := trie.NewDatabase(memorydb.New())
+				root, _ := trie.FillAccounts(t, serverTrieDB, common.Hash{}, 1000, func(t *testing.T, i int, account types.StateAccount) types.StateAccount {
+					if i%5 == 0 {
+						account.Root, _, _ = trie.GenerateTrie(t, serverTrieDB, 16, common.HashLength)
+					}
+
+					return account
+				})
+				return newPathSchemeDB(), serverTrieDB, root
+			},
+		},
+		"accounts with overlapping storage (path scheme)": {
+			prepareForTest: func(t *testing.T) (ethdb.Database, *trie.Database, common.Hash) {
+				serverTrieDB := trie.NewDatabase(memorydb.New())
+				root, _ := FillAccountsWithOverlappingStorage(t, serverTrieDB, common.Hash{}, 1000, 3)
+				return newPathSchemeDB(), serverTrieDB, root
 			},
 		},
 		"failed to fetch leafs": {
 			prepareForTest: func(t *testing.T) (ethdb.Database, *trie.Database, common.Hash) {
 				serverTrieDB := trie.NewDatabase(memorydb.New())
 				root, _ := trie.FillAccounts(t, serverTrieDB, common.Hash{}, 100, nil)
-				return memorydb.New(), serverTrieDB, root
+				return rawdb.NewMemoryDatabase(), serverTrieDB, root
 			},
 			GetLeafsIntercept: func(_ message.LeafsRequest, _ message.LeafsResponse) (message.LeafsResponse, error) {
 				return message.LeafsResponse{}, clientErr
@@ -167,7 +194,7 @@ func TestSimpleSyncCases(t *testing.T) {
 			prepareForTest: func(t *testing.T) (ethdb.Database, *trie.Database, common.Hash) {
 				serverTrieDB := trie.NewDatabase(memorydb.New())
 				root := fillAccountsWithStorage(t, serverTrieDB, common.Hash{}, 100)
-				return memorydb.New(), serverTrieDB, root
+				return rawdb.NewMemoryDatabase(), serverTrieDB, root
 			},
 			GetCodeIntercept: func(_ []common.Hash, _ [][]byte) ([][]byte, error) {
 				return nil, clientErr
@@ -192,7 +219,7 @@ func TestCancelSync(t *testing.T) {
 	testSync(t, syncTest{
 		ctx: ctx,
 		prepareForTest: func(t *testing.T) (ethdb.Database, *trie.Database, common.Hash) {
-			return memorydb.New(), serverTrieDB, root
+			return rawdb.NewMemoryDatabase(), serverTrieDB, root
 		},
 		expectedError: context.Canceled,
 		GetLeafsIntercept: func(_ message.LeafsRequest, lr message.LeafsResponse) (message.LeafsResponse, error) {
@@ -206,7 +233,7 @@ func TestResumeSyncAccountsTrieInterrupted(t *testing.T) {
 	serverTrieDB := trie.NewDatabase(memorydb.New())
 	root, _ := FillAccountsWithOverlappingStorage(t, serverTrieDB, common.Hash{}, 2000, 3)
 	errInterrupted := errors.New("interrupted sync")
-	clientDB := memorydb.New()
+	clientDB := rawdb.NewMemoryDatabase()
 	accountLeavesRequests := 0
 	testSync(t, syncTest{
 		prepareForTest: func(t *testing.T) (ethdb.Database, *trie.Database, common.Hash) {
@@ -245,7 +272,7 @@ func TestResumeSyncLargeStorageTrieInterrupted(t *testing.T) {
 		return account
 	})
 	errInterrupted := errors.New("interrupted sync")
-	clientDB := memorydb.New()
+	clientDB := rawdb.NewMemoryDatabase()
 	largeStorageRootRequests := 0
 	testSync(t, syncTest{
 		prepareForTest: func(t *testing.T) (ethdb.Database, *trie.Database, common.Hash) {
@@ -289,7 +316,7 @@ func TestResumeSyncToNewRootAfterLargeStorageTrieInterrupted(t *testing.T) {
 		return account
 	})
 	errInterrupted := errors.New("interrupted sync")
-	clientDB := memorydb.New()
+	clientDB := rawdb.NewMemoryDatabase()
 	largeStorageRootRequests := 0
 	testSync(t, syncTest{
 		prepareForTest: func(t *testing.T) (ethdb.Database, *trie.Database, common.Hash) {
@@ -328,7 +355,7 @@ func TestResumeSyncLargeStorageTrieWithConsecutiveDuplicatesInterrupted(t *testi
 		return account
 	})
 	errInterrupted := errors.New("interrupted sync")
-	clientDB := memorydb.New()
+	clientDB := rawdb.NewMemoryDatabase()
 	largeStorageRootRequests := 0
 	testSync(t, syncTest{
 		prepareForTest: func(t *testing.T) (ethdb.Database, *trie.Database, common.Hash) {
@@ -364,7 +391,7 @@ func TestResumeSyncLargeStorageTrieWithSpreadOutDuplicatesInterrupted(t *testing
 		return account
 	})
 	errInterrupted := errors.New("interrupted sync")
-	clientDB := memorydb.New()
+	clientDB := rawdb.NewMemoryDatabase()
 	largeStorageRootRequests := 0
 	testSync(t, syncTest{
 		prepareForTest: func(t *testing.T) (ethdb.Database, *trie.Database, common.Hash) {
@@ -463,7 +490,7 @@ func TestResyncNewRootAfterDeletes(t *testing.T) {
 
 func testSyncerSyncsToNewRoot(t *testing.T, deleteBetweenSyncs func(*testing.T, common.Hash, *trie.Database)) {
 	rand.Seed(1)
-	clientDB := memorydb.New()
+	clientDB := rawdb.NewMemoryDatabase()
 	serverTrieDB := trie.NewDatabase(memorydb.New())
 
 	root1, _ := FillAccountsWithOverlappingStorage(t, serverTrieDB, common.Hash{}, 1000, 3)
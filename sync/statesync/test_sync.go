@@ -73,7 +73,7 @@ func assertDBConsistency(t testing.TB, root common.Hash, serverTrieDB, clientTri
 		storageTrieLeavesCount := 0
 
 		// check storage trie and storage snapshot consistency
-		trie.AssertTrieConsistency(t, acc.Root, serverTrieDB, clientTrieDB, func(key, val []byte) error {
+		trie.AssertStorageTrieConsistency(t, accHash, acc.Root, serverTrieDB, clientTrieDB, func(key, val []byte) error {
 			storageTrieLeavesCount++
 			snapshotVal := rawdb.ReadStorageSnapshot(clientTrieDB.DiskDB(), accHash, common.BytesToHash(key))
 			assert.Equal(t, val, snapshotVal)
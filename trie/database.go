@@ -45,6 +45,7 @@ import (
 
 const (
 	defaultPreimagesLimit = 4 * 1024 * 1024 // 4 MB
+	defaultStateHistory   = 128             // Number of committed states served from reverse diffs by default
 )
 
 var (
@@ -86,6 +87,7 @@ var (
 // independent node access.
 type Database struct {
 	diskdb ethdb.KeyValueStore // Persistent storage for matured trie nodes
+	paths  *pathStore          // Trie nodes stored by owner and path, nil for the hash scheme
 
 	preimagesLock sync.RWMutex           // Used to gate acess to [preimagesSize] and [preimages]
 	preimagesSize common.StorageSize     // Storage size of the preimages cache
@@ -107,6 +109,9 @@ type Database struct {
 
 	dirtiesSize  common.StorageSize // Storage size of the dirty node cache (exc. metadata)
 	childrenSize common.StorageSize // Storage size of the external children tracking
+
+	flushCallbackLock sync.RWMutex      // Used to gate access to [flushCallback]
+	flushCallback     func(common.Hash) // Invoked with every trie node before it is flushed to disk
 }
 
 // rawNode is a simple binary blob used to differentiate between collapsed trie
@@ -294,6 +299,9 @@ func expandNode(hash hashNode, n node) node {
 type Config struct {
 	Cache     int  // Memory allowance (MB) to use for caching trie nodes in memory
 	Preimages bool // Flag whether the preimage of trie key is recorded
+
+	Scheme       string // Scheme of the trie nodes on disk (rawdb.HashScheme or rawdb.PathScheme), defaults to the scheme recorded in the database
+	StateHistory uint64 // Number of most recently committed states served from reverse diffs (path scheme only, defaults to 128)
 }
 
 // NewDatabase creates a new trie database to store ephemeral trie content before
@@ -321,9 +329,33 @@ func NewDatabaseWithConfig(diskdb ethdb.KeyValueStore, config *Config) *Database
 	if config == nil || config.Preimages { // TODO(karalabe): Flip to default off in the future
 		db.preimages = make(map[common.Hash][]byte)
 	}
+	var (
+		scheme  string
+		history uint64
+	)
+	if config != nil {
+		scheme, history = config.Scheme, config.StateHistory
+	}
+	if scheme == "" {
+		scheme = rawdb.ReadStateScheme(diskdb)
+	}
+	if scheme == rawdb.PathScheme {
+		if history == 0 {
+			history = defaultStateHistory
+		}
+		db.paths = newPathStore(diskdb, history)
+	}
 	return db
 }
 
+// Scheme returns the scheme used to store the trie nodes on disk.
+func (db *Database) Scheme() string {
+	if db.paths != nil {
+		return rawdb.PathScheme
+	}
+	return rawdb.HashScheme
+}
+
 // DiskDB retrieves the persistent storage backing the trie database.
 func (db *Database) DiskDB() ethdb.KeyValueStore {
 	return db.diskdb
@@ -408,11 +440,20 @@ func (db *Database) Preimage(hash common.Hash) []byte {
 // RawNode retrieves an encoded cached trie node from memory. If it cannot be found
 // cached, the method queries the persistent database for the content. This function
 // will not return the metaroot.
+//
+// With the path scheme, only the trie nodes in memory and the roots of the
+// account tries can be retrieved from their hash alone.
 func (db *Database) RawNode(h common.Hash) ([]byte, error) {
+	return db.rawNode(common.Hash{}, nil, h)
+}
+
+// rawNode retrieves the encoded trie node with hash [h] at [path] of the trie
+// of [owner] (see RawNode).
+func (db *Database) rawNode(owner common.Hash, path []byte, h common.Hash) ([]byte, error) {
 	if h == (common.Hash{}) {
 		return nil, errors.New("not found")
 	}
-	enc, cn, err := db.node(h)
+	enc, cn, err := db.node(owner, path, h)
 	if err != nil {
 		return nil, err
 	}
@@ -424,8 +465,17 @@ func (db *Database) RawNode(h common.Hash) ([]byte, error) {
 
 // EncodedNode returns a formatted [node] when given a node hash. If no node
 // exists, nil is returned. This function will return the metaroot.
+//
+// With the path scheme, only the trie nodes in memory and the roots of the
+// account tries can be retrieved from their hash alone.
 func (db *Database) EncodedNode(h common.Hash) node {
-	enc, cn, err := db.node(h)
+	return db.encodedNode(common.Hash{}, nil, h)
+}
+
+// encodedNode returns the formatted trie node with hash [h] at [path] of the
+// trie of [owner] (see EncodedNode).
+func (db *Database) encodedNode(owner common.Hash, path []byte, h common.Hash) node {
+	enc, cn, err := db.node(owner, path, h)
 	if err != nil {
 		return nil
 	}
@@ -436,11 +486,12 @@ func (db *Database) EncodedNode(h common.Hash) node {
 }
 
 // node retrieves an encoded cached trie node from memory. If it cannot be found
-// cached, the method queries the persistent database for the content.
+// cached, the method queries the persistent database for the content. The
+// [owner] and [path] of the trie node are only used by the path scheme.
 //
 // We do not return a single node representation to avoid useless
 // encoding/decoding depending on the caller.
-func (db *Database) node(hash common.Hash) ([]byte, *cachedNode, error) {
+func (db *Database) node(owner common.Hash, path []byte, hash common.Hash) ([]byte, *cachedNode, error) {
 	// Retrieve the node from the clean cache if available
 	if db.cleans != nil {
 		if enc := db.cleans.Get(nil, hash[:]); enc != nil {
@@ -462,7 +513,12 @@ func (db *Database) node(hash common.Hash) ([]byte, *cachedNode, error) {
 	memcacheDirtyMissMeter.Mark(1)
 
 	// Content unavailable in memory, attempt to retrieve from disk
-	enc := rawdb.ReadTrieNode(db.diskdb, hash)
+	var enc []byte
+	if db.paths != nil {
+		enc = db.paths.node(owner, path, hash)
+	} else {
+		enc = rawdb.ReadTrieNode(db.diskdb, hash)
+	}
 	if len(enc) != 0 {
 		if db.cleans != nil {
 			db.cleans.Set(hash[:], enc)
@@ -517,6 +573,39 @@ func (db *Database) Reference(child common.Hash, parent common.Hash) {
 	}
 }
 
+// ReferenceRoots adds a reference to each of the roots referenced by the meta
+// root, which prevents their nodes from being garbage collected, and returns
+// them. This includes the roots whose nodes were already flushed to disk by
+// [Cap], as they are still referenced until they are dereferenced. The caller
+// must [Dereference] each of the returned roots when done.
+func (db *Database) ReferenceRoots() []common.Hash {
+	db.dirtiesLock.Lock()
+	defer db.dirtiesLock.Unlock()
+
+	meta := db.dirties[common.Hash{}]
+	roots := make([]common.Hash, 0, len(meta.children))
+	for root := range meta.children {
+		// Roots flushed to disk are no longer cached, but the reference of the
+		// meta root is retained, so that [Dereference] remains balanced
+		if node, ok := db.dirties[root]; ok {
+			node.parents++
+		}
+		meta.children[root]++
+		roots = append(roots, root)
+	}
+	return roots
+}
+
+// SetFlushCallback sets [callback] to be invoked with the hash of every trie
+// node before it is written to disk by [Cap] or [Commit]. A nil [callback]
+// removes the current one.
+func (db *Database) SetFlushCallback(callback func(common.Hash)) {
+	db.flushCallbackLock.Lock()
+	defer db.flushCallbackLock.Unlock()
+
+	db.flushCallback = callback
+}
+
 // Dereference removes an existing reference from a root node.
 func (db *Database) Dereference(root common.Hash) {
 	// Sanity check to ensure that the meta-root is not removed
@@ -650,8 +739,14 @@ type flushItem struct {
 // [ethdb.IdealBatchSize]. This function does not access any variables inside
 // of [Database] and does not need to be synchronized.
 func (db *Database) writeFlushItems(toFlush []flushItem) error {
+	db.flushCallbackLock.RLock()
+	defer db.flushCallbackLock.RUnlock()
+
 	batch := db.diskdb.NewBatch()
 	for _, item := range toFlush {
+		if db.flushCallback != nil {
+			db.flushCallback(item.hash)
+		}
 		rlp := item.node.rlp()
 		item.rlp = rlp
 		rawdb.WriteTrieNode(batch, item.hash, rlp)
@@ -682,6 +777,10 @@ func (db *Database) Cap(limit common.StorageSize) error {
 	if err := db.WritePreimages(defaultPreimagesLimit); err != nil {
 		return err
 	}
+	// Trie nodes stored by path can only be written together with their root
+	if db.paths != nil {
+		return nil
+	}
 
 	// It is important that outside code doesn't see an inconsistent state
 	// (referenced data removed from memory cache during commit but not yet
@@ -759,11 +858,20 @@ func (db *Database) Cap(limit common.StorageSize) error {
 // Commit iterates over all the children of a particular node, writes them out
 // to disk, forcefully tearing down all references in both directions. As a side
 // effect, all pre-images accumulated up to this point are also written.
+//
+// With the path scheme, the state on disk is replaced by the state of [node],
+// keeping a reverse diff to serve the trie nodes of the replaced state.
 func (db *Database) Commit(node common.Hash, report bool, callback func(common.Hash)) error {
 	start := time.Now()
 	if err := db.WritePreimages(0); err != nil {
 		return err
 	}
+	// Trie nodes stored by path are overwritten by each commit, so commits
+	// must not interleave.
+	if db.paths != nil {
+		db.paths.commitLock.Lock()
+		defer db.paths.commitLock.Unlock()
+	}
 
 	// It is important that outside code doesn't see an inconsistent state (referenced
 	// data removed from memory cache during commit but not yet in persistent storage).
@@ -773,14 +881,31 @@ func (db *Database) Commit(node common.Hash, report bool, callback func(common.H
 	nodes, storage := len(db.dirties), db.dirtiesSize
 	toFlush, err := db.commit(node, make([]flushItem, 0, 128), callback)
 	if err != nil {
+		db.dirtiesLock.RUnlock()
 		log.Error("Failed to commit trie from trie database", "err", err)
 		return err
 	}
+	var (
+		diff   *stateDiff
+		parent common.Hash
+	)
+	if db.paths != nil {
+		if diff, parent, err = db.diffPath(node); err != nil {
+			db.dirtiesLock.RUnlock()
+			log.Error("Failed to diff trie against disk state", "root", node, "err", err)
+			return err
+		}
+	}
 	db.dirtiesLock.RUnlock()
 	lockTime := time.Since(lockStart)
 
 	// Write nodes to disk
-	if err := db.writeFlushItems(toFlush); err != nil {
+	if diff != nil {
+		err = db.writePathItems(node, parent, diff, toFlush)
+	} else {
+		err = db.writeFlushItems(toFlush)
+	}
+	if err != nil {
 		return err
 	}
 
@@ -0,0 +1,470 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package trie
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
+	"github.com/sankar-boro/axia-network-v2-coreth/ethdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/metrics"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	pathCommitNodesMeter   = metrics.NewRegisteredMeter("trie/path/commit/nodes", nil)
+	pathCommitDeletesMeter = metrics.NewRegisteredMeter("trie/path/commit/deletes", nil)
+	pathHistoryHitMeter    = metrics.NewRegisteredMeter("trie/path/history/hit", nil)
+)
+
+// reverseDiffNode is the trie node at [Path] of the trie of [Owner] before a
+// state root was committed. An empty [Blob] means that there was no trie node.
+type reverseDiffNode struct {
+	Owner common.Hash
+	Path  []byte
+	Blob  []byte
+}
+
+// reverseDiff holds the trie nodes overwritten by committing [Root] on top of
+// [Parent] with the path scheme. Together with the trie nodes on disk, the
+// reverse diffs committed after a state provide all trie nodes of that state.
+type reverseDiff struct {
+	Root   common.Hash
+	Parent common.Hash
+	Nodes  []reverseDiffNode
+
+	nodes map[string][]byte // Blobs of [Nodes] keyed by owner and path
+}
+
+// index builds the lookup of the trie nodes of the reverse diff.
+func (d *reverseDiff) index() {
+	d.nodes = make(map[string][]byte, len(d.Nodes))
+	for _, n := range d.Nodes {
+		d.nodes[pathKey(n.Owner, n.Path)] = n.Blob
+	}
+}
+
+// pathKey returns the lookup key of the trie node at [path] of the trie of
+// [owner].
+func pathKey(owner common.Hash, path []byte) string {
+	return string(owner[:]) + string(path)
+}
+
+// pathStore stores the trie nodes of a single state on disk keyed by their
+// owner and path. The trie nodes of the states committed before it are served
+// from the most recent reverse diffs.
+type pathStore struct {
+	diskdb ethdb.KeyValueStore
+	limit  uint64 // Maximum number of retained reverse diffs
+
+	commitLock sync.Mutex // Serializes commits, which replace the state on disk
+
+	lock  sync.RWMutex   // Protects [head] and [diffs]
+	head  uint64         // Id of the most recent reverse diff
+	diffs []*reverseDiff // Retained reverse diffs, oldest first
+}
+
+// newPathStore loads the path-based state stored in [diskdb], retaining at
+// most [limit] reverse diffs.
+func newPathStore(diskdb ethdb.KeyValueStore, limit uint64) *pathStore {
+	s := &pathStore{
+		diskdb: diskdb,
+		limit:  limit,
+		head:   rawdb.ReadReverseDiffHead(diskdb),
+	}
+	first := uint64(1)
+	if s.head > limit {
+		first = s.head - limit + 1
+	}
+	for id := first; id <= s.head; id++ {
+		diff := new(reverseDiff)
+		if err := rlp.DecodeBytes(rawdb.ReadReverseDiff(diskdb, id), diff); err != nil {
+			// Only the reverse diffs following a gap lead to the state on disk
+			log.Warn("Failed to load reverse diff", "id", id, "err", err)
+			s.diffs = s.diffs[:0]
+			continue
+		}
+		diff.index()
+		s.diffs = append(s.diffs, diff)
+	}
+	// The reverse diffs do not lead to the state on disk once it has been
+	// replaced by other means than committing, e.g. by state sync.
+	if n := len(s.diffs); n > 0 && s.diffs[n-1].Root != s.diskRoot() {
+		log.Warn("Discarding reverse diffs not matching the state on disk", "diffs", n, "root", s.diskRoot())
+		s.diffs = nil
+	}
+	return s
+}
+
+// diskRoot returns the root of the state on disk.
+func (s *pathStore) diskRoot() common.Hash {
+	blob := rawdb.ReadAccountTrieNode(s.diskdb, nil)
+	if len(blob) == 0 {
+		return emptyRoot
+	}
+	return crypto.Keccak256Hash(blob)
+}
+
+// node retrieves the trie node with [hash] at [path] of the trie of [owner],
+// either from disk or from the retained reverse diffs. It returns nil if the
+// trie node is not available.
+func (s *pathStore) node(owner common.Hash, path []byte, hash common.Hash) []byte {
+	if blob := rawdb.ReadTrieNodeWithPath(s.diskdb, owner, path); len(blob) > 0 && crypto.Keccak256Hash(blob) == hash {
+		return blob
+	}
+	if blob := s.historyNode(owner, path, hash); blob != nil {
+		return blob
+	}
+	// The state on disk may have been replaced through another Database
+	// sharing [diskdb], in which case its reverse diffs are loaded.
+	if s.refresh() {
+		return s.historyNode(owner, path, hash)
+	}
+	return nil
+}
+
+// historyNode retrieves the trie node with [hash] at [path] of the trie of
+// [owner] from the retained reverse diffs, or nil if it is not available.
+func (s *pathStore) historyNode(owner common.Hash, path []byte, hash common.Hash) []byte {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	key := pathKey(owner, path)
+	for i := len(s.diffs) - 1; i >= 0; i-- {
+		if blob := s.diffs[i].nodes[key]; len(blob) > 0 && crypto.Keccak256Hash(blob) == hash {
+			pathHistoryHitMeter.Mark(1)
+			return blob
+		}
+	}
+	return nil
+}
+
+// refresh loads the reverse diffs written to [diskdb] after [head] and reports
+// whether there were any.
+func (s *pathStore) refresh() bool {
+	head := rawdb.ReadReverseDiffHead(s.diskdb)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if head <= s.head {
+		return false
+	}
+	first := s.head + 1
+	if head > s.limit && head-s.limit+1 > first {
+		first = head - s.limit + 1
+		s.diffs = nil
+	}
+	for id := first; id <= head; id++ {
+		diff := new(reverseDiff)
+		if err := rlp.DecodeBytes(rawdb.ReadReverseDiff(s.diskdb, id), diff); err != nil {
+			log.Warn("Failed to load reverse diff", "id", id, "err", err)
+			s.diffs = s.diffs[:0]
+			continue
+		}
+		diff.index()
+		s.diffs = append(s.diffs, diff)
+	}
+	if n := uint64(len(s.diffs)); n > s.limit {
+		s.diffs = s.diffs[n-s.limit:]
+	}
+	s.head = head
+	return true
+}
+
+// commit writes the trie nodes in [nodes], which turn the state [parent] on
+// disk into the state [root], together with the reverse diff undoing them in a
+// single batch.
+func (s *pathStore) commit(root common.Hash, parent common.Hash, nodes []*pathNode) error {
+	diff := &reverseDiff{
+		Root:   root,
+		Parent: parent,
+		Nodes:  make([]reverseDiffNode, 0, len(nodes)),
+	}
+	batch := s.diskdb.NewBatch()
+	for _, n := range nodes {
+		diff.Nodes = append(diff.Nodes, reverseDiffNode{Owner: n.owner, Path: n.path, Blob: n.prev})
+		if len(n.blob) == 0 {
+			rawdb.DeleteTrieNodeWithPath(batch, n.owner, n.path)
+			pathCommitDeletesMeter.Mark(1)
+		} else {
+			rawdb.WriteTrieNodeWithPath(batch, n.owner, n.path, n.blob)
+			pathCommitNodesMeter.Mark(1)
+		}
+	}
+	diff.index()
+	enc, err := rlp.EncodeToBytes(diff)
+	if err != nil {
+		return err
+	}
+
+	// The reverse diff is made available before the trie nodes are
+	// overwritten, so that readers of the parent state never miss a trie node.
+	s.lock.Lock()
+	s.head++
+	rawdb.WriteReverseDiffHead(batch, s.head)
+	if s.limit > 0 {
+		rawdb.WriteReverseDiff(batch, s.head, enc)
+		s.diffs = append(s.diffs, diff)
+		if uint64(len(s.diffs)) > s.limit {
+			s.diffs[0] = nil
+			s.diffs = s.diffs[1:]
+		}
+	}
+	if s.head > s.limit {
+		rawdb.DeleteReverseDiff(batch, s.head-s.limit)
+	}
+	s.lock.Unlock()
+
+	return batch.Write()
+}
+
+// pathNode is a trie node written by a commit with the path scheme.
+type pathNode struct {
+	owner common.Hash
+	path  []byte
+	blob  []byte // Trie node after the commit, nil if it is deleted
+	prev  []byte // Trie node before the commit, nil if there was none
+}
+
+// stateDiff collects the trie node writes turning the state on disk into
+// another state with the path scheme. The subtries that are unchanged at the
+// same path are skipped, so only the changed trie nodes are visited.
+//
+// The read lock of the dirty cache must be held while using a stateDiff.
+type stateDiff struct {
+	db    *Database
+	nodes map[string]*pathNode
+
+	// The storage tries of the changed accounts are compared once the account
+	// trie has been compared, as the leaf of an account may move.
+	oldAccounts map[common.Hash]common.Hash // Storage roots of changed accounts before the commit
+	newAccounts map[common.Hash]common.Hash // Storage roots of changed accounts after the commit
+}
+
+// diffPath returns the trie node writes turning the state on disk into the
+// state [root], along with the root of the state on disk.
+//
+// It is assumed that the caller holds the read lock of [dirtiesLock].
+func (db *Database) diffPath(root common.Hash) (*stateDiff, common.Hash, error) {
+	d := &stateDiff{
+		db:          db,
+		nodes:       make(map[string]*pathNode),
+		oldAccounts: make(map[common.Hash]common.Hash),
+		newAccounts: make(map[common.Hash]common.Hash),
+	}
+	parent := db.paths.diskRoot()
+	if err := d.diff(common.Hash{}, nil, rootRef(parent), rootRef(root)); err != nil {
+		return nil, common.Hash{}, err
+	}
+	for account, oldRoot := range d.oldAccounts {
+		newRoot := emptyRoot
+		if root, ok := d.newAccounts[account]; ok {
+			newRoot = root
+		}
+		if err := d.diff(account, nil, rootRef(oldRoot), rootRef(newRoot)); err != nil {
+			return nil, common.Hash{}, err
+		}
+	}
+	for account, newRoot := range d.newAccounts {
+		if _, ok := d.oldAccounts[account]; ok {
+			continue
+		}
+		if err := d.diff(account, nil, nil, rootRef(newRoot)); err != nil {
+			return nil, common.Hash{}, err
+		}
+	}
+	return d, parent, nil
+}
+
+// writePathItems writes the trie node writes in [diff], turning the state
+// [parent] on disk into the state [root], and populates the encodings of the
+// committed dirty nodes in [toFlush].
+func (db *Database) writePathItems(root common.Hash, parent common.Hash, diff *stateDiff, toFlush []flushItem) error {
+	for i := range toFlush {
+		toFlush[i].rlp = toFlush[i].node.rlp()
+	}
+	if err := db.paths.commit(root, parent, diff.writes()); err != nil {
+		log.Error("Failed to write trie nodes to disk", "root", root, "err", err)
+		return err
+	}
+	return nil
+}
+
+// rootRef returns the reference to the root node of a trie with [root].
+func rootRef(root common.Hash) node {
+	if root == emptyRoot || root == (common.Hash{}) {
+		return nil
+	}
+	return hashNode(root.Bytes())
+}
+
+// writes returns the trie node writes changing the state on disk, sorted by
+// owner and path.
+func (d *stateDiff) writes() []*pathNode {
+	keys := make([]string, 0, len(d.nodes))
+	for key, n := range d.nodes {
+		if !bytes.Equal(n.blob, n.prev) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	nodes := make([]*pathNode, len(keys))
+	for i, key := range keys {
+		nodes[i] = d.nodes[key]
+	}
+	return nodes
+}
+
+// set records [blob] as the trie node at [path] of the trie of [owner], or
+// the deletion of that trie node if [blob] is nil. A trie node written by the
+// commit is never deleted by it, as a subtrie may be visited both as removed
+// and as added when its parent changes shape.
+func (d *stateDiff) set(owner common.Hash, path []byte, blob []byte) {
+	key := pathKey(owner, path)
+	n, ok := d.nodes[key]
+	if !ok {
+		n = &pathNode{
+			owner: owner,
+			path:  common.CopyBytes(path),
+			prev:  rawdb.ReadTrieNodeWithPath(d.db.paths.diskdb, owner, path),
+		}
+		d.nodes[key] = n
+	}
+	if blob != nil {
+		n.blob = blob
+	}
+}
+
+// resolveOld resolves the trie node with [hash] at [path] of the trie of
+// [owner] in the state on disk.
+func (d *stateDiff) resolveOld(owner common.Hash, path []byte, hash common.Hash) (node, error) {
+	blob := rawdb.ReadTrieNodeWithPath(d.db.paths.diskdb, owner, path)
+	if len(blob) == 0 || crypto.Keccak256Hash(blob) != hash {
+		return nil, &MissingNodeError{NodeHash: hash, Path: path}
+	}
+	return decodeNode(hash[:], blob)
+}
+
+// resolveNew resolves the trie node with [hash] at [path] of the trie of
+// [owner] in the committed state, along with its encoding.
+func (d *stateDiff) resolveNew(owner common.Hash, path []byte, hash common.Hash) (node, []byte, error) {
+	if dirty := d.db.dirties[hash]; dirty != nil {
+		return dirty.obj(hash), dirty.rlp(), nil
+	}
+	if d.db.cleans != nil {
+		if blob := d.db.cleans.Get(nil, hash[:]); len(blob) > 0 {
+			n, err := decodeNode(hash[:], blob)
+			return n, blob, err
+		}
+	}
+	if blob := d.db.paths.node(owner, path, hash); len(blob) > 0 {
+		n, err := decodeNode(hash[:], blob)
+		return n, blob, err
+	}
+	return nil, nil, &MissingNodeError{NodeHash: hash, Path: path}
+}
+
+// diff records the writes turning the subtrie [oldRef] at [path] of the trie
+// of [owner] into the subtrie [newRef]. A reference is either nil, the hash of
+// a trie node stored at [path], or a trie node embedded in its parent.
+func (d *stateDiff) diff(owner common.Hash, path []byte, oldRef, newRef node) error {
+	oldHash, oldStored := oldRef.(hashNode)
+	newHash, newStored := newRef.(hashNode)
+	if oldStored && newStored && bytes.Equal(oldHash, newHash) {
+		return nil
+	}
+	var (
+		oldNode = oldRef
+		newNode = newRef
+		err     error
+	)
+	if oldStored {
+		if oldNode, err = d.resolveOld(owner, path, common.BytesToHash(oldHash)); err != nil {
+			return err
+		}
+		d.set(owner, path, nil)
+	}
+	if newStored {
+		var blob []byte
+		if newNode, blob, err = d.resolveNew(owner, path, common.BytesToHash(newHash)); err != nil {
+			return err
+		}
+		d.set(owner, path, blob)
+	}
+	oldChildren, oldLeaf, oldValue := nodeChildren(oldNode)
+	newChildren, newLeaf, newValue := nodeChildren(newNode)
+	for suffix, child := range oldChildren {
+		if err := d.diff(owner, concat(path, []byte(suffix)...), child, newChildren[suffix]); err != nil {
+			return err
+		}
+	}
+	for suffix, child := range newChildren {
+		if _, ok := oldChildren[suffix]; ok {
+			continue
+		}
+		if err := d.diff(owner, concat(path, []byte(suffix)...), nil, child); err != nil {
+			return err
+		}
+	}
+	// Track the storage roots of the changed accounts
+	if owner != (common.Hash{}) {
+		return nil
+	}
+	if oldValue != nil && (newValue == nil || !bytes.Equal(oldLeaf, newLeaf) || !bytes.Equal(oldValue, newValue)) {
+		if err := trackAccount(d.oldAccounts, concat(path, oldLeaf...), oldValue); err != nil {
+			return err
+		}
+	}
+	if newValue != nil && (oldValue == nil || !bytes.Equal(oldLeaf, newLeaf) || !bytes.Equal(oldValue, newValue)) {
+		if err := trackAccount(d.newAccounts, concat(path, newLeaf...), newValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// trackAccount records the storage root of the account with the hex encoded
+// [key] and the encoding [value] in [accounts].
+func trackAccount(accounts map[common.Hash]common.Hash, key []byte, value valueNode) error {
+	if len(key) != 2*common.HashLength {
+		return fmt.Errorf("invalid account key length %d", len(key))
+	}
+	var account types.StateAccount
+	if err := rlp.DecodeBytes(value, &account); err != nil {
+		return fmt.Errorf("invalid account %x: %w", hexToKeybytes(key), err)
+	}
+	accounts[common.BytesToHash(hexToKeybytes(key))] = account.Root
+	return nil
+}
+
+// nodeChildren returns the children of [n] keyed by their path relative to
+// [n], or the relative key and the value if [n] is a leaf.
+func nodeChildren(n node) (map[string]node, []byte, valueNode) {
+	switch n := n.(type) {
+	case *shortNode:
+		if hasTerm(n.Key) {
+			value, _ := n.Val.(valueNode)
+			return nil, n.Key[:len(n.Key)-1], value
+		}
+		return map[string]node{string(n.Key): n.Val}, nil, nil
+	case *fullNode:
+		children := make(map[string]node)
+		for i := 0; i < 16; i++ {
+			if n.Children[i] != nil {
+				children[string([]byte{byte(i)})] = n.Children[i]
+			}
+		}
+		return children, nil, nil
+	}
+	return nil, nil, nil
+}
@@ -0,0 +1,360 @@
+// (c) 2022, Axia Systems, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package trie
+
+import (
+	"bytes"
+	"math/big"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/sankar-boro/axia-network-v2-coreth/core/rawdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/core/types"
+	"github.com/sankar-boro/axia-network-v2-coreth/ethdb"
+	"github.com/sankar-boro/axia-network-v2-coreth/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// pathTestAccount is an account of the states committed by the path scheme
+// tests.
+type pathTestAccount struct {
+	nonce   uint64
+	root    common.Hash // Storage root, set once the account is committed
+	storage map[common.Hash][]byte
+}
+
+type pathTestState map[common.Hash]*pathTestAccount
+
+// next returns a random modification of [s], creating, updating and deleting
+// accounts and storage slots.
+func (s pathTestState) next(rng *rand.Rand) pathTestState {
+	next := make(pathTestState, len(s))
+	for hash, acc := range s {
+		storage := make(map[common.Hash][]byte, len(acc.storage))
+		for slot, value := range acc.storage {
+			storage[slot] = value
+		}
+		next[hash] = &pathTestAccount{nonce: acc.nonce, root: acc.root, storage: storage}
+	}
+	for i := 0; i < 20; i++ {
+		hash := crypto.Keccak256Hash(big.NewInt(int64(rng.Intn(50))).Bytes())
+		acc, exists := next[hash]
+		if exists && rng.Intn(5) == 0 {
+			delete(next, hash)
+			continue
+		}
+		if !exists {
+			acc = &pathTestAccount{root: emptyRoot, storage: make(map[common.Hash][]byte)}
+			next[hash] = acc
+		}
+		acc.nonce++
+		for j := 0; j < 3; j++ {
+			slot := crypto.Keccak256Hash(big.NewInt(int64(rng.Intn(20))).Bytes())
+			if rng.Intn(4) == 0 {
+				delete(acc.storage, slot)
+			} else {
+				acc.storage[slot] = []byte{byte(rng.Intn(255) + 1), byte(rng.Intn(256))}
+			}
+		}
+	}
+	return next
+}
+
+// commitPathTestState commits the changes from [parent] with [parentRoot] to
+// [state] to [db] and returns the new root.
+func commitPathTestState(t *testing.T, db *Database, parentRoot common.Hash, parent, state pathTestState) common.Hash {
+	t.Helper()
+
+	accountTrie, err := New(parentRoot, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for hash := range parent {
+		if _, ok := state[hash]; !ok {
+			if err := accountTrie.TryDelete(hash[:]); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	for hash, acc := range state {
+		var (
+			parentRoot    = emptyRoot
+			parentStorage map[common.Hash][]byte
+		)
+		if parentAcc, ok := parent[hash]; ok {
+			parentRoot, parentStorage = parentAcc.root, parentAcc.storage
+		}
+		storageTrie, err := NewWithOwner(hash, parentRoot, db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for slot := range parentStorage {
+			if _, ok := acc.storage[slot]; !ok {
+				if err := storageTrie.TryDelete(slot[:]); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		for slot, value := range acc.storage {
+			if err := storageTrie.TryUpdate(slot[:], value); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if acc.root, _, err = storageTrie.Commit(nil); err != nil {
+			t.Fatal(err)
+		}
+		data, err := rlp.EncodeToBytes(&types.StateAccount{
+			Nonce:    acc.nonce,
+			Balance:  new(big.Int),
+			Root:     acc.root,
+			CodeHash: types.EmptyCodeHash[:],
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := accountTrie.TryUpdate(hash[:], data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	root, _, err := accountTrie.Commit(func(_ [][]byte, _ []byte, leaf []byte, parent common.Hash) error {
+		var acc types.StateAccount
+		if err := rlp.DecodeBytes(leaf, &acc); err != nil {
+			return err
+		}
+		if acc.Root != emptyRoot {
+			db.Reference(acc.Root, parent)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Commit(root, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+// checkPathTestState checks that [state] with [root] can be read in full
+// from [db].
+func checkPathTestState(t *testing.T, db *Database, root common.Hash, state pathTestState) {
+	t.Helper()
+
+	accountTrie, err := New(root, db)
+	if err != nil {
+		t.Fatalf("failed to open state %s: %v", root, err)
+	}
+	accounts := 0
+	it := NewIterator(accountTrie.NodeIterator(nil))
+	for it.Next() {
+		accounts++
+		expected, ok := state[common.BytesToHash(it.Key)]
+		if !ok {
+			t.Fatalf("unexpected account %x in state %s", it.Key, root)
+		}
+		var acc types.StateAccount
+		if err := rlp.DecodeBytes(it.Value, &acc); err != nil {
+			t.Fatal(err)
+		}
+		if acc.Nonce != expected.nonce || acc.Root != expected.root {
+			t.Fatalf("account %x mismatch in state %s: have nonce %d root %s, want nonce %d root %s", it.Key, root, acc.Nonce, acc.Root, expected.nonce, expected.root)
+		}
+		storageTrie, err := NewWithOwner(common.BytesToHash(it.Key), acc.Root, db)
+		if err != nil {
+			t.Fatalf("failed to open storage of account %x in state %s: %v", it.Key, root, err)
+		}
+		slots := 0
+		storageIt := NewIterator(storageTrie.NodeIterator(nil))
+		for storageIt.Next() {
+			slots++
+			if value := expected.storage[common.BytesToHash(storageIt.Key)]; !bytes.Equal(value, storageIt.Value) {
+				t.Fatalf("slot %x of account %x mismatch in state %s: have %x, want %x", storageIt.Key, it.Key, root, storageIt.Value, value)
+			}
+		}
+		if storageIt.Err != nil {
+			t.Fatalf("failed to iterate storage of account %x in state %s: %v", it.Key, root, storageIt.Err)
+		}
+		if slots != len(expected.storage) {
+			t.Fatalf("account %x in state %s has %d slots, want %d", it.Key, root, slots, len(expected.storage))
+		}
+	}
+	if it.Err != nil {
+		t.Fatalf("failed to iterate state %s: %v", root, it.Err)
+	}
+	if accounts != len(state) {
+		t.Fatalf("state %s has %d accounts, want %d", root, accounts, len(state))
+	}
+}
+
+// countTrieNodes returns the number of trie nodes of [state] with [root] that
+// are stored on their own.
+func countTrieNodes(t *testing.T, db *Database, root common.Hash, state pathTestState) int {
+	t.Helper()
+
+	accountTrie, err := New(root, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := 0
+	for it := accountTrie.NodeIterator(nil); it.Next(true); {
+		if it.Hash() != (common.Hash{}) {
+			count++
+		}
+	}
+	for hash, acc := range state {
+		storageTrie, err := NewWithOwner(hash, acc.root, db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for it := storageTrie.NodeIterator(nil); it.Next(true); {
+			if it.Hash() != (common.Hash{}) {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// countKeys returns the number of keys in [diskdb] with [prefix].
+func countKeys(diskdb ethdb.Iteratee, prefix []byte) int {
+	it := diskdb.NewIterator(prefix, nil)
+	defer it.Release()
+
+	count := 0
+	for it.Next() {
+		count++
+	}
+	return count
+}
+
+func newPathTestDatabase(diskdb ethdb.KeyValueStore, history uint64) *Database {
+	return NewDatabaseWithConfig(diskdb, &Config{Scheme: rawdb.PathScheme, StateHistory: history})
+}
+
+func TestPathDatabaseCommit(t *testing.T) {
+	const history = 4
+	var (
+		rng    = rand.New(rand.NewSource(1))
+		diskdb = memorydb.New()
+		db     = newPathTestDatabase(diskdb, history)
+		roots  = []common.Hash{emptyRoot}
+		states = []pathTestState{{}}
+	)
+	for i := 0; i < 12; i++ {
+		state := states[len(states)-1].next(rng)
+		roots = append(roots, commitPathTestState(t, db, roots[len(roots)-1], states[len(states)-1], state))
+		states = append(states, state)
+
+		// The trie nodes on disk are overwritten in place, so only the trie
+		// nodes of the most recent state are stored.
+		stored := countKeys(diskdb, rawdb.TrieNodeAccountPrefix) + countKeys(diskdb, rawdb.TrieNodeStoragePrefix)
+		if expected := countTrieNodes(t, db, roots[len(roots)-1], state); stored != expected {
+			t.Fatalf("state %d: %d trie nodes on disk, want %d", i+1, stored, expected)
+		}
+		// The states within the history are served from the reverse diffs
+		for j := len(roots) - 1; j >= 0 && j >= len(roots)-1-history; j-- {
+			checkPathTestState(t, db, roots[j], states[j])
+		}
+		if j := len(roots) - 2 - history; j > 0 {
+			if _, err := New(roots[j], db); err == nil {
+				t.Fatalf("state %d out of history is readable", j)
+			}
+		}
+	}
+	if head := rawdb.ReadReverseDiffHead(diskdb); head != 12 {
+		t.Fatalf("reverse diff head %d, want 12", head)
+	}
+	if diffs := countKeys(diskdb, []byte("D")); diffs != history {
+		t.Fatalf("%d reverse diffs on disk, want %d", diffs, history)
+	}
+
+	// Reopening the database restores the history
+	reopened := newPathTestDatabase(diskdb, history)
+	for j := len(roots) - 1; j >= len(roots)-1-history; j-- {
+		checkPathTestState(t, reopened, roots[j], states[j])
+	}
+}
+
+func TestPathDatabaseSharedDisk(t *testing.T) {
+	var (
+		rng    = rand.New(rand.NewSource(2))
+		diskdb = memorydb.New()
+		writer = newPathTestDatabase(diskdb, 8)
+		state  = pathTestState{}.next(rng)
+		root   = commitPathTestState(t, writer, emptyRoot, pathTestState{}, state)
+		reader = newPathTestDatabase(diskdb, 8)
+	)
+	checkPathTestState(t, reader, root, state)
+
+	// States replaced after the reader was opened remain readable from it
+	next := state.next(rng)
+	nextRoot := commitPathTestState(t, writer, root, state, next)
+	checkPathTestState(t, reader, root, state)
+	checkPathTestState(t, reader, nextRoot, next)
+}
+
+func TestPathDatabaseDiskRootMismatch(t *testing.T) {
+	var (
+		rng    = rand.New(rand.NewSource(3))
+		diskdb = memorydb.New()
+		db     = newPathTestDatabase(diskdb, 8)
+		state  = pathTestState{}.next(rng)
+		root   = commitPathTestState(t, db, emptyRoot, pathTestState{}, state)
+		next   = state.next(rng)
+	)
+	commitPathTestState(t, db, root, state, next)
+
+	// Replace the state on disk without a reverse diff, as done by state sync
+	rawdb.WriteAccountTrieNode(diskdb, nil, []byte{0xc0})
+	if len(newPathTestDatabase(diskdb, 8).paths.diffs) != 0 {
+		t.Fatal("reverse diffs not matching the state on disk were loaded")
+	}
+}
+
+func TestStackTrieWithOwner(t *testing.T) {
+	var (
+		owner  = common.HexToHash("0x01")
+		diskdb = memorydb.New()
+		st     = NewStackTrieWithOwner(owner, func(owner common.Hash, path []byte, _ common.Hash, blob []byte) {
+			rawdb.WriteTrieNodeWithPath(diskdb, owner, path, blob)
+		})
+		expected = newEmpty()
+		acc      = &pathTestAccount{storage: make(map[common.Hash][]byte)}
+	)
+	keys := make([]common.Hash, 0, 500)
+	for i := 0; i < 500; i++ {
+		keys = append(keys, crypto.Keccak256Hash(big.NewInt(int64(i)).Bytes()))
+	}
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i][:], keys[j][:]) < 0 })
+	for i, key := range keys {
+		value := big.NewInt(int64(i + 1)).Bytes()
+		st.Update(key[:], value)
+		expected.Update(key[:], value)
+		acc.storage[key] = value
+	}
+	root, err := st.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != expected.Hash() {
+		t.Fatalf("root mismatch: have %s, want %s", root, expected.Hash())
+	}
+	db := newPathTestDatabase(diskdb, 8)
+	storageTrie, err := NewWithOwner(owner, root, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := 0
+	for it := NewIterator(storageTrie.NodeIterator(nil)); it.Next(); count++ {
+		if !bytes.Equal(it.Value, acc.storage[common.BytesToHash(it.Key)]) {
+			t.Fatalf("value mismatch for key %x", it.Key)
+		}
+	}
+	if count != len(keys) {
+		t.Fatalf("%d keys in stack trie, want %d", count, len(keys))
+	}
+}
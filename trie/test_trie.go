@@ -72,11 +72,17 @@ func FillTrie(t *testing.T, numKeys int, keySize int, testTrie *Trie) ([][]byte,
 // AssertTrieConsistency ensures given trieDB [a] and [b] both have the same
 // non-empty trie at [root]. (all key/value pairs must be equal)
 func AssertTrieConsistency(t testing.TB, root common.Hash, a, b *Database, onLeaf func(key, val []byte) error) {
-	trieA, err := New(root, a)
+	AssertStorageTrieConsistency(t, common.Hash{}, root, a, b, onLeaf)
+}
+
+// AssertStorageTrieConsistency ensures given trieDB [a] and [b] both have the
+// same non-empty trie of [owner] at [root] (see AssertTrieConsistency).
+func AssertStorageTrieConsistency(t testing.TB, owner common.Hash, root common.Hash, a, b *Database, onLeaf func(key, val []byte) error) {
+	trieA, err := NewWithOwner(owner, root, a)
 	if err != nil {
 		t.Fatalf("error creating trieA, root=%s, err=%v", root, err)
 	}
-	trieB, err := New(root, b)
+	trieB, err := NewWithOwner(owner, root, b)
 	if err != nil {
 		t.Fatalf("error creating trieB, root=%s, err=%v", root, err)
 	}